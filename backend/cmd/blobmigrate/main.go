@@ -0,0 +1,127 @@
+package main
+
+import (
+	"backend/config"
+	"backend/internal/adapters/storage"
+	"backend/internal/core/domain"
+	"backend/internal/core/port"
+	"encoding/json"
+	"errors"
+	"flag"
+	"log"
+	"os"
+	"strings"
+)
+
+// Copies uploads between storage backends, e.g. from the local directory into
+// an S3 bucket before switching STORAGE_BACKEND. Blobs already present with the
+// same size are skipped, so an interrupted run can simply be restarted.
+// Usage: go run ./cmd/blobmigrate -from local -to s3 [-prefix media/] [-delete] [-dry-run]
+func main() {
+	from := flag.String("from", "local", "source backend: local or s3")
+	to := flag.String("to", "s3", "destination backend: local or s3")
+	fromDir := flag.String("from-dir", "", "directory of a local source (defaults to STORAGE_LOCAL_DIR)")
+	toDir := flag.String("to-dir", "", "directory of a local destination (defaults to STORAGE_LOCAL_DIR)")
+	prefix := flag.String("prefix", "", "only copy keys starting with this prefix")
+	skip := flag.String("skip", "tus/,temp/", "comma-separated key prefixes to leave behind")
+	deleteSource := flag.Bool("delete", false, "delete each blob from the source once copied")
+	dryRun := flag.Bool("dry-run", false, "report what would be copied without writing anything")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	src := open(*from, *fromDir, cfg)
+	dst := open(*to, *toDir, cfg)
+	if *from == *to && *fromDir == *toDir {
+		log.Fatalf("Source and destination are the same storage")
+	}
+
+	var skipped []string
+	for _, p := range strings.Split(*skip, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			skipped = append(skipped, p)
+		}
+	}
+
+	report := struct {
+		Scanned int      `json:"scanned"`
+		Copied  int      `json:"copied"`
+		Present int      `json:"present"`
+		Skipped int      `json:"skipped"`
+		Deleted int      `json:"deleted"`
+		Bytes   int64    `json:"bytes"`
+		DryRun  bool     `json:"dry_run"`
+		Errors  []string `json:"errors,omitempty"`
+	}{DryRun: *dryRun}
+
+	err = src.List(*prefix, func(info domain.BlobInfo) error {
+		report.Scanned++
+		for _, p := range skipped {
+			if strings.HasPrefix(info.Key, p) {
+				report.Skipped++
+				return nil
+			}
+		}
+
+		existing, err := dst.Stat(info.Key)
+		switch {
+		case err == nil && existing.Size == info.Size:
+			report.Present++
+		case err != nil && !errors.Is(err, domain.ErrBlobNotFound):
+			report.Errors = append(report.Errors, info.Key+": "+err.Error())
+			return nil
+		default:
+			if !*dryRun {
+				if err := copyBlob(src, dst, info); err != nil {
+					report.Errors = append(report.Errors, info.Key+": "+err.Error())
+					return nil
+				}
+			}
+			report.Copied++
+			report.Bytes += info.Size
+		}
+
+		if *deleteSource && !*dryRun {
+			if err := src.Delete(info.Key); err != nil {
+				report.Errors = append(report.Errors, info.Key+": "+err.Error())
+				return nil
+			}
+			report.Deleted++
+		}
+		return nil
+	})
+	if err != nil {
+		log.Fatalf("Failed to list %s storage: %v", *from, err)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(report)
+	if len(report.Errors) > 0 {
+		os.Exit(1)
+	}
+}
+
+func open(backend, dir string, cfg *config.Config) port.BlobStore {
+	c := *cfg
+	if dir != "" {
+		c.StorageLocalDir = dir
+	}
+	store, err := storage.New(backend, &c)
+	if err != nil {
+		log.Fatalf("Failed to open %s storage: %v", backend, err)
+	}
+	return store
+}
+
+func copyBlob(src, dst port.BlobStore, info domain.BlobInfo) error {
+	r, err := src.Get(info.Key)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	return dst.Put(info.Key, r, info.Size, info.ContentType)
+}
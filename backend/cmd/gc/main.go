@@ -0,0 +1,66 @@
+package main
+
+import (
+	"backend/config"
+	"backend/internal/adapters/repository"
+	"backend/internal/adapters/storage"
+	"backend/internal/core/service"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"time"
+)
+
+// Collects uploads no row references, same as the scheduled job of the server.
+// Usage: go run ./cmd/gc [-dry-run] [-grace 24h] [-retention 168h] [-restore key]
+func main() {
+	dryRun := flag.Bool("dry-run", false, "report orphans and reclaimable space without moving or deleting anything")
+	grace := flag.Duration("grace", 0, "keep unreferenced uploads younger than this (defaults to GC_GRACE_HOURS)")
+	retention := flag.Duration("retention", 0, "delete quarantined uploads older than this (defaults to GC_QUARANTINE_DAYS)")
+	restore := flag.String("restore", "", "move a quarantined upload back to its key instead of collecting")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	if *grace == 0 {
+		*grace = time.Duration(cfg.GCGraceHours) * time.Hour
+	}
+	if *retention == 0 {
+		*retention = time.Duration(cfg.GCQuarantineDays) * 24 * time.Hour
+	}
+
+	repo, err := repository.NewSQLiteRepository(cfg.DBUrl)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	blobs, err := storage.New(cfg.StorageBackend, cfg)
+	if err != nil {
+		log.Fatalf("Failed to open %s storage: %v", cfg.StorageBackend, err)
+	}
+
+	media := service.NewMediaService(repo, blobs, time.Duration(cfg.StoragePresignMinutes)*time.Minute)
+	gc := service.NewGCService(repo, media, *grace, *retention)
+
+	if *restore != "" {
+		if err := gc.Restore(*restore); err != nil {
+			log.Fatalf("Failed to restore %s: %v", *restore, err)
+		}
+		log.Printf("Restored %s", *restore)
+		return
+	}
+
+	report, err := gc.Run(*dryRun)
+	if err != nil {
+		log.Fatalf("GC failed: %v", err)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(report)
+	if len(report.Errors) > 0 {
+		os.Exit(1)
+	}
+}
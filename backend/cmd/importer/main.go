@@ -0,0 +1,68 @@
+package main
+
+import (
+	"backend/config"
+	"backend/internal/adapters/repository"
+	"backend/internal/core/service"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Bulk catalog import from the command line, same rules as POST /api/admin/import.
+// Usage: go run ./cmd/importer -file episodes.csv -entity episode [-dry-run]
+func main() {
+	file := flag.String("file", "", "CSV or JSON file to import")
+	format := flag.String("format", "", "csv or json (defaults to the file extension)")
+	entity := flag.String("entity", "", "anime, episode, category, type, season, studio or language (required for CSV)")
+	dryRun := flag.Bool("dry-run", false, "validate and print the diff without writing anything")
+	flag.Parse()
+
+	if *file == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(*file)), ".")
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	repo, err := repository.NewSQLiteRepository(cfg.DBUrl)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		log.Fatalf("Failed to open %s: %v", *file, err)
+	}
+	defer f.Close()
+
+	importService := service.NewImportService(repo, cfg.EmbedHosts)
+	batch, err := importService.Parse(f, *format, strings.ToLower(*entity))
+	if err != nil {
+		log.Fatalf("Failed to parse %s: %v", *file, err)
+	}
+
+	report, err := importService.Import(batch, *dryRun)
+	if err != nil {
+		log.Fatalf("Import failed: %v", err)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(report)
+
+	log.Printf("created=%d updated=%d unchanged=%d failed=%d committed=%v",
+		report.Created, report.Updated, report.Unchanged, report.Failed, report.Committed)
+	if report.Failed > 0 {
+		os.Exit(1)
+	}
+}
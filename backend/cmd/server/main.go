@@ -3,18 +3,27 @@ package main
 import (
 	"backend/config"
 	"backend/internal/adapters/handler"
+	"backend/internal/adapters/mailer"
 	"backend/internal/adapters/repository"
+	"backend/internal/adapters/storage"
+	"backend/internal/core/domain"
 	"backend/internal/core/service"
 	"backend/internal/middleware"
 	"backend/internal/migration"
 	"backend/internal/seeder"
+	"context"
+	"errors"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 func main() {
@@ -44,61 +53,152 @@ func main() {
 	if err := migration.CleanLocalhostURLs(repo.DB()); err != nil {
 		log.Printf("Warning: Failed to clean localhost URLs: %v", err)
 	}
+	if err := migration.SeedWorkflowPermissions(repo.DB()); err != nil {
+		log.Printf("Warning: Failed to seed workflow permissions: %v", err)
+	}
+	if err := migration.Once(repo.DB(), "sync_episode_published", migration.SyncEpisodePublished); err != nil {
+		log.Printf("Warning: Failed to sync episode is_published with the workflow state: %v", err)
+	}
+	if err := migration.Once(repo.DB(), "convert_video_urls", migration.ConvertVideoURLs); err != nil {
+		log.Printf("Warning: Failed to convert legacy video URLs: %v", err)
+	}
+	if err := migration.Once(repo.DB(), "convert_watch_later", migration.ConvertWatchLater); err != nil {
+		log.Printf("Warning: Failed to convert watch later to list entries: %v", err)
+	}
+	if err := migration.BackfillUsernames(repo.DB()); err != nil {
+		log.Printf("Warning: Failed to backfill usernames: %v", err)
+	}
+
+	// Uploads live in the blob store: a local directory or an S3-compatible
+	// bucket shared by every replica
+	blobs, err := storage.New(cfg.StorageBackend, cfg)
+	if err != nil {
+		log.Fatalf("Failed to open %s storage: %v", cfg.StorageBackend, err)
+	}
+	presign := time.Duration(cfg.StoragePresignMinutes) * time.Minute
+
+	// Move files referenced from outside the media store into it, then recount
+	// the references so the store matches the rows. Later writes go through
+	// Track, so this runs once per database.
+	mediaService := service.NewMediaService(repo, blobs, presign)
+	if err := migration.Once(repo.DB(), "import_legacy_media", func(tx *gorm.DB) error {
+		media := service.NewMediaService(repository.WithDB(tx), blobs, presign)
+		n, err := media.ImportLegacy()
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			log.Printf("Imported %d files into the media store", n)
+		}
+		return media.Reconcile()
+	}); err != nil {
+		log.Printf("Warning: Failed to import files into the media store: %v", err)
+	}
 
 	// Services
 	authService := service.NewAuthService(repo, repo, cfg)
-	userService := service.NewUserService(repo, repo)
+	userService := service.NewUserService(repo, repo, mediaService)
 	roleService := service.NewRoleService(repo, repo)
 	permService := service.NewPermissionService(repo)
 	typeService := service.NewTypeService(repo)
 	seasonService := service.NewSeasonService(repo)
 	studioService := service.NewStudioService(repo)
 	languageService := service.NewLanguageService(repo)
-	animeService := service.NewAnimeService(repo)
-	episodeService := service.NewEpisodeService(repo)
-	modelService := service.NewModelService(repo)
+	animeService := service.NewAnimeService(repo, repo, repo, repo, repo, repo, mediaService)
+	episodeService := service.NewEpisodeService(repo, cfg.EmbedHosts, mediaService)
+	imageService := service.NewImageService(repo, mediaService)
+	modelService := service.NewModelService(repo, imageService, mediaService)
 	categoryService := service.NewCategoryService(repo)
 
-	exportService := service.NewExportService(cfg.BlenderPath, cfg.ExportDir, cfg.ExportTimeout)
-	watchLaterService := service.NewWatchLaterService(repo)
+	exportService := service.NewExportService(cfg.BlenderPath, cfg.ExportDir, cfg.ExportTimeout, blobs, presign)
+	animeListService := service.NewAnimeListService(repo)
+	watchLaterService := service.NewWatchLaterService(repo, animeListService)
 	historyService := service.NewHistoryService(repo)
+	importService := service.NewImportService(repo, cfg.EmbedHosts)
+	catalogExportService := service.NewCatalogExportService(repo)
+	listImportService := service.NewListImportService(repo, animeListService)
+	trashService := service.NewTrashService(repo, mediaService, cfg.TrashRetentionDays)
+	gcService := service.NewGCService(repo, mediaService, time.Duration(cfg.GCGraceHours)*time.Hour, time.Duration(cfg.GCQuarantineDays)*24*time.Hour)
+	translationService := service.NewTranslationService(repo, repo)
+	subtitleService := service.NewSubtitleService(repo, blobs)
+	tusService := service.NewTusService(blobs, cfg.TusMaxSizeMB<<20, time.Duration(cfg.TusExpiryHours)*time.Hour)
+	tusService.RegisterTarget(domain.UploadTargetModel, modelService.UploadTarget())
+	tusService.RegisterTarget(domain.UploadTargetImage, imageService.UploadTarget())
+	tusService.RegisterTarget(domain.UploadTargetVideo, service.VideoUploadTarget(blobs))
+	animeMergeService := service.NewAnimeMergeService(repo)
+	watchProgressService := service.NewWatchProgressService(repo, cfg.WatchCompletePercent, time.Duration(cfg.WatchProgressThrottleSeconds)*time.Second)
+	collectionService := service.NewCollectionService(repo)
+	animeFollowService := service.NewAnimeFollowService(repo, mailer.New(cfg), cfg.EpisodeNotifyBatchSize)
+	socialService := service.NewSocialService(repo, time.Duration(cfg.FeedCacheSeconds)*time.Second)
+	profileService := service.NewProfileService(repo, animeListService, imageService)
+	statsService := service.NewStatsService(repo, time.Duration(cfg.StatsCacheSeconds)*time.Second)
+	serverHealthService := service.NewServerHealthService(repo, time.Duration(cfg.ServerCheckTimeoutSeconds)*time.Second, cfg.ServerCheckPerHost)
+
+	// Comments & Notifications Repositories
+	commentRepo := repository.NewCommentRepository(repo.DB())
+	notifRepo := repository.NewNotificationRepository(repo.DB())
+	workflowService := service.NewWorkflowService(repo, notifRepo)
 
 	// Handlers
 	authHandler := handler.NewAuthHandler(authService)
-	userHandler := handler.NewUserHandler(userService)
+	userHandler := handler.NewUserHandler(userService, imageService)
 	roleHandler := handler.NewRoleHandler(roleService)
 	permHandler := handler.NewPermissionHandler(permService)
-	typeHandler := handler.NewTypeHandler(typeService)
-	seasonHandler := handler.NewSeasonHandler(seasonService)
-	studioHandler := handler.NewStudioHandler(studioService)
-	languageHandler := handler.NewLanguageHandler(languageService)
-	animeHandler := handler.NewAnimeHandler(animeService)
-	episodeHandler := handler.NewEpisodeHandler(episodeService)
-	modelHandler := handler.NewModelHandler(modelService)
-	categoryHandler := handler.NewCategoryHandler(categoryService)
+	typeHandler := handler.NewTypeHandler(typeService, translationService)
+	seasonHandler := handler.NewSeasonHandler(seasonService, translationService)
+	studioHandler := handler.NewStudioHandler(studioService, translationService)
+	languageHandler := handler.NewLanguageHandler(languageService, translationService)
+	animeHandler := handler.NewAnimeHandler(animeService, workflowService, translationService, imageService, animeMergeService)
+	episodeHandler := handler.NewEpisodeHandler(episodeService, workflowService, translationService, subtitleService, imageService)
+	modelHandler := handler.NewModelHandler(modelService, imageService)
+	categoryHandler := handler.NewCategoryHandler(categoryService, translationService)
 
 	exportHandler := handler.NewExportHandler(exportService)
-	uploadHandler := handler.NewUploadHandler()
+	uploadHandler := handler.NewUploadHandler(imageService)
 	searchHandler := handler.NewSearchHandler(repo, repo, repo)
 	watchLaterHandler := handler.NewWatchLaterHandler(watchLaterService)
+	animeListHandler := handler.NewAnimeListHandler(animeListService)
 	historyHandler := handler.NewHistoryHandler(historyService)
+	importHandler := handler.NewImportHandler(importService)
+	catalogExportHandler := handler.NewCatalogExportHandler(catalogExportService)
+	listImportHandler := handler.NewListImportHandler(listImportService)
+	trashHandler := handler.NewTrashHandler(trashService)
+	workflowHandler := handler.NewWorkflowHandler(workflowService)
+	translationHandler := handler.NewTranslationHandler(translationService)
+	serverHealthHandler := handler.NewServerHealthHandler(serverHealthService)
+	animeMergeHandler := handler.NewAnimeMergeHandler(animeMergeService)
+	watchProgressHandler := handler.NewWatchProgressHandler(watchProgressService)
+	collectionHandler := handler.NewCollectionHandler(collectionService)
+	animeFollowHandler := handler.NewAnimeFollowHandler(animeFollowService)
+	socialHandler := handler.NewSocialHandler(socialService)
+	profileHandler := handler.NewProfileHandler(profileService)
+	statsHandler := handler.NewStatsHandler(statsService)
+	subtitleHandler := handler.NewSubtitleHandler(subtitleService, workflowService)
+	tusHandler := handler.NewTusHandler(tusService)
 
 	// Comments & Notifications Handlers
-	commentRepo := repository.NewCommentRepository(repo.DB())
-	notifRepo := repository.NewNotificationRepository(repo.DB())
 	commentHandler := handler.NewCommentHandler(commentRepo, notifRepo, historyService)
 	notifHandler := handler.NewNotificationHandler(notifRepo)
 
+	// Background jobs
+	go trashService.RunRetention(24 * time.Hour)
+	go serverHealthService.RunChecks(time.Duration(cfg.ServerCheckIntervalMinutes) * time.Minute)
+	go tusService.RunExpiry(time.Hour)
+	go imageService.RunBackfill(time.Hour)
+	go gcService.RunCollection(time.Duration(cfg.GCIntervalHours) * time.Hour)
+	go animeFollowService.RunDispatch(time.Duration(cfg.EpisodeNotifyIntervalSeconds) * time.Second)
+	go watchProgressService.RunFlush()
+
 	r := gin.Default()
-	r.MaxMultipartMemory = 1024 << 20 // 1GB
+	r.MaxMultipartMemory = 64 << 20 // Larger parts spill to temp files; big files should use /api/uploads/tus
 
 	// CORS Setup - PERMISSIVE MODE (Fix for network access)
 	r.Use(cors.New(cors.Config{
 		// AllowAllOrigins: true, // CANNOT use '*' with AllowCredentials: true
 		AllowOriginFunc:  func(origin string) bool { return true }, // Echoes the exact origin back
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"},
+		AllowHeaders:     append([]string{"Origin", "Content-Type", "Authorization", "If-Match"}, handler.TusHeaders...),
+		ExposeHeaders:    append([]string{"Content-Length", "ETag"}, handler.TusHeaders...),
 		AllowCredentials: true,
 	}))
 
@@ -113,14 +213,16 @@ func main() {
 
 		r.Use(cors.New(cors.Config{
 			AllowOrigins:     allowOrigins,
-			AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+			AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 			AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
 			ExposeHeaders:    []string{"Content-Length"},
 			AllowCredentials: true,
 		}))
 	*/
 
-	r.Static("/uploads", "./uploads")
+	blobHandler := handler.NewBlobHandler(blobs, presign)
+	r.GET("/uploads/*key", blobHandler.Serve)
+	r.HEAD("/uploads/*key", blobHandler.Serve)
 	r.Static("/assets", "./dist/assets")
 	r.Static("/custom-emojis", "./emoji")
 	r.StaticFile("/favicon.ico", "./dist/favicon.ico")
@@ -135,6 +237,7 @@ func main() {
 	})
 
 	api := r.Group("/api")
+	api.Use(middleware.Locale())
 	{
 		auth := api.Group("/auth")
 		{
@@ -155,26 +258,27 @@ func main() {
 		// --- Public Routes (No Auth Required) ---
 		public := api.Group("/")
 		{
-			// General Search
-			public.GET("/search", searchHandler.Search)
+			// Resumable upload capabilities (tus 1.0)
+			public.OPTIONS("/uploads/tus", tusHandler.Options)
 
 			// Anime Public (Read-Only)
 			animes := public.Group("/animes")
 			{
-				animes.GET("", animeHandler.GetAll)
+				animes.GET("", middleware.OptionalAuth(cfg), animeHandler.GetAll)
 				animes.GET("/latest", animeHandler.GetLatest)
 				animes.GET("/type/:type", animeHandler.GetByType)
 				animes.GET("/search", animeHandler.Search)
-				animes.GET("/:id", animeHandler.GetByID)
+				animes.GET("/:id", middleware.OptionalAuth(cfg), animeHandler.GetByID)
 			}
 
 			// Episode Public (Read-Only)
 			episodes := public.Group("/episodes")
 			{
-				episodes.GET("", episodeHandler.GetAll)
+				episodes.GET("", middleware.OptionalAuth(cfg), episodeHandler.GetAll)
 				episodes.GET("/latest", episodeHandler.GetLatest)
 				episodes.GET("/search", episodeHandler.Search)
-				episodes.GET("/:id", episodeHandler.GetByID)
+				episodes.GET("/:id", middleware.OptionalAuth(cfg), episodeHandler.GetByID)
+				episodes.GET("/:id/subtitles", middleware.OptionalAuth(cfg), subtitleHandler.List)
 			}
 
 			// Models Public
@@ -195,6 +299,13 @@ func main() {
 
 			// Public Comments (Read-only)
 			public.GET("/episodes/:id/comments", commentHandler.GetAllByEpisode)
+
+			// Collections: public and unlisted ones are readable by guests
+			public.GET("/collections", collectionHandler.Browse)
+			public.GET("/collections/:id", middleware.OptionalAuth(cfg), collectionHandler.Get)
+
+			// Public profiles, by user ID or username
+			public.GET("/users/:id/profile", middleware.OptionalAuth(cfg), profileHandler.Get)
 		}
 
 		// --- Protected Routes (Auth Required) ---
@@ -211,10 +322,21 @@ func main() {
 			protected.POST("/user/profile/update", userHandler.UpdateProfile)
 
 			// Admin/Protected Routes
-			protected.Group("/users").GET("", userHandler.GetAll).POST("", userHandler.Create).PUT("/:id", userHandler.Update).DELETE("/:id", userHandler.Delete)
+			protected.Group("/users", middleware.RequireRole("admin")).GET("", userHandler.GetAll).POST("", userHandler.Create).PUT("/:id", userHandler.Update).DELETE("/:id", userHandler.Delete)
+
+			// Social graph
+			social := protected.Group("/users/:id")
+			{
+				social.GET("/follow", socialHandler.Status)
+				social.POST("/follow", socialHandler.Follow)
+				social.DELETE("/follow", socialHandler.Unfollow)
+				social.GET("/followers", socialHandler.Followers)
+				social.GET("/following", socialHandler.Following)
+			}
 			protected.Group("/roles").GET("", roleHandler.GetAll).POST("", roleHandler.Create).PUT("/:id", roleHandler.Update).DELETE("/:id", roleHandler.Delete)
 			protected.Group("/permissions").GET("", permHandler.GetAll).POST("", permHandler.Create).PUT("/:id", permHandler.Update).DELETE("/:id", permHandler.Delete)
-			// protected.GET("/search", searchHandler.Search) // Search is public now
+			// General search returns users, roles and permissions, so it is admin only
+			protected.GET("/search", middleware.RequireRole("admin"), searchHandler.Search)
 
 			// Write/Delete Operations for Models
 			models := protected.Group("/models")
@@ -222,6 +344,16 @@ func main() {
 
 			protected.POST("/upload", uploadHandler.UploadFile)
 
+			// Resumable uploads (tus 1.0)
+			tus := protected.Group("/uploads/tus")
+			{
+				tus.POST("", tusHandler.Create)
+				tus.HEAD("/:id", tusHandler.Head)
+				tus.PATCH("/:id", tusHandler.Patch)
+				tus.DELETE("/:id", tusHandler.Delete)
+				tus.GET("/:id", tusHandler.Status)
+			}
+
 			// Write Operations for Metadata
 			protected.Group("/categories").POST("", categoryHandler.Create).PUT("/:id", categoryHandler.Update).DELETE("/:id", categoryHandler.Delete)
 			protected.Group("/types").POST("", typeHandler.Create).PUT("/:id", typeHandler.Update).DELETE("/:id", typeHandler.Delete)
@@ -231,13 +363,37 @@ func main() {
 
 			// Write Operations for Anime
 			animes := protected.Group("/animes")
-			animes.POST("", animeHandler.Create).PUT("/:id", animeHandler.Update).DELETE("/:id", animeHandler.Delete)
+			animes.POST("", animeHandler.Create).PUT("/:id", animeHandler.Update).PATCH("/:id", animeHandler.Patch).DELETE("/:id", animeHandler.Delete)
+			animes.GET("/:id/revisions", animeHandler.Revisions)
+			animes.GET("/:id/revisions/diff", animeHandler.Diff)
+			animes.GET("/:id/revisions/:version", animeHandler.Revision)
+			animes.POST("/:id/revisions/:version/rollback", animeHandler.Rollback)
+			animes.GET("/:id/follow", animeFollowHandler.Status)
+			animes.PUT("/:id/follow", animeFollowHandler.Follow)
+			animes.DELETE("/:id/follow", animeFollowHandler.Unfollow)
 
 			// Write Operations for Episodes
 			episodes := protected.Group("/episodes")
 			episodes.POST("", episodeHandler.Create).PUT("/:id", episodeHandler.Update).DELETE("/:id", episodeHandler.Delete)
+			episodes.GET("/:id/revisions", episodeHandler.Revisions)
+			episodes.GET("/:id/revisions/diff", episodeHandler.Diff)
+			episodes.GET("/:id/revisions/:version", episodeHandler.Revision)
+			episodes.POST("/:id/revisions/:version/rollback", episodeHandler.Rollback)
+			episodes.POST("/:id/servers/:serverId/report", serverHealthHandler.Report)
+			episodes.POST("/:id/subtitles", subtitleHandler.Upload)
+			episodes.PUT("/:id/subtitles/:trackId", subtitleHandler.Update)
+			episodes.DELETE("/:id/subtitles/:trackId", subtitleHandler.Delete)
+			episodes.POST("/:id/subtitles/:trackId/offset", subtitleHandler.Offset)
+
+			// Editorial Workflow
+			workflow := protected.Group("/workflow")
+			{
+				workflow.GET("/review-queue", workflowHandler.ReviewQueue)
+				workflow.GET("/:entity/:id", workflowHandler.Status)
+				workflow.POST("/:entity/:id/transition", workflowHandler.Transition)
+			}
 
-			// Watch Later Routes (Personal)
+			// Watch Later Routes (Personal), kept for older clients; backed by the anime list
 			watchLater := protected.Group("/watch-later")
 			{
 				watchLater.POST("", watchLaterHandler.Toggle)
@@ -264,6 +420,76 @@ func main() {
 			protected.GET("/notifications", notifHandler.GetUserNotifications)
 			protected.POST("/notifications/:id/read", notifHandler.MarkRead)
 			protected.POST("/notifications/read-all", notifHandler.MarkAllRead)
+
+			// Personal Routes
+			me := protected.Group("/me")
+			{
+				me.POST("/import/mal", listImportHandler.ImportMAL)
+				me.POST("/import/anilist", listImportHandler.ImportAniList)
+				me.POST("/import/resolve", listImportHandler.Resolve)
+
+				me.PUT("/progress/:episodeId", watchProgressHandler.Save)
+				me.GET("/progress", watchProgressHandler.ByAnime)
+				me.GET("/continue-watching", watchProgressHandler.ContinueWatching)
+
+				me.GET("/list", animeListHandler.List)
+				me.GET("/list/stats", animeListHandler.Stats)
+				me.GET("/list/:animeId", animeListHandler.Get)
+				me.PUT("/list/:animeId", animeListHandler.Save)
+				me.DELETE("/list/:animeId", animeListHandler.Delete)
+
+				me.GET("/collections", collectionHandler.Mine)
+				me.GET("/follows", animeFollowHandler.List)
+
+				me.GET("/feed", socialHandler.Feed)
+				me.GET("/privacy", socialHandler.Privacy)
+				me.PUT("/privacy", socialHandler.UpdatePrivacy)
+
+				me.GET("/stats", statsHandler.Get)
+			}
+
+			// Collections
+			collections := protected.Group("/collections")
+			{
+				collections.POST("", collectionHandler.Create)
+				collections.PUT("/:id", collectionHandler.Update)
+				collections.DELETE("/:id", collectionHandler.Delete)
+				collections.POST("/:id/slug", collectionHandler.RegenerateSlug)
+				collections.POST("/:id/items", collectionHandler.AddItem)
+				collections.PUT("/:id/items/order", collectionHandler.Reorder)
+				collections.PATCH("/:id/items/:itemId", collectionHandler.UpdateItem)
+				collections.DELETE("/:id/items/:itemId", collectionHandler.RemoveItem)
+				collections.POST("/:id/collaborators", collectionHandler.AddCollaborator)
+				collections.DELETE("/:id/collaborators/:userId", collectionHandler.RemoveCollaborator)
+				collections.POST("/:id/follow", collectionHandler.Follow)
+				collections.DELETE("/:id/follow", collectionHandler.Unfollow)
+				collections.POST("/:id/like", collectionHandler.Like)
+				collections.DELETE("/:id/like", collectionHandler.Unlike)
+			}
+
+			// Admin Routes
+			admin := protected.Group("/admin")
+			admin.Use(middleware.RequireRole("admin"))
+			{
+				admin.POST("/import", importHandler.Import)
+				admin.GET("/export/catalog", catalogExportHandler.Export)
+
+				admin.GET("/trash", trashHandler.List)
+				admin.POST("/trash/:type/:id/restore", trashHandler.Restore)
+				admin.DELETE("/trash/:type/:id", trashHandler.Purge)
+
+				admin.GET("/translations/:entity/:id", translationHandler.Get)
+				admin.PUT("/translations/:entity/:id/:locale", translationHandler.Save)
+				admin.DELETE("/translations/:entity/:id/:locale", translationHandler.Delete)
+
+				admin.GET("/servers/broken", serverHealthHandler.Broken)
+				admin.POST("/servers/:id/check", serverHealthHandler.Recheck)
+				admin.POST("/servers/:id/resolve", serverHealthHandler.Resolve)
+
+				admin.GET("/animes/duplicates", animeMergeHandler.Duplicates)
+				admin.POST("/animes/duplicates/dismiss", animeMergeHandler.Dismiss)
+				admin.POST("/animes/merge", animeMergeHandler.Merge)
+			}
 		}
 	}
 
@@ -278,8 +504,25 @@ func main() {
 		ReadTimeout: 0, WriteTimeout: 0, IdleTimeout: 0,
 	}
 
-	log.Printf("Server running on port %s", cfg.Port)
-	if err := srv.ListenAndServe(); err != nil {
-		log.Fatalf("Server failed: %v", err)
+	go func() {
+		log.Printf("Server running on port %s", cfg.Port)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Server failed: %v", err)
+		}
+	}()
+
+	// On SIGINT/SIGTERM finish the requests in flight, then write the watch
+	// progress still held in memory
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	<-ctx.Done()
+	stop()
+	log.Printf("Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Warning: Failed to finish requests in flight: %v", err)
+	}
+	if err := watchProgressService.Flush(); err != nil {
+		log.Printf("Warning: Failed to flush watch progress: %v", err)
 	}
 }
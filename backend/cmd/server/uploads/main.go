@@ -4,12 +4,14 @@ import (
 	"backend/config"
 	"backend/internal/adapters/handler"
 	"backend/internal/adapters/repository"
+	"backend/internal/adapters/storage"
 	"backend/internal/core/domain"
 	"backend/internal/core/service"
 	"backend/internal/middleware"
 	"log"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -39,26 +41,34 @@ func main() {
 	seedRoles(repo)
 
 	// Services
+	blobs, err := storage.New(cfg.StorageBackend, cfg)
+	if err != nil {
+		log.Fatalf("Failed to open %s storage: %v", cfg.StorageBackend, err)
+	}
+	presign := time.Duration(cfg.StoragePresignMinutes) * time.Minute
+	mediaService := service.NewMediaService(repo, blobs, presign)
 	authService := service.NewAuthService(repo, repo, cfg)
-	userService := service.NewUserService(repo, repo)
+	userService := service.NewUserService(repo, repo, mediaService)
 	roleService := service.NewRoleService(repo, repo) // Now accepts 2 args (roleRepo, permRepo)
 	permService := service.NewPermissionService(repo)
 	typeService := service.NewTypeService(repo)
 	seasonService := service.NewSeasonService(repo)
 	studioService := service.NewStudioService(repo)
 	languageService := service.NewLanguageService(repo)
-	animeService := service.NewAnimeService(repo)
+	animeService := service.NewAnimeService(repo, repo, repo, repo, repo, repo, mediaService)
+	translationService := service.NewTranslationService(repo, repo)
+	imageService := service.NewImageService(repo, mediaService)
 
 	// Handlers
 	authHandler := handler.NewAuthHandler(authService)
-	userHandler := handler.NewUserHandler(userService)
+	userHandler := handler.NewUserHandler(userService, imageService)
 	roleHandler := handler.NewRoleHandler(roleService)
 	permHandler := handler.NewPermissionHandler(permService)
-	typeHandler := handler.NewTypeHandler(typeService)
-	seasonHandler := handler.NewSeasonHandler(seasonService)
-	studioHandler := handler.NewStudioHandler(studioService)
-	languageHandler := handler.NewLanguageHandler(languageService)
-	animeHandler := handler.NewAnimeHandler(animeService)
+	typeHandler := handler.NewTypeHandler(typeService, translationService)
+	seasonHandler := handler.NewSeasonHandler(seasonService, translationService)
+	studioHandler := handler.NewStudioHandler(studioService, translationService)
+	languageHandler := handler.NewLanguageHandler(languageService, translationService)
+	animeHandler := handler.NewAnimeHandler(animeService, service.NewWorkflowService(repo, repository.NewNotificationRepository(repo.DB())), translationService, imageService, service.NewAnimeMergeService(repo))
 
 	r := gin.Default()
 	// Set 1GB limit for multipart forms (default is 32MB)
@@ -79,6 +89,7 @@ func main() {
 	r.Static("/uploads", "./uploads") // Serve uploded files
 
 	api := r.Group("/api")
+	api.Use(middleware.Locale())
 	{
 		auth := api.Group("/auth")
 		{
@@ -128,8 +139,8 @@ func main() {
 			protected.GET("/search", searchHandler.Search)
 
 			// Model Routes
-			modelService := service.NewModelService(repo)
-			modelHandler := handler.NewModelHandler(modelService)
+			modelService := service.NewModelService(repo, imageService, mediaService)
+			modelHandler := handler.NewModelHandler(modelService, imageService)
 
 			models := protected.Group("/models")
 			{
@@ -141,12 +152,12 @@ func main() {
 			}
 
 			// Upload Route
-			uploadHandler := handler.NewUploadHandler()
+			uploadHandler := handler.NewUploadHandler(imageService)
 			protected.POST("/upload", uploadHandler.UploadFile)
 
 			// Category Routes
 			categoryService := service.NewCategoryService(repo)
-			categoryHandler := handler.NewCategoryHandler(categoryService)
+			categoryHandler := handler.NewCategoryHandler(categoryService, translationService)
 
 			categories := protected.Group("/categories")
 			{
@@ -203,8 +214,8 @@ func main() {
 			}
 
 			// Episode Routes
-			episodeService := service.NewEpisodeService(repo)
-			episodeHandler := handler.NewEpisodeHandler(episodeService)
+			episodeService := service.NewEpisodeService(repo, cfg.EmbedHosts, mediaService)
+			episodeHandler := handler.NewEpisodeHandler(episodeService, service.NewWorkflowService(repo, repository.NewNotificationRepository(repo.DB())), translationService, service.NewSubtitleService(repo, blobs), imageService)
 
 			episodes := protected.Group("/episodes")
 			{
@@ -223,7 +234,7 @@ func main() {
 			api.GET("/ai/status/:task_id", aiHandler.CheckStatus)
 
 			// Export Routes (moved out of protected for testing)
-			exportService := service.NewExportService(cfg.BlenderPath, cfg.ExportDir, cfg.ExportTimeout)
+			exportService := service.NewExportService(cfg.BlenderPath, cfg.ExportDir, cfg.ExportTimeout, blobs, presign)
 			exportHandler := handler.NewExportHandler(exportService)
 
 			api.POST("/export/retarget", exportHandler.RetargetAndExport)
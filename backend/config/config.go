@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -14,10 +15,60 @@ type Config struct {
 	RTSecret     string // Refresh Token Secret
 	AllowOrigins string
 	MeshyAPIKey  string
-	// Blender Export Configuration
+	// Blender Export Configuration. ExportDir is Blender's local working
+	// directory; finished exports are published to the blob store.
 	BlenderPath   string
 	ExportTimeout int
 	ExportDir     string
+	// Days soft-deleted rows stay in the trash before being purged (0 keeps them forever)
+	TrashRetentionDays int
+	// Hosts (and their subdomains) allowed for embed video sources; "*" allows any
+	EmbedHosts []string
+	// Episode server health checks: minutes between rounds (0 disables), probe
+	// timeout and concurrent probes per host
+	ServerCheckIntervalMinutes int
+	ServerCheckTimeoutSeconds  int
+	ServerCheckPerHost         int
+	// Resumable (tus) uploads: largest accepted upload and hours an idle or
+	// finished upload is kept. Partial uploads live in the blob store.
+	TusMaxSizeMB   int64
+	TusExpiryHours int
+	// Blob storage for uploads and generated files: "local" keeps them under
+	// StorageLocalDir, "s3" in an S3-compatible bucket shared by every replica.
+	// Downloads from S3 use presigned URLs valid for StoragePresignMinutes.
+	StorageBackend        string
+	StorageLocalDir       string
+	StoragePresignMinutes int
+	S3Endpoint            string
+	S3Bucket              string
+	S3AccessKey           string
+	S3SecretKey           string
+	S3Region              string
+	S3UseSSL              bool
+	S3Prefix              string
+	// Orphaned upload collection: hours between runs (0 disables), hours an
+	// unreferenced upload is kept before quarantine and days it stays quarantined
+	GCIntervalHours  int
+	GCGraceHours     int
+	GCQuarantineDays int
+	// Watch progress: percent watched that marks an episode completed and the
+	// least seconds between two writes of the same user's episode progress
+	WatchCompletePercent         int
+	WatchProgressThrottleSeconds int
+	// Outgoing mail; email notifications are off when SMTPHost is empty
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+	// New-episode notifications: seconds between fan-out rounds (0 disables)
+	// and follows notified per batch
+	EpisodeNotifyIntervalSeconds int
+	EpisodeNotifyBatchSize       int
+	// Seconds a page of a user's activity feed is cached
+	FeedCacheSeconds int
+	// Seconds a user's viewing statistics are cached
+	StatsCacheSeconds int
 }
 
 func LoadConfig() (*Config, error) {
@@ -97,6 +148,108 @@ func LoadConfig() (*Config, error) {
 		exportDir = "uploads/exports"
 	}
 
+	trashRetentionDays := 30
+	if daysStr := os.Getenv("TRASH_RETENTION_DAYS"); daysStr != "" {
+		fmt.Sscanf(daysStr, "%d", &trashRetentionDays)
+	}
+
+	embedHosts := os.Getenv("EMBED_ALLOWED_HOSTS")
+	if embedHosts == "" {
+		embedHosts = "youtube.com,youtube-nocookie.com,player.vimeo.com,dailymotion.com,ok.ru,drive.google.com,mega.nz,streamtape.com,mp4upload.com,vidmoly.to"
+	}
+	var embedHostList []string
+	for _, h := range strings.Split(embedHosts, ",") {
+		if h = strings.ToLower(strings.TrimSpace(h)); h != "" {
+			embedHostList = append(embedHostList, h)
+		}
+	}
+
+	serverCheckInterval := 60
+	if v := os.Getenv("SERVER_CHECK_INTERVAL_MINUTES"); v != "" {
+		fmt.Sscanf(v, "%d", &serverCheckInterval)
+	}
+	serverCheckTimeout := 10
+	if v := os.Getenv("SERVER_CHECK_TIMEOUT_SECONDS"); v != "" {
+		fmt.Sscanf(v, "%d", &serverCheckTimeout)
+	}
+	serverCheckPerHost := 2
+	if v := os.Getenv("SERVER_CHECK_PER_HOST"); v != "" {
+		fmt.Sscanf(v, "%d", &serverCheckPerHost)
+	}
+
+	var tusMaxSizeMB int64 = 4096
+	if v := os.Getenv("TUS_MAX_SIZE_MB"); v != "" {
+		fmt.Sscanf(v, "%d", &tusMaxSizeMB)
+	}
+	tusExpiryHours := 24
+	if v := os.Getenv("TUS_EXPIRY_HOURS"); v != "" {
+		fmt.Sscanf(v, "%d", &tusExpiryHours)
+	}
+
+	storageBackend := strings.ToLower(os.Getenv("STORAGE_BACKEND"))
+	if storageBackend == "" {
+		storageBackend = "local"
+	}
+	storageLocalDir := os.Getenv("STORAGE_LOCAL_DIR")
+	if storageLocalDir == "" {
+		storageLocalDir = "uploads"
+	}
+	storagePresignMinutes := 15
+	if v := os.Getenv("STORAGE_PRESIGN_MINUTES"); v != "" {
+		fmt.Sscanf(v, "%d", &storagePresignMinutes)
+	}
+	gcIntervalHours := 24
+	if v := os.Getenv("GC_INTERVAL_HOURS"); v != "" {
+		fmt.Sscanf(v, "%d", &gcIntervalHours)
+	}
+	gcGraceHours := 24
+	if v := os.Getenv("GC_GRACE_HOURS"); v != "" {
+		fmt.Sscanf(v, "%d", &gcGraceHours)
+	}
+	gcQuarantineDays := 7
+	if v := os.Getenv("GC_QUARANTINE_DAYS"); v != "" {
+		fmt.Sscanf(v, "%d", &gcQuarantineDays)
+	}
+
+	watchCompletePercent := 90
+	if v := os.Getenv("WATCH_COMPLETE_PERCENT"); v != "" {
+		fmt.Sscanf(v, "%d", &watchCompletePercent)
+	}
+	watchProgressThrottle := 15
+	if v := os.Getenv("WATCH_PROGRESS_THROTTLE_SECONDS"); v != "" {
+		fmt.Sscanf(v, "%d", &watchProgressThrottle)
+	}
+
+	smtpPort := 587
+	if v := os.Getenv("SMTP_PORT"); v != "" {
+		fmt.Sscanf(v, "%d", &smtpPort)
+	}
+	smtpFrom := os.Getenv("SMTP_FROM")
+	if smtpFrom == "" {
+		smtpFrom = os.Getenv("SMTP_USERNAME")
+	}
+	episodeNotifyInterval := 30
+	if v := os.Getenv("EPISODE_NOTIFY_INTERVAL_SECONDS"); v != "" {
+		fmt.Sscanf(v, "%d", &episodeNotifyInterval)
+	}
+	episodeNotifyBatch := 500
+	if v := os.Getenv("EPISODE_NOTIFY_BATCH_SIZE"); v != "" {
+		fmt.Sscanf(v, "%d", &episodeNotifyBatch)
+	}
+	feedCacheSeconds := 30
+	if v := os.Getenv("FEED_CACHE_SECONDS"); v != "" {
+		fmt.Sscanf(v, "%d", &feedCacheSeconds)
+	}
+	statsCacheSeconds := 300
+	if v := os.Getenv("STATS_CACHE_SECONDS"); v != "" {
+		fmt.Sscanf(v, "%d", &statsCacheSeconds)
+	}
+
+	s3Region := os.Getenv("S3_REGION")
+	if s3Region == "" {
+		s3Region = "us-east-1"
+	}
+
 	return &Config{
 		Port:          port,
 		DBUrl:         dbUrl,
@@ -107,5 +260,45 @@ func LoadConfig() (*Config, error) {
 		BlenderPath:   blenderPath,
 		ExportTimeout: exportTimeout,
 		ExportDir:     exportDir,
+
+		TrashRetentionDays: trashRetentionDays,
+		EmbedHosts:         embedHostList,
+
+		ServerCheckIntervalMinutes: serverCheckInterval,
+		ServerCheckTimeoutSeconds:  serverCheckTimeout,
+		ServerCheckPerHost:         serverCheckPerHost,
+
+		TusMaxSizeMB:   tusMaxSizeMB,
+		TusExpiryHours: tusExpiryHours,
+
+		StorageBackend:        storageBackend,
+		StorageLocalDir:       storageLocalDir,
+		StoragePresignMinutes: storagePresignMinutes,
+		S3Endpoint:            os.Getenv("S3_ENDPOINT"),
+		S3Bucket:              os.Getenv("S3_BUCKET"),
+		S3AccessKey:           os.Getenv("S3_ACCESS_KEY"),
+		S3SecretKey:           os.Getenv("S3_SECRET_KEY"),
+		S3Region:              s3Region,
+		S3UseSSL:              os.Getenv("S3_USE_SSL") != "false",
+		S3Prefix:              os.Getenv("S3_PREFIX"),
+
+		GCIntervalHours:  gcIntervalHours,
+		GCGraceHours:     gcGraceHours,
+		GCQuarantineDays: gcQuarantineDays,
+
+		WatchCompletePercent:         watchCompletePercent,
+		WatchProgressThrottleSeconds: watchProgressThrottle,
+
+		SMTPHost:     os.Getenv("SMTP_HOST"),
+		SMTPPort:     smtpPort,
+		SMTPUsername: os.Getenv("SMTP_USERNAME"),
+		SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+		SMTPFrom:     smtpFrom,
+
+		EpisodeNotifyIntervalSeconds: episodeNotifyInterval,
+		EpisodeNotifyBatchSize:       episodeNotifyBatch,
+
+		FeedCacheSeconds:  feedCacheSeconds,
+		StatsCacheSeconds: statsCacheSeconds,
 	}, nil
 }
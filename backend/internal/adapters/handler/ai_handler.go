@@ -63,8 +63,7 @@ func (h *AIHandler) Generate3D(c *gin.Context) {
 
 	// Call Service
 	taskID, err := h.aiService.Generate3DModel(tempFilePath, prompt)
-
-	// Optional: defer os.Remove(tempFilePath)
+	os.Remove(tempFilePath) // Sent inline to Meshy, no longer needed
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
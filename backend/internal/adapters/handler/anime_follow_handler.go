@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"backend/internal/core/domain"
+	"backend/internal/core/service"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AnimeFollowHandler struct {
+	service *service.AnimeFollowService
+}
+
+func NewAnimeFollowHandler(service *service.AnimeFollowService) *AnimeFollowHandler {
+	return &AnimeFollowHandler{service: service}
+}
+
+// Status reports whether the caller follows an anime and how many users do
+// GET /api/animes/:id/follow
+func (h *AnimeFollowHandler) Status(c *gin.Context) {
+	animeID, _ := strconv.Atoi(c.Param("id"))
+	status, err := h.service.Status(c.GetUint("user_id"), uint(animeID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// Follow follows an anime to hear about new episodes, in the app (default) or by email.
+// Following again changes the channel.
+// PUT /api/animes/:id/follow {"channel": "in_app"|"email"}
+func (h *AnimeFollowHandler) Follow(c *gin.Context) {
+	animeID, _ := strconv.Atoi(c.Param("id"))
+	var req struct {
+		Channel domain.NotifyChannel `json:"channel"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	status, err := h.service.Follow(c.GetUint("user_id"), uint(animeID), req.Channel)
+	if err != nil {
+		c.JSON(followErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// Unfollow stops new-episode notifications for an anime
+// DELETE /api/animes/:id/follow
+func (h *AnimeFollowHandler) Unfollow(c *gin.Context) {
+	animeID, _ := strconv.Atoi(c.Param("id"))
+	status, err := h.service.Unfollow(c.GetUint("user_id"), uint(animeID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// List returns the anime the caller follows
+// GET /api/me/follows?limit=50&offset=0
+func (h *AnimeFollowHandler) List(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	items, total, err := h.service.List(c.GetUint("user_id"), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items, "total": total})
+}
+
+func followErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrInvalidNotifyChannel), errors.Is(err, domain.ErrEmailUnavailable):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrAnimeNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
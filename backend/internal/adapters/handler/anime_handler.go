@@ -3,6 +3,8 @@ package handler
 import (
 	"backend/internal/core/domain"
 	"backend/internal/core/service"
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
@@ -11,11 +13,37 @@ import (
 )
 
 type AnimeHandler struct {
-	service *service.AnimeService
+	service      *service.AnimeService
+	workflow     *service.WorkflowService
+	translations *service.TranslationService
+	images       *service.ImageService
+	merges       *service.AnimeMergeService
 }
 
-func NewAnimeHandler(service *service.AnimeService) *AnimeHandler {
-	return &AnimeHandler{service: service}
+func NewAnimeHandler(service *service.AnimeService, workflow *service.WorkflowService, translations *service.TranslationService, images *service.ImageService, merges *service.AnimeMergeService) *AnimeHandler {
+	return &AnimeHandler{service: service, workflow: workflow, translations: translations, images: images, merges: merges}
+}
+
+// localize applies the caller's translations and attaches responsive images. It
+// responds with the error and returns false when that fails.
+func (h *AnimeHandler) localize(c *gin.Context, animes []domain.Anime) bool {
+	err := h.translations.LocalizeAnimes(c.GetStringSlice("locales"), animes)
+	if err == nil {
+		ptrs := make([]*domain.Anime, len(animes))
+		for i := range animes {
+			ptrs[i] = &animes[i]
+		}
+		err = h.images.AttachAnimes(ptrs...)
+	}
+	return localized(c, err)
+}
+
+func (h *AnimeHandler) localizeOne(c *gin.Context, anime *domain.Anime) bool {
+	err := h.translations.LocalizeAnime(c.GetStringSlice("locales"), anime)
+	if err == nil {
+		err = h.images.AttachAnimes(anime)
+	}
+	return localized(c, err)
 }
 
 // sanitizeAnime removes the hardcoded localhost:8080 prefix from image URLs
@@ -42,17 +70,20 @@ func (h *AnimeHandler) Create(c *gin.Context) {
 		return
 	}
 
-	createdAnime, err := h.service.Create(&anime)
+	createdAnime, err := h.service.Create(&anime, h.workflow.InitialState(c.GetUint("user_id"), c.GetString("role")), c.GetUint("user_id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(animeErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 	h.sanitizeAnime(createdAnime)
+	setETag(c, createdAnime.Version)
 	c.JSON(http.StatusCreated, createdAnime)
 }
 
+// GetAll lists published anime; staff add ?all=true for every workflow state
+// GET /api/animes
 func (h *AnimeHandler) GetAll(c *gin.Context) {
-	animes, err := h.service.GetAll()
+	animes, err := h.service.GetAll(includeUnpublished(c, h.workflow))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -60,40 +91,179 @@ func (h *AnimeHandler) GetAll(c *gin.Context) {
 	for i := range animes {
 		h.sanitizeAnime(&animes[i])
 	}
+	if !h.localize(c, animes) {
+		return
+	}
 	c.JSON(http.StatusOK, animes)
 }
 
+// GetByID returns a published anime; staff add ?all=true for any workflow state
+// GET /api/animes/:id
 func (h *AnimeHandler) GetByID(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Param("id"))
-	anime, err := h.service.GetByID(uint(id))
+	anime, err := h.service.GetByID(uint(id), includeUnpublished(c, h.workflow))
 	if err != nil {
+		// A merged anime points at the one that absorbed it
+		if redirect, _ := h.merges.Redirect(uint(id), ""); redirect != nil {
+			c.Header("Location", "/api/animes/"+strconv.Itoa(int(redirect.ToID)))
+			c.JSON(http.StatusMovedPermanently, gin.H{"error": "Anime merged", "redirect_to": redirect.ToID})
+			return
+		}
 		c.JSON(http.StatusNotFound, gin.H{"error": "Anime not found"})
 		return
 	}
 	h.sanitizeAnime(anime)
+	if !h.localizeOne(c, anime) {
+		return
+	}
+	setETag(c, anime.Version)
 	c.JSON(http.StatusOK, anime)
 }
 
 func (h *AnimeHandler) Update(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Param("id"))
+	if !canEdit(c, h.workflow, domain.WorkflowEntityAnime, uint(id)) {
+		return
+	}
 	var anime domain.Anime
 	if err := c.ShouldBindJSON(&anime); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 	anime.ID = uint(id)
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+	if version != 0 {
+		anime.Version = version
+	}
 
-	updatedAnime, err := h.service.Update(&anime)
+	updatedAnime, err := h.service.Update(&anime, c.GetUint("user_id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(animeErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 	h.sanitizeAnime(updatedAnime)
+	setETag(c, updatedAnime.Version)
 	c.JSON(http.StatusOK, updatedAnime)
 }
 
+// Patch applies a JSON Merge Patch (application/merge-patch+json) to the anime
+func (h *AnimeHandler) Patch(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	if !canEdit(c, h.workflow, domain.WorkflowEntityAnime, uint(id)) {
+		return
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	updatedAnime, err := h.service.Patch(uint(id), body, version, c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(animeErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.sanitizeAnime(updatedAnime)
+	setETag(c, updatedAnime.Version)
+	c.JSON(http.StatusOK, updatedAnime)
+}
+
+// Revisions lists the edit history of an anime
+func (h *AnimeHandler) Revisions(c *gin.Context) {
+	if !requirePermission(c, h.workflow, domain.PermissionContentSubmit) {
+		return
+	}
+	id, _ := strconv.Atoi(c.Param("id"))
+	revisions, err := h.service.Revisions(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, revisions)
+}
+
+// Revision returns one revision with its snapshot
+func (h *AnimeHandler) Revision(c *gin.Context) {
+	if !requirePermission(c, h.workflow, domain.PermissionContentSubmit) {
+		return
+	}
+	id, _ := strconv.Atoi(c.Param("id"))
+	version, _ := strconv.Atoi(c.Param("version"))
+	revision, err := h.service.Revision(uint(id), version)
+	if err != nil {
+		c.JSON(animeErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, revision)
+}
+
+// Diff compares two revisions: GET /api/animes/:id/revisions/diff?from=1&to=3
+func (h *AnimeHandler) Diff(c *gin.Context) {
+	if !requirePermission(c, h.workflow, domain.PermissionContentSubmit) {
+		return
+	}
+	id, _ := strconv.Atoi(c.Param("id"))
+	from, to, ok := diffVersions(c)
+	if !ok {
+		return
+	}
+	diff, err := h.service.Diff(uint(id), from, to)
+	if err != nil {
+		c.JSON(animeErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, diff)
+}
+
+// Rollback restores an earlier revision as a new one
+func (h *AnimeHandler) Rollback(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	if !canEdit(c, h.workflow, domain.WorkflowEntityAnime, uint(id)) {
+		return
+	}
+	version, _ := strconv.Atoi(c.Param("version"))
+	current, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+	anime, err := h.service.Rollback(uint(id), version, current, c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(animeErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.sanitizeAnime(anime)
+	setETag(c, anime.Version)
+	c.JSON(http.StatusOK, anime)
+}
+
+func animeErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrAnimeNotFound), errors.Is(err, service.ErrRevisionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrVersionConflict):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrVersionRequired):
+		return http.StatusPreconditionRequired
+	case errors.Is(err, service.ErrInvalidReference), errors.Is(err, service.ErrInvalidPatch):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
 func (h *AnimeHandler) Delete(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Param("id"))
+	if !canEdit(c, h.workflow, domain.WorkflowEntityAnime, uint(id)) {
+		return
+	}
 	if err := h.service.Delete(uint(id)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -111,6 +281,9 @@ func (h *AnimeHandler) GetLatest(c *gin.Context) {
 	for i := range animes {
 		h.sanitizeAnime(&animes[i])
 	}
+	if !h.localize(c, animes) {
+		return
+	}
 	c.JSON(http.StatusOK, animes)
 }
 
@@ -125,6 +298,9 @@ func (h *AnimeHandler) GetByType(c *gin.Context) {
 	for i := range animes {
 		h.sanitizeAnime(&animes[i])
 	}
+	if !h.localize(c, animes) {
+		return
+	}
 	c.JSON(http.StatusOK, animes)
 }
 
@@ -143,5 +319,8 @@ func (h *AnimeHandler) Search(c *gin.Context) {
 	for i := range animes {
 		h.sanitizeAnime(&animes[i])
 	}
+	if !h.localize(c, animes) {
+		return
+	}
 	c.JSON(http.StatusOK, animes)
 }
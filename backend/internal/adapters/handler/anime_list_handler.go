@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"backend/internal/core/domain"
+	"backend/internal/core/service"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AnimeListHandler struct {
+	service *service.AnimeListService
+}
+
+func NewAnimeListHandler(service *service.AnimeListService) *AnimeListHandler {
+	return &AnimeListHandler{service: service}
+}
+
+// List returns the caller's anime list
+// GET /api/me/list?status=watching&q=naruto&sort=updated|score|title|started|finished&limit=50&offset=0
+func (h *AnimeListHandler) List(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	filter := domain.AnimeListFilter{
+		Status: domain.ListStatus(c.Query("status")),
+		Query:  c.Query("q"),
+		Sort:   c.Query("sort"),
+		Limit:  limit,
+		Offset: offset,
+	}
+
+	items, total, err := h.service.List(c.GetUint("user_id"), filter)
+	if err != nil {
+		c.JSON(listErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items, "total": total})
+}
+
+// Stats summarizes the caller's anime list
+// GET /api/me/list/stats
+func (h *AnimeListHandler) Stats(c *gin.Context) {
+	stats, err := h.service.Stats(c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// Get returns the caller's entry for an anime
+// GET /api/me/list/:animeId
+func (h *AnimeListHandler) Get(c *gin.Context) {
+	animeID, _ := strconv.Atoi(c.Param("animeId"))
+	entry, err := h.service.Get(c.GetUint("user_id"), uint(animeID))
+	if err != nil {
+		c.JSON(listErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, entry)
+}
+
+// Save adds an anime to the caller's list or updates its entry; omitted fields are kept
+// PUT /api/me/list/:animeId
+func (h *AnimeListHandler) Save(c *gin.Context) {
+	animeID, _ := strconv.Atoi(c.Param("animeId"))
+	var update domain.AnimeListUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entry, created, err := h.service.Save(c.GetUint("user_id"), uint(animeID), update)
+	if err != nil {
+		c.JSON(listErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.JSON(status, entry)
+}
+
+// Delete removes an anime from the caller's list
+// DELETE /api/me/list/:animeId
+func (h *AnimeListHandler) Delete(c *gin.Context) {
+	animeID, _ := strconv.Atoi(c.Param("animeId"))
+	if err := h.service.Remove(c.GetUint("user_id"), uint(animeID)); err != nil {
+		c.JSON(listErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Removed from list"})
+}
+
+func listErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrInvalidListStatus), errors.Is(err, domain.ErrInvalidListEntry):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrListEntryNotFound), errors.Is(err, service.ErrAnimeNotFound), errors.Is(err, service.ErrEpisodeNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
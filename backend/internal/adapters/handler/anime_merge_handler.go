@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"backend/internal/core/domain"
+	"backend/internal/core/service"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AnimeMergeHandler struct {
+	service *service.AnimeMergeService
+}
+
+func NewAnimeMergeHandler(service *service.AnimeMergeService) *AnimeMergeHandler {
+	return &AnimeMergeHandler{service: service}
+}
+
+// Duplicates lists likely duplicate anime pairs, best match first
+// GET /api/admin/animes/duplicates?min_score=0.75&limit=50
+func (h *AnimeMergeHandler) Duplicates(c *gin.Context) {
+	minScore, _ := strconv.ParseFloat(c.DefaultQuery("min_score", "0"), 64)
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+
+	items, err := h.service.FindDuplicates(minScore, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items, "total": len(items)})
+}
+
+// Dismiss marks a flagged pair as two different anime
+// POST /api/admin/animes/duplicates/dismiss
+func (h *AnimeMergeHandler) Dismiss(c *gin.Context) {
+	var input struct {
+		AnimeID uint `json:"anime_id" binding:"required"`
+		OtherID uint `json:"other_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.Dismiss(input.AnimeID, input.OtherID, c.GetUint("user_id")); err != nil {
+		c.JSON(mergeErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Pair dismissed"})
+}
+
+// Merge folds the loser anime into the survivor and redirects the loser to it
+// POST /api/admin/animes/merge
+func (h *AnimeMergeHandler) Merge(c *gin.Context) {
+	var input struct {
+		SurvivorID uint `json:"survivor_id" binding:"required"`
+		LoserID    uint `json:"loser_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := h.service.Merge(input.SurvivorID, input.LoserID, c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(mergeErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+func mergeErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrMergeSameAnime):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrAnimeNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"backend/internal/core/domain"
+	"backend/internal/core/port"
+	"backend/internal/core/service"
+	"errors"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// localBlobs is implemented by stores that keep blobs on this server's disk
+type localBlobs interface {
+	File(key string) (string, error)
+}
+
+// BlobHandler serves /uploads/<key> from the configured blob store. Local
+// blobs are served directly; remote ones redirect to a presigned URL, so the
+// URLs stored on rows keep working whatever the backend.
+type BlobHandler struct {
+	blobs   port.BlobStore
+	presign time.Duration
+}
+
+func NewBlobHandler(blobs port.BlobStore, presign time.Duration) *BlobHandler {
+	return &BlobHandler{blobs: blobs, presign: presign}
+}
+
+// Serve handles GET and HEAD /uploads/*key. ?download=name makes the browser
+// save the file as name.
+func (h *BlobHandler) Serve(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	filename := c.Query("download")
+	// Partial uploads are private to their uploader
+	if strings.HasPrefix(path.Clean("/"+key), "/"+service.TusBlobPrefix) {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	if local, ok := h.blobs.(localBlobs); ok {
+		path, err := local.File(key)
+		if err != nil {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		if _, err := h.blobs.Stat(key); err != nil {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		if filename != "" {
+			c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+		}
+		c.File(path)
+		return
+	}
+
+	if _, err := h.blobs.Stat(key); err != nil {
+		if errors.Is(err, domain.ErrBlobNotFound) {
+			c.Status(http.StatusNotFound)
+		} else {
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	url, err := h.blobs.URL(key, filename, h.presign)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+	// Presigned links expire, so the redirect must not be cached past them
+	c.Header("Cache-Control", "private, max-age=60")
+	c.Redirect(http.StatusFound, url)
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"backend/internal/core/service"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CatalogExportHandler struct {
+	service *service.CatalogExportService
+}
+
+func NewCatalogExportHandler(service *service.CatalogExportService) *CatalogExportHandler {
+	return &CatalogExportHandler{service: service}
+}
+
+// Export streams the catalog as JSON Lines or CSV
+// GET /api/admin/export/catalog?format=jsonl|csv&entity=anime&updated_since=2026-01-01T00:00:00Z
+// X-Export-Generated-At can be passed back as updated_since for the next incremental sync.
+func (h *CatalogExportHandler) Export(c *gin.Context) {
+	format := strings.ToLower(c.DefaultQuery("format", "jsonl"))
+	entity := strings.ToLower(c.Query("entity"))
+
+	var since *time.Time
+	if raw := c.Query("updated_since"); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			t, err = time.Parse("2006-01-02", raw)
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "updated_since must be RFC3339 or YYYY-MM-DD"})
+			return
+		}
+		since = &t
+	}
+
+	if err := h.service.Validate(format, entity); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	generatedAt := time.Now().UTC()
+	name := "catalog"
+	if entity != "" {
+		name += "-" + entity
+	}
+	contentType := "application/x-ndjson"
+	if format == "csv" {
+		contentType = "text/csv; charset=utf-8"
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s-%s.%s", name, generatedAt.Format("20060102150405"), format))
+	c.Header("X-Export-Generated-At", generatedAt.Format(time.RFC3339))
+	c.Status(http.StatusOK)
+
+	// Headers are already sent, so a failure can only be logged and the stream cut short
+	if err := h.service.Export(c.Writer, format, entity, since); err != nil {
+		log.Printf("Catalog export failed: %v", err)
+	}
+}
@@ -9,11 +9,12 @@ import (
 )
 
 type CategoryHandler struct {
-	service *service.CategoryService
+	service      *service.CategoryService
+	translations *service.TranslationService
 }
 
-func NewCategoryHandler(service *service.CategoryService) *CategoryHandler {
-	return &CategoryHandler{service: service}
+func NewCategoryHandler(service *service.CategoryService, translations *service.TranslationService) *CategoryHandler {
+	return &CategoryHandler{service: service, translations: translations}
 }
 
 func (h *CategoryHandler) Create(c *gin.Context) {
@@ -43,6 +44,9 @@ func (h *CategoryHandler) GetAll(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	if !localizeAll(c, h.translations, categories) {
+		return
+	}
 	c.JSON(http.StatusOK, categories)
 }
 
@@ -0,0 +1,283 @@
+package handler
+
+import (
+	"backend/internal/core/domain"
+	"backend/internal/core/service"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CollectionHandler struct {
+	service *service.CollectionService
+}
+
+func NewCollectionHandler(service *service.CollectionService) *CollectionHandler {
+	return &CollectionHandler{service: service}
+}
+
+// Browse lists public collections
+// GET /api/collections?q=mecha&sort=popular|recent&limit=50&offset=0
+func (h *CollectionHandler) Browse(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	filter := domain.CollectionFilter{
+		Query:  c.Query("q"),
+		Sort:   c.Query("sort"),
+		Limit:  limit,
+		Offset: offset,
+	}
+
+	items, total, err := h.service.Browse(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items, "total": total})
+}
+
+// Mine lists the caller's own collections, those shared with them or those they follow
+// GET /api/me/collections?relation=owned|shared|followed&limit=50&offset=0
+func (h *CollectionHandler) Mine(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	items, total, err := h.service.Mine(c.GetUint("user_id"), c.DefaultQuery("relation", "owned"), limit, offset)
+	if err != nil {
+		c.JSON(collectionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items, "total": total})
+}
+
+// Get returns a collection with its items, by ID or share slug. Guests can
+// open public and unlisted collections.
+// GET /api/collections/:id
+func (h *CollectionHandler) Get(c *gin.Context) {
+	collection, err := h.service.Get(c.GetUint("user_id"), c.GetString("role"), c.Param("id"))
+	if err != nil {
+		c.JSON(collectionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, collection)
+}
+
+// Create creates a collection owned by the caller; it is private unless a visibility is given
+// POST /api/collections
+func (h *CollectionHandler) Create(c *gin.Context) {
+	var input service.CollectionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	collection, err := h.service.Create(c.GetUint("user_id"), input)
+	if err != nil {
+		c.JSON(collectionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, collection)
+}
+
+// Update changes the title, description or visibility of a collection; omitted fields are kept
+// PUT /api/collections/:id
+func (h *CollectionHandler) Update(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var input service.CollectionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	collection, err := h.service.Update(c.GetUint("user_id"), c.GetString("role"), uint(id), input)
+	if err != nil {
+		c.JSON(collectionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, collection)
+}
+
+// Delete deletes a collection
+// DELETE /api/collections/:id
+func (h *CollectionHandler) Delete(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	if err := h.service.Delete(c.GetUint("user_id"), c.GetString("role"), uint(id)); err != nil {
+		c.JSON(collectionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Collection deleted"})
+}
+
+// RegenerateSlug gives a collection a new share slug; links with the old one stop working
+// POST /api/collections/:id/slug
+func (h *CollectionHandler) RegenerateSlug(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	slug, err := h.service.RegenerateSlug(c.GetUint("user_id"), c.GetString("role"), uint(id))
+	if err != nil {
+		c.JSON(collectionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"slug": slug})
+}
+
+// AddItem appends an anime, episode or model to a collection
+// POST /api/collections/:id/items
+func (h *CollectionHandler) AddItem(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var req struct {
+		ItemType domain.CollectionItemType `json:"item_type" binding:"required"`
+		ItemID   uint                      `json:"item_id" binding:"required"`
+		Note     string                    `json:"note"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	item, err := h.service.AddItem(c.GetUint("user_id"), c.GetString("role"), uint(id), req.ItemType, req.ItemID, req.Note)
+	if err != nil {
+		c.JSON(collectionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, item)
+}
+
+// UpdateItem moves an item to a 0-based position and/or changes its note
+// PATCH /api/collections/:id/items/:itemId
+func (h *CollectionHandler) UpdateItem(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	itemID, _ := strconv.Atoi(c.Param("itemId"))
+	var req struct {
+		Position *int    `json:"position"`
+		Note     *string `json:"note"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	err := h.service.UpdateItem(c.GetUint("user_id"), c.GetString("role"), uint(id), uint(itemID), req.Position, req.Note)
+	if err != nil {
+		c.JSON(collectionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Item updated"})
+}
+
+// RemoveItem removes an item from a collection
+// DELETE /api/collections/:id/items/:itemId
+func (h *CollectionHandler) RemoveItem(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	itemID, _ := strconv.Atoi(c.Param("itemId"))
+	if err := h.service.RemoveItem(c.GetUint("user_id"), c.GetString("role"), uint(id), uint(itemID)); err != nil {
+		c.JSON(collectionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Item removed"})
+}
+
+// Reorder sets the order of all items of a collection, e.g. after a drag and drop
+// PUT /api/collections/:id/items/order
+func (h *CollectionHandler) Reorder(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var req struct {
+		ItemIDs []uint `json:"item_ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.service.Reorder(c.GetUint("user_id"), c.GetString("role"), uint(id), req.ItemIDs); err != nil {
+		c.JSON(collectionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Items reordered"})
+}
+
+// AddCollaborator lets another user manage the items of a collection
+// POST /api/collections/:id/collaborators
+func (h *CollectionHandler) AddCollaborator(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var req struct {
+		UserID uint `json:"user_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	collaborator, err := h.service.AddCollaborator(c.GetUint("user_id"), c.GetString("role"), uint(id), req.UserID)
+	if err != nil {
+		c.JSON(collectionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, collaborator)
+}
+
+// RemoveCollaborator removes a collaborator; collaborators can remove themselves
+// DELETE /api/collections/:id/collaborators/:userId
+func (h *CollectionHandler) RemoveCollaborator(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	userID, _ := strconv.Atoi(c.Param("userId"))
+	if err := h.service.RemoveCollaborator(c.GetUint("user_id"), c.GetString("role"), uint(id), uint(userID)); err != nil {
+		c.JSON(collectionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Collaborator removed"})
+}
+
+// Follow follows a collection
+// POST /api/collections/:id/follow
+func (h *CollectionHandler) Follow(c *gin.Context) {
+	h.react(c, h.service.Follow, true)
+}
+
+// Unfollow unfollows a collection
+// DELETE /api/collections/:id/follow
+func (h *CollectionHandler) Unfollow(c *gin.Context) {
+	h.react(c, h.service.Follow, false)
+}
+
+// Like likes a collection
+// POST /api/collections/:id/like
+func (h *CollectionHandler) Like(c *gin.Context) {
+	h.react(c, h.service.Like, true)
+}
+
+// Unlike removes the caller's like from a collection
+// DELETE /api/collections/:id/like
+func (h *CollectionHandler) Unlike(c *gin.Context) {
+	h.react(c, h.service.Like, false)
+}
+
+// react sets a follow or like. :id may be a share slug, the only way to reach
+// unlisted collections of others.
+func (h *CollectionHandler) react(c *gin.Context, set func(userID uint, role string, ref string, on bool) error, on bool) {
+	if err := set(c.GetUint("user_id"), c.GetString("role"), c.Param("id"), on); err != nil {
+		c.JSON(collectionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	collection, err := h.service.Get(c.GetUint("user_id"), c.GetString("role"), c.Param("id"))
+	if err != nil {
+		c.JSON(collectionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"followers_count": collection.FollowersCount,
+		"likes_count":     collection.LikesCount,
+		"followed":        collection.Viewer.Followed,
+		"liked":           collection.Viewer.Liked,
+	})
+}
+
+func collectionErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrInvalidCollection), errors.Is(err, domain.ErrInvalidReorder),
+		errors.Is(err, domain.ErrInvalidCollaborator), errors.Is(err, domain.ErrCollectionFull):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrCollectionForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrCollectionNotFound), errors.Is(err, domain.ErrCollectionItemNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrCollectionItemExists):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"backend/internal/core/domain"
 	"backend/internal/core/service"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
@@ -11,11 +12,15 @@ import (
 )
 
 type EpisodeHandler struct {
-	service *service.EpisodeService
+	service      *service.EpisodeService
+	workflow     *service.WorkflowService
+	translations *service.TranslationService
+	subtitles    *service.SubtitleService
+	images       *service.ImageService
 }
 
-func NewEpisodeHandler(service *service.EpisodeService) *EpisodeHandler {
-	return &EpisodeHandler{service: service}
+func NewEpisodeHandler(service *service.EpisodeService, workflow *service.WorkflowService, translations *service.TranslationService, subtitles *service.SubtitleService, images *service.ImageService) *EpisodeHandler {
+	return &EpisodeHandler{service: service, workflow: workflow, translations: translations, subtitles: subtitles, images: images}
 }
 
 // sanitizeEpisode removes the hardcoded localhost:8080 prefix from image URLs
@@ -36,20 +41,57 @@ func (h *EpisodeHandler) sanitizeEpisode(e *domain.Episode) {
 	// Assuming VideoURLs are usually external embeds or handled separately.
 }
 
+// localize orders the servers and subtitle tracks of each episode by the request's
+// locale chain and server health, and resolves translated fields. It responds with
+// the error and returns false when that fails.
+func (h *EpisodeHandler) localize(c *gin.Context, episodes []domain.Episode) bool {
+	ptrs := make([]*domain.Episode, len(episodes))
+	for i := range episodes {
+		ptrs[i] = &episodes[i]
+	}
+	err := h.attach(c.GetStringSlice("locales"), ptrs...)
+	if err == nil {
+		err = h.translations.LocalizeEpisodes(c.GetStringSlice("locales"), episodes)
+	}
+	return localized(c, err)
+}
+
+func (h *EpisodeHandler) localizeOne(c *gin.Context, episode *domain.Episode) bool {
+	err := h.attach(c.GetStringSlice("locales"), episode)
+	if err == nil {
+		err = h.translations.LocalizeEpisode(c.GetStringSlice("locales"), episode)
+	}
+	return localized(c, err)
+}
+
+func (h *EpisodeHandler) attach(locales []string, episodes ...*domain.Episode) error {
+	if err := h.service.OrderSources(locales, episodes...); err != nil {
+		return err
+	}
+	if err := h.subtitles.Attach(locales, episodes...); err != nil {
+		return err
+	}
+	return h.images.AttachEpisodes(episodes...)
+}
+
 func (h *EpisodeHandler) Create(c *gin.Context) {
 	var episode domain.Episode
 	if err := c.ShouldBindJSON(&episode); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	if err := h.service.Create(&episode); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.service.Create(&episode, h.workflow.InitialState(c.GetUint("user_id"), c.GetString("role")), c.GetUint("user_id")); err != nil {
+		c.JSON(episodeErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 	h.sanitizeEpisode(&episode)
+	setETag(c, episode.Version)
 	c.JSON(http.StatusCreated, episode)
 }
 
+// GetAll lists published episodes, optionally of one anime; staff add ?all=true for
+// every workflow state
+// GET /api/episodes?anime_id=&episode_number=
 func (h *EpisodeHandler) GetAll(c *gin.Context) {
 	// Support filtering by anime_id and episode_number via query params
 	animeIDStr := c.Query("anime_id")
@@ -63,7 +105,7 @@ func (h *EpisodeHandler) GetAll(c *gin.Context) {
 			return
 		}
 
-		episodes, err := h.service.GetByAnimeID(uint(animeID))
+		episodes, err := h.service.GetByAnimeID(uint(animeID), includeUnpublished(c, h.workflow))
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
@@ -81,6 +123,9 @@ func (h *EpisodeHandler) GetAll(c *gin.Context) {
 			for _, ep := range episodes {
 				if ep.EpisodeNumber == episodeNum {
 					h.sanitizeEpisode(&ep)
+					if !h.localizeOne(c, &ep) {
+						return
+					}
 					c.JSON(http.StatusOK, []domain.Episode{ep})
 					return
 				}
@@ -92,12 +137,15 @@ func (h *EpisodeHandler) GetAll(c *gin.Context) {
 		for i := range episodes {
 			h.sanitizeEpisode(&episodes[i])
 		}
+		if !h.localize(c, episodes) {
+			return
+		}
 		c.JSON(http.StatusOK, episodes)
 		return
 	}
 
 	// No filters, return all
-	episodes, err := h.service.GetAll()
+	episodes, err := h.service.GetAll(includeUnpublished(c, h.workflow))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -105,17 +153,26 @@ func (h *EpisodeHandler) GetAll(c *gin.Context) {
 	for i := range episodes {
 		h.sanitizeEpisode(&episodes[i])
 	}
+	if !h.localize(c, episodes) {
+		return
+	}
 	c.JSON(http.StatusOK, episodes)
 }
 
+// GetByID returns a published episode; staff add ?all=true for any workflow state
+// GET /api/episodes/:id
 func (h *EpisodeHandler) GetByID(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Param("id"))
-	episode, err := h.service.GetByID(uint(id))
+	episode, err := h.service.GetByID(uint(id), includeUnpublished(c, h.workflow))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Episode not found"})
 		return
 	}
 	h.sanitizeEpisode(episode)
+	if !h.localizeOne(c, episode) {
+		return
+	}
+	setETag(c, episode.Version)
 	c.JSON(http.StatusOK, episode)
 }
 
@@ -131,16 +188,115 @@ func (h *EpisodeHandler) Update(c *gin.Context) {
 		return
 	}
 	episode.ID = uint(id)
-	if err := h.service.Update(&episode); err != nil {
+	if !canEdit(c, h.workflow, domain.WorkflowEntityEpisode, episode.ID) {
+		return
+	}
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+	if version != 0 {
+		episode.Version = version
+	}
+
+	updated, err := h.service.Update(&episode, c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(episodeErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.sanitizeEpisode(updated)
+	setETag(c, updated.Version)
+	c.JSON(http.StatusOK, updated)
+}
+
+// Revisions lists the edit history of an episode
+func (h *EpisodeHandler) Revisions(c *gin.Context) {
+	if !requirePermission(c, h.workflow, domain.PermissionContentSubmit) {
+		return
+	}
+	id, _ := strconv.Atoi(c.Param("id"))
+	revisions, err := h.service.Revisions(uint(id))
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	h.sanitizeEpisode(&episode)
+	c.JSON(http.StatusOK, revisions)
+}
+
+// Revision returns one revision with its snapshot
+func (h *EpisodeHandler) Revision(c *gin.Context) {
+	if !requirePermission(c, h.workflow, domain.PermissionContentSubmit) {
+		return
+	}
+	id, _ := strconv.Atoi(c.Param("id"))
+	version, _ := strconv.Atoi(c.Param("version"))
+	revision, err := h.service.Revision(uint(id), version)
+	if err != nil {
+		c.JSON(episodeErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, revision)
+}
+
+// Diff compares two revisions: GET /api/episodes/:id/revisions/diff?from=1&to=3
+func (h *EpisodeHandler) Diff(c *gin.Context) {
+	if !requirePermission(c, h.workflow, domain.PermissionContentSubmit) {
+		return
+	}
+	id, _ := strconv.Atoi(c.Param("id"))
+	from, to, ok := diffVersions(c)
+	if !ok {
+		return
+	}
+	diff, err := h.service.Diff(uint(id), from, to)
+	if err != nil {
+		c.JSON(episodeErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, diff)
+}
+
+// Rollback restores an earlier revision as a new one
+func (h *EpisodeHandler) Rollback(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	if !canEdit(c, h.workflow, domain.WorkflowEntityEpisode, uint(id)) {
+		return
+	}
+	version, _ := strconv.Atoi(c.Param("version"))
+	current, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+	episode, err := h.service.Rollback(uint(id), version, current, c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(episodeErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.sanitizeEpisode(episode)
+	setETag(c, episode.Version)
 	c.JSON(http.StatusOK, episode)
 }
 
+func episodeErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrEpisodeNotFound), errors.Is(err, service.ErrRevisionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrVersionConflict):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrVersionRequired):
+		return http.StatusPreconditionRequired
+	case errors.Is(err, domain.ErrInvalidSource):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
 func (h *EpisodeHandler) Delete(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Param("id"))
+	if !canEdit(c, h.workflow, domain.WorkflowEntityEpisode, uint(id)) {
+		return
+	}
 	if err := h.service.Delete(uint(id)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -158,6 +314,9 @@ func (h *EpisodeHandler) GetLatest(c *gin.Context) {
 	for i := range episodes {
 		h.sanitizeEpisode(&episodes[i])
 	}
+	if !h.localize(c, episodes) {
+		return
+	}
 	c.JSON(http.StatusOK, episodes)
 }
 
@@ -176,5 +335,8 @@ func (h *EpisodeHandler) Search(c *gin.Context) {
 	for i := range episodes {
 		h.sanitizeEpisode(&episodes[i])
 	}
+	if !h.localize(c, episodes) {
+		return
+	}
 	c.JSON(http.StatusOK, episodes)
 }
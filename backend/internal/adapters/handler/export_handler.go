@@ -2,9 +2,7 @@ package handler
 
 import (
 	"backend/internal/core/service"
-	"fmt"
 	"net/http"
-	"path/filepath"
 
 	"github.com/gin-gonic/gin"
 )
@@ -82,8 +80,8 @@ func (h *ExportHandler) Download(c *gin.Context) {
 		return
 	}
 
-	// Get file path from service
-	filePath, err := h.service.GetExportFile(filename)
+	// Exports live in the blob store: redirect to a (presigned) download link
+	url, err := h.service.DownloadURL(filename)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error": "file not found",
@@ -91,12 +89,5 @@ func (h *ExportHandler) Download(c *gin.Context) {
 		return
 	}
 
-	// Set headers for download
-	c.Header("Content-Description", "File Transfer")
-	c.Header("Content-Transfer-Encoding", "binary")
-	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filepath.Base(filename)))
-	c.Header("Content-Type", "application/octet-stream")
-
-	// Serve file
-	c.File(filePath)
+	c.Redirect(http.StatusFound, url)
 }
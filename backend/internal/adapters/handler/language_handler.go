@@ -10,11 +10,12 @@ import (
 )
 
 type LanguageHandler struct {
-	service *service.LanguageService
+	service      *service.LanguageService
+	translations *service.TranslationService
 }
 
-func NewLanguageHandler(service *service.LanguageService) *LanguageHandler {
-	return &LanguageHandler{service: service}
+func NewLanguageHandler(service *service.LanguageService, translations *service.TranslationService) *LanguageHandler {
+	return &LanguageHandler{service: service, translations: translations}
 }
 
 func (h *LanguageHandler) Create(c *gin.Context) {
@@ -43,6 +44,9 @@ func (h *LanguageHandler) GetAll(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	if !localizeAll(c, h.translations, languages) {
+		return
+	}
 	c.JSON(http.StatusOK, languages)
 }
 
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"backend/internal/core/domain"
+	"backend/internal/core/service"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ListImportHandler struct {
+	service *service.ListImportService
+}
+
+func NewListImportHandler(service *service.ListImportService) *ListImportHandler {
+	return &ListImportHandler{service: service}
+}
+
+// listImportSource returns the uploaded "file" field, or the raw body when no file was sent
+func listImportSource(c *gin.Context) (io.ReadCloser, error) {
+	if !strings.HasPrefix(c.ContentType(), "multipart/") {
+		return c.Request.Body, nil
+	}
+	file, err := c.FormFile("file")
+	if err != nil {
+		return nil, err
+	}
+	return file.Open()
+}
+
+// ImportMAL imports a MyAnimeList XML export (optionally gzipped)
+// POST /api/me/import/mal?dry_run=true
+func (h *ListImportHandler) ImportMAL(c *gin.Context) {
+	h.importList(c, domain.ListSourceMAL, h.service.ParseMAL)
+}
+
+// ImportAniList imports an AniList JSON export
+// POST /api/me/import/anilist?dry_run=true
+func (h *ListImportHandler) ImportAniList(c *gin.Context) {
+	h.importList(c, domain.ListSourceAniList, h.service.ParseAniList)
+}
+
+func (h *ListImportHandler) importList(c *gin.Context, source string, parse func(io.Reader) ([]domain.ExternalListEntry, error)) {
+	userID := c.GetUint("user_id")
+	dryRun, _ := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+
+	src, err := listImportSource(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+	defer src.Close()
+
+	entries, err := parse(src)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := h.service.Import(userID, source, entries, dryRun)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// Resolve imports entries the user matched manually from the unmatched report
+// POST /api/me/import/resolve
+func (h *ListImportHandler) Resolve(c *gin.Context) {
+	var req struct {
+		Source  string                        `json:"source" binding:"required,oneof=mal anilist"`
+		Entries []domain.ListImportResolution `json:"entries" binding:"required,dive"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := h.service.Resolve(c.GetUint("user_id"), req.Source, req.Entries)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
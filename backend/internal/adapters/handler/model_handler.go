@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"backend/internal/core/domain"
 	"backend/internal/core/service"
 	"net/http"
 	"strconv"
@@ -10,10 +11,11 @@ import (
 
 type ModelHandler struct {
 	service *service.ModelService
+	images  *service.ImageService
 }
 
-func NewModelHandler(service *service.ModelService) *ModelHandler {
-	return &ModelHandler{service: service}
+func NewModelHandler(service *service.ModelService, images *service.ImageService) *ModelHandler {
+	return &ModelHandler{service: service, images: images}
 }
 
 func (h *ModelHandler) Upload(c *gin.Context) {
@@ -38,7 +40,7 @@ func (h *ModelHandler) Upload(c *gin.Context) {
 
 	model, err := h.service.Upload(name, title, file, image, miniBlur, category)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(imageErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -51,6 +53,14 @@ func (h *ModelHandler) GetAll(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	ptrs := make([]*domain.Model, len(models))
+	for i := range models {
+		ptrs[i] = &models[i]
+	}
+	if err := h.images.AttachModels(ptrs...); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 	c.JSON(http.StatusOK, models)
 }
 
@@ -73,7 +83,7 @@ func (h *ModelHandler) Update(c *gin.Context) {
 
 	model, err := h.service.Update(uint(id), name, title, image, miniBlur, category)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(imageErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -88,7 +98,12 @@ func (h *ModelHandler) Download(c *gin.Context) {
 		return
 	}
 
-	// Sanitize filename or just use model.Name + ext
-	// model.Path is relative "uploads/models/xxxxx.fbx"
-	c.FileAttachment(model.Path, model.Name+"."+model.Type)
+	// The file may live in a bucket: send the client to a (presigned) link
+	// that downloads it as model.Name + ext
+	url, err := h.service.DownloadURL(model)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Model file not found"})
+		return
+	}
+	c.Redirect(http.StatusFound, url)
 }
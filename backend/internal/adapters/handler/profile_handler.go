@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"backend/internal/core/domain"
+	"backend/internal/core/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProfileHandler struct {
+	service *service.ProfileService
+}
+
+func NewProfileHandler(service *service.ProfileService) *ProfileHandler {
+	return &ProfileHandler{service: service}
+}
+
+// Get returns the public profile of a user, by ID or username (e.g. @naruto_fan).
+// Guests can see profiles; signed in callers also get whether they follow the user.
+// GET /api/users/:id/profile
+func (h *ProfileHandler) Get(c *gin.Context) {
+	profile, err := h.service.Get(c.GetUint("user_id"), c.Param("id"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, domain.ErrUserNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, profile)
+}
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setETag exposes a row's version so clients can send it back in If-Match
+func setETag(c *gin.Context, version int) {
+	c.Header("ETag", fmt.Sprintf("\"%d\"", version))
+}
+
+// ifMatchVersion reads the version from an If-Match header ("3", "\"3\"" or W/"3").
+// It returns 0 when the header is absent or "*"; the edit then has to carry the version
+// in its body, otherwise the service refuses it and the caller gets 428.
+func ifMatchVersion(c *gin.Context) (int, bool) {
+	raw := strings.TrimSpace(c.GetHeader("If-Match"))
+	if raw == "" || raw == "*" {
+		return 0, true
+	}
+	raw = strings.Trim(strings.TrimPrefix(raw, "W/"), "\"")
+	version, err := strconv.Atoi(raw)
+	if err != nil || version <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "If-Match must be a version number"})
+		return 0, false
+	}
+	return version, true
+}
+
+// diffVersions reads the from/to query parameters of a revision diff
+func diffVersions(c *gin.Context) (int, int, bool) {
+	from, errFrom := strconv.Atoi(c.Query("from"))
+	to, errTo := strconv.Atoi(c.Query("to"))
+	if errFrom != nil || errTo != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from and to revision versions are required"})
+		return 0, 0, false
+	}
+	return from, to, true
+}
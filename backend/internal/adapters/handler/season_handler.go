@@ -9,11 +9,12 @@ import (
 )
 
 type SeasonHandler struct {
-	service *service.SeasonService
+	service      *service.SeasonService
+	translations *service.TranslationService
 }
 
-func NewSeasonHandler(service *service.SeasonService) *SeasonHandler {
-	return &SeasonHandler{service: service}
+func NewSeasonHandler(service *service.SeasonService, translations *service.TranslationService) *SeasonHandler {
+	return &SeasonHandler{service: service, translations: translations}
 }
 
 func (h *SeasonHandler) Create(c *gin.Context) {
@@ -41,6 +42,9 @@ func (h *SeasonHandler) GetAll(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	if !localizeAll(c, h.translations, seasons) {
+		return
+	}
 	c.JSON(http.StatusOK, seasons)
 }
 
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"backend/internal/core/domain"
+	"backend/internal/core/service"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ServerHealthHandler struct {
+	service *service.ServerHealthService
+}
+
+func NewServerHealthHandler(service *service.ServerHealthService) *ServerHealthHandler {
+	return &ServerHealthHandler{service: service}
+}
+
+// Broken lists demoted and user-reported servers
+// GET /api/admin/servers/broken?limit=50&offset=0
+func (h *ServerHealthHandler) Broken(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	items, total, err := h.service.Broken(limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items, "total": total})
+}
+
+// Recheck probes a server now and returns its health
+// POST /api/admin/servers/:id/check
+func (h *ServerHealthHandler) Recheck(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	health, err := h.service.Recheck(uint(id))
+	if err != nil {
+		c.JSON(serverHealthErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, health)
+}
+
+// Resolve closes the open user reports of a server
+// POST /api/admin/servers/:id/resolve
+func (h *ServerHealthHandler) Resolve(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	resolved, err := h.service.Resolve(uint(id))
+	if err != nil {
+		c.JSON(serverHealthErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"resolved": resolved})
+}
+
+// Report lets a user flag a server that does not play
+// POST /api/episodes/:id/servers/:serverId/report
+func (h *ServerHealthHandler) Report(c *gin.Context) {
+	episodeID, _ := strconv.Atoi(c.Param("id"))
+	serverID, _ := strconv.Atoi(c.Param("serverId"))
+	var input struct {
+		Reason string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := h.service.Report(c.GetUint("user_id"), uint(episodeID), uint(serverID), input.Reason)
+	if err != nil {
+		c.JSON(serverHealthErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, report)
+}
+
+func serverHealthErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrServerNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrAlreadyReported):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"backend/internal/core/domain"
+	"backend/internal/core/service"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SocialHandler struct {
+	service *service.SocialService
+}
+
+func NewSocialHandler(service *service.SocialService) *SocialHandler {
+	return &SocialHandler{service: service}
+}
+
+// Status reports whether the caller follows a user, whether they follow back and their counts
+// GET /api/users/:id/follow
+func (h *SocialHandler) Status(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	status, err := h.service.Status(c.GetUint("user_id"), uint(id))
+	if err != nil {
+		c.JSON(socialErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// Follow follows a user; their shared activity shows up in the caller's feed
+// POST /api/users/:id/follow
+func (h *SocialHandler) Follow(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	status, err := h.service.Follow(c.GetUint("user_id"), uint(id))
+	if err != nil {
+		c.JSON(socialErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// Unfollow stops following a user
+// DELETE /api/users/:id/follow
+func (h *SocialHandler) Unfollow(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	status, err := h.service.Unfollow(c.GetUint("user_id"), uint(id))
+	if err != nil {
+		c.JSON(socialErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// Followers lists the users following a user
+// GET /api/users/:id/followers?limit=50&offset=0
+func (h *SocialHandler) Followers(c *gin.Context) {
+	h.listUsers(c, h.service.Followers)
+}
+
+// Following lists the users a user follows
+// GET /api/users/:id/following?limit=50&offset=0
+func (h *SocialHandler) Following(c *gin.Context) {
+	h.listUsers(c, h.service.Following)
+}
+
+func (h *SocialHandler) listUsers(c *gin.Context, list func(userID uint, limit, offset int) ([]domain.User, int64, error)) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	users, total, err := list(uint(id), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	items := make([]gin.H, 0, len(users))
+	for _, u := range users {
+		items = append(items, gin.H{"id": u.ID, "name": u.Name, "avatar": u.Avatar})
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items, "total": total})
+}
+
+// Privacy returns which activity types the caller shares with their followers
+// GET /api/me/privacy
+func (h *SocialHandler) Privacy(c *gin.Context) {
+	privacy, err := h.service.Privacy(c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, privacy)
+}
+
+// UpdatePrivacy changes which activity types the caller shares; omitted fields are kept
+// PUT /api/me/privacy
+func (h *SocialHandler) UpdatePrivacy(c *gin.Context) {
+	var update domain.UserPrivacyUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	privacy, err := h.service.UpdatePrivacy(c.GetUint("user_id"), update)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, privacy)
+}
+
+// Feed returns the shared activity of the users the caller follows, newest
+// first. Pass next_cursor from a page as cursor to get the next one.
+// GET /api/me/feed?cursor=&limit=20
+func (h *SocialHandler) Feed(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	page, err := h.service.Feed(c.GetUint("user_id"), c.Query("cursor"), limit)
+	if err != nil {
+		c.JSON(socialErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+func socialErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrCannotFollowSelf), errors.Is(err, domain.ErrInvalidFeedCursor):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrUserNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"backend/internal/core/domain"
+	"backend/internal/core/service"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StatsHandler struct {
+	service *service.StatsService
+}
+
+func NewStatsHandler(service *service.StatsService) *StatsHandler {
+	return &StatsHandler{service: service}
+}
+
+// Get returns the caller's viewing stats: totals, genre and studio breakdowns,
+// a weekday/hour heatmap, streaks and the wrapped summary of a year
+// GET /api/me/stats?tz=Europe/Paris&year=2025
+func (h *StatsHandler) Get(c *gin.Context) {
+	year, _ := strconv.Atoi(c.DefaultQuery("year", "0"))
+	stats, err := h.service.Get(c.GetUint("user_id"), c.Query("tz"), year)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, domain.ErrInvalidTimezone) || errors.Is(err, domain.ErrInvalidStatsYear) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
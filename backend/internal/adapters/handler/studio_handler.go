@@ -10,11 +10,12 @@ import (
 )
 
 type StudioHandler struct {
-	service *service.StudioService
+	service      *service.StudioService
+	translations *service.TranslationService
 }
 
-func NewStudioHandler(service *service.StudioService) *StudioHandler {
-	return &StudioHandler{service: service}
+func NewStudioHandler(service *service.StudioService, translations *service.TranslationService) *StudioHandler {
+	return &StudioHandler{service: service, translations: translations}
 }
 
 func (h *StudioHandler) Create(c *gin.Context) {
@@ -43,6 +44,9 @@ func (h *StudioHandler) GetAll(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	if !localizeAll(c, h.translations, studios) {
+		return
+	}
 	c.JSON(http.StatusOK, studios)
 }
 
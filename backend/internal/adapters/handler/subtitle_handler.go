@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"backend/internal/core/domain"
+	"backend/internal/core/service"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SubtitleHandler struct {
+	service  *service.SubtitleService
+	workflow *service.WorkflowService
+}
+
+func NewSubtitleHandler(service *service.SubtitleService, workflow *service.WorkflowService) *SubtitleHandler {
+	return &SubtitleHandler{service: service, workflow: workflow}
+}
+
+// List returns the subtitle tracks of a published episode; staff add ?all=true for
+// episodes in any workflow state
+// GET /api/episodes/:id/subtitles
+func (h *SubtitleHandler) List(c *gin.Context) {
+	episodeID, _ := strconv.Atoi(c.Param("id"))
+	tracks, err := h.service.List(c.GetStringSlice("locales"), uint(episodeID), includeUnpublished(c, h.workflow))
+	if err != nil {
+		c.JSON(subtitleErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, tracks)
+}
+
+// Upload adds a track from a multipart SRT, ASS/SSA or VTT file. Form fields:
+// file, language, label, is_default, charset and offset_ms.
+// POST /api/episodes/:id/subtitles
+func (h *SubtitleHandler) Upload(c *gin.Context) {
+	episodeID, _ := strconv.Atoi(c.Param("id"))
+	if !canEdit(c, h.workflow, domain.WorkflowEntityEpisode, uint(episodeID)) {
+		return
+	}
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No file uploaded"})
+		return
+	}
+	input := service.SubtitleUpload{
+		Language: c.PostForm("language"),
+		Label:    c.PostForm("label"),
+		Charset:  c.PostForm("charset"),
+	}
+	input.IsDefault, _ = strconv.ParseBool(c.PostForm("is_default"))
+	if raw := c.PostForm("offset_ms"); raw != "" {
+		if input.OffsetMs, err = strconv.ParseInt(raw, 10, 64); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "offset_ms must be an integer"})
+			return
+		}
+	}
+
+	track, err := h.service.Upload(uint(episodeID), file, input)
+	if err != nil {
+		c.JSON(subtitleErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, track)
+}
+
+// Update changes a track's language, label or default flag
+// PUT /api/episodes/:id/subtitles/:trackId
+func (h *SubtitleHandler) Update(c *gin.Context) {
+	episodeID, _ := strconv.Atoi(c.Param("id"))
+	if !canEdit(c, h.workflow, domain.WorkflowEntityEpisode, uint(episodeID)) {
+		return
+	}
+	trackID, _ := strconv.Atoi(c.Param("trackId"))
+	var input service.SubtitleTrackInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	track, err := h.service.Update(uint(episodeID), uint(trackID), input)
+	if err != nil {
+		c.JSON(subtitleErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, track)
+}
+
+// Offset shifts a track's timing by offset_ms, optionally only from from_ms on
+// POST /api/episodes/:id/subtitles/:trackId/offset
+func (h *SubtitleHandler) Offset(c *gin.Context) {
+	episodeID, _ := strconv.Atoi(c.Param("id"))
+	if !canEdit(c, h.workflow, domain.WorkflowEntityEpisode, uint(episodeID)) {
+		return
+	}
+	trackID, _ := strconv.Atoi(c.Param("trackId"))
+	var input struct {
+		OffsetMs int64 `json:"offset_ms" binding:"required"`
+		FromMs   int64 `json:"from_ms"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	track, err := h.service.Offset(uint(episodeID), uint(trackID), input.OffsetMs, input.FromMs)
+	if err != nil {
+		c.JSON(subtitleErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, track)
+}
+
+// Delete removes a track
+// DELETE /api/episodes/:id/subtitles/:trackId
+func (h *SubtitleHandler) Delete(c *gin.Context) {
+	episodeID, _ := strconv.Atoi(c.Param("id"))
+	if !canEdit(c, h.workflow, domain.WorkflowEntityEpisode, uint(episodeID)) {
+		return
+	}
+	trackID, _ := strconv.Atoi(c.Param("trackId"))
+	if err := h.service.Delete(uint(episodeID), uint(trackID)); err != nil {
+		c.JSON(subtitleErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Subtitle track deleted"})
+}
+
+func subtitleErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrSubtitleNotFound), errors.Is(err, service.ErrEpisodeNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidSubtitle):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
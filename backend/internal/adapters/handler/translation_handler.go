@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"backend/internal/core/domain"
+	"backend/internal/core/service"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TranslationHandler struct {
+	service *service.TranslationService
+}
+
+func NewTranslationHandler(service *service.TranslationService) *TranslationHandler {
+	return &TranslationHandler{service: service}
+}
+
+// Get lists every translation of a row: GET /api/admin/translations/:entity/:id
+func (h *TranslationHandler) Get(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	translations, err := h.service.Get(c.Param("entity"), uint(id))
+	if err != nil {
+		c.JSON(translationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"entity_type": c.Param("entity"), "entity_id": id, "translations": translations})
+}
+
+// Save sets fields of a row in one locale. The body maps field names to values,
+// e.g. {"title": "...", "description": ""}; an empty value clears the field.
+func (h *TranslationHandler) Save(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var values map[string]string
+	if err := c.ShouldBindJSON(&values); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	translations, err := h.service.Save(c.Param("entity"), uint(id), c.Param("locale"), values, c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(translationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"entity_type": c.Param("entity"), "entity_id": id, "translations": translations})
+}
+
+func (h *TranslationHandler) Delete(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	if err := h.service.Delete(c.Param("entity"), uint(id), c.Param("locale")); err != nil {
+		c.JSON(translationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Translations deleted"})
+}
+
+func translationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrContentNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrUnknownTranslationEntity), errors.Is(err, domain.ErrUnknownTranslationField),
+		errors.Is(err, domain.ErrInvalidLocale):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrLegacyLocale):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, domain.ErrVersionConflict):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// localized ends the request with a 500 when localizing its response failed and
+// reports whether the handler may go on
+func localized(c *gin.Context, err error) bool {
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return false
+	}
+	return true
+}
+
+// localizeAll applies the request's translations to a list of dictionary rows
+func localizeAll[T any, P interface {
+	*T
+	domain.Translatable
+}](c *gin.Context, translations *service.TranslationService, rows []T) bool {
+	items := make([]domain.Translatable, len(rows))
+	for i := range rows {
+		items[i] = P(&rows[i])
+	}
+	return localized(c, translations.Localize(c.GetStringSlice("locales"), items...))
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"backend/internal/core/domain"
+	"backend/internal/core/service"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TrashHandler struct {
+	service *service.TrashService
+}
+
+func NewTrashHandler(service *service.TrashService) *TrashHandler {
+	return &TrashHandler{service: service}
+}
+
+// List returns soft-deleted rows of one type
+// GET /api/admin/trash?type=anime&limit=50&offset=0
+func (h *TrashHandler) List(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+
+	items, total, err := h.service.List(c.DefaultQuery("type", domain.TrashEntityAnime), limit, offset)
+	if err != nil {
+		c.JSON(trashErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items, "total": total})
+}
+
+// Restore brings a row back, with the episodes/servers deleted along with it
+// POST /api/admin/trash/:type/:id/restore
+func (h *TrashHandler) Restore(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	if err := h.service.Restore(c.Param("type"), uint(id)); err != nil {
+		c.JSON(trashErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Restored"})
+}
+
+// Purge permanently deletes a row and its files
+// DELETE /api/admin/trash/:type/:id
+func (h *TrashHandler) Purge(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	if err := h.service.Purge(c.Param("type"), uint(id)); err != nil {
+		c.JSON(trashErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Permanently deleted"})
+}
+
+func trashErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrNotInTrash):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrUnknownTrashEntity):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrParentInTrash):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,234 @@
+package handler
+
+import (
+	"backend/internal/core/domain"
+	"backend/internal/core/service"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	tusVersion    = "1.0.0"
+	tusExtensions = "creation,creation-with-upload,expiration,checksum,termination"
+	// tusOffsetContentType is the only body type PATCH accepts
+	tusOffsetContentType = "application/offset+octet-stream"
+	// statusChecksumMismatch is the tus checksum extension's status for a bad chunk
+	statusChecksumMismatch = 460
+)
+
+// TusHeaders must be allowed and exposed by CORS for browser tus clients
+var TusHeaders = []string{
+	"Tus-Resumable", "Tus-Version", "Tus-Max-Size", "Tus-Extension", "Tus-Checksum-Algorithm",
+	"Upload-Length", "Upload-Offset", "Upload-Metadata", "Upload-Expires", "Upload-Checksum", "Location",
+}
+
+// TusHandler implements the tus 1.0 resumable upload protocol
+// (https://tus.io/protocols/resumable-upload) on /api/uploads/tus
+type TusHandler struct {
+	service *service.TusService
+}
+
+func NewTusHandler(service *service.TusService) *TusHandler {
+	return &TusHandler{service: service}
+}
+
+// Options advertises the protocol version, extensions and limits
+// OPTIONS /api/uploads/tus
+func (h *TusHandler) Options(c *gin.Context) {
+	c.Header("Tus-Resumable", tusVersion)
+	c.Header("Tus-Version", tusVersion)
+	c.Header("Tus-Extension", tusExtensions)
+	c.Header("Tus-Max-Size", strconv.FormatInt(h.service.MaxSize(), 10))
+	c.Header("Tus-Checksum-Algorithm", strings.Join(service.TusChecksumAlgorithms, ","))
+	c.Status(http.StatusNoContent)
+}
+
+// Create starts an upload. Upload-Metadata must carry target (model, image or
+// video) and filename; model uploads also carry name, title and category. A
+// first chunk may be sent in the body.
+// POST /api/uploads/tus
+func (h *TusHandler) Create(c *gin.Context) {
+	if !h.checkVersion(c) {
+		return
+	}
+	if c.GetHeader("Upload-Defer-Length") != "" {
+		h.fail(c, http.StatusBadRequest, errors.New("Upload-Defer-Length is not supported"))
+		return
+	}
+	length, err := strconv.ParseInt(c.GetHeader("Upload-Length"), 10, 64)
+	if err != nil {
+		h.fail(c, http.StatusBadRequest, errors.New("Upload-Length is required"))
+		return
+	}
+	metadata, err := parseUploadMetadata(c.GetHeader("Upload-Metadata"))
+	if err != nil {
+		h.fail(c, http.StatusBadRequest, err)
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	upload, err := h.service.Create(userID, length, metadata)
+	if err == nil && c.ContentType() == tusOffsetContentType && c.Request.ContentLength != 0 {
+		upload, err = h.service.Write(upload.ID, userID, 0, c.Request.Body, c.GetHeader("Upload-Checksum"))
+	}
+	if upload != nil {
+		c.Header("Location", "/api/uploads/tus/"+upload.ID)
+		h.setProgress(c, upload)
+	}
+	if err != nil {
+		h.fail(c, tusErrorStatus(err), err)
+		return
+	}
+	c.Status(http.StatusCreated)
+}
+
+// Head reports how much of an upload has been received
+// HEAD /api/uploads/tus/:id
+func (h *TusHandler) Head(c *gin.Context) {
+	if !h.checkVersion(c) {
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	upload, err := h.service.Get(c.Param("id"), c.GetUint("user_id"))
+	if err != nil {
+		c.Status(tusErrorStatus(err))
+		return
+	}
+	h.setProgress(c, upload)
+	c.Header("Upload-Length", strconv.FormatInt(upload.Length, 10))
+	if len(upload.Metadata) > 0 {
+		c.Header("Upload-Metadata", formatUploadMetadata(upload.Metadata))
+	}
+	c.Status(http.StatusOK)
+}
+
+// Patch appends a chunk at Upload-Offset
+// PATCH /api/uploads/tus/:id
+func (h *TusHandler) Patch(c *gin.Context) {
+	if !h.checkVersion(c) {
+		return
+	}
+	if c.ContentType() != tusOffsetContentType {
+		h.fail(c, http.StatusUnsupportedMediaType, errors.New("Content-Type must be "+tusOffsetContentType))
+		return
+	}
+	offset, err := strconv.ParseInt(c.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil {
+		h.fail(c, http.StatusBadRequest, errors.New("Upload-Offset is required"))
+		return
+	}
+
+	upload, err := h.service.Write(c.Param("id"), c.GetUint("user_id"), offset, c.Request.Body, c.GetHeader("Upload-Checksum"))
+	if upload != nil {
+		h.setProgress(c, upload)
+	}
+	if err != nil {
+		h.fail(c, tusErrorStatus(err), err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Delete terminates an upload
+// DELETE /api/uploads/tus/:id
+func (h *TusHandler) Delete(c *gin.Context) {
+	if !h.checkVersion(c) {
+		return
+	}
+	if err := h.service.Terminate(c.Param("id"), c.GetUint("user_id")); err != nil {
+		h.fail(c, tusErrorStatus(err), err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Status returns an upload's state, including what its pipeline created once complete
+// GET /api/uploads/tus/:id
+func (h *TusHandler) Status(c *gin.Context) {
+	upload, err := h.service.Get(c.Param("id"), c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(tusErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, upload)
+}
+
+// checkVersion rejects requests for a protocol version the server does not speak
+func (h *TusHandler) checkVersion(c *gin.Context) bool {
+	c.Header("Tus-Resumable", tusVersion)
+	if c.GetHeader("Tus-Resumable") != tusVersion {
+		c.Header("Tus-Version", tusVersion)
+		c.AbortWithStatus(http.StatusPreconditionFailed)
+		return false
+	}
+	return true
+}
+
+func (h *TusHandler) setProgress(c *gin.Context, upload *domain.TusUpload) {
+	c.Header("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	if upload.CompletedAt == nil {
+		c.Header("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	}
+}
+
+func (h *TusHandler) fail(c *gin.Context, status int, err error) {
+	c.JSON(status, gin.H{"error": err.Error()})
+}
+
+func tusErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrUploadNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrUploadExpired):
+		return http.StatusGone
+	case errors.Is(err, domain.ErrUploadOffsetMismatch):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrUploadTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, domain.ErrChecksumMismatch):
+		return statusChecksumMismatch
+	case errors.Is(err, domain.ErrInvalidUpload):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrUploadFinalize):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// parseUploadMetadata decodes "key base64value,key2 base64value2". Values are optional.
+func parseUploadMetadata(header string) (map[string]string, error) {
+	metadata := map[string]string{}
+	for _, pair := range strings.Split(header, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, encoded, _ := strings.Cut(pair, " ")
+		value, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, errors.New("Upload-Metadata value of " + key + " is not base64")
+		}
+		metadata[key] = string(value)
+	}
+	return metadata, nil
+}
+
+func formatUploadMetadata(metadata map[string]string) string {
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+" "+base64.StdEncoding.EncodeToString([]byte(metadata[key])))
+	}
+	return strings.Join(pairs, ",")
+}
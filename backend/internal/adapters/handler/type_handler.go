@@ -9,11 +9,12 @@ import (
 )
 
 type TypeHandler struct {
-	service *service.TypeService
+	service      *service.TypeService
+	translations *service.TranslationService
 }
 
-func NewTypeHandler(service *service.TypeService) *TypeHandler {
-	return &TypeHandler{service: service}
+func NewTypeHandler(service *service.TypeService, translations *service.TranslationService) *TypeHandler {
+	return &TypeHandler{service: service, translations: translations}
 }
 
 func (h *TypeHandler) Create(c *gin.Context) {
@@ -41,6 +42,9 @@ func (h *TypeHandler) GetAll(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	if !localizeAll(c, h.translations, types) {
+		return
+	}
 	c.JSON(http.StatusOK, types)
 }
 
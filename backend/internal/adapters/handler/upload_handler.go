@@ -1,21 +1,25 @@
 package handler
 
 import (
-	"fmt"
+	"backend/internal/core/domain"
+	"backend/internal/core/service"
+	"errors"
 	"net/http"
-	"os"
 	"path/filepath"
 	"strings"
-	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 )
 
-type UploadHandler struct{}
+// maxImageUploadSize caps direct image uploads; larger files go through tus
+const maxImageUploadSize = 20 << 20
 
-func NewUploadHandler() *UploadHandler {
-	return &UploadHandler{}
+type UploadHandler struct {
+	images *service.ImageService
+}
+
+func NewUploadHandler(images *service.ImageService) *UploadHandler {
+	return &UploadHandler{images: images}
 }
 
 func (h *UploadHandler) UploadFile(c *gin.Context) {
@@ -32,22 +36,31 @@ func (h *UploadHandler) UploadFile(c *gin.Context) {
 		return
 	}
 
-	// Create uploads directory if not exists
-	uploadDir := "./uploads"
-	if _, err := os.Stat(uploadDir); os.IsNotExist(err) {
-		os.MkdirAll(uploadDir, 0755)
+	if file.Size > maxImageUploadSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Image exceeds 20MB"})
+		return
 	}
 
-	// Generate unique filename
-	filename := fmt.Sprintf("%d_%s%s", time.Now().Unix(), uuid.New().String(), ext)
-	filepath := filepath.Join(uploadDir, filename)
-
-	if err := c.SaveUploadedFile(file, filepath); err != nil {
+	src, err := file.Open()
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save file"})
 		return
 	}
+	defer src.Close()
 
-	// Return relative URL
-	url := fmt.Sprintf("/uploads/%s", filename)
-	c.JSON(http.StatusOK, gin.H{"url": url})
+	// Orient, strip metadata and store by content; identical images share one file
+	media, asset, err := h.images.Store(src, ext)
+	if err != nil {
+		c.JSON(imageErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"url": media.URL, "media_id": media.ID, "image_set": service.ResponsiveImage(asset)})
+}
+
+// imageErrorStatus maps errors from storing an uploaded image
+func imageErrorStatus(err error) int {
+	if errors.Is(err, domain.ErrInvalidImage) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
 }
@@ -3,7 +3,11 @@ package handler
 import (
 	"backend/internal/core/domain"
 	"backend/internal/core/service"
+	"errors"
+	"log"
+	"mime/multipart"
 	"net/http"
+	"path/filepath"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -11,10 +15,32 @@ import (
 
 type UserHandler struct {
 	service *service.UserService
+	images  *service.ImageService
 }
 
-func NewUserHandler(service *service.UserService) *UserHandler {
-	return &UserHandler{service: service}
+func NewUserHandler(service *service.UserService, images *service.ImageService) *UserHandler {
+	return &UserHandler{service: service, images: images}
+}
+
+// storeAvatar orients and strips an uploaded avatar and puts it in the media
+// store, returning its URL
+func (h *UserHandler) storeAvatar(file *multipart.FileHeader) (string, error) {
+	src, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+	media, _, err := h.images.Store(src, filepath.Ext(file.Filename))
+	if err != nil {
+		return "", err
+	}
+	return media.URL, nil
+}
+
+func (h *UserHandler) attachAvatars(users ...*domain.User) {
+	if err := h.images.AttachUsers(users...); err != nil {
+		log.Printf("Users: attach avatars: %v", err)
+	}
 }
 
 func (h *UserHandler) GetAll(c *gin.Context) {
@@ -32,6 +58,11 @@ func (h *UserHandler) GetAll(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	refs := make([]*domain.User, len(users))
+	for i := range users {
+		refs[i] = &users[i]
+	}
+	h.attachAvatars(refs...)
 	c.JSON(http.StatusOK, users)
 }
 
@@ -50,13 +81,10 @@ func (h *UserHandler) Create(c *gin.Context) {
 	roleID, _ := strconv.Atoi(roleIDStr)
 
 	var avatarPath string
-	file, err := c.FormFile("avatar")
-	if err == nil {
-		filename := strconv.FormatInt(int64(c.Writer.Status()), 10) + "_" + file.Filename // Simple unique name
-		// In production use UUID or similar
-		dst := "uploads/avatars/" + filename
-		if err := c.SaveUploadedFile(file, dst); err == nil {
-			avatarPath = "/uploads/avatars/" + filename
+	if file, err := c.FormFile("avatar"); err == nil {
+		if avatarPath, err = h.storeAvatar(file); err != nil {
+			c.JSON(imageErrorStatus(err), gin.H{"error": err.Error()})
+			return
 		}
 	}
 
@@ -77,12 +105,10 @@ func (h *UserHandler) Update(c *gin.Context) {
 	roleID, _ := strconv.Atoi(roleIDStr)
 
 	var avatarPath string
-	file, err := c.FormFile("avatar")
-	if err == nil {
-		filename := strconv.FormatInt(int64(id), 10) + "_" + file.Filename
-		dst := "uploads/avatars/" + filename
-		if err := c.SaveUploadedFile(file, dst); err == nil {
-			avatarPath = "/uploads/avatars/" + filename
+	if file, err := c.FormFile("avatar"); err == nil {
+		if avatarPath, err = h.storeAvatar(file); err != nil {
+			c.JSON(imageErrorStatus(err), gin.H{"error": err.Error()})
+			return
 		}
 	}
 
@@ -102,6 +128,7 @@ func (h *UserHandler) Update(c *gin.Context) {
 		return
 	}
 
+	h.attachAvatars(updatedUser)
 	c.JSON(http.StatusOK, updatedUser)
 }
 
@@ -139,34 +166,31 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 	name := c.PostForm("name")
 	// If name is empty, we might want to fetch current or require it. Frontend sends it.
 
+	username := c.PostForm("username")
 	currentPassword := c.PostForm("current_password")
 	newPassword := c.PostForm("new_password")
 
 	// 3. Handle Avatar File
 	var avatarPath string
-	file, err := c.FormFile("avatar")
-	if err == nil {
-		// Create unique filename
-		filename := strconv.FormatInt(int64(userID), 10) + "_" + strconv.FormatInt(int64(c.Writer.Status()), 10) + "_" + file.Filename
-		dst := "uploads/avatars/" + filename
-
-		// Ensure directory exists (optional, but good practice)
-		// os.MkdirAll("uploads/avatars", os.ModePerm)
-
-		if err := c.SaveUploadedFile(file, dst); err == nil {
-			avatarPath = "/uploads/avatars/" + filename
-		} else {
-			// Log error if needed
+	if file, err := c.FormFile("avatar"); err == nil {
+		if avatarPath, err = h.storeAvatar(file); err != nil {
+			c.JSON(imageErrorStatus(err), gin.H{"message": err.Error(), "error": err.Error()})
+			return
 		}
 	}
 
 	// 4. Call Service
-	updatedUser, err := h.service.UpdateProfile(userID, name, currentPassword, newPassword, avatarPath)
+	updatedUser, err := h.service.UpdateProfile(userID, name, username, currentPassword, newPassword, avatarPath)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error(), "error": err.Error()})
+		status := http.StatusBadRequest
+		if errors.Is(err, domain.ErrUsernameTaken) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"message": err.Error(), "error": err.Error()})
 		return
 	}
 
+	h.attachAvatars(updatedUser)
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Profile updated successfully",
 		"user":    updatedUser,
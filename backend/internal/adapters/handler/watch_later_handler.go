@@ -38,7 +38,7 @@ func (h *WatchLaterHandler) Toggle(c *gin.Context) {
 
 	added, err := h.service.Toggle(userID, req.AnimeID, req.EpisodeID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(listErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
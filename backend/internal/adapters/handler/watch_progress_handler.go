@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"backend/internal/core/domain"
+	"backend/internal/core/service"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WatchProgressHandler struct {
+	service *service.WatchProgressService
+}
+
+func NewWatchProgressHandler(service *service.WatchProgressService) *WatchProgressHandler {
+	return &WatchProgressHandler{service: service}
+}
+
+// Save records where the caller is in an episode, in seconds
+// PUT /api/me/progress/:episodeId
+func (h *WatchProgressHandler) Save(c *gin.Context) {
+	episodeID, _ := strconv.Atoi(c.Param("episodeId"))
+	var input struct {
+		Position float64 `json:"position"`
+		Duration float64 `json:"duration" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	progress, err := h.service.Save(c.GetUint("user_id"), uint(episodeID), input.Position, input.Duration)
+	if err != nil {
+		c.JSON(progressErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, progress)
+}
+
+// ByAnime returns the caller's progress on every episode of an anime
+// GET /api/me/progress?anime_id=1
+func (h *WatchProgressHandler) ByAnime(c *gin.Context) {
+	animeID, err := strconv.Atoi(c.Query("anime_id"))
+	if err != nil || animeID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "anime_id is required"})
+		return
+	}
+
+	items, err := h.service.ByAnime(c.GetUint("user_id"), uint(animeID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items, "total": len(items)})
+}
+
+// ContinueWatching returns the next episode to play in each series the caller is watching
+// GET /api/me/continue-watching?limit=20
+func (h *WatchProgressHandler) ContinueWatching(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit <= 0 || limit > 200 {
+		limit = 20
+	}
+
+	items, err := h.service.ContinueWatching(c.GetUint("user_id"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items, "total": len(items)})
+}
+
+func progressErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrInvalidProgress):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrEpisodeNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
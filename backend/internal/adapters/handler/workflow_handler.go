@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"backend/internal/core/domain"
+	"backend/internal/core/service"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WorkflowHandler struct {
+	service *service.WorkflowService
+}
+
+func NewWorkflowHandler(service *service.WorkflowService) *WorkflowHandler {
+	return &WorkflowHandler{service: service}
+}
+
+// Status returns the state of an anime/episode, the caller's allowed transitions and the history
+// GET /api/workflow/:entity/:id
+func (h *WorkflowHandler) Status(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	status, err := h.service.Status(c.Param("entity"), uint(id), c.GetUint("user_id"), c.GetString("role"))
+	if err != nil {
+		c.JSON(workflowErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// Transition moves content to another state
+// POST /api/workflow/:entity/:id/transition {"to": "in_review", "comment": "..."}
+func (h *WorkflowHandler) Transition(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var input struct {
+		To      domain.ContentState `json:"to" binding:"required"`
+		Comment string              `json:"comment"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	status, err := h.service.Transition(c.Param("entity"), uint(id), input.To, input.Comment, c.GetUint("user_id"), c.GetString("role"))
+	if err != nil {
+		c.JSON(workflowErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// ReviewQueue lists content waiting for review (reviewers only)
+// GET /api/workflow/review-queue?entity=anime|episode
+func (h *WorkflowHandler) ReviewQueue(c *gin.Context) {
+	items, err := h.service.ReviewQueue(c.Query("entity"), c.GetUint("user_id"), c.GetString("role"))
+	if err != nil {
+		c.JSON(workflowErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+func workflowErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrContentNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrPermissionDenied):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrTransitionNotAllowed):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, domain.ErrVersionConflict):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// includeUnpublished reports whether a public read should also return draft, in review
+// and archived content. Staff screens ask for it with ?all=true; it is honored only for
+// callers who may submit content.
+func includeUnpublished(c *gin.Context, workflow *service.WorkflowService) bool {
+	userID := c.GetUint("user_id")
+	if c.Query("all") != "true" || userID == 0 {
+		return false
+	}
+	ok, err := workflow.Can(userID, c.GetString("role"), domain.PermissionContentSubmit)
+	return err == nil && ok
+}
+
+// canEdit responds with the error and returns false unless the caller may change the
+// anime or episode in its current workflow state
+func canEdit(c *gin.Context, workflow *service.WorkflowService, entityType string, id uint) bool {
+	if err := workflow.CanEdit(entityType, id, c.GetUint("user_id"), c.GetString("role")); err != nil {
+		c.JSON(workflowErrorStatus(err), gin.H{"error": err.Error()})
+		return false
+	}
+	return true
+}
+
+// requirePermission responds with 403 and returns false unless the caller holds key
+func requirePermission(c *gin.Context, workflow *service.WorkflowService, key string) bool {
+	ok, err := workflow.Can(c.GetUint("user_id"), c.GetString("role"), key)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return false
+	}
+	if !ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("%v: %s is required", domain.ErrPermissionDenied, key)})
+		return false
+	}
+	return true
+}
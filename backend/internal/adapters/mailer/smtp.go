@@ -0,0 +1,43 @@
+package mailer
+
+import (
+	"backend/config"
+	"backend/internal/core/port"
+	"fmt"
+	"mime"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SMTPMailer sends emails through an SMTP server, authenticating with PLAIN
+// auth when a username is set
+type SMTPMailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// New returns the mailer configured in cfg, or nil when no SMTP host is set
+func New(cfg *config.Config) port.Mailer {
+	if cfg.SMTPHost == "" {
+		return nil
+	}
+	var auth smtp.Auth
+	if cfg.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPHost)
+	}
+	return &SMTPMailer{addr: fmt.Sprintf("%s:%d", cfg.SMTPHost, cfg.SMTPPort), auth: auth, from: cfg.SMTPFrom}
+}
+
+func (m *SMTPMailer) Send(to, subject, body string) error {
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", m.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	return smtp.SendMail(m.addr, m.auth, m.from, []string{to}, []byte(msg.String()))
+}
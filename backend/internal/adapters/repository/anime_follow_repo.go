@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"backend/internal/core/domain"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SaveAnimeFollow follows an anime, or changes the channel of an existing follow
+func (r *SQLiteRepository) SaveAnimeFollow(follow *domain.AnimeFollow) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "anime_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"channel", "updated_at"}),
+	}).Create(follow).Error
+}
+
+// GetAnimeFollow returns a user's follow of an anime, or nil
+func (r *SQLiteRepository) GetAnimeFollow(userID, animeID uint) (*domain.AnimeFollow, error) {
+	var follow domain.AnimeFollow
+	result := r.db.Where("user_id = ? AND anime_id = ?", userID, animeID).Limit(1).Find(&follow)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+	return &follow, nil
+}
+
+// DeleteAnimeFollow unfollows an anime and reports whether the user followed it
+func (r *SQLiteRepository) DeleteAnimeFollow(userID, animeID uint) (bool, error) {
+	result := r.db.Where("user_id = ? AND anime_id = ?", userID, animeID).Delete(&domain.AnimeFollow{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// GetAnimeFollows returns the anime a user follows, latest first
+func (r *SQLiteRepository) GetAnimeFollows(userID uint, limit, offset int) ([]domain.AnimeFollow, int64, error) {
+	query := r.db.Model(&domain.AnimeFollow{}).Where("user_id = ? AND anime_id IN (?)", userID, publishedAnimeIDs(r.db))
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var follows []domain.AnimeFollow
+	err := query.Preload("Anime").Order("created_at DESC").Order("id DESC").Limit(limit).Offset(offset).Find(&follows).Error
+	return follows, total, err
+}
+
+// CountAnimeFollowers returns how many users follow an anime
+func (r *SQLiteRepository) CountAnimeFollowers(animeID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.AnimeFollow{}).Where("anime_id = ?", animeID).Count(&count).Error
+	return count, err
+}
+
+// enqueueEpisodeAnnouncement queues the new-episode notifications of a newly
+// published episode; an episode is only ever announced once
+func enqueueEpisodeAnnouncement(tx *gorm.DB, episodeID, animeID uint) error {
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&domain.EpisodeAnnouncement{EpisodeID: episodeID, AnimeID: animeID}).Error
+}
+
+// GetPendingAnnouncements returns announcements whose notifications are not all sent, oldest first
+func (r *SQLiteRepository) GetPendingAnnouncements(limit int) ([]domain.EpisodeAnnouncement, error) {
+	var announcements []domain.EpisodeAnnouncement
+	err := r.db.Where("sent_at IS NULL").Order("id").Limit(limit).Find(&announcements).Error
+	return announcements, err
+}
+
+// GetAnimeFollowersAfter returns the next batch of an anime's follows after the
+// cursor, with the followers' names and emails
+func (r *SQLiteRepository) GetAnimeFollowersAfter(animeID, afterID uint, limit int) ([]domain.AnimeFollow, error) {
+	var follows []domain.AnimeFollow
+	err := r.db.Preload("User", func(db *gorm.DB) *gorm.DB { return db.Select("id", "name", "email") }).
+		Where("anime_id = ? AND id > ?", animeID, afterID).
+		Order("id").
+		Limit(limit).
+		Find(&follows).Error
+	return follows, err
+}
+
+// errBatchClaimed rolls back a batch another replica moved the cursor of first
+var errBatchClaimed = errors.New("announcement batch claimed elsewhere")
+
+// SaveAnnouncementBatch claims one fan-out batch by moving the cursor of the
+// announcements from fromFollowID to lastFollowID, and stores its in-app
+// notifications. Dispatch runs on every replica: a batch whose cursor was moved
+// meanwhile is left to the replica that moved it, and claimed reports false.
+func (r *SQLiteRepository) SaveAnnouncementBatch(ids []uint, fromFollowID, lastFollowID uint, notifications []domain.Notification) (claimed bool, err error) {
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.EpisodeAnnouncement{}).
+			Where("id IN ? AND last_follow_id = ? AND sent_at IS NULL", ids, fromFollowID).
+			UpdateColumn("last_follow_id", lastFollowID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != int64(len(ids)) {
+			return errBatchClaimed
+		}
+		if len(notifications) > 0 {
+			return tx.CreateInBatches(notifications, 100).Error
+		}
+		return nil
+	})
+	if errors.Is(err, errBatchClaimed) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// CreateNotifications stores in-app notifications, e.g. for emails that could not be sent
+func (r *SQLiteRepository) CreateNotifications(notifications []domain.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	return r.db.CreateInBatches(notifications, 100).Error
+}
+
+// FinishAnnouncements marks announcements as fully sent
+func (r *SQLiteRepository) FinishAnnouncements(ids []uint) error {
+	return r.db.Model(&domain.EpisodeAnnouncement{}).Where("id IN ?", ids).UpdateColumn("sent_at", time.Now()).Error
+}
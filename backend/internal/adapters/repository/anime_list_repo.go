@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"backend/internal/core/domain"
+	"math"
+
+	"gorm.io/gorm"
+)
+
+// listSortOrders maps AnimeListFilter.Sort to an ORDER BY clause
+var listSortOrders = map[string]string{
+	"updated":  "anime_list_entries.updated_at DESC",
+	"score":    "anime_list_entries.score DESC, anime_list_entries.updated_at DESC",
+	"title":    "animes.title ASC",
+	"started":  "anime_list_entries.started_at DESC",
+	"finished": "anime_list_entries.finished_at DESC",
+}
+
+// GetListEntries returns a page of a user's list with the anime loaded, and the
+// total matching the filter. Anime that are not published are left out.
+func (r *SQLiteRepository) GetListEntries(userID uint, filter domain.AnimeListFilter) ([]domain.AnimeListEntry, int64, error) {
+	query := r.db.Model(&domain.AnimeListEntry{}).
+		Joins("JOIN animes ON animes.id = anime_list_entries.anime_id AND animes.deleted_at IS NULL AND animes.state = ?", domain.ContentStatePublished).
+		Where("anime_list_entries.user_id = ?", userID)
+	if filter.Status != "" {
+		query = query.Where("anime_list_entries.status = ?", filter.Status)
+	}
+	if filter.Query != "" {
+		like := "%" + filter.Query + "%"
+		query = query.Where("animes.title LIKE ? OR animes.title_en LIKE ?", like, like)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	order, ok := listSortOrders[filter.Sort]
+	if !ok {
+		order = listSortOrders["updated"]
+	}
+	var entries []domain.AnimeListEntry
+	err := query.Preload("Anime").Order(order).Order("anime_list_entries.id DESC").
+		Limit(filter.Limit).Offset(filter.Offset).Find(&entries).Error
+	return entries, total, err
+}
+
+// GetListEntry returns a user's entry for an anime, or (nil, nil) when the anime is not on their list
+func (r *SQLiteRepository) GetListEntry(userID, animeID uint) (*domain.AnimeListEntry, error) {
+	var entry domain.AnimeListEntry
+	result := r.db.Preload("Anime").Where("user_id = ? AND anime_id = ?", userID, animeID).Limit(1).Find(&entry)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+	return &entry, nil
+}
+
+// GetListEntriesByAnime returns a user's entries for the given anime keyed by anime ID
+func (r *SQLiteRepository) GetListEntriesByAnime(userID uint, animeIDs []uint) (map[uint]domain.AnimeListEntry, error) {
+	var rows []domain.AnimeListEntry
+	if err := r.db.Where("user_id = ? AND anime_id IN ?", userID, animeIDs).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	entries := make(map[uint]domain.AnimeListEntry, len(rows))
+	for _, row := range rows {
+		entries[row.AnimeID] = row
+	}
+	return entries, nil
+}
+
+func (r *SQLiteRepository) SaveListEntry(entry *domain.AnimeListEntry) error {
+	return r.db.Omit("Anime").Save(entry).Error
+}
+
+// DeleteListEntry removes an anime from a user's list and reports whether it was there
+func (r *SQLiteRepository) DeleteListEntry(userID, animeID uint) (bool, error) {
+	result := r.db.Where("user_id = ? AND anime_id = ?", userID, animeID).Delete(&domain.AnimeListEntry{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// GetListStats aggregates a user's list
+func (r *SQLiteRepository) GetListStats(userID uint) (*domain.AnimeListStats, error) {
+	stats := &domain.AnimeListStats{ByStatus: map[domain.ListStatus]int64{}, ScoreCounts: map[int]int64{}}
+	live := func() *gorm.DB {
+		return r.db.Model(&domain.AnimeListEntry{}).
+			Joins("JOIN animes ON animes.id = anime_list_entries.anime_id AND animes.deleted_at IS NULL AND animes.state = ?", domain.ContentStatePublished).
+			Where("anime_list_entries.user_id = ?", userID)
+	}
+
+	var byStatus []struct {
+		Status    domain.ListStatus
+		Entries   int64
+		Episodes  int64
+		Rewatches int64
+	}
+	err := live().
+		Select("anime_list_entries.status, COUNT(*) AS entries, COALESCE(SUM(anime_list_entries.episodes_watched), 0) AS episodes, " +
+			"COALESCE(SUM(anime_list_entries.rewatch_count), 0) AS rewatches").
+		Group("anime_list_entries.status").Scan(&byStatus).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range byStatus {
+		stats.ByStatus[row.Status] = row.Entries
+		stats.Total += row.Entries
+		stats.EpisodesWatched += row.Episodes
+		stats.Rewatches += row.Rewatches
+	}
+
+	var scores []struct {
+		Score   float64
+		Entries int64
+	}
+	err = live().
+		Select("anime_list_entries.score, COUNT(*) AS entries").
+		Where("anime_list_entries.score > 0").
+		Group("anime_list_entries.score").Scan(&scores).Error
+	if err != nil {
+		return nil, err
+	}
+	sum := 0.0
+	for _, row := range scores {
+		stats.Scored += row.Entries
+		stats.ScoreCounts[int(math.Round(row.Score))] += row.Entries
+		sum += row.Score * float64(row.Entries)
+	}
+	if stats.Scored > 0 {
+		stats.MeanScore = math.Round(sum/float64(stats.Scored)*100) / 100
+	}
+	return stats, nil
+}
+
+// CountPublishedEpisodes returns how many published episodes an anime has
+func (r *SQLiteRepository) CountPublishedEpisodes(animeID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.Episode{}).Where("anime_id = ? AND state = ?", animeID, domain.ContentStatePublished).Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,252 @@
+package repository
+
+import (
+	"backend/internal/core/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GetDuplicateScanAnimes returns the fields duplicate detection compares for every live anime
+func (r *SQLiteRepository) GetDuplicateScanAnimes() ([]domain.DuplicateAnime, error) {
+	var animes []domain.DuplicateAnime
+	err := r.db.Model(&domain.Anime{}).
+		Select("animes.id, animes.title, animes.title_en, animes.slug, animes.release_date, animes.studio_id, " +
+			"COALESCE(NULLIF(studios.name, ''), animes.studio_name) AS studio_name, animes.created_at, " +
+			"(SELECT COUNT(*) FROM episodes WHERE episodes.anime_id = animes.id AND episodes.deleted_at IS NULL) AS episodes").
+		Joins("LEFT JOIN studios ON studios.id = animes.studio_id").
+		Order("animes.id").
+		Scan(&animes).Error
+	return animes, err
+}
+
+// GetDuplicateDismissals returns the dismissed pairs keyed by [lower ID, higher ID]
+func (r *SQLiteRepository) GetDuplicateDismissals() (map[[2]uint]bool, error) {
+	var rows []domain.AnimeDuplicateDismissal
+	if err := r.db.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	pairs := make(map[[2]uint]bool, len(rows))
+	for _, row := range rows {
+		pairs[[2]uint{row.AnimeID, row.OtherID}] = true
+	}
+	return pairs, nil
+}
+
+// DismissDuplicate records that two anime are distinct. Dismissing twice is a no-op.
+func (r *SQLiteRepository) DismissDuplicate(dismissal *domain.AnimeDuplicateDismissal) error {
+	return r.db.Where(domain.AnimeDuplicateDismissal{AnimeID: dismissal.AnimeID, OtherID: dismissal.OtherID}).
+		FirstOrCreate(dismissal).Error
+}
+
+// GetAnimeRedirect returns where a merged anime now lives, by its ID or one of
+// its slugs. It returns (nil, nil) when there is no redirect.
+func (r *SQLiteRepository) GetAnimeRedirect(id uint, slug string) (*domain.AnimeRedirect, error) {
+	query := r.db.Where("from_id = ?", id)
+	if slug != "" {
+		query = r.db.Where("from_slug = ? OR from_slug_en = ?", slug, slug)
+	}
+	var redirect domain.AnimeRedirect
+	result := query.Order("id DESC").Limit(1).Find(&redirect)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+	return &redirect, nil
+}
+
+// MergeAnime moves everything attached to the loser onto the survivor in one
+// transaction, then soft-deletes the loser and leaves a redirect behind. It also
+// returns the survivor episodes that received servers, whose legacy video URLs
+// must be rebuilt.
+func (r *SQLiteRepository) MergeAnime(survivorID, loserID uint, userID *uint) (*domain.AnimeMergeReport, []uint, error) {
+	report := &domain.AnimeMergeReport{SurvivorID: survivorID, LoserID: loserID}
+	var folded []uint
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var survivor, loser domain.Anime
+		if err := tx.First(&survivor, survivorID).Error; err != nil {
+			return err
+		}
+		if err := tx.First(&loser, loserID).Error; err != nil {
+			return err
+		}
+
+		// Episodes: move the loser's, folding those whose number the survivor already has
+		var existing []domain.Episode
+		if err := tx.Select("id", "episode_number").Where("anime_id = ?", survivorID).Find(&existing).Error; err != nil {
+			return err
+		}
+		byNumber := make(map[int]uint, len(existing))
+		for _, ep := range existing {
+			byNumber[ep.EpisodeNumber] = ep.ID
+		}
+		var episodes []domain.Episode
+		if err := tx.Select("id", "episode_number").Where("anime_id = ?", loserID).Order("id").Find(&episodes).Error; err != nil {
+			return err
+		}
+		for _, ep := range episodes {
+			target, clash := byNumber[ep.EpisodeNumber]
+			if !clash {
+				if err := tx.Model(&domain.Episode{}).Where("id = ?", ep.ID).UpdateColumn("anime_id", survivorID).Error; err != nil {
+					return err
+				}
+				byNumber[ep.EpisodeNumber] = ep.ID
+				report.EpisodesMoved++
+				continue
+			}
+			servers, err := foldEpisode(tx, ep.ID, target, report)
+			if err != nil {
+				return err
+			}
+			if servers > 0 {
+				folded = append(folded, target)
+			}
+			report.EpisodesMerged++
+		}
+
+		// Anime-level history and list entries, keeping the entry a user already has on the survivor
+		result := tx.Model(&domain.History{}).Where("anime_id = ?", loserID).UpdateColumn("anime_id", survivorID)
+		if result.Error != nil {
+			return result.Error
+		}
+		report.History += result.RowsAffected
+		err := tx.Where("anime_id = ? AND user_id IN (?)", loserID,
+			tx.Model(&domain.AnimeListEntry{}).Select("user_id").Where("anime_id = ?", survivorID)).
+			Delete(&domain.AnimeListEntry{}).Error
+		if err != nil {
+			return err
+		}
+		result = tx.Model(&domain.AnimeListEntry{}).Where("anime_id = ?", loserID).UpdateColumn("anime_id", survivorID)
+		if result.Error != nil {
+			return result.Error
+		}
+		report.ListEntries = result.RowsAffected
+		if err := tx.Model(&domain.WatchProgress{}).Where("anime_id = ?", loserID).UpdateColumn("anime_id", survivorID).Error; err != nil {
+			return err
+		}
+		moved, err := moveCollectionItems(tx, domain.CollectionItemAnime, loserID, survivorID)
+		if err != nil {
+			return err
+		}
+		report.CollectionItems += moved
+
+		// Follows, keeping the one a user already has on the survivor, and queued announcements
+		err = tx.Where("anime_id = ? AND user_id IN (?)", loserID,
+			tx.Model(&domain.AnimeFollow{}).Select("user_id").Where("anime_id = ?", survivorID)).
+			Delete(&domain.AnimeFollow{}).Error
+		if err != nil {
+			return err
+		}
+		result = tx.Model(&domain.AnimeFollow{}).Where("anime_id = ?", loserID).UpdateColumn("anime_id", survivorID)
+		if result.Error != nil {
+			return result.Error
+		}
+		report.Follows = result.RowsAffected
+		if err := tx.Model(&domain.EpisodeAnnouncement{}).Where("anime_id = ?", loserID).UpdateColumn("anime_id", survivorID).Error; err != nil {
+			return err
+		}
+
+		// Category links and rating
+		result = tx.Exec("INSERT OR IGNORE INTO anime_categories (anime_id, category_id) SELECT ?, category_id FROM anime_categories WHERE anime_id = ?", survivorID, loserID)
+		if result.Error != nil {
+			return result.Error
+		}
+		report.Categories = result.RowsAffected
+		if err := tx.Exec("DELETE FROM anime_categories WHERE anime_id = ?", loserID).Error; err != nil {
+			return err
+		}
+		if survivor.Rating == 0 && loser.Rating > 0 {
+			if err := tx.Model(&survivor).UpdateColumn("rating", loser.Rating).Error; err != nil {
+				return err
+			}
+			report.RatingCopied = true
+		}
+
+		// Redirects: earlier merges into the loser follow it, a stale redirect
+		// away from either side (left by a restore from the trash) is dropped
+		result = tx.Model(&domain.AnimeRedirect{}).Where("to_id = ?", loserID).UpdateColumn("to_id", survivorID)
+		if result.Error != nil {
+			return result.Error
+		}
+		report.RedirectsUpdated = result.RowsAffected
+		if err := tx.Where("from_id IN ?", []uint{survivorID, loserID}).Delete(&domain.AnimeRedirect{}).Error; err != nil {
+			return err
+		}
+		redirect := &domain.AnimeRedirect{FromID: loserID, ToID: survivorID, FromSlug: loser.Slug, FromSlugEn: loser.SlugEn, MergedBy: userID}
+		if err := tx.Create(redirect).Error; err != nil {
+			return err
+		}
+
+		// Soft-delete the loser with its folded episodes, as DeleteAnime does
+		now := time.Now()
+		episodeIDs := tx.Model(&domain.Episode{}).Select("id").Where("anime_id = ?", loserID)
+		if err := tx.Model(&domain.EpisodeServer{}).Where("episode_id IN (?)", episodeIDs).UpdateColumn("deleted_at", now).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&domain.Episode{}).Where("anime_id = ?", loserID).UpdateColumn("deleted_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Model(&domain.Anime{}).Where("id = ?", loserID).UpdateColumn("deleted_at", now).Error
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return report, folded, nil
+}
+
+// foldEpisode moves the comments, history, watch progress and collection items of episode from
+// onto episode to, along with the subtitle languages and server URLs to lacks.
+// It returns how many servers were moved.
+func foldEpisode(tx *gorm.DB, from, to uint, report *domain.AnimeMergeReport) (int64, error) {
+	result := tx.Model(&domain.Comment{}).Where("episode_id = ?", from).UpdateColumn("episode_id", to)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	report.Comments += result.RowsAffected
+
+	result = tx.Model(&domain.History{}).Where("episode_id = ?", from).UpdateColumn("episode_id", to)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	report.History += result.RowsAffected
+
+	// Watch progress: the survivor's episode wins when the user has both
+	err := tx.Where("episode_id = ? AND user_id IN (?)", from,
+		tx.Model(&domain.WatchProgress{}).Select("user_id").Where("episode_id = ?", to)).
+		Delete(&domain.WatchProgress{}).Error
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Model(&domain.WatchProgress{}).Where("episode_id = ?", from).UpdateColumn("episode_id", to).Error; err != nil {
+		return 0, err
+	}
+	moved, err := moveCollectionItems(tx, domain.CollectionItemEpisode, from, to)
+	if err != nil {
+		return 0, err
+	}
+	report.CollectionItems += moved
+
+	var hasDefault int64
+	if err := tx.Model(&domain.SubtitleTrack{}).Where("episode_id = ? AND is_default", to).Count(&hasDefault).Error; err != nil {
+		return 0, err
+	}
+	updates := map[string]any{"episode_id": to}
+	if hasDefault > 0 {
+		updates["is_default"] = false
+	}
+	err = tx.Model(&domain.SubtitleTrack{}).
+		Where("episode_id = ? AND language NOT IN (?)", from, tx.Model(&domain.SubtitleTrack{}).Select("language").Where("episode_id = ?", to)).
+		UpdateColumns(updates).Error
+	if err != nil {
+		return 0, err
+	}
+
+	result = tx.Model(&domain.EpisodeServer{}).
+		Where("episode_id = ? AND url NOT IN (?)", from, tx.Model(&domain.EpisodeServer{}).Select("url").Where("episode_id = ?", to)).
+		UpdateColumn("episode_id", to)
+	return result.RowsAffected, result.Error
+}
+
+// SetEpisodeVideoURLs rewrites the legacy video URL JSON of an episode
+func (r *SQLiteRepository) SetEpisodeVideoURLs(id uint, videoURLs string) error {
+	return r.db.Model(&domain.Episode{}).Where("id = ?", id).UpdateColumn("video_urls", videoURLs).Error
+}
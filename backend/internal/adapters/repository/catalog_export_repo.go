@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"backend/internal/core/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// laterThan matches rows whose column is after t. Times are compared as
+// instants, so it holds whatever offset the rows were stored with.
+func laterThan(q *gorm.DB, column string, t time.Time) *gorm.DB {
+	return q.Where("julianday("+column+") > julianday(?)", t.UTC().Format(time.RFC3339Nano))
+}
+
+// eachBatch walks rows of T in primary key order, optionally limited to rows updated after since
+func eachBatch[T any](q *gorm.DB, since *time.Time, size int, fn func([]T) error) error {
+	if since != nil {
+		q = laterThan(q, "updated_at", *since)
+	}
+	var batch []T
+	return q.FindInBatches(&batch, size, func(tx *gorm.DB, _ int) error {
+		return fn(batch)
+	}).Error
+}
+
+func (r *SQLiteRepository) EachAnimeBatch(since *time.Time, size int, fn func([]domain.Anime) error) error {
+	q := r.db.Preload("Categories").Preload("Season").Preload("Studio").Preload("LanguageRel")
+	return eachBatch(q, since, size, fn)
+}
+
+func (r *SQLiteRepository) EachEpisodeBatch(since *time.Time, size int, fn func([]domain.Episode) error) error {
+	// Anime is not preloaded: rows reference it by anime_id, the slug is resolved by the caller
+	return eachBatch(r.db.Preload("Servers"), since, size, fn)
+}
+
+func (r *SQLiteRepository) EachCategoryBatch(since *time.Time, size int, fn func([]domain.Category) error) error {
+	return eachBatch(r.db, since, size, fn)
+}
+
+func (r *SQLiteRepository) EachTypeBatch(since *time.Time, size int, fn func([]domain.Type) error) error {
+	return eachBatch(r.db, since, size, fn)
+}
+
+func (r *SQLiteRepository) EachSeasonBatch(since *time.Time, size int, fn func([]domain.Season) error) error {
+	return eachBatch(r.db, since, size, fn)
+}
+
+func (r *SQLiteRepository) EachStudioBatch(since *time.Time, size int, fn func([]domain.Studio) error) error {
+	return eachBatch(r.db, since, size, fn)
+}
+
+func (r *SQLiteRepository) EachLanguageBatch(since *time.Time, size int, fn func([]domain.Language) error) error {
+	return eachBatch(r.db, since, size, fn)
+}
+
+// GetAnimeSlugs maps anime IDs to slugs, including soft-deleted anime
+func (r *SQLiteRepository) GetAnimeSlugs(ids []uint) (map[uint]string, error) {
+	var rows []struct {
+		ID   uint
+		Slug string
+	}
+	err := r.db.Unscoped().Model(&domain.Anime{}).Select("id", "slug").Where("id IN ?", ids).Find(&rows).Error
+	slugs := make(map[uint]string, len(rows))
+	for _, row := range rows {
+		slugs[row.ID] = row.Slug
+	}
+	return slugs, err
+}
+
+// GetDeletedSince lists rows of model's table soft-deleted after since
+func (r *SQLiteRepository) GetDeletedSince(model interface{}, since time.Time) ([]domain.CatalogTombstone, error) {
+	var rows []domain.CatalogTombstone
+	err := laterThan(r.db.Unscoped().Model(model), "deleted_at", since).
+		Select("id", "slug", "deleted_at").
+		Where("deleted_at IS NOT NULL").
+		Order("id").
+		Find(&rows).Error
+	return rows, err
+}
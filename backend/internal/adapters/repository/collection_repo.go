@@ -0,0 +1,399 @@
+package repository
+
+import (
+	"backend/internal/core/domain"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// publicUserColumns are the user fields shown next to collections
+func publicUserColumns(db *gorm.DB) *gorm.DB {
+	return db.Select("id", "name", "avatar")
+}
+
+// collectionSortOrders maps CollectionFilter.Sort to an ORDER BY clause
+var collectionSortOrders = map[string]string{
+	"popular": "collections.followers_count * 2 + collections.likes_count DESC, collections.updated_at DESC",
+	"recent":  "collections.updated_at DESC",
+}
+
+func (r *SQLiteRepository) CreateCollection(collection *domain.Collection) error {
+	return r.db.Omit(clause.Associations).Create(collection).Error
+}
+
+// GetCollection returns a collection with its owner and collaborators, or (nil, nil) when missing
+func (r *SQLiteRepository) GetCollection(id uint) (*domain.Collection, error) {
+	return r.findCollection(r.db.Where("collections.id = ?", id))
+}
+
+// GetCollectionBySlug returns a collection by share slug, or (nil, nil) when missing
+func (r *SQLiteRepository) GetCollectionBySlug(slug string) (*domain.Collection, error) {
+	return r.findCollection(r.db.Where("collections.slug = ?", slug))
+}
+
+func (r *SQLiteRepository) findCollection(query *gorm.DB) (*domain.Collection, error) {
+	var collection domain.Collection
+	result := query.Preload("User", publicUserColumns).
+		Preload("Collaborators", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Collaborators.User", publicUserColumns).
+		Limit(1).Find(&collection)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+	return &collection, nil
+}
+
+func (r *SQLiteRepository) UpdateCollection(id uint, fields map[string]any) error {
+	fields["updated_at"] = time.Now()
+	return r.db.Model(&domain.Collection{}).Where("id = ?", id).UpdateColumns(fields).Error
+}
+
+// DeleteCollection removes a collection with its items, collaborators, follows and likes
+func (r *SQLiteRepository) DeleteCollection(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return deleteCollections(tx, []uint{id})
+	})
+}
+
+func deleteCollections(tx *gorm.DB, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	for _, model := range []any{&domain.CollectionItem{}, &domain.CollectionCollaborator{}, &domain.CollectionFollow{}, &domain.CollectionLike{}} {
+		if err := tx.Where("collection_id IN ?", ids).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+	return tx.Where("id IN ?", ids).Delete(&domain.Collection{}).Error
+}
+
+// ListPublicCollections returns a page of public collections and the total matching the filter
+func (r *SQLiteRepository) ListPublicCollections(filter domain.CollectionFilter) ([]domain.Collection, int64, error) {
+	query := r.db.Model(&domain.Collection{}).Where("visibility = ? AND items_count > 0", domain.CollectionPublic)
+	if filter.Query != "" {
+		like := "%" + filter.Query + "%"
+		query = query.Where("title LIKE ? OR description LIKE ?", like, like)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	order, ok := collectionSortOrders[filter.Sort]
+	if !ok {
+		order = collectionSortOrders["popular"]
+	}
+	var collections []domain.Collection
+	err := query.Preload("User", publicUserColumns).Order(order).Order("collections.id DESC").
+		Limit(filter.Limit).Offset(filter.Offset).Find(&collections).Error
+	return collections, total, err
+}
+
+// ListUserCollections returns the collections a user owns, collaborates on or
+// follows (relation "owned", "shared" or "followed"), latest first
+func (r *SQLiteRepository) ListUserCollections(userID uint, relation string, limit, offset int) ([]domain.Collection, int64, error) {
+	query := r.db.Model(&domain.Collection{})
+	switch relation {
+	case "shared":
+		query = query.Where("id IN (?)", r.db.Model(&domain.CollectionCollaborator{}).Select("collection_id").Where("user_id = ?", userID))
+	case "followed":
+		query = query.Where("id IN (?) AND (visibility != ? OR user_id = ?)",
+			r.db.Model(&domain.CollectionFollow{}).Select("collection_id").Where("user_id = ?", userID), domain.CollectionPrivate, userID)
+	default:
+		query = query.Where("user_id = ?", userID)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var collections []domain.Collection
+	err := query.Preload("User", publicUserColumns).Order("updated_at DESC").Order("id DESC").
+		Limit(limit).Offset(offset).Find(&collections).Error
+	return collections, total, err
+}
+
+// GetCollectionItems returns the items of a collection in order
+func (r *SQLiteRepository) GetCollectionItems(collectionID uint) ([]domain.CollectionItem, error) {
+	var items []domain.CollectionItem
+	err := r.db.Where("collection_id = ?", collectionID).Order("position, id").Find(&items).Error
+	return items, err
+}
+
+// AddCollectionItem appends an item to a collection
+func (r *SQLiteRepository) AddCollectionItem(item *domain.CollectionItem) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var count, existing int64
+		if err := tx.Model(&domain.CollectionItem{}).Where("collection_id = ?", item.CollectionID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count >= domain.MaxCollectionItems {
+			return domain.ErrCollectionFull
+		}
+		err := tx.Model(&domain.CollectionItem{}).
+			Where("collection_id = ? AND item_type = ? AND item_id = ?", item.CollectionID, item.ItemType, item.ItemID).
+			Count(&existing).Error
+		if err != nil {
+			return err
+		}
+		if existing > 0 {
+			return domain.ErrCollectionItemExists
+		}
+		item.Position = int(count)
+		if err := tx.Create(item).Error; err != nil {
+			return err
+		}
+		return touchCollection(tx, item.CollectionID, 1)
+	})
+}
+
+// GetCollectionItem returns an item of a collection, or (nil, nil) when missing
+func (r *SQLiteRepository) GetCollectionItem(collectionID, itemID uint) (*domain.CollectionItem, error) {
+	var item domain.CollectionItem
+	result := r.db.Where("id = ? AND collection_id = ?", itemID, collectionID).Limit(1).Find(&item)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+	return &item, nil
+}
+
+func (r *SQLiteRepository) UpdateCollectionItemNote(itemID uint, note string) error {
+	return r.db.Model(&domain.CollectionItem{}).Where("id = ?", itemID).UpdateColumn("note", note).Error
+}
+
+// RemoveCollectionItem deletes an item and closes the gap it leaves in the order
+func (r *SQLiteRepository) RemoveCollectionItem(item *domain.CollectionItem) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&domain.CollectionItem{}, item.ID).Error; err != nil {
+			return err
+		}
+		err := tx.Model(&domain.CollectionItem{}).Where("collection_id = ? AND position > ?", item.CollectionID, item.Position).
+			UpdateColumn("position", gorm.Expr("position - 1")).Error
+		if err != nil {
+			return err
+		}
+		return touchCollection(tx, item.CollectionID, -1)
+	})
+}
+
+// MoveCollectionItem moves an item to position, shifting the items in between
+func (r *SQLiteRepository) MoveCollectionItem(item *domain.CollectionItem, position int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		switch {
+		case position < item.Position:
+			err = tx.Model(&domain.CollectionItem{}).
+				Where("collection_id = ? AND position >= ? AND position < ?", item.CollectionID, position, item.Position).
+				UpdateColumn("position", gorm.Expr("position + 1")).Error
+		case position > item.Position:
+			err = tx.Model(&domain.CollectionItem{}).
+				Where("collection_id = ? AND position > ? AND position <= ?", item.CollectionID, item.Position, position).
+				UpdateColumn("position", gorm.Expr("position - 1")).Error
+		default:
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&domain.CollectionItem{}).Where("id = ?", item.ID).UpdateColumn("position", position).Error; err != nil {
+			return err
+		}
+		return touchCollection(tx, item.CollectionID, 0)
+	})
+}
+
+// ReorderCollectionItems sets the order of every item of a collection. itemIDs
+// must list each item exactly once.
+func (r *SQLiteRepository) ReorderCollectionItems(collectionID uint, itemIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var current []uint
+		if err := tx.Model(&domain.CollectionItem{}).Where("collection_id = ?", collectionID).Pluck("id", &current).Error; err != nil {
+			return err
+		}
+		if len(current) != len(itemIDs) {
+			return domain.ErrInvalidReorder
+		}
+		pending := make(map[uint]bool, len(current))
+		for _, id := range current {
+			pending[id] = true
+		}
+		for _, id := range itemIDs {
+			if !pending[id] {
+				return domain.ErrInvalidReorder
+			}
+			delete(pending, id)
+		}
+		for position, id := range itemIDs {
+			if err := tx.Model(&domain.CollectionItem{}).Where("id = ?", id).UpdateColumn("position", position).Error; err != nil {
+				return err
+			}
+		}
+		return touchCollection(tx, collectionID, 0)
+	})
+}
+
+// touchCollection bumps updated_at and adjusts the item count by delta
+func touchCollection(tx *gorm.DB, collectionID uint, delta int) error {
+	return tx.Model(&domain.Collection{}).Where("id = ?", collectionID).UpdateColumns(map[string]any{
+		"items_count": gorm.Expr("items_count + ?", delta),
+		"updated_at":  time.Now(),
+	}).Error
+}
+
+func (r *SQLiteRepository) AddCollectionCollaborator(collaborator *domain.CollectionCollaborator) error {
+	return r.db.Omit(clause.Associations).Create(collaborator).Error
+}
+
+// RemoveCollectionCollaborator reports whether the user was a collaborator
+func (r *SQLiteRepository) RemoveCollectionCollaborator(collectionID, userID uint) (bool, error) {
+	result := r.db.Where("collection_id = ? AND user_id = ?", collectionID, userID).Delete(&domain.CollectionCollaborator{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// SetCollectionFollow follows or unfollows a collection and keeps its follower count
+func (r *SQLiteRepository) SetCollectionFollow(collectionID, userID uint, follow bool) error {
+	row := &domain.CollectionFollow{CollectionID: collectionID, UserID: userID}
+	return r.setCollectionReaction(row, collectionID, userID, "followers_count", follow)
+}
+
+// SetCollectionLike likes or unlikes a collection and keeps its like count
+func (r *SQLiteRepository) SetCollectionLike(collectionID, userID uint, like bool) error {
+	row := &domain.CollectionLike{CollectionID: collectionID, UserID: userID}
+	return r.setCollectionReaction(row, collectionID, userID, "likes_count", like)
+}
+
+// setCollectionReaction inserts or deletes a follow or like row; the counter only moves when the row did
+func (r *SQLiteRepository) setCollectionReaction(row any, collectionID, userID uint, counter string, on bool) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var result *gorm.DB
+		delta := 1
+		if on {
+			result = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(row)
+		} else {
+			result = tx.Where("collection_id = ? AND user_id = ?", collectionID, userID).Delete(row)
+			delta = -1
+		}
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return tx.Model(&domain.Collection{}).Where("id = ?", collectionID).
+			UpdateColumn(counter, gorm.Expr(counter+" + ?", delta)).Error
+	})
+}
+
+// GetCollectionViewer returns whether a user follows and likes a collection
+func (r *SQLiteRepository) GetCollectionViewer(collectionID, userID uint) (followed, liked bool, err error) {
+	var follows, likes int64
+	if err := r.db.Model(&domain.CollectionFollow{}).Where("collection_id = ? AND user_id = ?", collectionID, userID).Count(&follows).Error; err != nil {
+		return false, false, err
+	}
+	if err := r.db.Model(&domain.CollectionLike{}).Where("collection_id = ? AND user_id = ?", collectionID, userID).Count(&likes).Error; err != nil {
+		return false, false, err
+	}
+	return follows > 0, likes > 0, nil
+}
+
+// GetPublishedEpisodesByIDs returns the published episodes among ids
+func (r *SQLiteRepository) GetPublishedEpisodesByIDs(ids []uint) ([]domain.Episode, error) {
+	var episodes []domain.Episode
+	err := r.db.Preload("Anime").Where("id IN ? AND state = ?", ids, domain.ContentStatePublished).Find(&episodes).Error
+	return episodes, err
+}
+
+// GetModelsByIDs returns the models among ids
+func (r *SQLiteRepository) GetModelsByIDs(ids []uint) ([]domain.Model, error) {
+	var models []domain.Model
+	err := r.db.Where("id IN ?", ids).Find(&models).Error
+	return models, err
+}
+
+// purgeCollectionItems drops the items pointing at purged content and recounts the affected collections
+func purgeCollectionItems(tx *gorm.DB, itemType domain.CollectionItemType, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	var affected []uint
+	err := tx.Model(&domain.CollectionItem{}).Distinct("collection_id").
+		Where("item_type = ? AND item_id IN ?", itemType, ids).Pluck("collection_id", &affected).Error
+	if err != nil || len(affected) == 0 {
+		return err
+	}
+	if err := tx.Where("item_type = ? AND item_id IN ?", itemType, ids).Delete(&domain.CollectionItem{}).Error; err != nil {
+		return err
+	}
+	return recountCollections(tx, affected)
+}
+
+// moveCollectionItems points the items for content from at content to, dropping
+// them from collections that already hold to. It returns how many were moved.
+func moveCollectionItems(tx *gorm.DB, itemType domain.CollectionItemType, from, to uint) (int64, error) {
+	var affected []uint
+	err := tx.Model(&domain.CollectionItem{}).Where("item_type = ? AND item_id = ?", itemType, from).Pluck("collection_id", &affected).Error
+	if err != nil || len(affected) == 0 {
+		return 0, err
+	}
+	err = tx.Where("item_type = ? AND item_id = ? AND collection_id IN (?)", itemType, from,
+		tx.Model(&domain.CollectionItem{}).Select("collection_id").Where("item_type = ? AND item_id = ?", itemType, to)).
+		Delete(&domain.CollectionItem{}).Error
+	if err != nil {
+		return 0, err
+	}
+	result := tx.Model(&domain.CollectionItem{}).Where("item_type = ? AND item_id = ?", itemType, from).UpdateColumn("item_id", to)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, recountCollections(tx, affected)
+}
+
+// recountCollections renumbers the items of collections from 0 and fixes their counts
+func recountCollections(tx *gorm.DB, ids []uint) error {
+	for _, id := range ids {
+		var items []uint
+		if err := tx.Model(&domain.CollectionItem{}).Where("collection_id = ?", id).Order("position, id").Pluck("id", &items).Error; err != nil {
+			return err
+		}
+		for position, itemID := range items {
+			if err := tx.Model(&domain.CollectionItem{}).Where("id = ?", itemID).UpdateColumn("position", position).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&domain.Collection{}).Where("id = ?", id).UpdateColumn("items_count", len(items)).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// purgeUserCollections deletes a user's collections and their follows, likes
+// and collaborations on other collections
+func purgeUserCollections(tx *gorm.DB, userID uint) error {
+	var owned []uint
+	if err := tx.Model(&domain.Collection{}).Where("user_id = ?", userID).Pluck("id", &owned).Error; err != nil {
+		return err
+	}
+	if err := deleteCollections(tx, owned); err != nil {
+		return err
+	}
+	if err := tx.Where("user_id = ?", userID).Delete(&domain.CollectionCollaborator{}).Error; err != nil {
+		return err
+	}
+	counters := map[string]any{
+		"followers_count": tx.Model(&domain.CollectionFollow{}).Select("COUNT(*)").Where("collection_id = collections.id"),
+		"likes_count":     tx.Model(&domain.CollectionLike{}).Select("COUNT(*)").Where("collection_id = collections.id"),
+	}
+	var touched []uint
+	for _, model := range []any{&domain.CollectionFollow{}, &domain.CollectionLike{}} {
+		var ids []uint
+		if err := tx.Model(model).Where("user_id = ?", userID).Pluck("collection_id", &ids).Error; err != nil {
+			return err
+		}
+		touched = append(touched, ids...)
+		if err := tx.Where("user_id = ?", userID).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+	if len(touched) == 0 {
+		return nil
+	}
+	return tx.Model(&domain.Collection{}).Where("id IN ?", touched).UpdateColumns(counters).Error
+}
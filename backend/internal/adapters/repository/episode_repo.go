@@ -2,6 +2,9 @@ package repository
 
 import (
 	"backend/internal/core/domain"
+	"time"
+
+	"gorm.io/gorm"
 )
 
 type EpisodeRepository interface {
@@ -30,6 +33,24 @@ func (r *SQLiteRepository) GetAllEpisodes() ([]domain.Episode, error) {
 	return episodes, err
 }
 
+// GetPublishedEpisodeByID is GetEpisodeByID for the public site: the episode and
+// its anime must both be published
+func (r *SQLiteRepository) GetPublishedEpisodeByID(id uint) (*domain.Episode, error) {
+	var episode domain.Episode
+	err := r.db.Preload("Anime").Preload("Servers").
+		Where("state = ? AND anime_id IN (?)", domain.ContentStatePublished, publishedAnimeIDs(r.db)).
+		First(&episode, id).Error
+	return &episode, err
+}
+
+func (r *SQLiteRepository) GetPublishedEpisodes() ([]domain.Episode, error) {
+	var episodes []domain.Episode
+	err := r.db.Preload("Anime").Preload("Servers").
+		Where("state = ? AND anime_id IN (?)", domain.ContentStatePublished, publishedAnimeIDs(r.db)).
+		Find(&episodes).Error
+	return episodes, err
+}
+
 func (r *SQLiteRepository) UpdateEpisode(episode *domain.Episode) error {
 	// Explicitly update Servers association
 	if err := r.db.Model(episode).Association("Servers").Replace(episode.Servers); err != nil {
@@ -38,20 +59,43 @@ func (r *SQLiteRepository) UpdateEpisode(episode *domain.Episode) error {
 	return r.db.Save(episode).Error
 }
 
+// DeleteEpisode soft-deletes the episode and its servers with a shared deleted_at
 func (r *SQLiteRepository) DeleteEpisode(id uint) error {
-	return r.db.Delete(&domain.Episode{}, id).Error
+	now := time.Now()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.EpisodeServer{}).Where("episode_id = ?", id).UpdateColumn("deleted_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Model(&domain.Episode{}).Where("id = ?", id).UpdateColumn("deleted_at", now).Error
+	})
 }
 
-func (r *SQLiteRepository) GetEpisodesByAnimeID(animeID uint) ([]domain.Episode, error) {
+// GetAllEpisodesByAnimeID returns every episode of the anime whatever its workflow state
+func (r *SQLiteRepository) GetAllEpisodesByAnimeID(animeID uint) ([]domain.Episode, error) {
 	var episodes []domain.Episode
 	err := r.db.Preload("Servers").Where("anime_id = ?", animeID).Find(&episodes).Error
 	return episodes, err
 }
 
+func (r *SQLiteRepository) GetEpisodesByAnimeID(animeID uint) ([]domain.Episode, error) {
+	var episodes []domain.Episode
+	err := r.db.Preload("Servers").
+		Where("anime_id = ? AND state = ? AND anime_id IN (?)", animeID, domain.ContentStatePublished, publishedAnimeIDs(r.db)).
+		Find(&episodes).Error
+	return episodes, err
+}
+
+// publishedAnimeIDs is a subquery of anime visible on the public site
+func publishedAnimeIDs(db *gorm.DB) *gorm.DB {
+	return db.Model(&domain.Anime{}).Select("id").Where("state = ?", domain.ContentStatePublished)
+}
+
 func (r *SQLiteRepository) GetLatestEpisodes(limit int) ([]domain.Episode, error) {
 	var episodes []domain.Episode
 	// Preload Anime and Servers
-	err := r.db.Preload("Anime").Preload("Servers").Order("created_at desc").Limit(limit).Find(&episodes).Error
+	err := r.db.Preload("Anime").Preload("Servers").
+		Where("state = ? AND anime_id IN (?)", domain.ContentStatePublished, publishedAnimeIDs(r.db)).
+		Order("created_at desc").Limit(limit).Find(&episodes).Error
 	return episodes, err
 }
 
@@ -63,6 +107,7 @@ func (r *SQLiteRepository) SearchEpisodes(query string) ([]domain.Episode, error
 		Joins("LEFT JOIN animes ON episodes.anime_id = animes.id").
 		Where("episodes.title LIKE ? OR episodes.title_en LIKE ? OR animes.title LIKE ? OR animes.title_en LIKE ? OR CAST(episodes.episode_number AS TEXT) LIKE ?",
 			searchPattern, searchPattern, searchPattern, searchPattern, searchPattern).
+		Where("episodes.state = ? AND animes.state = ?", domain.ContentStatePublished, domain.ContentStatePublished).
 		Order("episodes.created_at desc").
 		Limit(50).
 		Find(&episodes).Error
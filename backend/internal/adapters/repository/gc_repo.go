@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"backend/internal/core/domain"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// uploadColumns lists every column that may hold the URL or path of an upload.
+// Episode video URLs and revision snapshots are JSON and may hold several.
+var uploadColumns = []struct {
+	model  any
+	column string
+}{
+	{&domain.Anime{}, "image"},
+	{&domain.Anime{}, "cover"},
+	{&domain.Episode{}, "thumbnail"},
+	{&domain.Episode{}, "banner"},
+	{&domain.Episode{}, "video_urls"},
+	{&domain.EpisodeServer{}, "url"},
+	{&domain.Model{}, "path"},
+	{&domain.Model{}, "image"},
+	{&domain.Model{}, "mini_blur_path"},
+	{&domain.User{}, "avatar"},
+	{&domain.SubtitleTrack{}, "path"},
+	{&domain.Revision{}, "snapshot"},
+}
+
+// GetUploadRefs returns the distinct values of every column that mention
+// uploads/, deleted rows included since they may still be restored, plus the
+// paths of stored media that still have references
+func (r *SQLiteRepository) GetUploadRefs() ([]string, error) {
+	var refs []string
+	for _, col := range uploadColumns {
+		var values []string
+		if err := r.db.Unscoped().Model(col.model).Distinct().Where(col.column+" LIKE ?", "%uploads/%").Pluck(col.column, &values).Error; err != nil {
+			return nil, err
+		}
+		refs = append(refs, values...)
+	}
+	var paths []string
+	if err := r.db.Model(&domain.Media{}).Where("ref_count > 0").Pluck("path", &paths).Error; err != nil {
+		return nil, err
+	}
+	return append(refs, paths...), nil
+}
+
+// ClaimOrphanMedia marks the row of a stored file that has no references and
+// was not stored again since before as being deleted, the way ClaimReleasedMedia
+// does for released files. A file without a row, e.g. after an interrupted run,
+// is claimed with a new row so it is not stored again while it is moved. It
+// reports whether this call made the claim.
+func (r *SQLiteRepository) ClaimOrphanMedia(media *domain.Media, before time.Time) (bool, error) {
+	result := r.db.Model(&domain.Media{}).
+		Where("id = ? AND deleting = ? AND ref_count = 0 AND updated_at < ?", media.ID, false, before).
+		UpdateColumns(map[string]any{"deleting": true, "updated_at": time.Now()})
+	if result.Error != nil || result.RowsAffected > 0 {
+		return result.RowsAffected > 0, result.Error
+	}
+	media.Deleting = true
+	result = r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(media)
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeleteImageAsset removes the processed image of a source
+func (r *SQLiteRepository) DeleteImageAsset(source string) error {
+	return r.db.Where("source = ?", source).Delete(&domain.ImageAsset{}).Error
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"backend/internal/core/domain"
+
+	"gorm.io/gorm/clause"
+)
+
+// GetImageAssets returns the processed images of the given sources keyed by source
+func (r *SQLiteRepository) GetImageAssets(sources []string) (map[string]*domain.ImageAsset, error) {
+	assets := map[string]*domain.ImageAsset{}
+	if len(sources) == 0 {
+		return assets, nil
+	}
+	var rows []domain.ImageAsset
+	if err := r.db.Where("source IN ?", sources).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		assets[rows[i].Source] = &rows[i]
+	}
+	return assets, nil
+}
+
+// GetImageAssetSources returns the source of every processed image
+func (r *SQLiteRepository) GetImageAssetSources() ([]string, error) {
+	var sources []string
+	err := r.db.Model(&domain.ImageAsset{}).Pluck("source", &sources).Error
+	return sources, err
+}
+
+// SaveImageAsset inserts or replaces the processed image of a source
+func (r *SQLiteRepository) SaveImageAsset(asset *domain.ImageAsset) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "source"}},
+		DoUpdates: clause.AssignmentColumns([]string{"width", "height", "blur_hash", "lqip", "variants", "error", "updated_at"}),
+	}).Create(asset).Error
+}
+
+// GetImageRefs returns the distinct non-empty image references of live anime,
+// episodes, models and users
+func (r *SQLiteRepository) GetImageRefs() ([]string, error) {
+	columns := []struct {
+		model  any
+		column string
+	}{
+		{&domain.Anime{}, "image"},
+		{&domain.Anime{}, "cover"},
+		{&domain.Episode{}, "thumbnail"},
+		{&domain.Model{}, "image"},
+		{&domain.User{}, "avatar"},
+	}
+	seen := map[string]bool{}
+	var refs []string
+	for _, col := range columns {
+		var values []string
+		if err := r.db.Model(col.model).Distinct().Where(col.column+" <> ''").Pluck(col.column, &values).Error; err != nil {
+			return nil, err
+		}
+		for _, v := range values {
+			if !seen[v] {
+				seen[v] = true
+				refs = append(refs, v)
+			}
+		}
+	}
+	return refs, nil
+}
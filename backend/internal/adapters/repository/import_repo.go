@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"backend/internal/core/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Transaction runs fn against a repository bound to a single database transaction.
+// Returning an error from fn rolls everything back.
+func (r *SQLiteRepository) Transaction(fn func(tx *SQLiteRepository) error) error {
+	return r.db.Transaction(func(db *gorm.DB) error {
+		return fn(&SQLiteRepository{db: db})
+	})
+}
+
+// findBySlug returns (nil, nil) when no row matches so callers can decide between create and update
+func findBySlug[T any](db *gorm.DB, slug string) (*T, error) {
+	var row T
+	result := db.Where("slug = ?", slug).Limit(1).Find(&row)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+	return &row, nil
+}
+
+func (r *SQLiteRepository) GetCategoryBySlug(slug string) (*domain.Category, error) {
+	return findBySlug[domain.Category](r.db, slug)
+}
+
+func (r *SQLiteRepository) GetTypeBySlug(slug string) (*domain.Type, error) {
+	return findBySlug[domain.Type](r.db, slug)
+}
+
+func (r *SQLiteRepository) GetSeasonBySlug(slug string) (*domain.Season, error) {
+	return findBySlug[domain.Season](r.db, slug)
+}
+
+func (r *SQLiteRepository) GetStudioBySlug(slug string) (*domain.Studio, error) {
+	return findBySlug[domain.Studio](r.db, slug)
+}
+
+func (r *SQLiteRepository) GetLanguageBySlug(slug string) (*domain.Language, error) {
+	return findBySlug[domain.Language](r.db, slug)
+}
+
+// GetAnimeBySlug also follows merge redirects, so importing a merged anime's
+// slug updates the anime that absorbed it instead of recreating the duplicate
+func (r *SQLiteRepository) GetAnimeBySlug(slug string) (*domain.Anime, error) {
+	anime, err := findBySlug[domain.Anime](r.db.Preload("Categories"), slug)
+	if anime != nil || err != nil {
+		return anime, err
+	}
+	redirect, err := r.GetAnimeRedirect(0, slug)
+	if redirect == nil || err != nil {
+		return nil, err
+	}
+	var survivor domain.Anime
+	result := r.db.Preload("Categories").Where("id = ?", redirect.ToID).Limit(1).Find(&survivor)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+	return &survivor, nil
+}
+
+func (r *SQLiteRepository) GetEpisodeBySlug(slug string) (*domain.Episode, error) {
+	return findBySlug[domain.Episode](r.db.Preload("Servers"), slug)
+}
+
+// SaveDictionary creates or updates any dictionary row (category, type, season, studio, language)
+func (r *SQLiteRepository) SaveDictionary(row interface{}) error {
+	return r.db.Save(row).Error
+}
+
+// SaveAnimeWithCategories saves the anime's own columns and replaces its category links.
+// Belongs-to associations are omitted so that SeasonID/StudioID/LanguageID are written as set.
+// The version is bumped (see saveVersioned) and revision, when given, is recorded with it.
+func (r *SQLiteRepository) SaveAnimeWithCategories(anime *domain.Anime, categories []domain.Category, revision *domain.Revision) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := saveVersioned(tx, &domain.Anime{}, anime.ID, &anime.Version); err != nil {
+			return err
+		}
+		if err := tx.Omit(clause.Associations).Save(anime).Error; err != nil {
+			return err
+		}
+		if categories != nil {
+			if err := tx.Model(anime).Association("Categories").Replace(categories); err != nil {
+				return err
+			}
+			anime.Categories = categories
+		}
+		return recordRevision(tx, revision, domain.RevisionEntityAnime, anime.ID, anime.Version)
+	})
+}
+
+// SaveEpisodeWithServers saves the episode's own columns. When servers is non-nil the
+// episode's existing servers are deleted and replaced. Versioning works as for anime.
+// Saving an episode as published when it was not queues its new-episode notifications.
+func (r *SQLiteRepository) SaveEpisodeWithServers(episode *domain.Episode, servers []domain.EpisodeServer, revision *domain.Revision) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var previous []domain.ContentState
+		if episode.ID != 0 {
+			if err := tx.Model(&domain.Episode{}).Where("id = ?", episode.ID).Pluck("state", &previous).Error; err != nil {
+				return err
+			}
+		}
+		if err := saveVersioned(tx, &domain.Episode{}, episode.ID, &episode.Version); err != nil {
+			return err
+		}
+		if err := tx.Omit(clause.Associations).Save(episode).Error; err != nil {
+			return err
+		}
+		if episode.State == domain.ContentStatePublished && (len(previous) == 0 || previous[0] != domain.ContentStatePublished) {
+			if err := enqueueEpisodeAnnouncement(tx, episode.ID, episode.AnimeID); err != nil {
+				return err
+			}
+		}
+		if servers != nil {
+			if err := tx.Where("episode_id = ?", episode.ID).Delete(&domain.EpisodeServer{}).Error; err != nil {
+				return err
+			}
+			for i := range servers {
+				servers[i].ID = 0
+				servers[i].EpisodeID = episode.ID
+			}
+			if len(servers) > 0 {
+				if err := tx.Create(&servers).Error; err != nil {
+					return err
+				}
+			}
+			episode.Servers = servers
+		}
+		return recordRevision(tx, revision, domain.RevisionEntityEpisode, episode.ID, episode.Version)
+	})
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"backend/internal/core/domain"
+)
+
+// GetAnimeTitles loads only the columns needed for title matching (plus the image for display).
+// Only published anime are matched, so imports never reveal drafts or anime under review.
+func (r *SQLiteRepository) GetAnimeTitles() ([]domain.Anime, error) {
+	var animes []domain.Anime
+	err := r.db.Select("id", "title", "title_en", "slug", "slug_en", "image").
+		Where("id IN (?)", publishedAnimeIDs(r.db)).
+		Find(&animes).Error
+	return animes, err
+}
+
+// GetPublishedEpisodeByNumber finds a published episode of an anime by its number
+func (r *SQLiteRepository) GetPublishedEpisodeByNumber(animeID uint, number int) (*domain.Episode, error) {
+	var episode domain.Episode
+	result := r.db.Where("anime_id = ? AND episode_number = ? AND state = ?", animeID, number, domain.ContentStatePublished).Limit(1).Find(&episode)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+	return &episode, nil
+}
+
+// HasImportedHistory reports whether an anime entry from source was already imported for the user
+func (r *SQLiteRepository) HasImportedHistory(userID, animeID uint, source string) (bool, error) {
+	var count int64
+	err := r.db.Model(&domain.History{}).
+		Where("user_id = ? AND anime_id = ? AND activity_type = ? AND import_source = ?", userID, animeID, domain.ActivityListImport, source).
+		Count(&count).Error
+	return count > 0, err
+}
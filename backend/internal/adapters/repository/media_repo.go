@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"backend/internal/core/domain"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetMedia returns a stored file by its hash
+func (r *SQLiteRepository) GetMedia(id string) (*domain.Media, error) {
+	var media domain.Media
+	result := r.db.Where("id = ?", id).Limit(1).Find(&media)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return nil, domain.ErrMediaNotFound
+	}
+	return &media, result.Error
+}
+
+// SaveMedia inserts a stored file. Storing a released file again revives it;
+// a file claimed for deletion is left alone and domain.ErrMediaDeleting returned.
+func (r *SQLiteRepository) SaveMedia(media *domain.Media) error {
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.Assignments(map[string]any{"released_at": nil, "updated_at": time.Now()}),
+		Where:     clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "media.deleting = ?", Vars: []any{false}}}},
+	}).Create(media)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return domain.ErrMediaDeleting
+	}
+	return result.Error
+}
+
+// unreferencedBySnapshots keeps files that a revision snapshot still points at:
+// rolling back to that revision brings the reference back
+const unreferencedBySnapshots = "NOT EXISTS (SELECT 1 FROM revisions WHERE revisions.snapshot LIKE '%' || media.id || '%')"
+
+// releasedMedia matches files whose last reference was dropped and that no
+// revision snapshot holds, plus claims older than the cutoff argument, left by a
+// replica that stopped before finishing the deletion
+const releasedMedia = "(deleting = false AND ref_count = 0 AND released_at IS NOT NULL AND " + unreferencedBySnapshots + ") OR (deleting = true AND updated_at < ?)"
+
+// GetReleasedMedia returns the files that may be deleted, including claims
+// abandoned before staleClaim
+func (r *SQLiteRepository) GetReleasedMedia(staleClaim time.Time) ([]domain.Media, error) {
+	var media []domain.Media
+	err := r.db.Where(releasedMedia, staleClaim).Find(&media).Error
+	return media, err
+}
+
+// ClaimReleasedMedia marks a released file as being deleted, so no replica
+// revives it while its file is removed. The conditions are checked again in the
+// same statement, and only the replica whose update changed the row may delete
+// the file; it reports whether this call made the claim.
+func (r *SQLiteRepository) ClaimReleasedMedia(id string, staleClaim time.Time) (bool, error) {
+	result := r.db.Model(&domain.Media{}).
+		Where("id = ?", id).Where(releasedMedia, staleClaim).
+		UpdateColumns(map[string]any{"deleting": true, "updated_at": time.Now()})
+	return result.RowsAffected > 0, result.Error
+}
+
+// UnclaimMedia returns a claimed file to the released ones, e.g. when its
+// file could not be removed
+func (r *SQLiteRepository) UnclaimMedia(id string) error {
+	return r.db.Model(&domain.Media{}).Where("id = ? AND deleting = ?", id, true).
+		UpdateColumns(map[string]any{"deleting": false, "updated_at": time.Now()}).Error
+}
+
+// DeleteClaimedMedia removes the row of a claimed file once the file is gone
+func (r *SQLiteRepository) DeleteClaimedMedia(id string) error {
+	return r.db.Where("id = ? AND deleting = ?", id, true).Delete(&domain.Media{}).Error
+}
+
+// SetMediaRefs points the given fields of an owner row at media IDs. A field
+// mapped to "" drops its reference; fields absent from refs are left alone.
+func (r *SQLiteRepository) SetMediaRefs(ownerType string, ownerID uint, refs map[string]string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var existing []domain.MediaRef
+		if err := tx.Where("owner_type = ? AND owner_id = ?", ownerType, ownerID).Find(&existing).Error; err != nil {
+			return err
+		}
+		current := map[string]string{}
+		for _, ref := range existing {
+			current[ref.Field] = ref.MediaID
+		}
+
+		var affected []string
+		for field, mediaID := range refs {
+			old, ok := current[field]
+			if old == mediaID {
+				continue
+			}
+			if ok {
+				if err := tx.Where("owner_type = ? AND owner_id = ? AND field = ?", ownerType, ownerID, field).Delete(&domain.MediaRef{}).Error; err != nil {
+					return err
+				}
+				affected = append(affected, old)
+			}
+			if mediaID != "" {
+				ref := domain.MediaRef{MediaID: mediaID, OwnerType: ownerType, OwnerID: ownerID, Field: field}
+				if err := tx.Create(&ref).Error; err != nil {
+					return err
+				}
+				affected = append(affected, mediaID)
+			}
+		}
+		return recountMedia(tx, affected)
+	})
+}
+
+// ReplaceMediaRefs swaps every reference for refs, e.g. after collecting them
+// from the rows. Counts are recomputed but nothing is marked released, since
+// files that were never referenced are left to garbage collection.
+func (r *SQLiteRepository) ReplaceMediaRefs(refs []domain.MediaRef) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&domain.MediaRef{}).Error; err != nil {
+			return err
+		}
+		if len(refs) > 0 {
+			if err := tx.CreateInBatches(refs, 200).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Exec("UPDATE media SET ref_count = (SELECT COUNT(*) FROM media_refs WHERE media_refs.media_id = media.id)").Error; err != nil {
+			return err
+		}
+		return tx.Exec("UPDATE media SET released_at = NULL WHERE ref_count > 0").Error
+	})
+}
+
+// CollectMediaRefs reads the media references held by every row, deleted ones
+// included since they may still be restored
+func (r *SQLiteRepository) CollectMediaRefs() ([]domain.MediaRef, error) {
+	var refs []domain.MediaRef
+	for _, column := range domain.MediaColumns {
+		var rows []struct {
+			ID    uint
+			Value string
+		}
+		err := r.db.Unscoped().Model(mediaOwnerModel(column.OwnerType)).
+			Select("id, "+column.Column+" AS value").
+			Where(column.Column+" LIKE ?", "%"+domain.MediaDir+"/%").
+			Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			if id, ok := domain.MediaIDFromURL(row.Value); ok {
+				refs = append(refs, domain.MediaRef{MediaID: id, OwnerType: column.OwnerType, OwnerID: row.ID, Field: column.Field})
+			}
+		}
+	}
+	return refs, nil
+}
+
+// GetLegacyMediaValues returns the distinct values of a media column that do not
+// point into the store yet
+func (r *SQLiteRepository) GetLegacyMediaValues(column domain.MediaColumn) ([]string, error) {
+	var values []string
+	err := r.db.Unscoped().Model(mediaOwnerModel(column.OwnerType)).Distinct().
+		Where(column.Column+" <> '' AND "+column.Column+" NOT LIKE ?", "%"+domain.MediaDir+"/%").
+		Pluck(column.Column, &values).Error
+	return values, err
+}
+
+// GetMediaValueOwners returns the IDs of the rows whose media column holds value
+func (r *SQLiteRepository) GetMediaValueOwners(column domain.MediaColumn, value string) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(mediaOwnerModel(column.OwnerType)).Where(column.Column+" = ?", value).Pluck("id", &ids).Error
+	return ids, err
+}
+
+// ReplaceMediaValue rewrites a media column from one value to another on every
+// row. It bypasses versioning, so it is meant for rows without revisions.
+func (r *SQLiteRepository) ReplaceMediaValue(column domain.MediaColumn, from, to string) error {
+	return r.db.Unscoped().Model(mediaOwnerModel(column.OwnerType)).
+		Where(column.Column+" = ?", from).
+		UpdateColumn(column.Column, to).Error
+}
+
+func mediaOwnerModel(ownerType string) any {
+	switch ownerType {
+	case domain.MediaOwnerAnime:
+		return &domain.Anime{}
+	case domain.MediaOwnerEpisode:
+		return &domain.Episode{}
+	case domain.MediaOwnerModel:
+		return &domain.Model{}
+	default:
+		return &domain.User{}
+	}
+}
+
+// releaseMediaRefs drops every reference held by the given owner rows
+func releaseMediaRefs(tx *gorm.DB, ownerType string, ownerIDs []uint) error {
+	if len(ownerIDs) == 0 {
+		return nil
+	}
+	var mediaIDs []string
+	if err := tx.Model(&domain.MediaRef{}).Where("owner_type = ? AND owner_id IN ?", ownerType, ownerIDs).Pluck("media_id", &mediaIDs).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("owner_type = ? AND owner_id IN ?", ownerType, ownerIDs).Delete(&domain.MediaRef{}).Error; err != nil {
+		return err
+	}
+	return recountMedia(tx, mediaIDs)
+}
+
+// recountMedia recomputes the reference counts of the given media. Files that
+// lose their last reference are marked released and files referenced again are
+// revived.
+func recountMedia(tx *gorm.DB, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	now := time.Now()
+	if err := tx.Exec("UPDATE media SET ref_count = (SELECT COUNT(*) FROM media_refs WHERE media_refs.media_id = media.id), updated_at = ? WHERE id IN ?", now, ids).Error; err != nil {
+		return err
+	}
+	if err := tx.Exec("UPDATE media SET released_at = ? WHERE id IN ? AND ref_count = 0 AND released_at IS NULL", now, ids).Error; err != nil {
+		return err
+	}
+	return tx.Exec("UPDATE media SET released_at = NULL WHERE id IN ? AND ref_count > 0", ids).Error
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"backend/internal/core/domain"
+
+	"gorm.io/gorm"
+)
+
+// uniqueUsername returns the first free handle derived from a name and email.
+// Users in the trash keep their handle.
+func uniqueUsername(tx *gorm.DB, name, email string) (string, error) {
+	base := domain.UsernameBase(name, email)
+	for n := 1; ; n++ {
+		candidate := domain.UsernameCandidate(base, n)
+		var count int64
+		if err := tx.Unscoped().Model(&domain.User{}).Where("username = ?", candidate).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return candidate, nil
+		}
+	}
+}
+
+// GetUserByUsername returns the user with a handle, or nil
+func (r *SQLiteRepository) GetUserByUsername(username string) (*domain.User, error) {
+	var user domain.User
+	result := r.db.Where("username = ?", username).Limit(1).Find(&user)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+	return &user, nil
+}
+
+// UsernameTaken reports whether a user other than exceptID has the handle,
+// including users in the trash
+func (r *SQLiteRepository) UsernameTaken(username string, exceptID uint) (bool, error) {
+	var count int64
+	err := r.db.Unscoped().Model(&domain.User{}).Where("username = ? AND id != ?", username, exceptID).Count(&count).Error
+	return count > 0, err
+}
+
+// GetFavoriteListEntries returns the highest scored anime on a user's list,
+// without the entries' private details
+func (r *SQLiteRepository) GetFavoriteListEntries(userID uint, limit int) ([]domain.FavoriteAnime, error) {
+	var entries []domain.AnimeListEntry
+	err := r.db.Select("id", "anime_id", "score", "status").Preload("Anime").
+		Where("user_id = ? AND score > 0 AND anime_id IN (?)", userID, publishedAnimeIDs(r.db)).
+		Order("score DESC").Order("updated_at DESC").
+		Limit(limit).
+		Find(&entries).Error
+	favorites := make([]domain.FavoriteAnime, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Anime != nil {
+			favorites = append(favorites, domain.FavoriteAnime{Anime: entry.Anime, Score: entry.Score, Status: entry.Status})
+		}
+	}
+	return favorites, err
+}
+
+// GetTopGenres counts the categories of the anime a user watches or watched,
+// most frequent first; planned and dropped anime are left out
+func (r *SQLiteRepository) GetTopGenres(userID uint, limit int) ([]domain.GenreCount, error) {
+	genres := []domain.GenreCount{}
+	err := r.db.Table("anime_list_entries").
+		Select("categories.id, categories.name, categories.name_en, categories.slug, COUNT(*) AS count").
+		Joins("JOIN anime_categories ON anime_categories.anime_id = anime_list_entries.anime_id").
+		Joins("JOIN categories ON categories.id = anime_categories.category_id AND categories.deleted_at IS NULL").
+		Where("anime_list_entries.user_id = ? AND anime_list_entries.status NOT IN ?", userID,
+			[]domain.ListStatus{domain.ListStatusPlanToWatch, domain.ListStatusDropped}).
+		Where("anime_list_entries.anime_id IN (?)", publishedAnimeIDs(r.db)).
+		Group("categories.id").
+		Order("count DESC").Order("categories.id").
+		Limit(limit).
+		Scan(&genres).Error
+	return genres, err
+}
+
+// CountUserComments returns how many comments a user has written
+func (r *SQLiteRepository) CountUserComments(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.Comment{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// CountCompletedEpisodes returns how many episodes a user finished watching
+func (r *SQLiteRepository) CountCompletedEpisodes(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.WatchProgress{}).Where("user_id = ? AND completed", userID).Count(&count).Error
+	return count, err
+}
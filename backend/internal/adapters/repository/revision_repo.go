@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"backend/internal/core/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// saveVersioned prepares a row for saving: new rows start at version 1, existing rows are
+// bumped only if their stored version still equals *version (the one the caller loaded).
+// Anything else means a concurrent edit and yields domain.ErrVersionConflict.
+func saveVersioned(tx *gorm.DB, model interface{}, id uint, version *int) error {
+	if id == 0 {
+		*version = 1
+		return nil
+	}
+	result := tx.Model(model).
+		Where("id = ? AND version = ?", id, *version).
+		UpdateColumn("version", gorm.Expr("version + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrVersionConflict
+	}
+	*version++
+	return nil
+}
+
+func recordRevision(tx *gorm.DB, revision *domain.Revision, entityType string, entityID uint, version int) error {
+	if revision == nil {
+		return nil
+	}
+	revision.EntityType = entityType
+	revision.EntityID = entityID
+	revision.Version = version
+	return tx.Create(revision).Error
+}
+
+func (r *SQLiteRepository) CreateRevision(revision *domain.Revision) error {
+	return r.db.Create(revision).Error
+}
+
+// CreateBaselineRevision stores a baseline revision unless one was stored for
+// the same version in the meantime, by a concurrent first edit
+func (r *SQLiteRepository) CreateBaselineRevision(revision *domain.Revision) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(revision).Error
+}
+
+// GetRevisions lists an entity's revisions, newest first, without their snapshots
+func (r *SQLiteRepository) GetRevisions(entityType string, entityID uint) ([]domain.Revision, error) {
+	var revisions []domain.Revision
+	err := r.db.Omit("snapshot").
+		Preload("User", func(db *gorm.DB) *gorm.DB { return db.Select("id", "name", "avatar") }).
+		Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Order("version desc").
+		Find(&revisions).Error
+	return revisions, err
+}
+
+func (r *SQLiteRepository) GetRevision(entityType string, entityID uint, version int) (*domain.Revision, error) {
+	var revision domain.Revision
+	err := r.db.Preload("User", func(db *gorm.DB) *gorm.DB { return db.Select("id", "name", "avatar") }).
+		Where("entity_type = ? AND entity_id = ? AND version = ?", entityType, entityID, version).
+		First(&revision).Error
+	return &revision, err
+}
+
+func (r *SQLiteRepository) CountRevisions(entityType string, entityID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.Revision{}).Where("entity_type = ? AND entity_id = ?", entityType, entityID).Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"backend/internal/core/domain"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetServersToCheck returns one live server per distinct URL whose health was not
+// checked since before, least recently checked first
+func (r *SQLiteRepository) GetServersToCheck(before time.Time, limit int) ([]domain.EpisodeServer, error) {
+	var servers []domain.EpisodeServer
+	err := r.db.Model(&domain.EpisodeServer{}).
+		Select("MIN(episode_servers.id) AS id, episode_servers.url, episode_servers.headers").
+		Joins("LEFT JOIN server_healths ON server_healths.url = episode_servers.url").
+		Where("server_healths.checked_at IS NULL OR server_healths.checked_at < ?", before).
+		Group("episode_servers.url").
+		Order("server_healths.checked_at").
+		Limit(limit).
+		Find(&servers).Error
+	return servers, err
+}
+
+// GetServerHealth returns the health of a URL, or nil if it was never checked
+func (r *SQLiteRepository) GetServerHealth(url string) (*domain.ServerHealth, error) {
+	var health domain.ServerHealth
+	result := r.db.Where("url = ?", url).Limit(1).Find(&health)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+	return &health, nil
+}
+
+// GetServerHealths returns the health of each checked URL keyed by URL
+func (r *SQLiteRepository) GetServerHealths(urls []string) (map[string]*domain.ServerHealth, error) {
+	healths := map[string]*domain.ServerHealth{}
+	if len(urls) == 0 {
+		return healths, nil
+	}
+	var rows []domain.ServerHealth
+	if err := r.db.Where("url IN ?", urls).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		healths[rows[i].URL] = &rows[i]
+	}
+	return healths, nil
+}
+
+// RecordServerProbe stores a probe of health.URL. health is filled as the URL's first
+// probe; on later probes the consecutive failures and the broken status are counted
+// from the stored row in the same statement, so concurrent probes never lose one.
+func (r *SQLiteRepository) RecordServerProbe(health *domain.ServerHealth, brokenAfter int) (*domain.ServerHealth, error) {
+	err := r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "url"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"host":        gorm.Expr("excluded.host"),
+			"status_code": gorm.Expr("excluded.status_code"),
+			"latency_ms":  gorm.Expr("excluded.latency_ms"),
+			"error":       gorm.Expr("excluded.error"),
+			"checked_at":  gorm.Expr("excluded.checked_at"),
+			"last_ok_at":  gorm.Expr("COALESCE(excluded.last_ok_at, server_healths.last_ok_at)"),
+			"consecutive_failures": gorm.Expr("CASE WHEN excluded.status = ? THEN 0 ELSE server_healths.consecutive_failures + 1 END",
+				domain.ServerHealthOK),
+			"status": gorm.Expr("CASE WHEN excluded.status = ? THEN excluded.status WHEN server_healths.consecutive_failures + 1 >= ? THEN ? ELSE excluded.status END",
+				domain.ServerHealthOK, brokenAfter, domain.ServerHealthBroken),
+		}),
+	}).Create(health).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.GetServerHealth(health.URL)
+}
+
+// PruneServerHealth deletes health rows of URLs no live server uses any more
+func (r *SQLiteRepository) PruneServerHealth() (int64, error) {
+	used := r.db.Model(&domain.EpisodeServer{}).Select("url")
+	result := r.db.Where("url NOT IN (?)", used).Delete(&domain.ServerHealth{})
+	return result.RowsAffected, result.Error
+}
+
+// GetEpisodeServer returns a live server of an episode
+func (r *SQLiteRepository) GetEpisodeServer(episodeID, serverID uint) (*domain.EpisodeServer, error) {
+	var server domain.EpisodeServer
+	query := r.db.Where("id = ?", serverID)
+	if episodeID != 0 {
+		query = query.Where("episode_id = ?", episodeID)
+	}
+	result := query.Limit(1).Find(&server)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return nil, domain.ErrServerNotFound
+	}
+	return &server, result.Error
+}
+
+// CreateServerReport stores a report unless the user already has an open one for the URL
+func (r *SQLiteRepository) CreateServerReport(report *domain.ServerReport) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var open int64
+		if err := tx.Model(&domain.ServerReport{}).
+			Where("user_id = ? AND url = ? AND resolved_at IS NULL", report.UserID, report.URL).
+			Count(&open).Error; err != nil {
+			return err
+		}
+		if open > 0 {
+			return domain.ErrAlreadyReported
+		}
+		return tx.Create(report).Error
+	})
+}
+
+// ResolveServerReports closes every open report of a URL
+func (r *SQLiteRepository) ResolveServerReports(url string) (int64, error) {
+	result := r.db.Model(&domain.ServerReport{}).
+		Where("url = ? AND resolved_at IS NULL", url).
+		UpdateColumn("resolved_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+// GetBrokenServers lists live servers whose URL is broken or has open reports,
+// most reported first
+func (r *SQLiteRepository) GetBrokenServers(limit, offset int) ([]domain.BrokenServer, int64, error) {
+	brokenURLs := r.db.Model(&domain.ServerHealth{}).Select("url").Where("status = ?", domain.ServerHealthBroken)
+	reportedURLs := r.db.Model(&domain.ServerReport{}).Select("url").Where("resolved_at IS NULL")
+	openReports := r.db.Model(&domain.ServerReport{}).
+		Select("url, COUNT(*) AS open_reports").
+		Where("resolved_at IS NULL").
+		Group("url")
+
+	query := func() *gorm.DB {
+		return r.db.Model(&domain.EpisodeServer{}).
+			Where("episode_servers.url IN (?) OR episode_servers.url IN (?)", brokenURLs, reportedURLs)
+	}
+	var total int64
+	if err := query().Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var servers []domain.EpisodeServer
+	err := query().
+		Select("episode_servers.*").
+		Joins("LEFT JOIN (?) AS reports ON reports.url = episode_servers.url", openReports).
+		Order("COALESCE(reports.open_reports, 0) DESC, episode_servers.id DESC").
+		Limit(limit).Offset(offset).
+		Find(&servers).Error
+	if err != nil || len(servers) == 0 {
+		return []domain.BrokenServer{}, total, err
+	}
+
+	urls := make([]string, 0, len(servers))
+	episodeIDs := make([]uint, 0, len(servers))
+	for _, sv := range servers {
+		urls = append(urls, sv.URL)
+		episodeIDs = append(episodeIDs, sv.EpisodeID)
+	}
+	healths, err := r.GetServerHealths(urls)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var episodes []domain.Episode
+	err = r.db.Select("id", "anime_id", "title", "episode_number").
+		Preload("Anime", func(db *gorm.DB) *gorm.DB { return db.Select("id", "title") }).
+		Where("id IN ?", episodeIDs).
+		Find(&episodes).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	episodeByID := map[uint]*domain.Episode{}
+	for i := range episodes {
+		episodeByID[episodes[i].ID] = &episodes[i]
+	}
+
+	var reports []domain.ServerReport
+	if err := r.db.Where("url IN ? AND resolved_at IS NULL", urls).Order("id").Find(&reports).Error; err != nil {
+		return nil, 0, err
+	}
+	reportsByURL := map[string][]domain.ServerReport{}
+	for _, rep := range reports {
+		reportsByURL[rep.URL] = append(reportsByURL[rep.URL], rep)
+	}
+
+	items := make([]domain.BrokenServer, 0, len(servers))
+	for _, sv := range servers {
+		sv.Health = healths[sv.URL]
+		item := domain.BrokenServer{Server: sv, Episode: episodeByID[sv.EpisodeID]}
+		if open := reportsByURL[sv.URL]; len(open) > 0 {
+			last := open[len(open)-1]
+			item.OpenReports = int64(len(open))
+			item.LastReason = last.Reason
+			item.LastReportedAt = &last.CreatedAt
+		}
+		items = append(items, item)
+	}
+	return items, total, nil
+}
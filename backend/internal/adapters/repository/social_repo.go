@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"backend/internal/core/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FollowUser makes follower follow following and reports whether it is a new follow
+func (r *SQLiteRepository) FollowUser(followerID, followingID uint) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&domain.UserFollow{FollowerID: followerID, FollowingID: followingID})
+	return result.RowsAffected > 0, result.Error
+}
+
+// UnfollowUser removes a follow and reports whether there was one
+func (r *SQLiteRepository) UnfollowUser(followerID, followingID uint) (bool, error) {
+	result := r.db.Where("follower_id = ? AND following_id = ?", followerID, followingID).Delete(&domain.UserFollow{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// activeUserIDs is a subquery of users that are not in the trash
+func activeUserIDs(db *gorm.DB) *gorm.DB {
+	return db.Model(&domain.User{}).Select("id")
+}
+
+// GetUserFollowStatus returns how viewer and user follow each other and the
+// follower counts of user. viewerID 0 leaves the flags false.
+func (r *SQLiteRepository) GetUserFollowStatus(viewerID, userID uint) (*domain.UserFollowStatus, error) {
+	status := &domain.UserFollowStatus{}
+	err := r.db.Model(&domain.UserFollow{}).
+		Where("following_id = ? AND follower_id IN (?)", userID, activeUserIDs(r.db)).
+		Count(&status.FollowersCount).Error
+	if err != nil {
+		return nil, err
+	}
+	err = r.db.Model(&domain.UserFollow{}).
+		Where("follower_id = ? AND following_id IN (?)", userID, activeUserIDs(r.db)).
+		Count(&status.FollowingCount).Error
+	if err != nil || viewerID == 0 {
+		return status, err
+	}
+	var pairs []domain.UserFollow
+	err = r.db.Where("(follower_id = ? AND following_id = ?) OR (follower_id = ? AND following_id = ?)", viewerID, userID, userID, viewerID).
+		Find(&pairs).Error
+	for _, p := range pairs {
+		if p.FollowerID == viewerID {
+			status.IsFollowing = true
+		} else {
+			status.FollowsYou = true
+		}
+	}
+	return status, err
+}
+
+// GetFollowers returns the users following userID, latest first
+func (r *SQLiteRepository) GetFollowers(userID uint, limit, offset int) ([]domain.User, int64, error) {
+	return r.listFollowUsers("follower_id", "following_id", userID, limit, offset)
+}
+
+// GetFollowing returns the users userID follows, latest first
+func (r *SQLiteRepository) GetFollowing(userID uint, limit, offset int) ([]domain.User, int64, error) {
+	return r.listFollowUsers("following_id", "follower_id", userID, limit, offset)
+}
+
+func (r *SQLiteRepository) listFollowUsers(listed, by string, userID uint, limit, offset int) ([]domain.User, int64, error) {
+	query := r.db.Model(&domain.User{}).
+		Joins("JOIN user_follows ON user_follows."+listed+" = users.id").
+		Where("user_follows."+by+" = ?", userID)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var users []domain.User
+	err := query.Select("users.id", "users.name", "users.username", "users.avatar").
+		Order("user_follows.created_at DESC").
+		Limit(limit).Offset(offset).
+		Find(&users).Error
+	return users, total, err
+}
+
+// GetFollowingIDs returns the IDs of the users userID follows
+func (r *SQLiteRepository) GetFollowingIDs(userID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&domain.UserFollow{}).
+		Where("follower_id = ? AND following_id IN (?)", userID, activeUserIDs(r.db)).
+		Pluck("following_id", &ids).Error
+	return ids, err
+}
+
+// GetUserPrivacies returns the privacy settings stored for the given users;
+// users without settings are missing from the map
+func (r *SQLiteRepository) GetUserPrivacies(userIDs []uint) (map[uint]domain.UserPrivacy, error) {
+	privacies := make(map[uint]domain.UserPrivacy, len(userIDs))
+	if len(userIDs) == 0 {
+		return privacies, nil
+	}
+	var rows []domain.UserPrivacy
+	if err := r.db.Where("user_id IN ?", userIDs).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, p := range rows {
+		privacies[p.UserID] = p
+	}
+	return privacies, nil
+}
+
+func (r *SQLiteRepository) SaveUserPrivacy(privacy *domain.UserPrivacy) error {
+	return r.db.Save(privacy).Error
+}
+
+// GetFeed returns the shared activity of the given sources, newest first,
+// starting after cursor when it is set. Activity on content that is no longer
+// public and entries imported from other sites are left out.
+func (r *SQLiteRepository) GetFeed(sources []domain.FeedSource, cursor *domain.FeedCursor, limit int) ([]domain.History, error) {
+	if len(sources) == 0 {
+		return []domain.History{}, nil
+	}
+	shared := r.db.Where("histories.user_id IN ? AND histories.activity_type IN ?", sources[0].UserIDs, sources[0].Types)
+	for _, s := range sources[1:] {
+		shared = shared.Or("histories.user_id IN ? AND histories.activity_type IN ?", s.UserIDs, s.Types)
+	}
+	publicEpisodes := r.db.Model(&domain.Episode{}).Select("id").
+		Where("state = ? AND anime_id IN (?)", domain.ContentStatePublished, publishedAnimeIDs(r.db))
+
+	query := r.db.Model(&domain.History{}).
+		Where(shared).
+		Where("histories.import_source = ?", "").
+		Where("histories.episode_id IS NULL OR histories.episode_id IN (?)", publicEpisodes).
+		Where("histories.anime_id IS NULL OR histories.anime_id IN (?)", publishedAnimeIDs(r.db)).
+		Where("histories.comment_id IS NULL OR histories.comment_id IN (?)", r.db.Model(&domain.Comment{}).Select("id"))
+	if cursor != nil {
+		query = query.Where("histories.created_at < ? OR (histories.created_at = ? AND histories.id < ?)", cursor.CreatedAt, cursor.CreatedAt, cursor.ID)
+	}
+
+	var items []domain.History
+	err := query.
+		Preload("User", func(db *gorm.DB) *gorm.DB { return db.Select("id", "name", "username", "avatar") }).
+		Preload("Episode.Anime").
+		Preload("Anime").
+		Preload("Comment").
+		Order("histories.created_at DESC").Order("histories.id DESC").
+		Limit(limit).
+		Find(&items).Error
+	return items, err
+}
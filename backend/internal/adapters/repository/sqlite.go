@@ -4,6 +4,7 @@ import (
 	"backend/internal/core/domain"
 	"backend/internal/core/port"
 	"fmt"
+	"time"
 
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
@@ -24,11 +25,19 @@ var _ port.SeasonRepository = &SQLiteRepository{}
 var _ port.StudioRepository = &SQLiteRepository{}
 var _ port.LanguageRepository = &SQLiteRepository{}
 var _ port.AnimeRepository = &SQLiteRepository{}
+var _ port.TrashRepository = &SQLiteRepository{}
+var _ port.RevisionRepository = &SQLiteRepository{}
 
 func (r *SQLiteRepository) DB() *gorm.DB {
 	return r.db
 }
 
+// WithDB returns a repository working on db, e.g. the transaction a data
+// migration runs in
+func WithDB(db *gorm.DB) *SQLiteRepository {
+	return &SQLiteRepository{db: db}
+}
+
 func NewSQLiteRepository(dbUrl string) (*SQLiteRepository, error) {
 	db, err := gorm.Open(sqlite.Open(dbUrl), &gorm.Config{})
 	if err != nil {
@@ -42,7 +51,15 @@ func NewSQLiteRepository(dbUrl string) (*SQLiteRepository, error) {
 		&domain.Season{}, &domain.Studio{}, &domain.Language{},
 		&domain.Anime{}, &domain.Episode{}, &domain.EpisodeServer{},
 		&domain.Comment{}, &domain.CommentLike{}, &domain.Notification{},
-		&domain.WatchLater{}, &domain.History{},
+		&domain.WatchLater{}, &domain.History{}, &domain.Revision{},
+		&domain.ContentTransition{}, &domain.Translation{},
+		&domain.ServerHealth{}, &domain.ServerReport{}, &domain.SubtitleTrack{}, &domain.ImageAsset{},
+		&domain.Media{}, &domain.MediaRef{},
+		&domain.AnimeRedirect{}, &domain.AnimeDuplicateDismissal{},
+		&domain.WatchProgress{}, &domain.AnimeListEntry{},
+		&domain.Collection{}, &domain.CollectionItem{}, &domain.CollectionCollaborator{}, &domain.CollectionFollow{}, &domain.CollectionLike{},
+		&domain.AnimeFollow{}, &domain.EpisodeAnnouncement{},
+		&domain.UserFollow{}, &domain.UserPrivacy{},
 	)
 
 	if err != nil {
@@ -60,6 +77,13 @@ var _ port.PermissionRepository = &SQLiteRepository{}
 // --- User Repository ---
 
 func (r *SQLiteRepository) CreateUser(user *domain.User) error {
+	if user.Username == nil {
+		username, err := uniqueUsername(r.db, user.Name, user.Email)
+		if err != nil {
+			return err
+		}
+		user.Username = &username
+	}
 	return r.db.Create(user).Error
 }
 
@@ -241,6 +265,15 @@ func (r *SQLiteRepository) GetCategoryByID(id uint) (*domain.Category, error) {
 	return &category, err
 }
 
+func (r *SQLiteRepository) GetCategoriesByIDs(ids []uint) ([]domain.Category, error) {
+	var categories []domain.Category
+	if len(ids) == 0 {
+		return categories, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&categories).Error
+	return categories, err
+}
+
 func (r *SQLiteRepository) UpdateCategory(category *domain.Category) error {
 	return r.db.Save(category).Error
 }
@@ -371,23 +404,49 @@ func (r *SQLiteRepository) GetAllAnimes() ([]domain.Anime, error) {
 	return animes, err
 }
 
+// GetPublishedAnimeByID is GetAnimeByID for the public site: anime outside the
+// published state are reported as not found
+func (r *SQLiteRepository) GetPublishedAnimeByID(id uint) (*domain.Anime, error) {
+	var anime domain.Anime
+	err := r.db.Preload("Categories").Preload("Season").Preload("Studio").Preload("LanguageRel").Where("state = ?", domain.ContentStatePublished).First(&anime, id).Error
+	return &anime, err
+}
+
+func (r *SQLiteRepository) GetPublishedAnimes() ([]domain.Anime, error) {
+	var animes []domain.Anime
+	err := r.db.Preload("Categories").Preload("Season").Preload("Studio").Preload("LanguageRel").Where("state = ?", domain.ContentStatePublished).Find(&animes).Error
+	return animes, err
+}
+
 func (r *SQLiteRepository) UpdateAnime(anime *domain.Anime) error {
 	return r.db.Save(anime).Error
 }
 
+// DeleteAnime soft-deletes the anime along with its episodes and their servers, all stamped
+// with the same deleted_at so that restoring the anime brings them back together.
 func (r *SQLiteRepository) DeleteAnime(id uint) error {
-	return r.db.Delete(&domain.Anime{}, id).Error
+	now := time.Now()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		episodeIDs := tx.Model(&domain.Episode{}).Select("id").Where("anime_id = ?", id)
+		if err := tx.Model(&domain.EpisodeServer{}).Where("episode_id IN (?)", episodeIDs).UpdateColumn("deleted_at", now).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&domain.Episode{}).Where("anime_id = ?", id).UpdateColumn("deleted_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Model(&domain.Anime{}).Where("id = ?", id).UpdateColumn("deleted_at", now).Error
+	})
 }
 
 func (r *SQLiteRepository) GetLatestAnimes(limit int) ([]domain.Anime, error) {
 	var animes []domain.Anime
-	err := r.db.Preload("Categories").Preload("Season").Preload("Studio").Preload("LanguageRel").Order("created_at desc").Limit(limit).Find(&animes).Error
+	err := r.db.Preload("Categories").Preload("Season").Preload("Studio").Preload("LanguageRel").Where("state = ?", domain.ContentStatePublished).Order("created_at desc").Limit(limit).Find(&animes).Error
 	return animes, err
 }
 
 func (r *SQLiteRepository) GetAnimesByType(animeType string, limit int) ([]domain.Anime, error) {
 	var animes []domain.Anime
-	query := r.db.Preload("Categories").Preload("Season").Preload("Studio").Preload("LanguageRel").Where("type = ?", animeType).Order("created_at desc")
+	query := r.db.Preload("Categories").Preload("Season").Preload("Studio").Preload("LanguageRel").Where("type = ? AND state = ?", animeType, domain.ContentStatePublished).Order("created_at desc")
 	if limit > 0 {
 		query = query.Limit(limit)
 	}
@@ -400,7 +459,7 @@ func (r *SQLiteRepository) SearchAnimes(query string) ([]domain.Anime, error) {
 	searchPattern := "%" + query + "%"
 
 	err := r.db.Preload("Categories").Preload("Season").Preload("Studio").Preload("LanguageRel").
-		Where("title LIKE ? OR title_en LIKE ?", searchPattern, searchPattern).
+		Where("(title LIKE ? OR title_en LIKE ?) AND state = ?", searchPattern, searchPattern, domain.ContentStatePublished).
 		Order("created_at desc").
 		Limit(50).
 		Find(&animes).Error
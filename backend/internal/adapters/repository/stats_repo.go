@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"backend/internal/core/domain"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// statsBucket is the width in seconds of the slots times are grouped in before
+// they are read in the scope's time zone. UTC offsets are whole quarter hours,
+// so every time in a slot falls on the same local hour and day.
+const statsBucket = 15 * 60
+
+// statsBucketOf is the SQL expression of the slot a time column falls in
+func statsBucketOf(column string) string {
+	return fmt.Sprintf("CAST(strftime('%%s', %s) AS INTEGER) / %d", column, statsBucket)
+}
+
+// statsLocalTime returns the start of a slot in the scope's time zone
+func statsLocalTime(scope domain.StatsScope, bucket int64) time.Time {
+	loc := scope.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	return time.Unix(bucket*statsBucket, 0).In(loc)
+}
+
+// statsInYear narrows query to times of column within the scope's year, which
+// starts and ends at local midnight
+func statsInYear(query *gorm.DB, scope domain.StatsScope, column string) *gorm.DB {
+	if scope.Year == 0 {
+		return query
+	}
+	loc := scope.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	start := time.Date(scope.Year, time.January, 1, 0, 0, 0, 0, loc).UTC()
+	end := time.Date(scope.Year+1, time.January, 1, 0, 0, 0, 0, loc).UTC()
+	return query.Where("julianday("+column+") >= julianday(?) AND julianday("+column+") < julianday(?)", start, end)
+}
+
+// completedEpisodes is a subquery of the episodes a user completed in scope,
+// with the minutes each one counts for
+func completedEpisodes(db *gorm.DB, scope domain.StatsScope) *gorm.DB {
+	query := db.Table("watch_progresses AS wp").
+		Select("wp.episode_id, wp.anime_id, wp.completed_at, "+
+			"CASE WHEN e.duration > 0 THEN e.duration ELSE CAST(wp.duration / 60 AS INTEGER) END AS minutes").
+		Joins("JOIN episodes e ON e.id = wp.episode_id").
+		Where("wp.user_id = ? AND wp.completed", scope.UserID)
+	return statsInYear(query, scope, "wp.completed_at")
+}
+
+// GetWatchTotals sums the episodes completed in scope
+func (r *SQLiteRepository) GetWatchTotals(scope domain.StatsScope) (domain.WatchTotals, error) {
+	var totals domain.WatchTotals
+	err := r.db.Table("(?) AS w", completedEpisodes(r.db, scope)).
+		Select("COUNT(*) AS episodes, COALESCE(SUM(w.minutes), 0) AS minutes, COUNT(DISTINCT w.anime_id) AS anime").
+		Scan(&totals).Error
+	return totals, err
+}
+
+// GetGenreBreakdown splits the episodes completed in scope by the categories
+// of their anime, most watched first. An episode counts for every category of its anime.
+func (r *SQLiteRepository) GetGenreBreakdown(scope domain.StatsScope, limit int) ([]domain.StatsBreakdown, error) {
+	genres := []domain.StatsBreakdown{}
+	err := r.db.Table("(?) AS w", completedEpisodes(r.db, scope)).
+		Select("c.id, c.name, c.name_en, c.slug, COUNT(*) AS episodes, SUM(w.minutes) AS minutes").
+		Joins("JOIN anime_categories ac ON ac.anime_id = w.anime_id").
+		Joins("JOIN categories c ON c.id = ac.category_id AND c.deleted_at IS NULL").
+		Group("c.id").
+		Order("minutes DESC").Order("episodes DESC").Order("c.id").
+		Limit(limit).
+		Scan(&genres).Error
+	return genres, err
+}
+
+// GetStudioBreakdown splits the episodes completed in scope by the studio of their anime, most watched first
+func (r *SQLiteRepository) GetStudioBreakdown(scope domain.StatsScope, limit int) ([]domain.StatsBreakdown, error) {
+	studios := []domain.StatsBreakdown{}
+	err := r.db.Table("(?) AS w", completedEpisodes(r.db, scope)).
+		Select("s.id, s.name, s.name_en, s.slug, COUNT(*) AS episodes, SUM(w.minutes) AS minutes").
+		Joins("JOIN animes a ON a.id = w.anime_id").
+		Joins("JOIN studios s ON s.id = a.studio_id AND s.deleted_at IS NULL").
+		Group("s.id").
+		Order("minutes DESC").Order("episodes DESC").Order("s.id").
+		Limit(limit).
+		Scan(&studios).Error
+	return studios, err
+}
+
+// GetTopWatchedAnime returns the anime with the most minutes completed in scope
+func (r *SQLiteRepository) GetTopWatchedAnime(scope domain.StatsScope, limit int) ([]domain.WatchedAnime, error) {
+	animes := []domain.WatchedAnime{}
+	err := r.db.Table("(?) AS w", completedEpisodes(r.db, scope)).
+		Select("a.id, a.title, a.title_en, a.slug, a.image, COUNT(*) AS episodes, SUM(w.minutes) AS minutes").
+		Joins("JOIN animes a ON a.id = w.anime_id AND a.deleted_at IS NULL AND a.state = ?", domain.ContentStatePublished).
+		Group("a.id").
+		Order("minutes DESC").Order("episodes DESC").Order("a.id").
+		Limit(limit).
+		Scan(&animes).Error
+	return animes, err
+}
+
+// GetMonthlyMinutes returns the minutes completed in each month of the scope's year, January first
+func (r *SQLiteRepository) GetMonthlyMinutes(scope domain.StatsScope) ([12]int64, error) {
+	var months [12]int64
+	var rows []struct {
+		Bucket  int64
+		Minutes int64
+	}
+	err := r.db.Table("(?) AS w", completedEpisodes(r.db, scope)).
+		Select(statsBucketOf("w.completed_at") + " AS bucket, SUM(w.minutes) AS minutes").
+		Where("w.completed_at IS NOT NULL").
+		Group("bucket").
+		Scan(&rows).Error
+	for _, row := range rows {
+		months[statsLocalTime(scope, row.Bucket).Month()-1] += row.Minutes
+	}
+	return months, err
+}
+
+// GetViewHeatmap counts a user's episode views in scope by weekday and hour.
+// Entries imported from other sites carry no time of viewing and are left out.
+func (r *SQLiteRepository) GetViewHeatmap(scope domain.StatsScope) (domain.WatchHeatmap, error) {
+	var heatmap domain.WatchHeatmap
+	query := r.db.Model(&domain.History{}).
+		Select(statsBucketOf("created_at")+" AS bucket, COUNT(*) AS views").
+		Where("user_id = ? AND activity_type = ? AND import_source = ?", scope.UserID, domain.ActivityEpisodeView, "")
+	var rows []struct {
+		Bucket int64
+		Views  int64
+	}
+	err := statsInYear(query, scope, "created_at").Group("bucket").Scan(&rows).Error
+	for _, row := range rows {
+		local := statsLocalTime(scope, row.Bucket)
+		heatmap[local.Weekday()][local.Hour()] += row.Views
+	}
+	return heatmap, err
+}
+
+// GetActiveDays returns the days, as YYYY-MM-DD in the scope's time zone, on
+// which a user viewed or completed an episode, oldest first. Imported entries
+// do not count and the scope's year is ignored.
+func (r *SQLiteRepository) GetActiveDays(scope domain.StatsScope) ([]string, error) {
+	var buckets []int64
+	err := r.db.Raw(`SELECT `+statsBucketOf("created_at")+` AS bucket FROM histories
+		WHERE user_id = ? AND activity_type = ? AND import_source = ?
+		UNION
+		SELECT `+statsBucketOf("completed_at")+` FROM watch_progresses WHERE user_id = ? AND completed_at IS NOT NULL
+		ORDER BY bucket`,
+		scope.UserID, domain.ActivityEpisodeView, "", scope.UserID).
+		Scan(&buckets).Error
+	days := []string{}
+	for _, bucket := range buckets {
+		day := statsLocalTime(scope, bucket).Format(time.DateOnly)
+		if len(days) == 0 || days[len(days)-1] != day {
+			days = append(days, day)
+		}
+	}
+	return days, err
+}
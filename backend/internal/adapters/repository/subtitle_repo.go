@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"backend/internal/core/domain"
+
+	"gorm.io/gorm"
+)
+
+// GetSubtitleTracks returns the subtitle tracks of the given episodes
+func (r *SQLiteRepository) GetSubtitleTracks(episodeIDs []uint) ([]domain.SubtitleTrack, error) {
+	var tracks []domain.SubtitleTrack
+	if len(episodeIDs) == 0 {
+		return tracks, nil
+	}
+	err := r.db.Where("episode_id IN ?", episodeIDs).Order("id").Find(&tracks).Error
+	return tracks, err
+}
+
+// GetSubtitleTrack returns a subtitle track of an episode
+func (r *SQLiteRepository) GetSubtitleTrack(episodeID, trackID uint) (*domain.SubtitleTrack, error) {
+	var track domain.SubtitleTrack
+	result := r.db.Where("id = ? AND episode_id = ?", trackID, episodeID).Limit(1).Find(&track)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return nil, domain.ErrSubtitleNotFound
+	}
+	return &track, result.Error
+}
+
+// SaveSubtitleTrack inserts or updates a track. A default track clears the flag on
+// the episode's other tracks.
+func (r *SQLiteRepository) SaveSubtitleTrack(track *domain.SubtitleTrack) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(track).Error; err != nil {
+			return err
+		}
+		if !track.IsDefault {
+			return nil
+		}
+		return tx.Model(&domain.SubtitleTrack{}).
+			Where("episode_id = ? AND id <> ?", track.EpisodeID, track.ID).
+			UpdateColumn("is_default", false).Error
+	})
+}
+
+// DeleteSubtitleTrack removes a track and hands the default flag to the episode's
+// oldest remaining track
+func (r *SQLiteRepository) DeleteSubtitleTrack(track *domain.SubtitleTrack) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&domain.SubtitleTrack{}, track.ID).Error; err != nil {
+			return err
+		}
+		if !track.IsDefault {
+			return nil
+		}
+		var next domain.SubtitleTrack
+		result := tx.Where("episode_id = ?", track.EpisodeID).Order("id").Limit(1).Find(&next)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return tx.Model(&next).UpdateColumn("is_default", true).Error
+	})
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"backend/internal/core/domain"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func translationModel(entityType string) (domain.Translatable, error) {
+	switch entityType {
+	case domain.TranslationEntityAnime:
+		return &domain.Anime{}, nil
+	case domain.TranslationEntityEpisode:
+		return &domain.Episode{}, nil
+	case domain.TranslationEntityCategory:
+		return &domain.Category{}, nil
+	case domain.TranslationEntityType:
+		return &domain.Type{}, nil
+	case domain.TranslationEntitySeason:
+		return &domain.Season{}, nil
+	case domain.TranslationEntityStudio:
+		return &domain.Studio{}, nil
+	case domain.TranslationEntityLanguage:
+		return &domain.Language{}, nil
+	default:
+		return nil, fmt.Errorf("unknown translation entity: %q", entityType)
+	}
+}
+
+// GetTranslatable loads the row whose translations are being read or written. Anime
+// and episodes come with the relations their revision snapshots need.
+func (r *SQLiteRepository) GetTranslatable(entityType string, id uint) (domain.Translatable, error) {
+	model, err := translationModel(entityType)
+	if err != nil {
+		return nil, err
+	}
+	query := r.db
+	switch entityType {
+	case domain.TranslationEntityAnime:
+		query = query.Preload("Categories")
+	case domain.TranslationEntityEpisode:
+		query = query.Preload("Servers")
+	}
+	result := query.Where("id = ?", id).Limit(1).Find(model)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return nil, domain.ErrContentNotFound
+	}
+	return model, result.Error
+}
+
+// GetTranslations returns stored translations of the given rows. A nil locales
+// slice returns every locale.
+func (r *SQLiteRepository) GetTranslations(entityType string, ids []uint, locales []string) ([]domain.Translation, error) {
+	var translations []domain.Translation
+	if len(ids) == 0 {
+		return translations, nil
+	}
+	query := r.db.Where("entity_type = ? AND entity_id IN ?", entityType, ids)
+	if locales != nil {
+		query = query.Where("locale IN ?", locales)
+	}
+	err := query.Order("entity_id, locale, field").Find(&translations).Error
+	return translations, err
+}
+
+// SaveTranslations writes field values of one row in one locale. Values for legacy
+// locales go to the legacy columns; other locales are upserted into translations,
+// and an empty value removes the translation. When revision is set the legacy
+// columns are saved as a new version of a row loaded at version, recording revision.
+func (r *SQLiteRepository) SaveTranslations(entityType string, id uint, locale string, values map[string]string, revision *domain.Revision, version int) error {
+	model, err := translationModel(entityType)
+	if err != nil {
+		return err
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		legacy := map[string]interface{}{}
+		now := time.Now()
+		for field, value := range values {
+			if column, ok := domain.LegacyColumn(entityType, field, locale); ok {
+				legacy[column] = value
+				continue
+			}
+			key := tx.Where("entity_type = ? AND entity_id = ? AND field = ? AND locale = ?", entityType, id, field, locale)
+			if value == "" {
+				if err := key.Delete(&domain.Translation{}).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			translation := domain.Translation{
+				EntityType: entityType,
+				EntityID:   id,
+				Field:      field,
+				Locale:     locale,
+				Value:      value,
+				CreatedAt:  now,
+				UpdatedAt:  now,
+			}
+			err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "entity_type"}, {Name: "entity_id"}, {Name: "field"}, {Name: "locale"}},
+				DoUpdates: clause.AssignmentColumns([]string{"value", "updated_at"}),
+			}).Create(&translation).Error
+			if err != nil {
+				return err
+			}
+		}
+		if len(legacy) == 0 {
+			return nil
+		}
+		if revision != nil {
+			if err := saveVersioned(tx, model, id, &version); err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(model).Where("id = ?", id).Updates(legacy).Error; err != nil {
+			return err
+		}
+		return recordRevision(tx, revision, entityType, id, version)
+	})
+}
+
+// DeleteTranslations removes every translation of a row in one locale
+func (r *SQLiteRepository) DeleteTranslations(entityType string, id uint, locale string) error {
+	return r.db.Where("entity_type = ? AND entity_id = ? AND locale = ?", entityType, id, locale).
+		Delete(&domain.Translation{}).Error
+}
+
+func purgeTranslations(tx *gorm.DB, entityType string, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return tx.Where("entity_type = ? AND entity_id IN ?", entityType, ids).Delete(&domain.Translation{}).Error
+}
@@ -0,0 +1,285 @@
+package repository
+
+import (
+	"backend/internal/core/domain"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type trashTable struct {
+	model func() interface{}
+	label string // Column shown in the trash listing
+}
+
+var trashTables = map[string]trashTable{
+	domain.TrashEntityAnime:    {func() interface{} { return &domain.Anime{} }, "title"},
+	domain.TrashEntityEpisode:  {func() interface{} { return &domain.Episode{} }, "title"},
+	domain.TrashEntityModel:    {func() interface{} { return &domain.Model{} }, "name"},
+	domain.TrashEntityUser:     {func() interface{} { return &domain.User{} }, "email"},
+	domain.TrashEntityCategory: {func() interface{} { return &domain.Category{} }, "name"},
+	domain.TrashEntityType:     {func() interface{} { return &domain.Type{} }, "name"},
+	domain.TrashEntitySeason:   {func() interface{} { return &domain.Season{} }, "name"},
+	domain.TrashEntityStudio:   {func() interface{} { return &domain.Studio{} }, "name"},
+	domain.TrashEntityLanguage: {func() interface{} { return &domain.Language{} }, "name"},
+}
+
+func lookupTrashTable(entity string) (trashTable, error) {
+	table, ok := trashTables[entity]
+	if !ok {
+		return table, fmt.Errorf("unknown trash entity: %q", entity)
+	}
+	return table, nil
+}
+
+// ListTrash returns soft-deleted rows of one entity, most recently deleted first
+func (r *SQLiteRepository) ListTrash(entity string, limit, offset int) ([]domain.TrashItem, int64, error) {
+	table, err := lookupTrashTable(entity)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := r.db.Unscoped().Model(table.model()).Where("deleted_at IS NOT NULL")
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var items []domain.TrashItem
+	err = query.Select("id", table.label+" AS label", "deleted_at").
+		Order("deleted_at desc").
+		Limit(limit).Offset(offset).
+		Find(&items).Error
+	for i := range items {
+		items[i].Entity = entity
+	}
+	return items, total, err
+}
+
+// GetTrashIDsBefore lists IDs of rows soft-deleted before cutoff
+func (r *SQLiteRepository) GetTrashIDsBefore(entity string, cutoff time.Time) ([]uint, error) {
+	table, err := lookupTrashTable(entity)
+	if err != nil {
+		return nil, err
+	}
+	var ids []uint
+	err = r.db.Unscoped().Model(table.model()).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// GetDeletedModel loads a soft-deleted model so its files can be removed after purge
+func (r *SQLiteRepository) GetDeletedModel(id uint) (*domain.Model, error) {
+	var model domain.Model
+	result := r.db.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).Limit(1).Find(&model)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return nil, domain.ErrNotInTrash
+	}
+	return &model, result.Error
+}
+
+// RestoreTrash clears deleted_at on a row and on the dependents that were deleted with it
+// (episodes and servers removed at the same time or later than their parent).
+func (r *SQLiteRepository) RestoreTrash(entity string, id uint) error {
+	table, err := lookupTrashTable(entity)
+	if err != nil {
+		return err
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var deleted []time.Time
+		if err := tx.Unscoped().Model(table.model()).Where("id = ? AND deleted_at IS NOT NULL", id).Pluck("deleted_at", &deleted).Error; err != nil {
+			return err
+		}
+		if len(deleted) == 0 {
+			return domain.ErrNotInTrash
+		}
+		deletedAt := deleted[0]
+
+		if entity == domain.TrashEntityEpisode {
+			var trashedParents int64
+			err := tx.Unscoped().Model(&domain.Anime{}).
+				Where("deleted_at IS NOT NULL AND id = (?)", tx.Unscoped().Model(&domain.Episode{}).Select("anime_id").Where("id = ?", id)).
+				Count(&trashedParents).Error
+			if err != nil {
+				return err
+			}
+			if trashedParents > 0 {
+				return domain.ErrParentInTrash
+			}
+		}
+
+		if err := tx.Unscoped().Model(table.model()).Where("id = ?", id).UpdateColumn("deleted_at", nil).Error; err != nil {
+			return err
+		}
+
+		switch entity {
+		case domain.TrashEntityAnime:
+			episodeIDs := tx.Unscoped().Model(&domain.Episode{}).Select("id").Where("anime_id = ?", id)
+			if err := restoreDeletedSince(tx, &domain.EpisodeServer{}, deletedAt, "episode_id IN (?)", episodeIDs); err != nil {
+				return err
+			}
+			return restoreDeletedSince(tx, &domain.Episode{}, deletedAt, "anime_id = ?", id)
+		case domain.TrashEntityEpisode:
+			return restoreDeletedSince(tx, &domain.EpisodeServer{}, deletedAt, "episode_id = ?", id)
+		}
+		return nil
+	})
+}
+
+func restoreDeletedSince(tx *gorm.DB, model interface{}, since time.Time, query string, args ...interface{}) error {
+	return tx.Unscoped().Model(model).
+		Where(query, args...).
+		Where("deleted_at >= ?", since).
+		UpdateColumn("deleted_at", nil).Error
+}
+
+// PurgeTrash permanently deletes a soft-deleted row together with its dependent rows.
+// Rows that merely reference it (anime dictionaries) are unlinked instead.
+func (r *SQLiteRepository) PurgeTrash(entity string, id uint) error {
+	table, err := lookupTrashTable(entity)
+	if err != nil {
+		return err
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Where("deleted_at IS NOT NULL").Delete(table.model(), id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrNotInTrash
+		}
+		if _, ok := domain.TranslatableFields[entity]; ok {
+			if err := purgeTranslations(tx, entity, []uint{id}); err != nil {
+				return err
+			}
+		}
+
+		switch entity {
+		case domain.TrashEntityAnime:
+			var episodeIDs []uint
+			if err := tx.Unscoped().Model(&domain.Episode{}).Where("anime_id = ?", id).Pluck("id", &episodeIDs).Error; err != nil {
+				return err
+			}
+			if err := purgeEpisodes(tx, episodeIDs); err != nil {
+				return err
+			}
+			if err := tx.Exec("DELETE FROM anime_categories WHERE anime_id = ?", id).Error; err != nil {
+				return err
+			}
+			if err := releaseMediaRefs(tx, domain.MediaOwnerAnime, []uint{id}); err != nil {
+				return err
+			}
+			if err := tx.Where("anime_id = ?", id).Delete(&domain.AnimeListEntry{}).Error; err != nil {
+				return err
+			}
+			if err := purgeCollectionItems(tx, domain.CollectionItemAnime, []uint{id}); err != nil {
+				return err
+			}
+			if err := tx.Where("anime_id = ?", id).Delete(&domain.AnimeFollow{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("anime_id = ?", id).Delete(&domain.EpisodeAnnouncement{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("anime_id = ?", id).Delete(&domain.History{}).Error; err != nil {
+				return err
+			}
+			return tx.Where("anime_id = ?", id).Delete(&domain.WatchLater{}).Error
+		case domain.TrashEntityEpisode:
+			return purgeEpisodes(tx, []uint{id})
+		case domain.TrashEntityModel:
+			if err := purgeCollectionItems(tx, domain.CollectionItemModel, []uint{id}); err != nil {
+				return err
+			}
+			return releaseMediaRefs(tx, domain.MediaOwnerModel, []uint{id})
+		case domain.TrashEntityUser:
+			return purgeUserData(tx, id)
+		case domain.TrashEntityCategory:
+			return tx.Exec("DELETE FROM anime_categories WHERE category_id = ?", id).Error
+		case domain.TrashEntitySeason:
+			return tx.Unscoped().Model(&domain.Anime{}).Where("season_id = ?", id).UpdateColumn("season_id", nil).Error
+		case domain.TrashEntityStudio:
+			return tx.Unscoped().Model(&domain.Anime{}).Where("studio_id = ?", id).UpdateColumn("studio_id", nil).Error
+		case domain.TrashEntityLanguage:
+			return tx.Unscoped().Model(&domain.Anime{}).Where("language_id = ?", id).UpdateColumn("language_id", nil).Error
+		}
+		return nil
+	})
+}
+
+func purgeEpisodes(tx *gorm.DB, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	commentIDs := tx.Model(&domain.Comment{}).Select("id").Where("episode_id IN ?", ids)
+	if err := tx.Where("comment_id IN (?)", commentIDs).Delete(&domain.CommentLike{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("episode_id IN ? OR comment_id IN (?)", ids, commentIDs).Delete(&domain.History{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("episode_id IN ?", ids).Delete(&domain.Comment{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("episode_id IN ?", ids).Delete(&domain.WatchLater{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("episode_id IN ?", ids).Delete(&domain.WatchProgress{}).Error; err != nil {
+		return err
+	}
+	if err := purgeCollectionItems(tx, domain.CollectionItemEpisode, ids); err != nil {
+		return err
+	}
+	if err := tx.Where("episode_id IN ?", ids).Delete(&domain.EpisodeAnnouncement{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("episode_id IN ?", ids).Delete(&domain.ServerReport{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("episode_id IN ?", ids).Delete(&domain.SubtitleTrack{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Where("episode_id IN ?", ids).Delete(&domain.EpisodeServer{}).Error; err != nil {
+		return err
+	}
+	if err := purgeTranslations(tx, domain.TranslationEntityEpisode, ids); err != nil {
+		return err
+	}
+	if err := releaseMediaRefs(tx, domain.MediaOwnerEpisode, ids); err != nil {
+		return err
+	}
+	return tx.Unscoped().Where("id IN ?", ids).Delete(&domain.Episode{}).Error
+}
+
+func purgeUserData(tx *gorm.DB, userID uint) error {
+	var commentIDs []uint
+	if err := tx.Model(&domain.Comment{}).Where("user_id = ?", userID).Pluck("id", &commentIDs).Error; err != nil {
+		return err
+	}
+	if len(commentIDs) > 0 {
+		// Replies from other users stay, detached from the removed parent
+		if err := tx.Model(&domain.Comment{}).Where("parent_id IN ?", commentIDs).UpdateColumn("parent_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("comment_id IN ?", commentIDs).Delete(&domain.CommentLike{}).Error; err != nil {
+			return err
+		}
+	}
+	for _, model := range []interface{}{&domain.CommentLike{}, &domain.Comment{}, &domain.History{}, &domain.WatchLater{}, &domain.WatchProgress{}, &domain.AnimeListEntry{}, &domain.AnimeFollow{}, &domain.Notification{}} {
+		if err := tx.Where("user_id = ?", userID).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+	if err := purgeUserCollections(tx, userID); err != nil {
+		return err
+	}
+	if err := tx.Where("follower_id = ? OR following_id = ?", userID, userID).Delete(&domain.UserFollow{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("user_id = ?", userID).Delete(&domain.UserPrivacy{}).Error; err != nil {
+		return err
+	}
+	return releaseMediaRefs(tx, domain.MediaOwnerUser, []uint{userID})
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"backend/internal/core/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SaveWatchProgress upserts the progress of a user on an episode unless a later
+// report is stored already. Completion is kept once reached, along with the time
+// it was first reached.
+func (r *SQLiteRepository) SaveWatchProgress(progress *domain.WatchProgress) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "episode_id"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"anime_id":     gorm.Expr("excluded.anime_id"),
+			"position":     gorm.Expr("excluded.position"),
+			"duration":     gorm.Expr("excluded.duration"),
+			"completed":    gorm.Expr("watch_progresses.completed OR excluded.completed"),
+			"completed_at": gorm.Expr("COALESCE(watch_progresses.completed_at, excluded.completed_at)"),
+			"updated_at":   gorm.Expr("excluded.updated_at"),
+		}),
+		// A report held back on another replica may be flushed after a newer one
+		Where: clause.Where{Exprs: []clause.Expression{gorm.Expr("excluded.updated_at >= watch_progresses.updated_at")}},
+	}).Create(progress).Error
+}
+
+// GetWatchProgressByAnime returns the progress of a user on every episode of an anime they started
+func (r *SQLiteRepository) GetWatchProgressByAnime(userID, animeID uint) ([]domain.WatchProgress, error) {
+	var rows []domain.WatchProgress
+	err := r.db.Where("user_id = ? AND anime_id = ?", userID, animeID).Order("episode_id").Find(&rows).Error
+	return rows, err
+}
+
+// GetEpisodeAnimeID returns the anime of a live episode
+func (r *SQLiteRepository) GetEpisodeAnimeID(episodeID uint) (uint, error) {
+	var episode domain.Episode
+	result := r.db.Select("id", "anime_id").Where("id = ?", episodeID).Limit(1).Find(&episode)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return 0, gorm.ErrRecordNotFound
+	}
+	return episode.AnimeID, nil
+}
+
+// GetPublishedEpisodeAnimeID is GetEpisodeAnimeID for episodes the public site
+// shows: published, in a published anime
+func (r *SQLiteRepository) GetPublishedEpisodeAnimeID(episodeID uint) (uint, error) {
+	var episode domain.Episode
+	result := r.db.Select("id", "anime_id").
+		Where("id = ? AND state = ? AND anime_id IN (?)", episodeID, domain.ContentStatePublished, publishedAnimeIDs(r.db)).
+		Limit(1).Find(&episode)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return 0, gorm.ErrRecordNotFound
+	}
+	return episode.AnimeID, nil
+}
+
+// GetRecentWatchProgress returns the progress rows of a user, latest first
+func (r *SQLiteRepository) GetRecentWatchProgress(userID uint, limit int) ([]domain.WatchProgress, error) {
+	var rows []domain.WatchProgress
+	err := r.db.Where("user_id = ?", userID).Order("updated_at DESC").Limit(limit).Find(&rows).Error
+	return rows, err
+}
+
+// GetRecentEpisodeViews returns the episode views of a user, latest first.
+// Entries imported from other sites are not views on this one and are left out.
+func (r *SQLiteRepository) GetRecentEpisodeViews(userID uint, limit int) ([]domain.History, error) {
+	var rows []domain.History
+	err := r.db.Select("id", "anime_id", "episode_id", "created_at").
+		Where("user_id = ? AND activity_type = ? AND import_source = ? AND episode_id IS NOT NULL AND anime_id IS NOT NULL", userID, domain.ActivityEpisodeView, "").
+		Order("created_at DESC").Limit(limit).Find(&rows).Error
+	return rows, err
+}
+
+// GetPlayableEpisodes returns the published episodes of the given anime in watch order
+func (r *SQLiteRepository) GetPlayableEpisodes(animeIDs []uint) ([]domain.Episode, error) {
+	var episodes []domain.Episode
+	err := r.db.Select("id", "anime_id", "title", "title_en", "slug", "episode_number", "thumbnail", "duration").
+		Where("anime_id IN ? AND state = ?", animeIDs, domain.ContentStatePublished).
+		Order("anime_id, episode_number, id").Find(&episodes).Error
+	return episodes, err
+}
+
+// GetPublishedAnimesByIDs returns the published anime among ids
+func (r *SQLiteRepository) GetPublishedAnimesByIDs(ids []uint) ([]domain.Anime, error) {
+	var animes []domain.Anime
+	err := r.db.Where("id IN ? AND state = ?", ids, domain.ContentStatePublished).Find(&animes).Error
+	return animes, err
+}
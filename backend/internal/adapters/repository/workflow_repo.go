@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"backend/internal/core/domain"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+func workflowModel(entityType string) (interface{}, error) {
+	switch entityType {
+	case domain.WorkflowEntityAnime:
+		return &domain.Anime{}, nil
+	case domain.WorkflowEntityEpisode:
+		return &domain.Episode{}, nil
+	default:
+		return nil, fmt.Errorf("unknown workflow entity: %q", entityType)
+	}
+}
+
+// GetContentState returns the workflow state and title of an anime or episode
+func (r *SQLiteRepository) GetContentState(entityType string, id uint) (domain.ContentState, string, error) {
+	model, err := workflowModel(entityType)
+	if err != nil {
+		return "", "", err
+	}
+	var row struct {
+		State domain.ContentState
+		Title string
+	}
+	result := r.db.Model(model).Select("state", "title").Where("id = ?", id).Limit(1).Find(&row)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return "", "", domain.ErrContentNotFound
+	}
+	return row.State, row.Title, result.Error
+}
+
+// SetContentState moves a row from one state to another and logs the transition.
+// The update is conditional on the row still being in transition.From. Publishing
+// an episode queues the notifications to the anime's followers.
+func (r *SQLiteRepository) SetContentState(transition *domain.ContentTransition) error {
+	model, err := workflowModel(transition.EntityType)
+	if err != nil {
+		return err
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		updates := map[string]interface{}{"state": transition.To}
+		if transition.EntityType == domain.WorkflowEntityEpisode {
+			updates["is_published"] = transition.To == domain.ContentStatePublished
+		}
+		result := tx.Model(model).
+			Where("id = ? AND state = ?", transition.EntityID, transition.From).
+			UpdateColumns(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrVersionConflict
+		}
+		if transition.EntityType == domain.WorkflowEntityEpisode && transition.To == domain.ContentStatePublished {
+			var animeIDs []uint
+			if err := tx.Model(&domain.Episode{}).Where("id = ?", transition.EntityID).Pluck("anime_id", &animeIDs).Error; err != nil {
+				return err
+			}
+			if err := enqueueEpisodeAnnouncement(tx, transition.EntityID, animeIDs[0]); err != nil {
+				return err
+			}
+		}
+		return tx.Create(transition).Error
+	})
+}
+
+// GetContentTransitions returns the workflow history of a row, newest first
+func (r *SQLiteRepository) GetContentTransitions(entityType string, id uint) ([]domain.ContentTransition, error) {
+	var transitions []domain.ContentTransition
+	err := r.db.Preload("User", func(db *gorm.DB) *gorm.DB { return db.Select("id", "name", "avatar") }).
+		Where("entity_type = ? AND entity_id = ?", entityType, id).
+		Order("id desc").
+		Find(&transitions).Error
+	return transitions, err
+}
+
+// GetLastSubmission returns the transition that last sent a row to review, or nil
+func (r *SQLiteRepository) GetLastSubmission(entityType string, id uint) (*domain.ContentTransition, error) {
+	var transition domain.ContentTransition
+	result := r.db.Preload("User", func(db *gorm.DB) *gorm.DB { return db.Select("id", "name", "avatar") }).
+		Where("entity_type = ? AND entity_id = ? AND to_state = ?", entityType, id, domain.ContentStateInReview).
+		Order("id desc").
+		Limit(1).
+		Find(&transition)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+	return &transition, nil
+}
+
+// GetReviewQueue lists anime and episodes waiting in review, oldest submission first
+func (r *SQLiteRepository) GetReviewQueue(entityType string) ([]domain.ReviewQueueItem, error) {
+	var items []domain.ReviewQueueItem
+
+	if entityType == "" || entityType == domain.WorkflowEntityAnime {
+		var animes []domain.Anime
+		if err := r.db.Select("id", "title", "updated_at").Where("state = ?", domain.ContentStateInReview).Find(&animes).Error; err != nil {
+			return nil, err
+		}
+		for _, a := range animes {
+			items = append(items, domain.ReviewQueueItem{EntityType: domain.WorkflowEntityAnime, EntityID: a.ID, Title: a.Title, SubmittedAt: a.UpdatedAt})
+		}
+	}
+	if entityType == "" || entityType == domain.WorkflowEntityEpisode {
+		var episodes []domain.Episode
+		if err := r.db.Select("id", "title", "anime_id", "updated_at").Where("state = ?", domain.ContentStateInReview).Find(&episodes).Error; err != nil {
+			return nil, err
+		}
+		for _, e := range episodes {
+			items = append(items, domain.ReviewQueueItem{EntityType: domain.WorkflowEntityEpisode, EntityID: e.ID, Title: e.Title, AnimeID: e.AnimeID, SubmittedAt: e.UpdatedAt})
+		}
+	}
+
+	for i := range items {
+		submission, err := r.GetLastSubmission(items[i].EntityType, items[i].EntityID)
+		if err != nil {
+			return nil, err
+		}
+		if submission != nil {
+			items[i].SubmittedBy = submission.User
+			items[i].SubmittedAt = submission.CreatedAt
+			items[i].Comment = submission.Comment
+		}
+	}
+	return items, nil
+}
+
+// UserHasPermission reports whether the user's role grants the permission key
+func (r *SQLiteRepository) UserHasPermission(userID uint, key string) (bool, error) {
+	var count int64
+	err := r.db.Table("users").
+		Joins("JOIN role_permissions ON role_permissions.role_id = users.role_id").
+		Joins("JOIN permissions ON permissions.id = role_permissions.permission_id").
+		Where("users.id = ? AND users.deleted_at IS NULL AND permissions.key = ?", userID, key).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// GetUserIDsWithPermission lists users whose role grants key, plus every admin
+func (r *SQLiteRepository) GetUserIDsWithPermission(key string) ([]uint, error) {
+	var ids []uint
+	err := r.db.Table("users").
+		Distinct("users.id").
+		Joins("JOIN roles ON roles.id = users.role_id").
+		Joins("LEFT JOIN role_permissions ON role_permissions.role_id = roles.id").
+		Joins("LEFT JOIN permissions ON permissions.id = role_permissions.permission_id").
+		Where("users.deleted_at IS NULL AND (LOWER(roles.name) = 'admin' OR permissions.key = ?)", key).
+		Pluck("users.id", &ids).Error
+	return ids, err
+}
@@ -0,0 +1,168 @@
+package storage
+
+import (
+	"backend/internal/core/domain"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// LocalStore keeps blobs under a directory that this server serves at /uploads.
+// Replicas can only share it through a network filesystem.
+type LocalStore struct {
+	root string
+}
+
+func NewLocalStore(root string) *LocalStore {
+	return &LocalStore{root: root}
+}
+
+// cleanKey rejects keys that would escape the root
+func cleanKey(key string) (string, error) {
+	clean := path.Clean("/" + key)[1:]
+	if clean == "" || clean != strings.TrimPrefix(key, "/") {
+		return "", errors.New("invalid blob key " + key)
+	}
+	return clean, nil
+}
+
+func (s *LocalStore) path(key string) (string, error) {
+	clean, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, filepath.FromSlash(clean)), nil
+}
+
+// Put writes to a temporary file first so readers never see partial objects
+func (s *LocalStore) Put(key string, r io.Reader, size int64, contentType string) error {
+	dst, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".put-*")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+func (s *LocalStore) Get(key string) (io.ReadCloser, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, domain.ErrBlobNotFound
+	}
+	return f, err
+}
+
+func (s *LocalStore) Stat(key string) (*domain.BlobInfo, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(p)
+	if errors.Is(err, os.ErrNotExist) || (err == nil && !info.Mode().IsRegular()) {
+		return nil, domain.ErrBlobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &domain.BlobInfo{
+		Key:         key,
+		Size:        info.Size(),
+		ContentType: mime.TypeByExtension(path.Ext(key)),
+		ModTime:     info.ModTime(),
+	}, nil
+}
+
+func (s *LocalStore) Delete(key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// List walks the directory holding prefix, skipping in-progress Put files
+func (s *LocalStore) List(prefix string, fn func(domain.BlobInfo) error) error {
+	dir := path.Dir("/" + prefix)[1:]
+	start := filepath.Join(s.root, filepath.FromSlash(dir))
+	err := filepath.WalkDir(start, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".put-") {
+			return nil
+		}
+		rel, err := filepath.Rel(s.root, p)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil // Removed while walking
+		}
+		return fn(domain.BlobInfo{Key: key, Size: info.Size(), ContentType: mime.TypeByExtension(path.Ext(key)), ModTime: info.ModTime()})
+	})
+	return err
+}
+
+// URL points at the /uploads route, which serves local blobs directly. Local
+// links do not expire.
+func (s *LocalStore) URL(key, filename string, expiry time.Duration) (string, error) {
+	clean, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	u := url.URL{Path: "/uploads/" + clean}
+	if filename != "" {
+		u.RawQuery = url.Values{"download": {filename}}.Encode()
+	}
+	return u.String(), nil
+}
+
+// File returns the path of a blob on disk, for serving it with http.ServeFile
+func (s *LocalStore) File(key string) (string, error) {
+	return s.path(key)
+}
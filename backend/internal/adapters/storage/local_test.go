@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"backend/internal/core/domain"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalStore(t *testing.T) {
+	testBlobStore(t, NewLocalStore(t.TempDir()))
+}
+
+func TestLocalStoreListSkipsPartialPuts(t *testing.T) {
+	root := t.TempDir()
+	store := NewLocalStore(root)
+	if err := os.MkdirAll(filepath.Join(root, "media"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "media", ".put-123"), []byte("partial"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	if err := store.List("", func(info domain.BlobInfo) error {
+		keys = append(keys, info.Key)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 0 {
+		t.Fatalf("List = %v, want the in-progress file skipped", keys)
+	}
+	// A missing prefix directory is an empty listing
+	if err := store.List("nothing/here/", func(domain.BlobInfo) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLocalStoreURL(t *testing.T) {
+	store := NewLocalStore(t.TempDir())
+	tests := []struct {
+		key, filename, want string
+	}{
+		{"media/ab/one.jpg", "", "/uploads/media/ab/one.jpg"},
+		{"subtitles/1.vtt", "Episode 1.vtt", "/uploads/subtitles/1.vtt?download=Episode+1.vtt"},
+	}
+	for _, tt := range tests {
+		got, err := store.URL(tt.key, tt.filename, 0)
+		if err != nil || got != tt.want {
+			t.Errorf("URL(%q, %q) = %q, %v; want %q", tt.key, tt.filename, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package storage
+
+import (
+	"backend/internal/core/domain"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Config selects an S3-compatible bucket (AWS S3, MinIO, R2, ...)
+type S3Config struct {
+	Endpoint  string // host[:port], e.g. "s3.amazonaws.com" or "localhost:9000"
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	Region    string
+	UseSSL    bool
+	Prefix    string // Optional key prefix inside the bucket, e.g. "anime/"
+}
+
+// S3Store keeps blobs in a bucket. Clients download them with presigned URLs,
+// so every replica serves the same files without proxying them.
+type S3Store struct {
+	client *minio.Client
+	bucket string
+	prefix string
+}
+
+// NewS3Store connects to the bucket, creating it when missing
+func NewS3Store(cfg S3Config) (*S3Store, error) {
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	exists, err := client.BucketExists(ctx, cfg.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("check bucket %s: %w", cfg.Bucket, err)
+	}
+	if !exists {
+		if err := client.MakeBucket(ctx, cfg.Bucket, minio.MakeBucketOptions{Region: cfg.Region}); err != nil {
+			return nil, fmt.Errorf("create bucket %s: %w", cfg.Bucket, err)
+		}
+	}
+	return &S3Store{client: client, bucket: cfg.Bucket, prefix: cfg.Prefix}, nil
+}
+
+func (s *S3Store) object(key string) (string, error) {
+	clean, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return s.prefix + clean, nil
+}
+
+// notFound maps S3 "no such key" errors to domain.ErrBlobNotFound
+func notFound(err error) error {
+	if err != nil && minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		return domain.ErrBlobNotFound
+	}
+	return err
+}
+
+func (s *S3Store) Put(key string, r io.Reader, size int64, contentType string) error {
+	object, err := s.object(key)
+	if err != nil {
+		return err
+	}
+	if contentType == "" {
+		contentType = mime.TypeByExtension(path.Ext(key))
+	}
+	_, err = s.client.PutObject(context.Background(), s.bucket, object, r, size, minio.PutObjectOptions{ContentType: contentType})
+	return err
+}
+
+func (s *S3Store) Get(key string) (io.ReadCloser, error) {
+	object, err := s.object(key)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := s.client.GetObject(context.Background(), s.bucket, object, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, notFound(err)
+	}
+	// GetObject is lazy: stat it so a missing key fails here rather than on Read
+	if _, err := obj.Stat(); err != nil {
+		obj.Close()
+		return nil, notFound(err)
+	}
+	return obj, nil
+}
+
+func (s *S3Store) Stat(key string) (*domain.BlobInfo, error) {
+	object, err := s.object(key)
+	if err != nil {
+		return nil, err
+	}
+	info, err := s.client.StatObject(context.Background(), s.bucket, object, minio.StatObjectOptions{})
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &domain.BlobInfo{Key: key, Size: info.Size, ContentType: info.ContentType, ModTime: info.LastModified}, nil
+}
+
+func (s *S3Store) Delete(key string) error {
+	object, err := s.object(key)
+	if err != nil {
+		return err
+	}
+	return notFound(s.client.RemoveObject(context.Background(), s.bucket, object, minio.RemoveObjectOptions{}))
+}
+
+func (s *S3Store) List(prefix string, fn func(domain.BlobInfo) error) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for info := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: s.prefix + prefix, Recursive: true}) {
+		if info.Err != nil {
+			return info.Err
+		}
+		key := info.Key[len(s.prefix):]
+		if err := fn(domain.BlobInfo{Key: key, Size: info.Size, ContentType: info.ContentType, ModTime: info.LastModified}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// URL presigns a GET. Attachments carry a response-content-disposition so the
+// browser saves them under filename.
+func (s *S3Store) URL(key, filename string, expiry time.Duration) (string, error) {
+	object, err := s.object(key)
+	if err != nil {
+		return "", err
+	}
+	params := url.Values{}
+	if filename != "" {
+		params.Set("response-content-disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	}
+	u, err := s.client.PresignedGetObject(context.Background(), s.bucket, object, expiry, params)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
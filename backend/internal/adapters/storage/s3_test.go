@@ -0,0 +1,202 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeS3 serves the part of the S3 API S3Store uses, with path-style buckets
+type fakeS3 struct {
+	mu      sync.Mutex
+	buckets map[string]map[string]fakeObject
+}
+
+type fakeObject struct {
+	data        []byte
+	contentType string
+	modTime     time.Time
+}
+
+func newFakeS3(t *testing.T) *httptest.Server {
+	t.Helper()
+	fake := &fakeS3{buckets: map[string]map[string]fakeObject{}}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	objects, exists := f.buckets[bucket]
+	switch {
+	case key == "" && r.Method == http.MethodHead:
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	case key == "" && r.Method == http.MethodPut:
+		f.buckets[bucket] = map[string]fakeObject{}
+	case !exists:
+		s3Error(w, http.StatusNotFound, "NoSuchBucket")
+	case key == "" && r.Method == http.MethodGet:
+		f.list(w, objects, r.URL.Query().Get("prefix"))
+	case r.Method == http.MethodPut:
+		data, err := readS3Body(r)
+		if err != nil {
+			s3Error(w, http.StatusBadRequest, "IncompleteBody")
+			return
+		}
+		objects[key] = fakeObject{data: data, contentType: r.Header.Get("Content-Type"), modTime: time.Now().UTC()}
+		w.Header().Set("ETag", `"etag"`)
+	case r.Method == http.MethodDelete:
+		delete(objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		obj, ok := objects[key]
+		if !ok {
+			s3Error(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		w.Header().Set("Content-Type", obj.contentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(obj.data)))
+		w.Header().Set("Last-Modified", obj.modTime.Format(http.TimeFormat))
+		w.Header().Set("ETag", `"etag"`)
+		if r.Method == http.MethodGet {
+			w.Write(obj.data)
+		}
+	default:
+		s3Error(w, http.StatusMethodNotAllowed, "MethodNotAllowed")
+	}
+}
+
+func (f *fakeS3) list(w http.ResponseWriter, objects map[string]fakeObject, prefix string) {
+	type content struct {
+		Key          string
+		Size         int
+		LastModified string
+		ETag         string
+	}
+	result := struct {
+		XMLName     xml.Name `xml:"ListBucketResult"`
+		Prefix      string
+		KeyCount    int
+		IsTruncated bool
+		Contents    []content
+	}{Prefix: prefix}
+	for key, obj := range objects {
+		if strings.HasPrefix(key, prefix) {
+			result.Contents = append(result.Contents, content{key, len(obj.data), obj.modTime.Format(time.RFC3339), `"etag"`})
+		}
+	}
+	sort.Slice(result.Contents, func(i, j int) bool { return result.Contents[i].Key < result.Contents[j].Key })
+	result.KeyCount = len(result.Contents)
+	w.Header().Set("Content-Type", "application/xml")
+	xml.NewEncoder(w).Encode(result)
+}
+
+func s3Error(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", code, code)
+}
+
+// readS3Body reads a PUT body, decoding the aws-chunked encoding used for
+// streaming signatures over plain HTTP
+func readS3Body(r *http.Request) ([]byte, error) {
+	if !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return io.ReadAll(r.Body)
+	}
+	var data bytes.Buffer
+	body := bufio.NewReader(r.Body)
+	for {
+		header, err := body.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		sizeHex, _, _ := strings.Cut(strings.TrimSpace(header), ";")
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return data.Bytes(), nil
+		}
+		if _, err := io.CopyN(&data, body, size); err != nil {
+			return nil, err
+		}
+		if _, err := body.Discard(2); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func newTestS3Store(t *testing.T, srv *httptest.Server, prefix string) *S3Store {
+	t.Helper()
+	store, err := NewS3Store(S3Config{
+		Endpoint:  strings.TrimPrefix(srv.URL, "http://"),
+		Bucket:    "anime",
+		AccessKey: "access",
+		SecretKey: "secret",
+		Region:    "us-east-1",
+		Prefix:    prefix,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestS3Store(t *testing.T) {
+	testBlobStore(t, newTestS3Store(t, newFakeS3(t), ""))
+}
+
+func TestS3StorePrefix(t *testing.T) {
+	srv := newFakeS3(t)
+	prefixed := newTestS3Store(t, srv, "site/")
+	testBlobStore(t, prefixed)
+
+	// Keys are stored under the prefix and listed without it
+	if err := prefixed.Put("media/x.jpg", strings.NewReader("x"), 1, ""); err != nil {
+		t.Fatal(err)
+	}
+	bare := newTestS3Store(t, srv, "")
+	if _, err := bare.Stat("site/media/x.jpg"); err != nil {
+		t.Fatalf("prefixed object not stored under the prefix: %v", err)
+	}
+	if _, err := bare.Stat("media/x.jpg"); err == nil {
+		t.Fatal("prefixed object stored without the prefix")
+	}
+}
+
+func TestS3StoreURL(t *testing.T) {
+	store := newTestS3Store(t, newFakeS3(t), "site/")
+	raw, err := store.URL("subtitles/1.vtt", "Episode 1.vtt", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	if u.Path != "/anime/site/subtitles/1.vtt" || q.Get("X-Amz-Signature") == "" || q.Get("X-Amz-Expires") != "3600" {
+		t.Fatalf("presigned URL = %s", raw)
+	}
+	if got := q.Get("response-content-disposition"); got != `attachment; filename="Episode 1.vtt"` {
+		t.Fatalf("content disposition = %q", got)
+	}
+}
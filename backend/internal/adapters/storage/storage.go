@@ -0,0 +1,29 @@
+package storage
+
+import (
+	"backend/config"
+	"backend/internal/core/port"
+	"fmt"
+)
+
+// New opens the blob store named by backend ("local" or "s3") with the settings in cfg
+func New(backend string, cfg *config.Config) (port.BlobStore, error) {
+	switch backend {
+	case "local":
+		return NewLocalStore(cfg.StorageLocalDir), nil
+	case "s3":
+		if cfg.S3Endpoint == "" || cfg.S3Bucket == "" {
+			return nil, fmt.Errorf("s3 storage needs S3_ENDPOINT and S3_BUCKET")
+		}
+		return NewS3Store(S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			Region:    cfg.S3Region,
+			UseSSL:    cfg.S3UseSSL,
+			Prefix:    cfg.S3Prefix,
+		})
+	}
+	return nil, fmt.Errorf("unknown storage backend %q", backend)
+}
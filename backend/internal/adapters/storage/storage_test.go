@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"backend/internal/core/domain"
+	"backend/internal/core/port"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// testBlobStore runs the behaviour every port.BlobStore shares against store
+func testBlobStore(t *testing.T, store port.BlobStore) {
+	t.Helper()
+	put := func(key, data string) {
+		t.Helper()
+		if err := store.Put(key, strings.NewReader(data), int64(len(data)), ""); err != nil {
+			t.Fatalf("Put(%q): %v", key, err)
+		}
+	}
+	put("media/ab/one.jpg", "first")
+	put("media/ab/one.jpg", "replaced")
+	put("media/cd/two.png", "2")
+	put("models/three.glb", "three")
+
+	r, err := store.Get("media/ab/one.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(r)
+	r.Close()
+	if err != nil || string(data) != "replaced" {
+		t.Fatalf("Get = %q, %v; want the replaced content", data, err)
+	}
+
+	info, err := store.Stat("media/ab/one.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Key != "media/ab/one.jpg" || info.Size != 8 || info.ContentType != "image/jpeg" || info.ModTime.IsZero() {
+		t.Fatalf("Stat = %+v", info)
+	}
+
+	var keys []string
+	err = store.List("media/", func(info domain.BlobInfo) error {
+		keys = append(keys, info.Key)
+		return nil
+	})
+	sort.Strings(keys)
+	if err != nil || strings.Join(keys, ",") != "media/ab/one.jpg,media/cd/two.png" {
+		t.Fatalf("List(media/) = %v, %v", keys, err)
+	}
+	stop := errors.New("stop")
+	if err := store.List("", func(domain.BlobInfo) error { return stop }); !errors.Is(err, stop) {
+		t.Fatalf("List did not return the callback error: %v", err)
+	}
+
+	if err := store.Delete("media/ab/one.jpg"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete("media/ab/one.jpg"); err != nil {
+		t.Fatalf("deleting a missing object: %v", err)
+	}
+	if _, err := store.Get("media/ab/one.jpg"); !errors.Is(err, domain.ErrBlobNotFound) {
+		t.Fatalf("Get of a deleted object = %v, want ErrBlobNotFound", err)
+	}
+	if _, err := store.Stat("media/ab/one.jpg"); !errors.Is(err, domain.ErrBlobNotFound) {
+		t.Fatalf("Stat of a deleted object = %v, want ErrBlobNotFound", err)
+	}
+
+	for _, key := range []string{"../escape.txt", "media/../../escape.txt", "media//double.txt", ""} {
+		if err := store.Put(key, strings.NewReader("x"), 1, ""); err == nil {
+			t.Errorf("Put(%q) accepted a key outside the root", key)
+		}
+	}
+}
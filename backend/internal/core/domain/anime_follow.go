@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrInvalidNotifyChannel is returned for a follow channel other than in_app or email
+	ErrInvalidNotifyChannel = errors.New("channel must be in_app or email")
+	// ErrEmailUnavailable is returned when following by email while no mail server is configured
+	ErrEmailUnavailable = errors.New("email notifications are not available")
+)
+
+// NotifyChannel is how a follower hears about new episodes
+type NotifyChannel string
+
+const (
+	NotifyInApp NotifyChannel = "in_app"
+	NotifyEmail NotifyChannel = "email"
+)
+
+func (c NotifyChannel) Valid() bool {
+	return c == NotifyInApp || c == NotifyEmail
+}
+
+// AnimeFollow subscribes a user to new episodes of an anime
+type AnimeFollow struct {
+	ID        uint          `gorm:"primaryKey" json:"id"`
+	UserID    uint          `gorm:"not null;uniqueIndex:idx_follow_user_anime" json:"user_id"`
+	User      *User         `gorm:"foreignKey:UserID" json:"-"`
+	AnimeID   uint          `gorm:"not null;uniqueIndex:idx_follow_user_anime;index" json:"anime_id"`
+	Anime     *Anime        `gorm:"foreignKey:AnimeID" json:"anime,omitempty"`
+	Channel   NotifyChannel `gorm:"size:10;not null;default:'in_app'" json:"channel"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// AnimeFollowStatus is the caller's follow of an anime and how many follow it
+type AnimeFollowStatus struct {
+	Following bool          `json:"following"`
+	Channel   NotifyChannel `json:"channel,omitempty"`
+	Followers int64         `json:"followers"`
+}
+
+// EpisodeAnnouncement queues the new-episode notifications of an episode that
+// was published. It is created once per episode, so republishing does not
+// notify again. LastFollowID is the fan-out cursor over the anime's follows.
+type EpisodeAnnouncement struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	EpisodeID    uint       `gorm:"not null;uniqueIndex" json:"episode_id"`
+	AnimeID      uint       `gorm:"not null;index" json:"anime_id"`
+	LastFollowID uint       `gorm:"not null;default:0" json:"last_follow_id"`
+	SentAt       *time.Time `gorm:"index" json:"sent_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidListStatus = errors.New("status must be one of watching, completed, on_hold, dropped, plan_to_watch")
+	ErrInvalidListEntry  = errors.New("score must be 0-10, episodes watched and rewatch count >= 0, finished on or after started")
+)
+
+// ListStatuses are the valid list statuses, in display order
+var ListStatuses = []ListStatus{ListStatusWatching, ListStatusCompleted, ListStatusOnHold, ListStatusDropped, ListStatusPlanToWatch}
+
+func (s ListStatus) Valid() bool {
+	for _, status := range ListStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// AnimeListEntry is one anime on a user's tracking list
+type AnimeListEntry struct {
+	ID              uint       `gorm:"primaryKey" json:"id"`
+	UserID          uint       `gorm:"not null;uniqueIndex:idx_list_user_anime" json:"user_id"`
+	AnimeID         uint       `gorm:"not null;uniqueIndex:idx_list_user_anime;index" json:"anime_id"`
+	Anime           *Anime     `gorm:"foreignKey:AnimeID" json:"anime,omitempty"`
+	Status          ListStatus `gorm:"size:20;not null;index" json:"status"`
+	Score           float64    `gorm:"default:0" json:"score"` // 0-10, 0 when not scored
+	EpisodesWatched int        `gorm:"default:0" json:"episodes_watched"`
+	StartedAt       *time.Time `json:"started_at,omitempty"`
+	FinishedAt      *time.Time `json:"finished_at,omitempty"`
+	RewatchCount    int        `gorm:"default:0" json:"rewatch_count"`
+	Notes           string     `gorm:"type:text" json:"notes"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// AnimeListUpdate changes a list entry; nil fields keep their value
+type AnimeListUpdate struct {
+	Status          *ListStatus `json:"status"`
+	Score           *float64    `json:"score"`
+	EpisodesWatched *int        `json:"episodes_watched"`
+	StartedAt       *time.Time  `json:"started_at"`
+	FinishedAt      *time.Time  `json:"finished_at"`
+	RewatchCount    *int        `json:"rewatch_count"`
+	Notes           *string     `json:"notes"`
+}
+
+// AnimeListFilter narrows a list query
+type AnimeListFilter struct {
+	Status ListStatus
+	Query  string // Matched against the anime titles
+	Sort   string // updated (default), score, title, started, finished
+	Limit  int
+	Offset int
+}
+
+// AnimeListStats summarizes a user's list
+type AnimeListStats struct {
+	Total           int64                `json:"total"`
+	ByStatus        map[ListStatus]int64 `json:"by_status"`
+	EpisodesWatched int64                `json:"episodes_watched"`
+	Rewatches       int64                `json:"rewatches"`
+	Scored          int64                `json:"scored"`
+	MeanScore       float64              `json:"mean_score"`   // Over scored entries
+	ScoreCounts     map[int]int64        `json:"score_counts"` // Rounded score -> entries
+}
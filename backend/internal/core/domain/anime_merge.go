@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrMergeSameAnime is returned when an anime is merged into itself
+var ErrMergeSameAnime = errors.New("cannot merge an anime into itself")
+
+// AnimeRedirect points a merged (soft-deleted) anime at the one that absorbed it,
+// so old links by ID or slug keep resolving
+type AnimeRedirect struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	FromID     uint      `gorm:"uniqueIndex;not null" json:"from_id"`
+	ToID       uint      `gorm:"index;not null" json:"to_id"`
+	FromSlug   string    `gorm:"index" json:"from_slug"`
+	FromSlugEn string    `gorm:"index" json:"from_slug_en"`
+	MergedBy   *uint     `json:"merged_by,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// AnimeDuplicateDismissal records a candidate pair an editor marked as distinct
+type AnimeDuplicateDismissal struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	AnimeID   uint      `gorm:"not null;uniqueIndex:idx_duplicate_pair" json:"anime_id"` // Lower ID of the pair
+	OtherID   uint      `gorm:"not null;uniqueIndex:idx_duplicate_pair" json:"other_id"`
+	UserID    *uint     `json:"user_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DuplicateAnime is one side of a candidate pair
+type DuplicateAnime struct {
+	ID          uint       `json:"id"`
+	Title       string     `json:"title"`
+	TitleEn     string     `json:"title_en"`
+	Slug        string     `json:"slug"`
+	ReleaseDate *time.Time `json:"release_date"`
+	StudioID    *uint      `json:"studio_id"`
+	StudioName  string     `json:"studio_name"`
+	Episodes    int64      `json:"episodes"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// DuplicateCandidate is a pair of anime that are probably the same show
+type DuplicateCandidate struct {
+	A       DuplicateAnime `json:"a"`
+	B       DuplicateAnime `json:"b"`
+	Score   float64        `json:"score"`   // 0-1, title similarity adjusted by date and studio
+	Reasons []string       `json:"reasons"` // e.g. "title", "release_date", "studio"
+}
+
+// AnimeMergeReport counts what a merge moved onto the surviving anime
+type AnimeMergeReport struct {
+	SurvivorID       uint  `json:"survivor_id"`
+	LoserID          uint  `json:"loser_id"`
+	EpisodesMoved    int64 `json:"episodes_moved"`
+	EpisodesMerged   int64 `json:"episodes_merged"` // Same number on both: folded into the survivor's episode
+	Comments         int64 `json:"comments"`
+	History          int64 `json:"history"`
+	ListEntries      int64 `json:"list_entries"`
+	CollectionItems  int64 `json:"collection_items"`
+	Follows          int64 `json:"follows"`
+	Categories       int64 `json:"categories"`
+	RatingCopied     bool  `json:"rating_copied"`
+	RedirectsUpdated int64 `json:"redirects_updated"` // Earlier redirects to the loser now pointing at the survivor
+}
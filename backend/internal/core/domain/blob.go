@@ -0,0 +1,19 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrBlobNotFound is returned when a blob store has no object under a key
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobInfo describes an object in a blob store. Keys are slash-separated paths
+// relative to the uploads root, e.g. "media/ab/ab12....jpg", so an object with
+// key k is served at /uploads/k whatever the backend.
+type BlobInfo struct {
+	Key         string    `json:"key"`
+	Size        int64     `json:"size"`
+	ContentType string    `json:"content_type,omitempty"`
+	ModTime     time.Time `json:"mod_time"`
+}
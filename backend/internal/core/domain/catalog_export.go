@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+// CatalogExportLine is one JSON Lines record of a catalog export
+type CatalogExportLine struct {
+	Entity  string      `json:"entity"`
+	Deleted bool        `json:"deleted,omitempty"` // Tombstone for rows soft-deleted since updated_since
+	Data    interface{} `json:"data"`
+}
+
+// CatalogTombstone identifies a row that was soft-deleted
+type CatalogTombstone struct {
+	ID        uint      `json:"id"`
+	Slug      string    `json:"slug"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
@@ -0,0 +1,130 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrCollectionNotFound is returned for missing collections and for ones the caller may not see
+	ErrCollectionNotFound = errors.New("collection not found")
+	// ErrCollectionForbidden is returned when the caller may see a collection but not change it
+	ErrCollectionForbidden = errors.New("you cannot change this collection")
+	// ErrInvalidCollection is returned for a missing or too long title or description, or an unknown visibility
+	ErrInvalidCollection = errors.New("title is required (max 120 characters), description max 2000, visibility private, unlisted or public")
+	// ErrCollectionItemNotFound is returned for missing items and for items whose content does not exist
+	ErrCollectionItemNotFound = errors.New("collection item not found")
+	// ErrCollectionItemExists is returned when the same anime, episode or model is added twice
+	ErrCollectionItemExists = errors.New("already in this collection")
+	// ErrCollectionFull is returned when a collection reaches MaxCollectionItems
+	ErrCollectionFull = errors.New("collection is full")
+	// ErrInvalidReorder is returned when a reorder does not list every item exactly once
+	ErrInvalidReorder = errors.New("item_ids must list every item of the collection exactly once")
+	// ErrInvalidCollaborator is returned when adding the owner, a missing user or an existing collaborator
+	ErrInvalidCollaborator = errors.New("user cannot be added as a collaborator")
+)
+
+// MaxCollectionItems caps the items of one collection
+const MaxCollectionItems = 500
+
+// CollectionVisibility controls who can see a collection. Unlisted ones are
+// reachable by their share slug but not browsed.
+type CollectionVisibility string
+
+const (
+	CollectionPrivate  CollectionVisibility = "private"
+	CollectionUnlisted CollectionVisibility = "unlisted"
+	CollectionPublic   CollectionVisibility = "public"
+)
+
+func (v CollectionVisibility) Valid() bool {
+	return v == CollectionPrivate || v == CollectionUnlisted || v == CollectionPublic
+}
+
+// CollectionItemType is the kind of content a collection item points at
+type CollectionItemType string
+
+const (
+	CollectionItemAnime   CollectionItemType = "anime"
+	CollectionItemEpisode CollectionItemType = "episode"
+	CollectionItemModel   CollectionItemType = "model"
+)
+
+func (t CollectionItemType) Valid() bool {
+	return t == CollectionItemAnime || t == CollectionItemEpisode || t == CollectionItemModel
+}
+
+// Collection is a user-curated, ordered list of anime, episodes and 3D models
+type Collection struct {
+	ID             uint                     `gorm:"primaryKey" json:"id"`
+	UserID         uint                     `gorm:"not null;index" json:"user_id"`
+	User           *User                    `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Title          string                   `gorm:"size:120;not null" json:"title"`
+	Description    string                   `gorm:"type:text" json:"description"`
+	Visibility     CollectionVisibility     `gorm:"size:10;not null;default:'private';index" json:"visibility"`
+	Slug           string                   `gorm:"uniqueIndex;not null" json:"slug"` // Share slug, regenerated to revoke old links
+	ItemsCount     int                      `gorm:"not null;default:0" json:"items_count"`
+	FollowersCount int                      `gorm:"not null;default:0;index" json:"followers_count"`
+	LikesCount     int                      `gorm:"not null;default:0;index" json:"likes_count"`
+	Items          []CollectionItem         `gorm:"foreignKey:CollectionID" json:"items,omitempty"`
+	Collaborators  []CollectionCollaborator `gorm:"foreignKey:CollectionID" json:"collaborators,omitempty"`
+	Viewer         *CollectionViewer        `gorm:"-" json:"viewer,omitempty"` // The caller's relation to it, filled on read
+	CreatedAt      time.Time                `json:"created_at"`
+	UpdatedAt      time.Time                `json:"updated_at"`
+}
+
+// CollectionItem is one entry of a collection. The content is filled on read;
+// items whose content was deleted or unpublished are left out.
+type CollectionItem struct {
+	ID           uint               `gorm:"primaryKey" json:"id"`
+	CollectionID uint               `gorm:"not null;uniqueIndex:idx_collection_item;index:idx_collection_position" json:"collection_id"`
+	ItemType     CollectionItemType `gorm:"size:10;not null;uniqueIndex:idx_collection_item;index:idx_collection_content" json:"item_type"`
+	ItemID       uint               `gorm:"not null;uniqueIndex:idx_collection_item;index:idx_collection_content" json:"item_id"`
+	Position     int                `gorm:"not null;index:idx_collection_position" json:"position"` // 0-based
+	Note         string             `gorm:"size:500" json:"note"`
+	AddedBy      uint               `json:"added_by"`
+	Anime        *Anime             `gorm:"-" json:"anime,omitempty"`
+	Episode      *Episode           `gorm:"-" json:"episode,omitempty"`
+	Model        *Model             `gorm:"-" json:"model,omitempty"`
+	CreatedAt    time.Time          `json:"created_at"`
+}
+
+// CollectionCollaborator can add, remove and reorder the items of someone else's collection
+type CollectionCollaborator struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	CollectionID uint      `gorm:"not null;uniqueIndex:idx_collection_collaborator" json:"collection_id"`
+	UserID       uint      `gorm:"not null;uniqueIndex:idx_collection_collaborator;index" json:"user_id"`
+	User         *User     `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	AddedBy      uint      `json:"added_by"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// CollectionFollow subscribes a user to a collection
+type CollectionFollow struct {
+	CollectionID uint      `gorm:"primaryKey;autoIncrement:false" json:"collection_id"`
+	UserID       uint      `gorm:"primaryKey;autoIncrement:false;index" json:"user_id"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// CollectionLike is a user's like of a collection
+type CollectionLike struct {
+	CollectionID uint      `gorm:"primaryKey;autoIncrement:false" json:"collection_id"`
+	UserID       uint      `gorm:"primaryKey;autoIncrement:false;index" json:"user_id"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// CollectionViewer is what the caller can do with a collection and whether they follow or like it
+type CollectionViewer struct {
+	IsOwner  bool `json:"is_owner"`
+	CanEdit  bool `json:"can_edit"` // Owner or collaborator
+	Followed bool `json:"followed"`
+	Liked    bool `json:"liked"`
+}
+
+// CollectionFilter narrows a collection listing
+type CollectionFilter struct {
+	Query  string
+	Sort   string // popular (default) or recent
+	Limit  int
+	Offset int
+}
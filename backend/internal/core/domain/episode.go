@@ -7,40 +7,51 @@ import (
 )
 
 type Episode struct {
-	ID            uint            `gorm:"primaryKey" json:"id"`
-	CreatedAt     time.Time       `json:"created_at"`
-	UpdatedAt     time.Time       `json:"updated_at"`
-	DeletedAt     gorm.DeletedAt  `gorm:"index" json:"-"`
-	AnimeID       uint            `json:"anime_id"`
-	Anime         Anime           `json:"anime" gorm:"foreignKey:AnimeID"`
-	Title         string          `json:"title"`
-	TitleEn       string          `json:"title_en"`
-	Slug          string          `json:"slug"`
-	SlugEn        string          `json:"slug_en"`
-	EpisodeNumber int             `json:"episode_number"`
-	Description   string          `json:"description"`
-	DescriptionEn string          `json:"description_en"`
-	Thumbnail     string          `json:"thumbnail"`
-	Banner        string          `json:"banner"`
-	VideoURLs     string          `json:"video_urls"` // JSON string: [{url, type, name}]
-	Duration      int             `json:"duration"`
-	Quality       string          `json:"quality"`
-	VideoFormat   string          `json:"video_format"`
-	ReleaseDate   time.Time       `json:"release_date"`
-	IsPublished   bool            `json:"is_published"`
-	Language      string          `json:"language"`
-	Rating        float64         `json:"rating"`
-	Servers       []EpisodeServer `json:"servers" gorm:"foreignKey:EpisodeID"`
+	ID            uint              `gorm:"primaryKey" json:"id"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt    `gorm:"index" json:"-"`
+	AnimeID       uint              `json:"anime_id"`
+	Anime         Anime             `json:"anime" gorm:"foreignKey:AnimeID"`
+	Title         string            `json:"title"`
+	TitleEn       string            `json:"title_en"`
+	Slug          string            `json:"slug"`
+	SlugEn        string            `json:"slug_en"`
+	EpisodeNumber int               `json:"episode_number"`
+	Description   string            `json:"description"`
+	DescriptionEn string            `json:"description_en"`
+	Localized     map[string]string `gorm:"-" json:"localized,omitempty"`
+	Thumbnail     string            `json:"thumbnail"`
+	ThumbnailSet  *ResponsiveImage  `gorm:"-" json:"thumbnail_set,omitempty"`
+	Banner        string            `json:"banner"`
+	VideoURLs     string            `json:"video_urls"` // Legacy JSON string [{url, type, name}], rebuilt from Servers on save
+	Duration      int               `json:"duration"`
+	Quality       string            `json:"quality"`
+	VideoFormat   string            `json:"video_format"`
+	ReleaseDate   time.Time         `json:"release_date"`
+	IsPublished   bool              `json:"is_published"` // Mirrors State == published
+	State         ContentState      `gorm:"size:20;default:'published';index" json:"state"`
+	Language      string            `json:"language"`
+	Rating        float64           `json:"rating"`
+	Servers       []EpisodeServer   `json:"servers" gorm:"foreignKey:EpisodeID"`
+	Subtitles     []SubtitleTrack   `gorm:"-" json:"subtitles,omitempty"`      // Filled on read, managed through the subtitles endpoints
+	Version       int               `gorm:"not null;default:1" json:"version"` // Bumped on every save, used for optimistic locking
 }
 
 type EpisodeServer struct {
-	ID        uint           `gorm:"primaryKey" json:"id"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
-	EpisodeID uint           `json:"episode_id"`
-	Language  string         `json:"language"` // 'ar', 'en', etc.
-	Name      string         `json:"name"`     // Server Name (e.g. 'Main', '4Shared')
-	URL       string         `json:"url"`      // The video URL/Embed
-	Type      string         `json:"type"`     // 'embed', 'direct', etc. (optional)
+	ID        uint              `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+	DeletedAt gorm.DeletedAt    `gorm:"index" json:"-"`
+	EpisodeID uint              `json:"episode_id"`
+	Language  string            `json:"language"`                                 // 'ar', 'en', etc.
+	Name      string            `json:"name"`                                     // Server Name (e.g. 'Main', '4Shared')
+	URL       string            `json:"url"`                                      // The video URL/Embed
+	Type      string            `json:"type"`                                     // Legacy, mirrors Kind
+	Provider  string            `gorm:"size:50" json:"provider"`                  // e.g. 'youtube', derived from the host when empty
+	Kind      SourceKind        `gorm:"size:10" json:"kind"`                      // embed, direct, hls or dash
+	Quality   string            `gorm:"size:10" json:"quality"`                   // e.g. '720p', 'auto'
+	Priority  int               `gorm:"not null;default:0" json:"priority"`       // Higher is preferred within a language
+	Headers   map[string]string `gorm:"serializer:json" json:"headers,omitempty"` // Request headers the player must send (e.g. Referer)
+	Health    *ServerHealth     `gorm:"-" json:"health,omitempty"`                // Latest probe results, filled on read
 }
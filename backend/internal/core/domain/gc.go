@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+// GCQuarantinePrefix is where the upload garbage collector moves orphaned blobs.
+// A quarantined blob keeps its key after the prefix, so it can be restored as is.
+const GCQuarantinePrefix = "quarantine/"
+
+// GCReport summarises one run of the upload garbage collector
+type GCReport struct {
+	DryRun           bool      `json:"dry_run"`
+	StartedAt        time.Time `json:"started_at"`
+	FinishedAt       time.Time `json:"finished_at"`
+	Scanned          int       `json:"scanned"`           // Blobs examined
+	Referenced       int       `json:"referenced"`        // Blobs still pointed at by a row
+	Young            int       `json:"young"`             // Unreferenced blobs still inside the grace period
+	Quarantined      int       `json:"quarantined"`       // Orphans moved (or, in a dry run, to be moved) to quarantine
+	QuarantinedBytes int64     `json:"quarantined_bytes"` // Size of those orphans
+	Purged           int       `json:"purged"`            // Quarantined blobs deleted after the retention period
+	ReclaimedBytes   int64     `json:"reclaimed_bytes"`   // Space freed by the purge
+	Orphans          []string  `json:"orphans,omitempty"` // Keys of the quarantined orphans, capped
+	Errors           []string  `json:"errors,omitempty"`
+}
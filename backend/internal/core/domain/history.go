@@ -12,6 +12,7 @@ const (
 	ActivityComment     ActivityType = "comment"
 	ActivityLike        ActivityType = "like"
 	ActivityReply       ActivityType = "reply"
+	ActivityListImport  ActivityType = "list_import" // An entry imported from another site's list, not a view
 )
 
 type History struct {
@@ -35,6 +36,8 @@ type History struct {
 	Image    string `gorm:"type:varchar(500)" json:"image,omitempty"` // Image path for quick display
 	Metadata string `gorm:"type:text" json:"metadata,omitempty"`      // JSON for additional data
 
+	ImportSource string `gorm:"size:20;index" json:"import_source,omitempty"` // List the entry was imported from, empty for own activity
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrInvalidImage is returned when an uploaded file cannot be decoded as an image or is too large
+var ErrInvalidImage = errors.New("invalid image")
+
+// ImageVariantWidths are the widths responsive variants are generated at. Widths
+// above the source width are skipped, so small images get fewer variants.
+var ImageVariantWidths = []int{160, 320, 640, 1280}
+
+// ImageVariant is one resized copy of an image
+type ImageVariant struct {
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Format string `json:"format"` // "webp" or "jpeg"
+	URL    string `json:"url"`
+	Size   int64  `json:"size"`
+}
+
+// ImageAsset records the processed variants and placeholders of a local image. It
+// is keyed by the image's path under uploads/ so every column referencing the
+// same file shares it.
+type ImageAsset struct {
+	ID        uint           `gorm:"primaryKey" json:"-"`
+	Source    string         `gorm:"uniqueIndex;not null" json:"source"` // e.g. "uploads/animes/naruto.jpg"
+	Width     int            `json:"width"`
+	Height    int            `json:"height"`
+	BlurHash  string         `gorm:"size:64" json:"blurhash"`
+	LQIP      string         `gorm:"column:lqip" json:"lqip"`         // Tiny JPEG as a data: URI
+	Variants  []ImageVariant `gorm:"serializer:json" json:"variants"` // Widest last within each format
+	Error     string         `json:"error,omitempty"`                 // Why processing failed; not retried
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// ResponsiveImage is the srcset-ready form of an ImageAsset included in API responses.
+// Models carry one next to each image path (Anime.ImageSet for Anime.Image, and so
+// on); it is never stored and is only filled when the model is read.
+type ResponsiveImage struct {
+	Src        string `json:"src"`         // Widest JPEG variant
+	SrcSet     string `json:"srcset"`      // JPEG variants, e.g. "/uploads/variants/ab/160.jpg 160w, ..."
+	WebPSrcSet string `json:"webp_srcset"` // WebP variants, only those smaller than their JPEG
+	Width      int    `json:"width"`
+	Height     int    `json:"height"`
+	BlurHash   string `json:"blurhash"`
+	LQIP       string `json:"lqip"`
+}
@@ -0,0 +1,163 @@
+package domain
+
+// Import actions reported per row
+const (
+	ImportActionCreate    = "create"
+	ImportActionUpdate    = "update"
+	ImportActionUnchanged = "unchanged"
+	ImportActionError     = "error"
+)
+
+// Importable entities (used by CSV imports and row reports)
+const (
+	ImportEntityAnime    = "anime"
+	ImportEntityEpisode  = "episode"
+	ImportEntityCategory = "category"
+	ImportEntityType     = "type"
+	ImportEntitySeason   = "season"
+	ImportEntityStudio   = "studio"
+	ImportEntityLanguage = "language"
+)
+
+// ImportClearValue in a date field clears the existing date; an empty field keeps it
+const ImportClearValue = "-"
+
+// CatalogImport is a normalized batch of catalog rows, regardless of source format.
+// Empty fields leave the existing value untouched when a row matches by slug.
+type CatalogImport struct {
+	Categories []DictionaryImportRecord `json:"categories"`
+	Types      []DictionaryImportRecord `json:"types"`
+	Seasons    []DictionaryImportRecord `json:"seasons"`
+	Studios    []DictionaryImportRecord `json:"studios"`
+	Languages  []DictionaryImportRecord `json:"languages"`
+	Animes     []AnimeImportRecord      `json:"animes"`
+	Episodes   []EpisodeImportRecord    `json:"episodes"`
+}
+
+type DictionaryImportRecord struct {
+	Row         int      `json:"-"`
+	Errors      []string `json:"-"` // Parse errors collected while reading the source file
+	Slug        string   `json:"slug"`
+	Name        string   `json:"name"`
+	NameEn      string   `json:"name_en"`
+	Description string   `json:"description"` // Categories only
+	IsActive    *bool    `json:"is_active"`
+}
+
+type AnimeImportRecord struct {
+	Row           int      `json:"-"`
+	Errors        []string `json:"-"`
+	Slug          string   `json:"slug"`
+	SlugEn        string   `json:"slug_en"`
+	Title         string   `json:"title"`
+	TitleEn       string   `json:"title_en"`
+	Description   string   `json:"description"`
+	DescriptionEn string   `json:"description_en"`
+	Status        string   `json:"status"`
+	Type          string   `json:"type"`
+	ReleaseDate   string   `json:"release_date"` // YYYY-MM-DD, or ImportClearValue
+	Rating        *float64 `json:"rating"`
+	Seasons       *int     `json:"seasons_count"`
+	Duration      *int     `json:"duration"`
+	Image         string   `json:"image"`
+	Cover         string   `json:"cover"`
+	Trailer       string   `json:"trailer"`
+	StudioName    string   `json:"studio_name"`
+	Language      string   `json:"language"`
+	IsActive      *bool    `json:"is_active"`
+	Categories    []string `json:"categories"` // Category slugs, replaces existing links when present
+	Season        string   `json:"season"`     // Season slug
+	Studio        string   `json:"studio"`     // Studio slug
+	LanguageRel   string   `json:"language_rel"`
+}
+
+type EpisodeImportRecord struct {
+	Row           int                  `json:"-"`
+	Errors        []string             `json:"-"`
+	AnimeSlug     string               `json:"anime_slug"`
+	EpisodeNumber int                  `json:"episode_number"`
+	Slug          string               `json:"slug"` // Defaults to "<anime_slug>-<episode_number>"
+	SlugEn        string               `json:"slug_en"`
+	Title         string               `json:"title"`
+	TitleEn       string               `json:"title_en"`
+	Description   string               `json:"description"`
+	DescriptionEn string               `json:"description_en"`
+	Thumbnail     string               `json:"thumbnail"`
+	Banner        string               `json:"banner"`
+	Duration      *int                 `json:"duration"`
+	Quality       string               `json:"quality"`
+	VideoFormat   string               `json:"video_format"`
+	ReleaseDate   string               `json:"release_date"` // YYYY-MM-DD
+	IsPublished   *bool                `json:"is_published"`
+	Language      string               `json:"language"`
+	Servers       []ServerImportRecord `json:"servers"` // Replaces existing servers when present
+}
+
+type ServerImportRecord struct {
+	Language string            `json:"language"`
+	Name     string            `json:"name"`
+	URL      string            `json:"url"`
+	Type     string            `json:"type"` // Legacy, used as the kind when Kind is empty
+	Provider string            `json:"provider,omitempty"`
+	Kind     SourceKind        `json:"kind,omitempty"`
+	Quality  string            `json:"quality,omitempty"`
+	Priority int               `json:"priority,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"`
+}
+
+// NewServerImportRecord converts a stored server to its import/export form
+func NewServerImportRecord(sv EpisodeServer) ServerImportRecord {
+	return ServerImportRecord{
+		Language: sv.Language, Name: sv.Name, URL: sv.URL, Type: sv.Type,
+		Provider: sv.Provider, Kind: sv.Kind, Quality: sv.Quality, Priority: sv.Priority, Headers: sv.Headers,
+	}
+}
+
+// EpisodeServer converts the record to an unsaved server row
+func (r ServerImportRecord) EpisodeServer() EpisodeServer {
+	return EpisodeServer{
+		Language: r.Language, Name: r.Name, URL: r.URL, Type: r.Type,
+		Provider: r.Provider, Kind: r.Kind, Quality: r.Quality, Priority: r.Priority, Headers: r.Headers,
+	}
+}
+
+// ImportFieldChange is a single field difference between the stored and imported row
+type ImportFieldChange struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+type ImportRowResult struct {
+	Row     int                          `json:"row"`
+	Entity  string                       `json:"entity"`
+	Slug    string                       `json:"slug"`
+	Action  string                       `json:"action"`
+	Changes map[string]ImportFieldChange `json:"changes,omitempty"`
+	Errors  []string                     `json:"errors,omitempty"`
+}
+
+// ImportReport summarizes an import run. Nothing is written unless Committed is true.
+type ImportReport struct {
+	DryRun    bool              `json:"dry_run"`
+	Committed bool              `json:"committed"`
+	Created   int               `json:"created"`
+	Updated   int               `json:"updated"`
+	Unchanged int               `json:"unchanged"`
+	Failed    int               `json:"failed"`
+	Rows      []ImportRowResult `json:"rows"`
+}
+
+// Add appends a row result and updates the summary counters
+func (r *ImportReport) Add(row ImportRowResult) {
+	switch row.Action {
+	case ImportActionCreate:
+		r.Created++
+	case ImportActionUpdate:
+		r.Updated++
+	case ImportActionUnchanged:
+		r.Unchanged++
+	default:
+		r.Failed++
+	}
+	r.Rows = append(r.Rows, row)
+}
@@ -0,0 +1,69 @@
+package domain
+
+import "time"
+
+// ListStatus is a user's tracking status for an anime
+type ListStatus string
+
+const (
+	ListStatusWatching    ListStatus = "watching"
+	ListStatusCompleted   ListStatus = "completed"
+	ListStatusOnHold      ListStatus = "on_hold"
+	ListStatusDropped     ListStatus = "dropped"
+	ListStatusPlanToWatch ListStatus = "plan_to_watch"
+)
+
+// External list sources
+const (
+	ListSourceMAL     = "mal"
+	ListSourceAniList = "anilist"
+)
+
+// ExternalListEntry is one anime from a MyAnimeList or AniList export
+type ExternalListEntry struct {
+	ExternalID string     `json:"external_id"`
+	Titles     []string   `json:"titles"` // Romaji / English / native, best first
+	Status     ListStatus `json:"status"`
+	Progress   int        `json:"progress"` // Episodes watched
+	Score      float64    `json:"score"`    // 0-10
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Rewatches  int        `json:"rewatches"`
+	Notes      string     `json:"notes,omitempty"`
+}
+
+type ListImportCandidate struct {
+	AnimeID uint    `json:"anime_id"`
+	Title   string  `json:"title"`
+	TitleEn string  `json:"title_en"`
+	Slug    string  `json:"slug"`
+	Score   float64 `json:"score"`
+}
+
+type ListImportMatch struct {
+	ExternalListEntry
+	AnimeID    uint    `json:"anime_id"`
+	Title      string  `json:"title"`
+	Confidence float64 `json:"confidence"`
+	History    bool    `json:"history"` // Entry recorded in history as a list import
+}
+
+type ListImportUnmatched struct {
+	ExternalListEntry
+	Candidates []ListImportCandidate `json:"candidates"`
+}
+
+// ListImportReport is returned by list imports; unmatched entries can be resolved manually
+type ListImportReport struct {
+	Source    string                `json:"source"`
+	DryRun    bool                  `json:"dry_run"`
+	Total     int                   `json:"total"`
+	Matched   []ListImportMatch     `json:"matched"`
+	Unmatched []ListImportUnmatched `json:"unmatched"`
+}
+
+// ListImportResolution maps an unmatched external entry to one of our anime
+type ListImportResolution struct {
+	ExternalListEntry
+	AnimeID uint `json:"anime_id" binding:"required"`
+}
@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"errors"
+	"path"
+	"strings"
+	"time"
+)
+
+// ErrMediaNotFound is returned when no stored file has the requested hash
+var ErrMediaNotFound = errors.New("media not found")
+
+// ErrMediaDeleting is returned when content is stored while another replica
+// deletes the same content; storing it again after the deletion succeeds
+var ErrMediaDeleting = errors.New("media is being deleted")
+
+// Owners of media references. Each is the table of the row holding the reference.
+const (
+	MediaOwnerAnime   = "anime"
+	MediaOwnerEpisode = "episode"
+	MediaOwnerModel   = "model"
+	MediaOwnerUser    = "user"
+)
+
+// Media is a file in the content-addressed store, kept under
+// uploads/media/<first two hex digits>/<sha256>.<ext>. Identical uploads share a
+// single row and file; RefCount counts the MediaRef rows pointing at it.
+type Media struct {
+	ID         string     `gorm:"primaryKey;size:64" json:"id"` // Hex SHA-256 of the content
+	Size       int64      `json:"size"`
+	MimeType   string     `json:"mime_type"`
+	Ext        string     `json:"ext"`
+	Path       string     `gorm:"not null" json:"-"`
+	URL        string     `gorm:"not null" json:"url"`
+	RefCount   int        `gorm:"not null;default:0;index" json:"ref_count"`
+	ReleasedAt *time.Time `json:"released_at,omitempty"`           // When the last reference was dropped; the file is deleted then
+	Deleting   bool       `gorm:"not null;default:false" json:"-"` // Claimed by the replica deleting the file
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// MediaRef records that a column of a row points at a stored file, e.g. the
+// cover of anime 12
+type MediaRef struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	MediaID   string    `gorm:"size:64;not null;index" json:"media_id"`
+	OwnerType string    `gorm:"not null;uniqueIndex:idx_media_ref_owner" json:"owner_type"`
+	OwnerID   uint      `gorm:"not null;uniqueIndex:idx_media_ref_owner" json:"owner_id"`
+	Field     string    `gorm:"not null;uniqueIndex:idx_media_ref_owner" json:"field"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MediaDir is where stored files live, relative to the working directory
+const MediaDir = "uploads/media"
+
+// MediaColumn is a column that may hold the URL of a stored file
+type MediaColumn struct {
+	OwnerType string
+	Field     string // Name of the reference, e.g. "cover"
+	Column    string
+}
+
+// MediaColumns lists every column whose files are tracked by the store
+var MediaColumns = []MediaColumn{
+	{MediaOwnerAnime, "image", "image"},
+	{MediaOwnerAnime, "cover", "cover"},
+	{MediaOwnerEpisode, "thumbnail", "thumbnail"},
+	{MediaOwnerEpisode, "banner", "banner"},
+	{MediaOwnerModel, "file", "path"},
+	{MediaOwnerModel, "image", "image"},
+	{MediaOwnerModel, "mini_blur", "mini_blur_path"},
+	{MediaOwnerUser, "avatar", "avatar"},
+}
+
+// MediaIDFromURL returns the hash of a stored file from its URL or path, as in
+// "/uploads/media/ab/ab12....jpg". ok is false for anything outside the store.
+func MediaIDFromURL(url string) (id string, ok bool) {
+	url = strings.TrimPrefix(strings.TrimSpace(url), "http://localhost:8080")
+	url = strings.TrimPrefix(url, "/")
+	rest, found := strings.CutPrefix(url, MediaDir+"/")
+	if !found {
+		return "", false
+	}
+	dir, name, found := strings.Cut(rest, "/")
+	id = strings.TrimSuffix(name, path.Ext(name))
+	if !found || len(id) != 64 || strings.Trim(id, "0123456789abcdef") != "" || dir != id[:2] {
+		return "", false
+	}
+	return id, true
+}
@@ -9,10 +9,11 @@ import (
 type NotificationType string
 
 const (
-	NotificationTypeReply   NotificationType = "reply"
-	NotificationTypeLike    NotificationType = "like"
-	NotificationTypeSystem  NotificationType = "system"
-	NotificationTypeNewPost NotificationType = "new_post" // New anime/episode
+	NotificationTypeReply    NotificationType = "reply"
+	NotificationTypeLike     NotificationType = "like"
+	NotificationTypeSystem   NotificationType = "system"
+	NotificationTypeNewPost  NotificationType = "new_post" // New anime/episode
+	NotificationTypeWorkflow NotificationType = "workflow" // Editorial state change or review request
 )
 
 // Notification represents a user notification
@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidUsername is returned for a handle that does not match usernamePattern or has no letter
+	ErrInvalidUsername = errors.New("username must be 3-30 lowercase letters, digits or underscores, with at least one letter")
+	// ErrUsernameTaken is returned when another user already has the handle
+	ErrUsernameTaken = errors.New("username already taken")
+)
+
+var usernamePattern = regexp.MustCompile(`^[a-z0-9_]{3,30}$`)
+
+// NormalizeUsername lowercases a handle and drops a leading @
+func NormalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(username), "@"))
+}
+
+// ValidUsername reports whether a normalized handle is acceptable. Handles need
+// a letter so they can never be mistaken for a user ID.
+func ValidUsername(username string) bool {
+	return usernamePattern.MatchString(username) && strings.ContainsAny(username, "abcdefghijklmnopqrstuvwxyz")
+}
+
+// GenreCount is how many anime of a category are on a user's list
+type GenreCount struct {
+	ID     uint   `json:"id"`
+	Name   string `json:"name"`
+	NameEn string `json:"name_en"`
+	Slug   string `json:"slug"`
+	Count  int64  `json:"count"`
+}
+
+// Badge is an achievement shown on a profile
+type Badge struct {
+	Key         string `json:"key"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// FavoriteAnime is a highly scored anime on a profile. It leaves out the notes,
+// dates and rewatches of the list entry, which stay with its owner.
+type FavoriteAnime struct {
+	Anime  *Anime     `json:"anime"`
+	Score  float64    `json:"score"`
+	Status ListStatus `json:"status"`
+}
+
+// UserProfile is what anyone can see about a user. List stats, favorites, top
+// genres and list badges are left out when the user hides their list, and the
+// activity only holds the types they share.
+type UserProfile struct {
+	ID             uint              `json:"id"`
+	Username       string            `json:"username"`
+	Name           string            `json:"name"`
+	Avatar         string            `json:"avatar"`
+	AvatarSet      *ResponsiveImage  `json:"avatar_set,omitempty"`
+	JoinedAt       time.Time         `json:"joined_at"`
+	Follow         *UserFollowStatus `json:"follow"`
+	ListHidden     bool              `json:"list_hidden"`
+	ListStats      *AnimeListStats   `json:"list_stats,omitempty"`
+	Favorites      []FavoriteAnime   `json:"favorites"`
+	TopGenres      []GenreCount      `json:"top_genres"`
+	RecentActivity []History         `json:"recent_activity"`
+	Badges         []Badge           `json:"badges"`
+}
+
+// UsernameBase derives a handle from a user's name, or from their email when
+// the name has too few ASCII letters or digits
+func UsernameBase(name, email string) string {
+	for _, source := range []string{name, strings.SplitN(email, "@", 2)[0]} {
+		var b strings.Builder
+		for _, r := range strings.ToLower(source) {
+			switch {
+			case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+				b.WriteRune(r)
+			case !strings.HasSuffix(b.String(), "_") && b.Len() > 0:
+				b.WriteByte('_')
+			}
+		}
+		base := strings.Trim(b.String(), "_")
+		if len(base) > 30 {
+			base = strings.TrimRight(base[:30], "_")
+		}
+		if ValidUsername(base) {
+			return base
+		}
+	}
+	return "user"
+}
+
+// UsernameCandidate returns the nth handle to try for base: base itself, then base_2, base_3 and so on
+func UsernameCandidate(base string, n int) string {
+	if n < 2 {
+		return base
+	}
+	suffix := fmt.Sprintf("_%d", n)
+	return base[:min(len(base), 30-len(suffix))] + suffix
+}
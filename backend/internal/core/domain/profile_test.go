@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUsernameBase(t *testing.T) {
+	tests := []struct {
+		name  string
+		email string
+		want  string
+	}{
+		{"Alice", "alice@example.com", "alice"},
+		{"Mary Jane Watson", "mj@example.com", "mary_jane_watson"},
+		{"  O'Brien -- Jr. ", "ob@example.com", "o_brien_jr"},
+		{"محمد", "mohamed.ali@example.com", "mohamed_ali"},
+		{"محمد 7", "mo7@example.com", "mo7"},
+		{"Jo", "jo@example.com", "user"},
+		{"Al", "al.x@example.com", "al_x"},
+		{"2024", "2024@example.com", "user"},
+		{"", "", "user"},
+		{"", "no-at-sign", "no_at_sign"},
+		{strings.Repeat("ab", 14) + " cdef", "x@example.com", strings.Repeat("ab", 14) + "_c"},
+		{strings.Repeat("ab", 14) + "a cdef", "x@example.com", strings.Repeat("ab", 14) + "a"},
+		{strings.Repeat("a", 40), "x@example.com", strings.Repeat("a", 30)},
+	}
+	for _, tt := range tests {
+		got := UsernameBase(tt.name, tt.email)
+		if got != tt.want {
+			t.Errorf("UsernameBase(%q, %q) = %q, want %q", tt.name, tt.email, got, tt.want)
+		}
+		if !ValidUsername(got) {
+			t.Errorf("UsernameBase(%q, %q) = %q is not a valid handle", tt.name, tt.email, got)
+		}
+	}
+}
+
+func TestUsernameCandidate(t *testing.T) {
+	long := strings.Repeat("a", 30)
+	tests := []struct {
+		base string
+		n    int
+		want string
+	}{
+		{"alice", 0, "alice"},
+		{"alice", 1, "alice"},
+		{"alice", 2, "alice_2"},
+		{"alice", 10, "alice_10"},
+		{long, 2, strings.Repeat("a", 28) + "_2"},
+		{long, 100, strings.Repeat("a", 26) + "_100"},
+		{strings.Repeat("a", 28), 2, strings.Repeat("a", 28) + "_2"},
+	}
+	for _, tt := range tests {
+		got := UsernameCandidate(tt.base, tt.n)
+		if got != tt.want {
+			t.Errorf("UsernameCandidate(%q, %d) = %q, want %q", tt.base, tt.n, got, tt.want)
+		}
+		if !ValidUsername(got) {
+			t.Errorf("UsernameCandidate(%q, %d) = %q is not a valid handle", tt.base, tt.n, got)
+		}
+	}
+}
+
+func TestValidUsername(t *testing.T) {
+	tests := []struct {
+		username string
+		valid    bool
+	}{
+		{"alice", true},
+		{"a_1", true},
+		{"ab", false},
+		{strings.Repeat("a", 30), true},
+		{strings.Repeat("a", 31), false},
+		{"12345", false},
+		{"Alice", false},
+		{"al ice", false},
+		{"al-ice", false},
+		{"@alice", false},
+	}
+	for _, tt := range tests {
+		if got := ValidUsername(tt.username); got != tt.valid {
+			t.Errorf("ValidUsername(%q) = %v, want %v", tt.username, got, tt.valid)
+		}
+	}
+	if got := NormalizeUsername("  @Alice_1 "); got != "alice_1" {
+		t.Errorf("NormalizeUsername = %q, want alice_1", got)
+	}
+}
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ErrVersionConflict is returned when a row changed since the version the client edited
+var ErrVersionConflict = errors.New("the record was modified by someone else, reload and try again")
+
+// ErrVersionRequired is returned when an edit does not say which version it was based on
+var ErrVersionRequired = errors.New("the version being edited is required: send its ETag in If-Match")
+
+// Revisioned entities
+const (
+	RevisionEntityAnime   = "anime"
+	RevisionEntityEpisode = "episode"
+)
+
+// Revision actions
+const (
+	RevisionActionBaseline = "baseline" // State before the first tracked edit
+	RevisionActionCreate   = "create"
+	RevisionActionUpdate   = "update"
+	RevisionActionRollback = "rollback"
+	RevisionActionImport   = "import"
+)
+
+// Revision is a snapshot of an anime or episode's editable fields after a change
+type Revision struct {
+	ID         uint            `gorm:"primaryKey" json:"id"`
+	EntityType string          `gorm:"size:20;not null;uniqueIndex:idx_revision_version,priority:1" json:"entity_type"`
+	EntityID   uint            `gorm:"not null;uniqueIndex:idx_revision_version,priority:2" json:"entity_id"`
+	Version    int             `gorm:"not null;uniqueIndex:idx_revision_version,priority:3" json:"version"`
+	Action     string          `gorm:"size:20;not null" json:"action"`
+	RollbackOf *int            `json:"rollback_of,omitempty"` // Version restored by a rollback
+	UserID     *uint           `json:"user_id"`
+	User       *User           `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Snapshot   json.RawMessage `gorm:"type:text" json:"snapshot,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// RevisionChange is one field that differs between two revisions
+type RevisionChange struct {
+	Field string          `json:"field"`
+	Old   json.RawMessage `json:"old"`
+	New   json.RawMessage `json:"new"`
+}
+
+type RevisionDiff struct {
+	EntityType string           `json:"entity_type"`
+	EntityID   uint             `json:"entity_id"`
+	From       int              `json:"from"`
+	To         int              `json:"to"`
+	Changes    []RevisionChange `json:"changes"`
+}
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrServerNotFound is returned when an episode server does not exist
+	ErrServerNotFound = errors.New("server not found")
+	// ErrAlreadyReported is returned when a user reports the same server twice before it is resolved
+	ErrAlreadyReported = errors.New("server already reported")
+)
+
+// ServerHealthStatus is the outcome of the latest probes of a server URL
+type ServerHealthStatus string
+
+const (
+	ServerHealthOK      ServerHealthStatus = "ok"
+	ServerHealthFailing ServerHealthStatus = "failing" // Failed recently, still listed normally
+	ServerHealthBroken  ServerHealthStatus = "broken"  // Failed repeatedly, demoted behind healthy servers
+)
+
+// ServerHealth tracks probes of one server URL. It is keyed by URL rather than by
+// server row so it survives episode edits, which recreate the server rows.
+type ServerHealth struct {
+	ID                  uint               `gorm:"primaryKey" json:"-"`
+	URL                 string             `gorm:"uniqueIndex;not null" json:"-"`
+	Host                string             `gorm:"index" json:"host"`
+	Status              ServerHealthStatus `gorm:"size:10;index" json:"status"`
+	StatusCode          int                `json:"status_code"`
+	LatencyMs           int64              `json:"latency_ms"`
+	Error               string             `json:"error,omitempty"`
+	ConsecutiveFailures int                `json:"consecutive_failures"`
+	CheckedAt           *time.Time         `gorm:"index" json:"checked_at"`
+	LastOKAt            *time.Time         `json:"last_ok_at"`
+}
+
+// ServerReport is a user's report that a server does not play
+type ServerReport struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	ServerID   uint       `gorm:"index" json:"server_id"`
+	EpisodeID  uint       `gorm:"index" json:"episode_id"`
+	URL        string     `gorm:"index;not null" json:"-"`
+	UserID     uint       `gorm:"index" json:"user_id"`
+	Reason     string     `gorm:"size:500" json:"reason"`
+	ResolvedAt *time.Time `gorm:"index" json:"resolved_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// BrokenServer is an entry of the admin broken servers list
+type BrokenServer struct {
+	Server         EpisodeServer `json:"server"`
+	Episode        *Episode      `json:"episode,omitempty"`
+	OpenReports    int64         `json:"open_reports"`
+	LastReason     string        `json:"last_reason,omitempty"`
+	LastReportedAt *time.Time    `json:"last_reported_at,omitempty"`
+}
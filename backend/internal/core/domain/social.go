@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrCannotFollowSelf is returned when a user tries to follow themselves
+	ErrCannotFollowSelf = errors.New("you cannot follow yourself")
+	// ErrUserNotFound is returned when following or looking up a missing user
+	ErrUserNotFound = errors.New("user not found")
+	// ErrInvalidFeedCursor is returned for a feed cursor that was not issued by the feed
+	ErrInvalidFeedCursor = errors.New("invalid cursor")
+)
+
+// UserFollow is one user following another
+type UserFollow struct {
+	FollowerID  uint      `gorm:"primaryKey;autoIncrement:false" json:"follower_id"`
+	FollowingID uint      `gorm:"primaryKey;autoIncrement:false;index" json:"following_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// UserPrivacy holds which of a user's activity types their followers see in
+// their feed and on their profile, and whether the profile shows their list.
+// Users without a row use DefaultUserPrivacy.
+type UserPrivacy struct {
+	UserID       uint      `gorm:"primaryKey;autoIncrement:false" json:"user_id"`
+	EpisodeViews bool      `gorm:"not null" json:"episode_views"`
+	AnimeViews   bool      `gorm:"not null" json:"anime_views"`
+	Comments     bool      `gorm:"not null" json:"comments"`
+	Replies      bool      `gorm:"not null" json:"replies"`
+	Likes        bool      `gorm:"not null" json:"likes"`
+	ShowList     bool      `gorm:"not null" json:"show_list"` // Shows list stats, favorites, top genres and list badges on the profile
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// DefaultUserPrivacy shares comments and replies, which are public on the site
+// anyway, and keeps what the user watches and likes to themselves, their list
+// included
+func DefaultUserPrivacy(userID uint) UserPrivacy {
+	return UserPrivacy{UserID: userID, Comments: true, Replies: true}
+}
+
+// PublicActivityTypes lists the activity types the user shares
+func (p UserPrivacy) PublicActivityTypes() []ActivityType {
+	var types []ActivityType
+	for _, t := range []struct {
+		on  bool
+		typ ActivityType
+	}{
+		{p.EpisodeViews, ActivityEpisodeView},
+		{p.AnimeViews, ActivityAnimeView},
+		{p.Comments, ActivityComment},
+		{p.Replies, ActivityReply},
+		{p.Likes, ActivityLike},
+	} {
+		if t.on {
+			types = append(types, t.typ)
+		}
+	}
+	return types
+}
+
+// UserPrivacyUpdate changes privacy settings; nil fields keep their value
+type UserPrivacyUpdate struct {
+	EpisodeViews *bool `json:"episode_views"`
+	AnimeViews   *bool `json:"anime_views"`
+	Comments     *bool `json:"comments"`
+	Replies      *bool `json:"replies"`
+	Likes        *bool `json:"likes"`
+	ShowList     *bool `json:"show_list"`
+}
+
+// UserFollowStatus is how the caller and another user follow each other
+type UserFollowStatus struct {
+	IsFollowing    bool  `json:"is_following"`
+	FollowsYou     bool  `json:"follows_you"`
+	FollowersCount int64 `json:"followers_count"`
+	FollowingCount int64 `json:"following_count"`
+}
+
+// FeedCursor marks where a feed page ended, in the feed's created_at, id order
+type FeedCursor struct {
+	CreatedAt time.Time
+	ID        uint
+}
+
+// FeedPage is one page of a user's feed; NextCursor is empty on the last page
+type FeedPage struct {
+	Items      []History `json:"items"`
+	NextCursor string    `json:"next_cursor"`
+}
+
+// FeedSource is a set of followed users sharing the same activity types
+type FeedSource struct {
+	UserIDs []uint
+	Types   []ActivityType
+}
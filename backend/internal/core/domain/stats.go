@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrInvalidTimezone is returned for a tz that is not an IANA time zone name
+	ErrInvalidTimezone = errors.New("tz must be an IANA time zone such as Europe/Paris")
+	// ErrInvalidStatsYear is returned for a wrapped year outside the site's lifetime
+	ErrInvalidStatsYear = errors.New("invalid year")
+)
+
+// WatchTotals sums the episodes a user completed. Minutes come from the
+// episode's duration, or from the player's when the episode has none.
+type WatchTotals struct {
+	Episodes int64 `json:"episodes_watched"`
+	Minutes  int64 `json:"minutes_watched"`
+	Anime    int64 `json:"anime_watched"` // Anime with at least one completed episode
+}
+
+// StatsBreakdown is the completed episodes and minutes of one genre or studio
+type StatsBreakdown struct {
+	ID       uint   `json:"id"`
+	Name     string `json:"name"`
+	NameEn   string `json:"name_en"`
+	Slug     string `json:"slug"`
+	Episodes int64  `json:"episodes"`
+	Minutes  int64  `json:"minutes"`
+}
+
+// WatchedAnime is the completed episodes and minutes of one anime
+type WatchedAnime struct {
+	ID       uint   `json:"id"`
+	Title    string `json:"title"`
+	TitleEn  string `json:"title_en"`
+	Slug     string `json:"slug"`
+	Image    string `json:"image"`
+	Episodes int64  `json:"episodes"`
+	Minutes  int64  `json:"minutes"`
+}
+
+// WatchHeatmap counts episode views by weekday (0 is Sunday) and hour
+type WatchHeatmap [7][24]int64
+
+// WatchStreaks are runs of consecutive days with an episode viewed or
+// completed. Dates are YYYY-MM-DD in the requested time zone; the current
+// streak is still alive when the last active day is today or yesterday.
+type WatchStreaks struct {
+	Current      int    `json:"current"`
+	Longest      int    `json:"longest"`
+	LongestStart string `json:"longest_start,omitempty"`
+	LongestEnd   string `json:"longest_end,omitempty"`
+	ActiveDays   int    `json:"active_days"`
+	LastActive   string `json:"last_active,omitempty"`
+}
+
+// YearWrapped summarizes a user's viewing over one calendar year
+type YearWrapped struct {
+	WatchTotals
+	Year            int              `json:"year"`
+	MonthlyMinutes  [12]int64        `json:"monthly_minutes"`  // January first
+	BusiestMonth    int              `json:"busiest_month"`    // 1-12, 0 without minutes
+	FavoriteWeekday int              `json:"favorite_weekday"` // 0 is Sunday, -1 without views
+	FavoriteHour    int              `json:"favorite_hour"`    // -1 without views
+	Streaks         WatchStreaks     `json:"streaks"`
+	TopAnime        []WatchedAnime   `json:"top_anime"`
+	TopGenres       []StatsBreakdown `json:"top_genres"`
+	TopStudio       *StatsBreakdown  `json:"top_studio"`
+}
+
+// ViewingStats is a user's viewing dashboard: all time totals, breakdowns,
+// heatmap and streaks, plus the wrapped summary of one year
+type ViewingStats struct {
+	WatchTotals
+	Genres      []StatsBreakdown `json:"genres"`
+	Studios     []StatsBreakdown `json:"studios"`
+	Heatmap     WatchHeatmap     `json:"heatmap"`
+	Streaks     WatchStreaks     `json:"streaks"`
+	Wrapped     YearWrapped      `json:"wrapped"`
+	Timezone    string           `json:"timezone"`
+	GeneratedAt time.Time        `json:"generated_at"` // Stats may be cached for a few minutes
+}
+
+// StatsScope selects whose viewing is aggregated, over which calendar year (0
+// for all time) and in which time zone days and hours are read. Each time is
+// read at the offset in effect at that time, so daylight saving is honoured.
+type StatsScope struct {
+	UserID   uint
+	Year     int
+	Location *time.Location
+}
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrSubtitleNotFound is returned when a subtitle track does not exist on the episode
+	ErrSubtitleNotFound = errors.New("subtitle track not found")
+	// ErrInvalidSubtitle is returned when an uploaded subtitle file cannot be parsed or decoded
+	ErrInvalidSubtitle = errors.New("invalid subtitle file")
+)
+
+// SubtitleFormat is the format a subtitle track was uploaded in. Tracks are
+// always served as WebVTT.
+type SubtitleFormat string
+
+const (
+	SubtitleFormatSRT SubtitleFormat = "srt"
+	SubtitleFormatASS SubtitleFormat = "ass" // Also covers SSA, which shares the [Events] layout
+	SubtitleFormatVTT SubtitleFormat = "vtt"
+)
+
+// SubtitleTrack is one WebVTT subtitle file of an episode
+type SubtitleTrack struct {
+	ID             uint           `gorm:"primaryKey" json:"id"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	EpisodeID      uint           `gorm:"index;not null" json:"episode_id"`
+	Language       string         `gorm:"size:20;not null" json:"language"` // e.g. 'ar', 'en'
+	Label          string         `gorm:"size:100" json:"label"`            // Shown in the player menu, e.g. 'العربية'
+	IsDefault      bool           `json:"is_default"`                       // At most one track per episode
+	URL            string         `json:"url"`                              // Public URL of the converted .vtt file
+	Path           string         `json:"-"`                                // File on disk
+	SourceFormat   SubtitleFormat `gorm:"size:10" json:"source_format"`
+	SourceEncoding string         `gorm:"size:30" json:"source_encoding"` // Charset the upload was decoded from
+	CueCount       int            `json:"cue_count"`
+	OffsetMs       int64          `json:"offset_ms"` // Total shift applied with the timing tool
+}
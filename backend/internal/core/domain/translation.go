@@ -0,0 +1,220 @@
+package domain
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrUnknownTranslationField is returned when a field is not translatable for the entity
+	ErrUnknownTranslationField = errors.New("field is not translatable")
+	// ErrInvalidLocale is returned for locale tags that are not BCP 47-like (e.g. "fr", "pt-br")
+	ErrInvalidLocale = errors.New("invalid locale")
+	// ErrLegacyLocale is returned when deleting a locale that lives in the legacy columns
+	ErrLegacyLocale = errors.New("locale is stored in legacy columns and cannot be deleted")
+)
+
+// Entities that carry translatable fields
+const (
+	TranslationEntityAnime    = "anime"
+	TranslationEntityEpisode  = "episode"
+	TranslationEntityCategory = "category"
+	TranslationEntityType     = "type"
+	TranslationEntitySeason   = "season"
+	TranslationEntityStudio   = "studio"
+	TranslationEntityLanguage = "language"
+)
+
+// Locales backed by the legacy columns: the base column (Title, Name, ...) holds
+// Arabic and the *En column holds English.
+const (
+	LocaleArabic  = "ar"
+	LocaleEnglish = "en"
+)
+
+// FallbackLocales is appended to every requested locale chain
+var FallbackLocales = []string{LocaleEnglish, LocaleArabic}
+
+// TranslatableFields lists the translatable fields of each entity
+var TranslatableFields = map[string][]string{
+	TranslationEntityAnime:    {"title", "description"},
+	TranslationEntityEpisode:  {"title", "description"},
+	TranslationEntityCategory: {"title", "name", "description"},
+	TranslationEntityType:     {"name"},
+	TranslationEntitySeason:   {"name"},
+	TranslationEntityStudio:   {"name"},
+	TranslationEntityLanguage: {"name"},
+}
+
+// Translation holds one field of one row in one locale. Arabic and English values
+// are kept in the legacy columns instead (see LegacyColumn).
+type Translation struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	EntityType string    `gorm:"size:20;not null;uniqueIndex:idx_translation_key" json:"entity_type"`
+	EntityID   uint      `gorm:"not null;uniqueIndex:idx_translation_key" json:"entity_id"`
+	Field      string    `gorm:"size:30;not null;uniqueIndex:idx_translation_key" json:"field"`
+	Locale     string    `gorm:"size:20;not null;uniqueIndex:idx_translation_key" json:"locale"`
+	Value      string    `gorm:"type:text;not null" json:"value"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Translatable is implemented by models whose fields can be localized for a response
+type Translatable interface {
+	TranslationRef() (entity string, id uint)
+	// LegacyValue returns the value of a legacy column such as "title_en"
+	LegacyValue(column string) string
+	// SetLocalized records a field resolved for the request's locales; models keep
+	// them in a Localized map that is only filled on read
+	SetLocalized(field, value string)
+}
+
+var localePattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})*$`)
+
+// NormalizeLocale lowercases a locale tag and validates its shape ("pt_BR" -> "pt-br")
+func NormalizeLocale(locale string) (string, error) {
+	locale = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+	if !localePattern.MatchString(locale) {
+		return "", ErrInvalidLocale
+	}
+	return locale, nil
+}
+
+// IsTranslatableField reports whether field can be translated for entity
+func IsTranslatableField(entity, field string) bool {
+	for _, f := range TranslatableFields[entity] {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// LegacyColumn returns the column that stores field in locale, if that locale is
+// kept in the legacy columns. Category descriptions only have an Arabic column.
+func LegacyColumn(entity, field, locale string) (string, bool) {
+	switch locale {
+	case LocaleArabic:
+		return field, true
+	case LocaleEnglish:
+		if entity == TranslationEntityCategory && field == "description" {
+			return "", false
+		}
+		return field + "_en", true
+	}
+	return "", false
+}
+
+func (a *Anime) TranslationRef() (string, uint) { return TranslationEntityAnime, a.ID }
+
+func (a *Anime) LegacyValue(column string) string {
+	switch column {
+	case "title":
+		return a.Title
+	case "title_en":
+		return a.TitleEn
+	case "description":
+		return a.Description
+	case "description_en":
+		return a.DescriptionEn
+	}
+	return ""
+}
+
+func (a *Anime) SetLocalized(field, value string) {
+	a.Localized = setLocalized(a.Localized, field, value)
+}
+
+func (e *Episode) TranslationRef() (string, uint) { return TranslationEntityEpisode, e.ID }
+
+func (e *Episode) LegacyValue(column string) string {
+	switch column {
+	case "title":
+		return e.Title
+	case "title_en":
+		return e.TitleEn
+	case "description":
+		return e.Description
+	case "description_en":
+		return e.DescriptionEn
+	}
+	return ""
+}
+
+func (e *Episode) SetLocalized(field, value string) {
+	e.Localized = setLocalized(e.Localized, field, value)
+}
+
+func (c *Category) TranslationRef() (string, uint) { return TranslationEntityCategory, c.ID }
+
+func (c *Category) LegacyValue(column string) string {
+	switch column {
+	case "title":
+		return c.Title
+	case "title_en":
+		return c.TitleEn
+	case "name":
+		return c.Name
+	case "name_en":
+		return c.NameEn
+	case "description":
+		return c.Description
+	}
+	return ""
+}
+
+func (c *Category) SetLocalized(field, value string) {
+	c.Localized = setLocalized(c.Localized, field, value)
+}
+
+func (t *Type) TranslationRef() (string, uint) { return TranslationEntityType, t.ID }
+
+func (t *Type) LegacyValue(column string) string { return legacyName(column, t.Name, t.NameEn) }
+
+func (t *Type) SetLocalized(field, value string) {
+	t.Localized = setLocalized(t.Localized, field, value)
+}
+
+func (s *Season) TranslationRef() (string, uint) { return TranslationEntitySeason, s.ID }
+
+func (s *Season) LegacyValue(column string) string { return legacyName(column, s.Name, s.NameEn) }
+
+func (s *Season) SetLocalized(field, value string) {
+	s.Localized = setLocalized(s.Localized, field, value)
+}
+
+func (s *Studio) TranslationRef() (string, uint) { return TranslationEntityStudio, s.ID }
+
+func (s *Studio) LegacyValue(column string) string { return legacyName(column, s.Name, s.NameEn) }
+
+func (s *Studio) SetLocalized(field, value string) {
+	s.Localized = setLocalized(s.Localized, field, value)
+}
+
+func (l *Language) TranslationRef() (string, uint) { return TranslationEntityLanguage, l.ID }
+
+func (l *Language) LegacyValue(column string) string { return legacyName(column, l.Name, l.NameEn) }
+
+func (l *Language) SetLocalized(field, value string) {
+	l.Localized = setLocalized(l.Localized, field, value)
+}
+
+func legacyName(column, name, nameEn string) string {
+	switch column {
+	case "name":
+		return name
+	case "name_en":
+		return nameEn
+	}
+	return ""
+}
+
+func setLocalized(m map[string]string, field, value string) map[string]string {
+	if m == nil {
+		m = make(map[string]string)
+	}
+	m[field] = value
+	return m
+}
@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeLocale(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		err  error
+	}{
+		{"en", "en", nil},
+		{"AR", "ar", nil},
+		{" pt_BR ", "pt-br", nil},
+		{"zh-Hant-TW", "zh-hant-tw", nil},
+		{"fil", "fil", nil},
+		{"es-419", "es-419", nil},
+		{"", "", ErrInvalidLocale},
+		{"e", "", ErrInvalidLocale},
+		{"engl", "", ErrInvalidLocale},
+		{"en-", "", ErrInvalidLocale},
+		{"en-a", "", ErrInvalidLocale},
+		{"en-toolongtag", "", ErrInvalidLocale},
+		{"*", "", ErrInvalidLocale},
+		{"en;q=0.8", "", ErrInvalidLocale},
+		{"../en", "", ErrInvalidLocale},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeLocale(tt.in)
+		if got != tt.want || !errors.Is(err, tt.err) {
+			t.Errorf("NormalizeLocale(%q) = %q, %v; want %q, %v", tt.in, got, err, tt.want, tt.err)
+		}
+	}
+}
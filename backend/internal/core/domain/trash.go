@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrNotInTrash is returned when restoring or purging a row that is missing or not deleted
+	ErrNotInTrash = errors.New("item not found in trash")
+	// ErrParentInTrash is returned when restoring an episode whose anime is still in the trash
+	ErrParentInTrash = errors.New("the anime of this episode is in the trash; restore it first")
+)
+
+// Entities that can be listed, restored and purged from the trash
+const (
+	TrashEntityAnime    = "anime"
+	TrashEntityEpisode  = "episode"
+	TrashEntityModel    = "model"
+	TrashEntityUser     = "user"
+	TrashEntityCategory = "category"
+	TrashEntityType     = "type"
+	TrashEntitySeason   = "season"
+	TrashEntityStudio   = "studio"
+	TrashEntityLanguage = "language"
+)
+
+// TrashEntities lists trash entities in retention purge order: parents before the
+// dependents they purge with them.
+var TrashEntities = []string{
+	TrashEntityAnime, TrashEntityEpisode, TrashEntityModel, TrashEntityUser,
+	TrashEntityCategory, TrashEntityType, TrashEntitySeason, TrashEntityStudio, TrashEntityLanguage,
+}
+
+// TrashItem is a soft-deleted row as shown in the admin trash bin
+type TrashItem struct {
+	Entity    string     `json:"entity"`
+	ID        uint       `json:"id"`
+	Label     string     `json:"label"` // Title or name, whichever the entity has
+	DeletedAt time.Time  `json:"deleted_at"`
+	PurgeAt   *time.Time `json:"purge_at,omitempty"` // When the retention job will purge it
+}
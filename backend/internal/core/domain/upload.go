@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrUploadNotFound is returned when a resumable upload does not exist or belongs to another user
+	ErrUploadNotFound = errors.New("upload not found")
+	// ErrUploadExpired is returned when a resumable upload was not completed before its expiry
+	ErrUploadExpired = errors.New("upload expired")
+	// ErrUploadOffsetMismatch is returned when a chunk does not start at the upload's current offset
+	ErrUploadOffsetMismatch = errors.New("upload offset mismatch")
+	// ErrUploadTooLarge is returned when an upload exceeds the configured maximum size
+	ErrUploadTooLarge = errors.New("upload too large")
+	// ErrChecksumMismatch is returned when a chunk does not match its Upload-Checksum
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+	// ErrInvalidUpload is returned for malformed upload requests (metadata, checksum algorithm, target)
+	ErrInvalidUpload = errors.New("invalid upload")
+	// ErrUploadFinalize is returned when the pipeline of a completed upload rejects the file
+	ErrUploadFinalize = errors.New("upload finalize failed")
+)
+
+// UploadTarget names the pipeline a completed resumable upload is handed to
+type UploadTarget string
+
+const (
+	UploadTargetModel UploadTarget = "model" // 3D model, creates a Model row
+	UploadTargetImage UploadTarget = "image" // Image served from /uploads
+	UploadTargetVideo UploadTarget = "video" // Video file served from /uploads/videos
+)
+
+// TusUpload is the state of a resumable upload. It is persisted as JSON next to
+// the partial file so uploads survive restarts.
+type TusUpload struct {
+	ID          string            `json:"id"`
+	UserID      uint              `json:"user_id"`
+	Target      UploadTarget      `json:"target"`
+	Length      int64             `json:"length"`
+	Offset      int64             `json:"offset"`
+	Metadata    map[string]string `json:"metadata"` // Upload-Metadata pairs, e.g. filename, name, title
+	CreatedAt   time.Time         `json:"created_at"`
+	ExpiresAt   time.Time         `json:"expires_at"` // Pushed back by every chunk
+	CompletedAt *time.Time        `json:"completed_at,omitempty"`
+	Result      any               `json:"result,omitempty"` // What the target pipeline created
+	Error       string            `json:"error,omitempty"`  // Why the target pipeline rejected the file
+}
+
+// Complete reports whether every byte has been received
+func (u *TusUpload) Complete() bool {
+	return u.Offset == u.Length
+}
@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"errors"
+	"path"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// ErrInvalidSource is returned when an episode server fails validation
+var ErrInvalidSource = errors.New("invalid video source")
+
+// SourceKind tells the player how to load an episode server
+type SourceKind string
+
+const (
+	SourceKindEmbed  SourceKind = "embed"  // iframe player on an allowlisted host
+	SourceKindDirect SourceKind = "direct" // progressive file (mp4, webm, ...)
+	SourceKindHLS    SourceKind = "hls"    // .m3u8 playlist
+	SourceKindDASH   SourceKind = "dash"   // .mpd manifest
+)
+
+// IsValid reports whether k is one of the known source kinds
+func (k SourceKind) IsValid() bool {
+	switch k {
+	case SourceKindEmbed, SourceKindDirect, SourceKindHLS, SourceKindDASH:
+		return true
+	}
+	return false
+}
+
+// InferSourceKind guesses the kind of a source from the extension of its URL path.
+// ok is false when the extension says nothing about the kind.
+func InferSourceKind(rawURL string) (kind SourceKind, ok bool) {
+	p := rawURL
+	if i := strings.IndexAny(p, "?#"); i >= 0 {
+		p = p[:i]
+	}
+	switch strings.ToLower(path.Ext(p)) {
+	case ".m3u8":
+		return SourceKindHLS, true
+	case ".mpd":
+		return SourceKindDASH, true
+	case ".mp4", ".webm", ".mkv", ".mov", ".m4v", ".ogv":
+		return SourceKindDirect, true
+	}
+	return "", false
+}
+
+// ProviderFromHost names a provider after the registrable domain of its host without
+// the public suffix, e.g. "www.youtube.com" -> "youtube", "player.bbc.co.uk" -> "bbc".
+// IPs and hosts that are not under a public suffix are returned as is.
+func ProviderFromHost(host string) string {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	site, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return host
+	}
+	suffix, _ := publicsuffix.PublicSuffix(site)
+	return strings.TrimSuffix(site, "."+suffix)
+}
+
+// LegacyVideoURL is one entry of the legacy Episode.VideoURLs JSON. Type holds the
+// server language ("ar", "en"), as written by the admin panel.
+type LegacyVideoURL struct {
+	URL  string `json:"url"`
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrInvalidProgress is returned for a negative position or a missing duration
+var ErrInvalidProgress = errors.New("position must be >= 0 and duration > 0")
+
+// WatchProgress is where a user stopped in an episode, so the player can resume
+// and show watched bars. Completed sticks once set, rewinding does not clear it.
+type WatchProgress struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	UserID      uint       `gorm:"not null;uniqueIndex:idx_progress_user_episode;index:idx_progress_user_anime" json:"user_id"`
+	EpisodeID   uint       `gorm:"not null;uniqueIndex:idx_progress_user_episode" json:"episode_id"`
+	AnimeID     uint       `gorm:"not null;index:idx_progress_user_anime" json:"anime_id"`
+	Position    float64    `gorm:"not null" json:"position"` // Seconds
+	Duration    float64    `gorm:"not null" json:"duration"` // Seconds
+	Completed   bool       `gorm:"default:false;index" json:"completed"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// Percent is how much of the episode was watched, 0-100
+func (p *WatchProgress) Percent() float64 {
+	if p.Duration <= 0 {
+		return 0
+	}
+	return min(100, p.Position/p.Duration*100)
+}
+
+// ContinueWatchingEpisode is the episode a continue-watching entry points at
+type ContinueWatchingEpisode struct {
+	ID            uint   `json:"id"`
+	EpisodeNumber int    `json:"episode_number"`
+	Title         string `json:"title"`
+	TitleEn       string `json:"title_en"`
+	Slug          string `json:"slug"`
+	Thumbnail     string `json:"thumbnail"`
+	Duration      int    `json:"duration"`
+}
+
+// ContinueWatchingEntry is the next thing to play in a series the user started
+type ContinueWatchingEntry struct {
+	Anime           *Anime                  `json:"anime"`
+	Episode         ContinueWatchingEpisode `json:"episode"`
+	Resume          bool                    `json:"resume"`   // Episode was started: play from Position. Otherwise it is the next one.
+	Position        float64                 `json:"position"` // Seconds
+	Duration        float64                 `json:"duration"` // Seconds, 0 when not started
+	EpisodesWatched int                     `json:"episodes_watched"`
+	EpisodesTotal   int                     `json:"episodes_total"`
+	LastWatchedAt   time.Time               `json:"last_watched_at"`
+}
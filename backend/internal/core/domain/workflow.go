@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrContentNotFound      = errors.New("content not found")
+	ErrTransitionNotAllowed = errors.New("transition not allowed from the current state")
+	ErrPermissionDenied     = errors.New("insufficient permissions")
+)
+
+// ContentState is the editorial state of an anime or episode. Only published content is
+// listed on the public site.
+type ContentState string
+
+const (
+	ContentStateDraft     ContentState = "draft"
+	ContentStateInReview  ContentState = "in_review"
+	ContentStateApproved  ContentState = "approved"
+	ContentStatePublished ContentState = "published"
+	ContentStateArchived  ContentState = "archived"
+)
+
+// Workflow entities
+const (
+	WorkflowEntityAnime   = "anime"
+	WorkflowEntityEpisode = "episode"
+)
+
+// Permission keys guarding workflow transitions (the admin role holds them all)
+const (
+	PermissionContentSubmit  = "content.submit"
+	PermissionContentReview  = "content.review"
+	PermissionContentPublish = "content.publish"
+	PermissionContentArchive = "content.archive"
+)
+
+type WorkflowTransition struct {
+	From       ContentState `json:"from"`
+	To         ContentState `json:"to"`
+	Permission string       `json:"permission"`
+}
+
+// WorkflowTransitions lists every allowed state change
+var WorkflowTransitions = []WorkflowTransition{
+	{ContentStateDraft, ContentStateInReview, PermissionContentSubmit},
+	{ContentStateInReview, ContentStateDraft, PermissionContentReview}, // Changes requested
+	{ContentStateInReview, ContentStateApproved, PermissionContentReview},
+	{ContentStateApproved, ContentStateDraft, PermissionContentReview},
+	{ContentStateApproved, ContentStatePublished, PermissionContentPublish},
+	{ContentStateDraft, ContentStatePublished, PermissionContentPublish}, // Direct publish by publishers
+	{ContentStatePublished, ContentStateDraft, PermissionContentPublish}, // Unpublish
+	{ContentStatePublished, ContentStateArchived, PermissionContentArchive},
+	{ContentStateArchived, ContentStateDraft, PermissionContentArchive},
+}
+
+// FindTransition returns the transition from → to, or nil if the workflow doesn't allow it
+func FindTransition(from, to ContentState) *WorkflowTransition {
+	for i := range WorkflowTransitions {
+		if WorkflowTransitions[i].From == from && WorkflowTransitions[i].To == to {
+			return &WorkflowTransitions[i]
+		}
+	}
+	return nil
+}
+
+// ContentTransition records a state change, who made it and why
+type ContentTransition struct {
+	ID         uint         `gorm:"primaryKey" json:"id"`
+	EntityType string       `gorm:"size:20;not null;index:idx_transition_entity,priority:1" json:"entity_type"`
+	EntityID   uint         `gorm:"not null;index:idx_transition_entity,priority:2" json:"entity_id"`
+	From       ContentState `gorm:"column:from_state;size:20" json:"from"`
+	To         ContentState `gorm:"column:to_state;size:20;not null" json:"to"`
+	Comment    string       `json:"comment"`
+	UserID     uint         `gorm:"not null" json:"user_id"`
+	User       *User        `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+// WorkflowStatus is the current state of an item plus the transitions open to the caller
+type WorkflowStatus struct {
+	EntityType string              `json:"entity_type"`
+	EntityID   uint                `json:"entity_id"`
+	State      ContentState        `json:"state"`
+	Allowed    []ContentState      `json:"allowed"`
+	History    []ContentTransition `json:"history"`
+}
+
+// ReviewQueueItem is an anime or episode waiting for review
+type ReviewQueueItem struct {
+	EntityType  string    `json:"entity_type"`
+	EntityID    uint      `json:"entity_id"`
+	Title       string    `json:"title"`
+	AnimeID     uint      `json:"anime_id,omitempty"` // Episodes only
+	SubmittedBy *User     `json:"submitted_by,omitempty"`
+	SubmittedAt time.Time `json:"submitted_at"`
+	Comment     string    `json:"comment"`
+}
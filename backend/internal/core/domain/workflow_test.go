@@ -0,0 +1,36 @@
+package domain
+
+import "testing"
+
+func TestFindTransition(t *testing.T) {
+	tests := []struct {
+		from, to   ContentState
+		permission string // Empty when the workflow forbids the change
+	}{
+		{ContentStateDraft, ContentStateInReview, PermissionContentSubmit},
+		{ContentStateDraft, ContentStatePublished, PermissionContentPublish},
+		{ContentStateDraft, ContentStateApproved, ""},
+		{ContentStateInReview, ContentStateApproved, PermissionContentReview},
+		{ContentStateInReview, ContentStateDraft, PermissionContentReview},
+		{ContentStateInReview, ContentStatePublished, ""},
+		{ContentStateApproved, ContentStatePublished, PermissionContentPublish},
+		{ContentStateApproved, ContentStateDraft, PermissionContentReview},
+		{ContentStatePublished, ContentStateDraft, PermissionContentPublish},
+		{ContentStatePublished, ContentStateArchived, PermissionContentArchive},
+		{ContentStatePublished, ContentStateInReview, ""},
+		{ContentStateArchived, ContentStateDraft, PermissionContentArchive},
+		{ContentStateArchived, ContentStatePublished, ""},
+		{ContentStatePublished, ContentStatePublished, ""},
+	}
+	for _, tt := range tests {
+		got := FindTransition(tt.from, tt.to)
+		switch {
+		case tt.permission == "" && got != nil:
+			t.Errorf("%s → %s allowed with %s, want forbidden", tt.from, tt.to, got.Permission)
+		case tt.permission != "" && got == nil:
+			t.Errorf("%s → %s forbidden, want allowed with %s", tt.from, tt.to, tt.permission)
+		case got != nil && got.Permission != tt.permission:
+			t.Errorf("%s → %s needs %s, want %s", tt.from, tt.to, got.Permission, tt.permission)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package port
+
+import (
+	"backend/internal/core/domain"
+	"io"
+	"time"
+)
+
+// BlobStore keeps uploaded and generated files, on the local filesystem or in
+// an S3-compatible bucket, so several server replicas can share them
+type BlobStore interface {
+	// Put stores r under key, replacing any existing object. size may be -1 when unknown.
+	Put(key string, r io.Reader, size int64, contentType string) error
+	// Get opens an object; domain.ErrBlobNotFound when missing
+	Get(key string) (io.ReadCloser, error)
+	// Stat describes an object; domain.ErrBlobNotFound when missing
+	Stat(key string) (*domain.BlobInfo, error)
+	// Delete removes an object. Missing objects are not an error.
+	Delete(key string) error
+	// List calls fn for every object whose key starts with prefix
+	List(prefix string, fn func(domain.BlobInfo) error) error
+	// URL returns where clients download an object: a presigned URL valid for
+	// expiry, or a path on this server. A non-empty filename makes it an attachment.
+	URL(key, filename string, expiry time.Duration) (string, error)
+}
@@ -0,0 +1,6 @@
+package port
+
+// Mailer sends plain text emails
+type Mailer interface {
+	Send(to, subject, body string) error
+}
@@ -1,6 +1,9 @@
 package port
 
-import "backend/internal/core/domain"
+import (
+	"backend/internal/core/domain"
+	"time"
+)
 
 type UserRepository interface {
 	CreateUser(user *domain.User) error
@@ -10,6 +13,8 @@ type UserRepository interface {
 	UpdateUser(user *domain.User) error
 	DeleteUser(id uint) error
 	SearchUsers(query string) ([]domain.User, error)
+	GetUserByUsername(username string) (*domain.User, error)
+	UsernameTaken(username string, exceptID uint) (bool, error)
 }
 
 type RoleRepository interface {
@@ -43,6 +48,7 @@ type CategoryRepository interface {
 	CreateCategory(category *domain.Category) error
 	GetAllCategories() ([]domain.Category, error)
 	GetCategoryByID(id uint) (*domain.Category, error)
+	GetCategoriesByIDs(ids []uint) ([]domain.Category, error)
 	UpdateCategory(category *domain.Category) error
 	DeleteCategory(id uint) error
 }
@@ -51,9 +57,12 @@ type AnimeRepository interface {
 	CreateAnime(anime *domain.Anime) error
 	GetAnimeByID(id uint) (*domain.Anime, error)
 	GetAllAnimes() ([]domain.Anime, error)
+	GetPublishedAnimeByID(id uint) (*domain.Anime, error)
+	GetPublishedAnimes() ([]domain.Anime, error)
 	GetLatestAnimes(limit int) ([]domain.Anime, error)
 	GetAnimesByType(animeType string, limit int) ([]domain.Anime, error)
 	UpdateAnime(anime *domain.Anime) error
+	SaveAnimeWithCategories(anime *domain.Anime, categories []domain.Category, revision *domain.Revision) error
 	DeleteAnime(id uint) error
 	SearchAnimes(query string) ([]domain.Anime, error)
 }
@@ -98,9 +107,25 @@ type HistoryRepository interface {
 	DeleteOldHistory(days int) error
 }
 
-type WatchLaterRepository interface {
-	AddToWatchLater(entry *domain.WatchLater) error
-	RemoveFromWatchLater(userID uint, animeID *uint, episodeID *uint) error
-	GetWatchLaterByUser(userID uint) ([]domain.WatchLater, error)
-	IsWatchLater(userID uint, animeID *uint, episodeID *uint) (bool, error)
+type TrashRepository interface {
+	ListTrash(entity string, limit, offset int) ([]domain.TrashItem, int64, error)
+	GetTrashIDsBefore(entity string, cutoff time.Time) ([]uint, error)
+	GetDeletedModel(id uint) (*domain.Model, error)
+	RestoreTrash(entity string, id uint) error
+	PurgeTrash(entity string, id uint) error
+}
+
+type TranslationRepository interface {
+	GetTranslatable(entityType string, id uint) (domain.Translatable, error)
+	GetTranslations(entityType string, ids []uint, locales []string) ([]domain.Translation, error)
+	SaveTranslations(entityType string, id uint, locale string, values map[string]string, revision *domain.Revision, version int) error
+	DeleteTranslations(entityType string, id uint, locale string) error
+}
+
+type RevisionRepository interface {
+	CreateRevision(revision *domain.Revision) error
+	CreateBaselineRevision(revision *domain.Revision) error
+	GetRevisions(entityType string, entityID uint) ([]domain.Revision, error)
+	GetRevision(entityType string, entityID uint, version int) (*domain.Revision, error)
+	CountRevisions(entityType string, entityID uint) (int64, error)
 }
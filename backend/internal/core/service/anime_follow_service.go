@@ -0,0 +1,269 @@
+package service
+
+import (
+	"backend/internal/adapters/repository"
+	"backend/internal/core/domain"
+	"backend/internal/core/port"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// announcementScan is how many pending announcements one dispatch round reads
+const announcementScan = 1000
+
+// An email is tried emailAttempts times, waiting emailRetryDelay longer after
+// each failure
+const (
+	emailAttempts   = 3
+	emailRetryDelay = 500 * time.Millisecond
+)
+
+// AnimeFollowService lets users follow anime and tells followers about new
+// episodes. Publishing an episode only queues an announcement; Dispatch fans it
+// out in batches of follows, as an in-app notification or an email depending
+// on each follow; emails that keep failing fall back to in-app notifications.
+// Episodes of one anime published together go out as a single notification.
+type AnimeFollowService struct {
+	repo      *repository.SQLiteRepository
+	mailer    port.Mailer // nil when email is not configured
+	batchSize int
+}
+
+func NewAnimeFollowService(repo *repository.SQLiteRepository, mailer port.Mailer, batchSize int) *AnimeFollowService {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	return &AnimeFollowService{repo: repo, mailer: mailer, batchSize: batchSize}
+}
+
+// Follow follows a published anime, or changes how the user is notified about it
+func (s *AnimeFollowService) Follow(userID, animeID uint, channel domain.NotifyChannel) (*domain.AnimeFollowStatus, error) {
+	if channel == "" {
+		channel = domain.NotifyInApp
+	}
+	if !channel.Valid() {
+		return nil, domain.ErrInvalidNotifyChannel
+	}
+	if channel == domain.NotifyEmail && s.mailer == nil {
+		return nil, domain.ErrEmailUnavailable
+	}
+	anime, err := s.repo.GetAnimeByID(animeID)
+	if err != nil || anime.State != domain.ContentStatePublished {
+		return nil, ErrAnimeNotFound
+	}
+	if err := s.repo.SaveAnimeFollow(&domain.AnimeFollow{UserID: userID, AnimeID: animeID, Channel: channel}); err != nil {
+		return nil, err
+	}
+	return s.Status(userID, animeID)
+}
+
+func (s *AnimeFollowService) Unfollow(userID, animeID uint) (*domain.AnimeFollowStatus, error) {
+	if _, err := s.repo.DeleteAnimeFollow(userID, animeID); err != nil {
+		return nil, err
+	}
+	return s.Status(userID, animeID)
+}
+
+// Status reports whether the user follows an anime and how many users do
+func (s *AnimeFollowService) Status(userID, animeID uint) (*domain.AnimeFollowStatus, error) {
+	follow, err := s.repo.GetAnimeFollow(userID, animeID)
+	if err != nil {
+		return nil, err
+	}
+	count, err := s.repo.CountAnimeFollowers(animeID)
+	if err != nil {
+		return nil, err
+	}
+	status := &domain.AnimeFollowStatus{Followers: count}
+	if follow != nil {
+		status.Following = true
+		status.Channel = follow.Channel
+	}
+	return status, nil
+}
+
+func (s *AnimeFollowService) List(userID uint, limit, offset int) ([]domain.AnimeFollow, int64, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	return s.repo.GetAnimeFollows(userID, limit, max(offset, 0))
+}
+
+// announcementGroup is the pending announcements of one anime at the same fan-out cursor
+type announcementGroup struct {
+	animeID    uint
+	cursor     uint
+	ids        []uint
+	episodeIDs []uint
+}
+
+// Dispatch sends the notifications of every pending announcement
+func (s *AnimeFollowService) Dispatch() error {
+	pending, err := s.repo.GetPendingAnnouncements(announcementScan)
+	if err != nil {
+		return err
+	}
+	var groups []*announcementGroup
+	byKey := map[[2]uint]*announcementGroup{}
+	for _, a := range pending {
+		key := [2]uint{a.AnimeID, a.LastFollowID}
+		g, ok := byKey[key]
+		if !ok {
+			g = &announcementGroup{animeID: a.AnimeID, cursor: a.LastFollowID}
+			byKey[key] = g
+			groups = append(groups, g)
+		}
+		g.ids = append(g.ids, a.ID)
+		g.episodeIDs = append(g.episodeIDs, a.EpisodeID)
+	}
+
+	var errs []error
+	for _, g := range groups {
+		if err := s.announce(g); err != nil {
+			errs = append(errs, fmt.Errorf("anime %d: %w", g.animeID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// announce fans a group out to the anime's follows, one batch at a time.
+// Episodes unpublished or deleted since are left out.
+func (s *AnimeFollowService) announce(g *announcementGroup) error {
+	rows, err := s.repo.GetPublishedEpisodesByIDs(g.episodeIDs)
+	if err != nil {
+		return err
+	}
+	var anime *domain.Anime
+	episodes := rows[:0]
+	for _, ep := range rows {
+		if ep.Anime.ID == g.animeID && ep.Anime.State == domain.ContentStatePublished {
+			anime = &ep.Anime
+			episodes = append(episodes, ep)
+		}
+	}
+	if len(episodes) == 0 {
+		return s.repo.FinishAnnouncements(g.ids)
+	}
+	sort.Slice(episodes, func(i, j int) bool { return episodes[i].EpisodeNumber < episodes[j].EpisodeNumber })
+
+	listed := make([]map[string]any, 0, len(episodes))
+	for _, ep := range episodes {
+		listed = append(listed, map[string]any{
+			"id":             ep.ID,
+			"episode_number": ep.EpisodeNumber,
+			"title":          ep.Title,
+			"title_en":       ep.TitleEn,
+			"slug":           ep.Slug,
+		})
+	}
+	data, _ := json.Marshal(map[string]any{
+		"anime_id":       anime.ID,
+		"anime_title":    anime.Title,
+		"anime_title_en": anime.TitleEn,
+		"anime_slug":     anime.Slug,
+		"image":          anime.Image,
+		"episodes":       listed,
+	})
+	subject, body := episodeEmail(anime, episodes)
+
+	cursor := g.cursor
+	for {
+		follows, err := s.repo.GetAnimeFollowersAfter(g.animeID, cursor, s.batchSize)
+		if err != nil {
+			return err
+		}
+		if len(follows) == 0 {
+			break
+		}
+		var notifications []domain.Notification
+		var emailed []domain.AnimeFollow
+		for _, f := range follows {
+			if f.User == nil {
+				continue // Deleted user
+			}
+			if f.Channel == domain.NotifyEmail && s.mailer != nil && f.User.Email != "" {
+				emailed = append(emailed, f)
+			} else {
+				notifications = append(notifications, domain.Notification{UserID: f.UserID, Type: domain.NotificationTypeNewPost, Data: data})
+			}
+		}
+		from := cursor
+		cursor = follows[len(follows)-1].ID
+		claimed, err := s.repo.SaveAnnouncementBatch(g.ids, from, cursor, notifications)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			return nil // Another replica is sending this group
+		}
+
+		// Followers whose email cannot be sent get the in-app notification instead
+		var fallback []domain.Notification
+		for _, f := range emailed {
+			if err := s.sendEmail(f.User.Email, subject, body); err != nil {
+				log.Printf("Episode notifications: failed to email %s, notifying in-app: %v", f.User.Email, err)
+				fallback = append(fallback, domain.Notification{UserID: f.UserID, Type: domain.NotificationTypeNewPost, Data: data})
+			}
+		}
+		if err := s.repo.CreateNotifications(fallback); err != nil {
+			return err
+		}
+		if len(follows) < s.batchSize {
+			break
+		}
+	}
+	return s.repo.FinishAnnouncements(g.ids)
+}
+
+// sendEmail sends an email, retrying transient failures
+func (s *AnimeFollowService) sendEmail(to, subject, body string) error {
+	var err error
+	for attempt := 1; attempt <= emailAttempts; attempt++ {
+		if err = s.mailer.Send(to, subject, body); err == nil {
+			return nil
+		}
+		if attempt < emailAttempts {
+			time.Sleep(time.Duration(attempt) * emailRetryDelay)
+		}
+	}
+	return err
+}
+
+// episodeEmail writes the email announcing new episodes of an anime
+func episodeEmail(anime *domain.Anime, episodes []domain.Episode) (string, string) {
+	subject := fmt.Sprintf("New episode of %s", anime.Title)
+	if len(episodes) > 1 {
+		subject = fmt.Sprintf("%d new episodes of %s", len(episodes), anime.Title)
+	}
+	var body strings.Builder
+	fmt.Fprintf(&body, "New on %s:\n\n", anime.Title)
+	for _, ep := range episodes {
+		fmt.Fprintf(&body, "  Episode %d", ep.EpisodeNumber)
+		if ep.Title != "" {
+			fmt.Fprintf(&body, ": %s", ep.Title)
+		}
+		body.WriteString("\n")
+	}
+	body.WriteString("\nYou get this email because you follow this anime. Unfollow it to stop these emails.\n")
+	return subject, body.String()
+}
+
+// RunDispatch sends pending new-episode notifications every interval until the process exits
+func (s *AnimeFollowService) RunDispatch(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		<-ticker.C
+		if err := s.Dispatch(); err != nil {
+			log.Printf("Episode notifications: dispatch failed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"backend/internal/adapters/repository"
+	"backend/internal/core/domain"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// fakeMailer records sent emails and fails for the addresses in fail
+type fakeMailer struct {
+	mu   sync.Mutex
+	sent []string
+	fail map[string]bool
+}
+
+func (m *fakeMailer) Send(to, subject, body string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.fail[to] {
+		return errors.New("mailbox unavailable")
+	}
+	m.sent = append(m.sent, to+": "+subject)
+	return nil
+}
+
+// seedFollowers creates n users following animeID through channel and returns their IDs
+func seedFollowers(t *testing.T, repo *repository.SQLiteRepository, s *AnimeFollowService, animeID uint, n int, channel domain.NotifyChannel) []uint {
+	t.Helper()
+	var count int64
+	repo.DB().Model(&domain.User{}).Count(&count)
+	ids := make([]uint, n)
+	for i := range ids {
+		name := fmt.Sprintf("follower%d", int(count)+i+1)
+		user := domain.User{Name: name, Email: name + "@example.com", Password: "x"}
+		if err := repo.DB().Create(&user).Error; err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.Follow(user.ID, animeID, channel); err != nil {
+			t.Fatal(err)
+		}
+		ids[i] = user.ID
+	}
+	return ids
+}
+
+// announce queues the announcements of episodes as publishing them does
+func announce(t *testing.T, repo *repository.SQLiteRepository, animeID uint, episodeIDs ...uint) {
+	t.Helper()
+	for _, id := range episodeIDs {
+		if err := repo.DB().Create(&domain.EpisodeAnnouncement{EpisodeID: id, AnimeID: animeID}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// notificationsByUser counts the in-app notifications of each user
+func notificationsByUser(t *testing.T, repo *repository.SQLiteRepository) map[uint]int {
+	t.Helper()
+	var rows []domain.Notification
+	if err := repo.DB().Find(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+	counts := map[uint]int{}
+	for _, n := range rows {
+		counts[n.UserID]++
+	}
+	return counts
+}
+
+func TestAnimeFollowOnlyPublishedAnime(t *testing.T) {
+	repo := newProgressTestRepo(t)
+	s := NewAnimeFollowService(repo, nil, 2)
+	draft, _ := seedAnime(t, repo, domain.ContentStateDraft, 0)
+	published, _ := seedAnime(t, repo, domain.ContentStatePublished, 0)
+
+	if _, err := s.Follow(1, draft, ""); !errors.Is(err, ErrAnimeNotFound) {
+		t.Fatalf("following a draft = %v, want ErrAnimeNotFound", err)
+	}
+	if _, err := s.Follow(1, published, domain.NotifyEmail); !errors.Is(err, domain.ErrEmailUnavailable) {
+		t.Fatalf("email follow without a mailer = %v, want ErrEmailUnavailable", err)
+	}
+	status, err := s.Follow(1, published, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Following || status.Channel != domain.NotifyInApp || status.Followers != 1 {
+		t.Fatalf("status = %+v, want an in-app follow", status)
+	}
+}
+
+func TestAnimeFollowDispatchBatches(t *testing.T) {
+	repo := newProgressTestRepo(t)
+	s := NewAnimeFollowService(repo, nil, 2)
+	animeID, episodes := seedAnime(t, repo, domain.ContentStatePublished, 3)
+	followers := seedFollowers(t, repo, s, animeID, 5, domain.NotifyInApp)
+	if err := repo.DB().Model(&domain.Episode{}).Where("id = ?", episodes[2]).UpdateColumn("state", domain.ContentStateDraft).Error; err != nil {
+		t.Fatal(err)
+	}
+	announce(t, repo, animeID, episodes...)
+
+	if err := s.Dispatch(); err != nil {
+		t.Fatal(err)
+	}
+	counts := notificationsByUser(t, repo)
+	for _, id := range followers {
+		if counts[id] != 1 {
+			t.Errorf("follower %d got %d notifications, want one for both episodes", id, counts[id])
+		}
+	}
+	var notification domain.Notification
+	if err := repo.DB().First(&notification).Error; err != nil {
+		t.Fatal(err)
+	}
+	var data struct {
+		Episodes []struct {
+			ID uint `json:"id"`
+		} `json:"episodes"`
+	}
+	if err := json.Unmarshal(notification.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Episodes) != 2 {
+		t.Fatalf("notification lists %d episodes, want the 2 still published", len(data.Episodes))
+	}
+
+	var pending int64
+	repo.DB().Model(&domain.EpisodeAnnouncement{}).Where("sent_at IS NULL").Count(&pending)
+	if pending != 0 {
+		t.Fatalf("%d announcements left pending", pending)
+	}
+	if err := s.Dispatch(); err != nil {
+		t.Fatal(err)
+	}
+	if n := countRows(t, repo, &domain.Notification{}); n != int64(len(followers)) {
+		t.Fatalf("%d notifications after a second dispatch, want %d", n, len(followers))
+	}
+}
+
+func TestAnimeFollowBatchesAreClaimedOnce(t *testing.T) {
+	repo := newProgressTestRepo(t)
+	animeID, episodes := seedAnime(t, repo, domain.ContentStatePublished, 1)
+	followers := seedFollowers(t, repo, NewAnimeFollowService(repo, nil, 2), animeID, 7, domain.NotifyInApp)
+	announce(t, repo, animeID, episodes...)
+
+	var announcement domain.EpisodeAnnouncement
+	if err := repo.DB().First(&announcement).Error; err != nil {
+		t.Fatal(err)
+	}
+	claimed, err := repo.SaveAnnouncementBatch([]uint{announcement.ID}, 0, 1, nil)
+	if err != nil || !claimed {
+		t.Fatalf("first claim = %v, %v", claimed, err)
+	}
+	if claimed, err := repo.SaveAnnouncementBatch([]uint{announcement.ID}, 0, 1, []domain.Notification{{UserID: 1}}); err != nil || claimed {
+		t.Fatalf("claim of a moved cursor = %v, %v; want it refused", claimed, err)
+	}
+	if n := countRows(t, repo, &domain.Notification{}); n != 0 {
+		t.Fatal("a refused claim stored its notifications")
+	}
+	if err := repo.DB().Model(&announcement).UpdateColumn("last_follow_id", 0).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// Replicas dispatching at once notify every follower once
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := NewAnimeFollowService(repo, nil, 2).Dispatch(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	counts := notificationsByUser(t, repo)
+	for _, id := range followers {
+		if counts[id] != 1 {
+			t.Errorf("follower %d got %d notifications, want 1", id, counts[id])
+		}
+	}
+}
+
+func TestAnimeFollowEmailFallsBackToInApp(t *testing.T) {
+	repo := newProgressTestRepo(t)
+	mailer := &fakeMailer{fail: map[string]bool{}}
+	s := NewAnimeFollowService(repo, mailer, 10)
+	animeID, episodes := seedAnime(t, repo, domain.ContentStatePublished, 1)
+	emailed := seedFollowers(t, repo, s, animeID, 2, domain.NotifyEmail)
+	inApp := seedFollowers(t, repo, s, animeID, 1, domain.NotifyInApp)
+	mailer.fail[fmt.Sprintf("follower%d@example.com", emailed[1])] = true
+	announce(t, repo, animeID, episodes...)
+
+	if err := s.Dispatch(); err != nil {
+		t.Fatal(err)
+	}
+	if len(mailer.sent) != 1 || mailer.sent[0] != fmt.Sprintf("follower%d@example.com: New episode of Anime 1", emailed[0]) {
+		t.Fatalf("sent emails = %v, want one to the first email follower", mailer.sent)
+	}
+	counts := notificationsByUser(t, repo)
+	if counts[emailed[0]] != 0 || counts[emailed[1]] != 1 || counts[inApp[0]] != 1 {
+		t.Fatalf("in-app notifications = %v, want the failed email and the in-app follower", counts)
+	}
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"backend/internal/adapters/repository"
+	"backend/internal/core/domain"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrListEntryNotFound = errors.New("anime is not on your list")
+
+// AnimeListService manages users' tracking lists
+type AnimeListService struct {
+	repo *repository.SQLiteRepository
+}
+
+func NewAnimeListService(repo *repository.SQLiteRepository) *AnimeListService {
+	return &AnimeListService{repo: repo}
+}
+
+func (s *AnimeListService) List(userID uint, filter domain.AnimeListFilter) ([]domain.AnimeListEntry, int64, error) {
+	if filter.Status != "" && !filter.Status.Valid() {
+		return nil, 0, domain.ErrInvalidListStatus
+	}
+	if filter.Limit <= 0 || filter.Limit > 200 {
+		filter.Limit = 50
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	filter.Query = strings.TrimSpace(filter.Query)
+	return s.repo.GetListEntries(userID, filter)
+}
+
+func (s *AnimeListService) Get(userID, animeID uint) (*domain.AnimeListEntry, error) {
+	entry, err := s.repo.GetListEntry(userID, animeID)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, ErrListEntryNotFound
+	}
+	return entry, nil
+}
+
+// Save adds a published anime to the user's list or updates its entry. New
+// entries default to plan_to_watch. Moving to watching fills the start date and
+// moving to completed fills the finish date and the episode count when they are
+// unset. It reports whether the entry was created.
+func (s *AnimeListService) Save(userID, animeID uint, update domain.AnimeListUpdate) (*domain.AnimeListEntry, bool, error) {
+	anime, err := s.repo.GetAnimeByID(animeID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && anime.State != domain.ContentStatePublished) {
+		return nil, false, ErrAnimeNotFound
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	entry, err := s.repo.GetListEntry(userID, animeID)
+	if err != nil {
+		return nil, false, err
+	}
+	created := entry == nil
+	if created {
+		entry = &domain.AnimeListEntry{UserID: userID, AnimeID: animeID, Status: domain.ListStatusPlanToWatch}
+	}
+	previous := entry.Status
+
+	if update.Status != nil {
+		entry.Status = *update.Status
+	}
+	if update.Score != nil {
+		entry.Score = *update.Score
+	}
+	if update.EpisodesWatched != nil {
+		entry.EpisodesWatched = *update.EpisodesWatched
+	}
+	if update.StartedAt != nil {
+		entry.StartedAt = update.StartedAt
+	}
+	if update.FinishedAt != nil {
+		entry.FinishedAt = update.FinishedAt
+	}
+	if update.RewatchCount != nil {
+		entry.RewatchCount = *update.RewatchCount
+	}
+	if update.Notes != nil {
+		entry.Notes = strings.TrimSpace(*update.Notes)
+	}
+
+	if entry.Status != previous || created {
+		if err := s.fillStatusDefaults(entry); err != nil {
+			return nil, false, err
+		}
+	}
+	if err := validateListEntry(entry); err != nil {
+		return nil, false, err
+	}
+	if err := s.repo.SaveListEntry(entry); err != nil {
+		return nil, false, err
+	}
+	entry.Anime = anime
+	return entry, created, nil
+}
+
+// fillStatusDefaults sets the dates and episode count implied by a new status
+func (s *AnimeListService) fillStatusDefaults(entry *domain.AnimeListEntry) error {
+	now := time.Now()
+	switch entry.Status {
+	case domain.ListStatusWatching:
+		if entry.StartedAt == nil {
+			entry.StartedAt = &now
+		}
+	case domain.ListStatusCompleted:
+		if entry.FinishedAt == nil {
+			entry.FinishedAt = &now
+		}
+		total, err := s.repo.CountPublishedEpisodes(entry.AnimeID)
+		if err != nil {
+			return err
+		}
+		entry.EpisodesWatched = max(entry.EpisodesWatched, int(total))
+	}
+	return nil
+}
+
+func validateListEntry(entry *domain.AnimeListEntry) error {
+	if !entry.Status.Valid() {
+		return domain.ErrInvalidListStatus
+	}
+	if entry.Score < 0 || entry.Score > 10 || entry.EpisodesWatched < 0 || entry.RewatchCount < 0 {
+		return domain.ErrInvalidListEntry
+	}
+	if entry.StartedAt != nil && entry.FinishedAt != nil && entry.FinishedAt.Before(*entry.StartedAt) {
+		return domain.ErrInvalidListEntry
+	}
+	return nil
+}
+
+func (s *AnimeListService) Remove(userID, animeID uint) error {
+	removed, err := s.repo.DeleteListEntry(userID, animeID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrListEntryNotFound
+	}
+	return nil
+}
+
+func (s *AnimeListService) Stats(userID uint) (*domain.AnimeListStats, error) {
+	stats, err := s.repo.GetListStats(userID)
+	if err != nil {
+		return nil, err
+	}
+	// List every status, even empty ones
+	for _, status := range domain.ListStatuses {
+		if _, ok := stats.ByStatus[status]; !ok {
+			stats.ByStatus[status] = 0
+		}
+	}
+	return stats, nil
+}
+
+// Import records an entry from an external list. Imported values win, except
+// that episodes watched and rewatches never go down and a zero score, missing
+// dates or empty notes keep what the user already has.
+func (s *AnimeListService) Import(userID, animeID uint, external domain.ExternalListEntry) error {
+	entry, err := s.repo.GetListEntry(userID, animeID)
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		entry = &domain.AnimeListEntry{UserID: userID, AnimeID: animeID}
+	}
+	entry.Status = external.Status
+	entry.EpisodesWatched = max(entry.EpisodesWatched, external.Progress)
+	entry.RewatchCount = max(entry.RewatchCount, external.Rewatches)
+	if external.Score > 0 && external.Score <= 10 {
+		entry.Score = external.Score
+	}
+	if external.StartedAt != nil {
+		entry.StartedAt = external.StartedAt
+	}
+	if external.FinishedAt != nil {
+		entry.FinishedAt = external.FinishedAt
+	}
+	if notes := strings.TrimSpace(external.Notes); notes != "" {
+		entry.Notes = notes
+	}
+	if entry.StartedAt != nil && entry.FinishedAt != nil && entry.FinishedAt.Before(*entry.StartedAt) {
+		entry.StartedAt = nil
+	}
+	entry.Anime = nil
+	return s.repo.SaveListEntry(entry)
+}
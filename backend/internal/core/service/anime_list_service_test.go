@@ -0,0 +1,72 @@
+package service
+
+import (
+	"backend/internal/core/domain"
+	"errors"
+	"testing"
+)
+
+func TestAnimeListSave(t *testing.T) {
+	repo := newProgressTestRepo(t)
+	s := NewAnimeListService(repo)
+	animeID, _ := seedAnime(t, repo, domain.ContentStatePublished, 3)
+
+	entry, created, err := s.Save(1, animeID, domain.AnimeListUpdate{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !created || entry.Status != domain.ListStatusPlanToWatch || entry.Anime == nil {
+		t.Fatalf("new entry = %+v created=%v, want plan_to_watch with the anime", entry, created)
+	}
+
+	watching := domain.ListStatusWatching
+	entry, created, err = s.Save(1, animeID, domain.AnimeListUpdate{Status: &watching})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created || entry.StartedAt == nil {
+		t.Fatalf("watching entry = %+v created=%v, want the start date filled", entry, created)
+	}
+
+	completed := domain.ListStatusCompleted
+	entry, _, err = s.Save(1, animeID, domain.AnimeListUpdate{Status: &completed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.FinishedAt == nil || entry.EpisodesWatched != 3 {
+		t.Fatalf("completed entry finished=%v watched=%d, want the date and all 3 episodes", entry.FinishedAt, entry.EpisodesWatched)
+	}
+
+	score := 11.0
+	if _, _, err := s.Save(1, animeID, domain.AnimeListUpdate{Score: &score}); !errors.Is(err, domain.ErrInvalidListEntry) {
+		t.Fatalf("score 11 = %v, want ErrInvalidListEntry", err)
+	}
+}
+
+func TestAnimeListOnlyHoldsPublishedAnime(t *testing.T) {
+	repo := newProgressTestRepo(t)
+	s := NewAnimeListService(repo)
+	published, _ := seedAnime(t, repo, domain.ContentStatePublished, 0)
+	draft, _ := seedAnime(t, repo, domain.ContentStateDraft, 0)
+
+	for _, id := range []uint{draft, 999} {
+		if _, _, err := s.Save(1, id, domain.AnimeListUpdate{}); !errors.Is(err, ErrAnimeNotFound) {
+			t.Errorf("saving anime %d = %v, want ErrAnimeNotFound", id, err)
+		}
+	}
+
+	// An anime unpublished after it was added drops out of the list
+	if _, _, err := s.Save(1, published, domain.AnimeListUpdate{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.DB().Create(&domain.AnimeListEntry{UserID: 1, AnimeID: draft, Status: domain.ListStatusWatching}).Error; err != nil {
+		t.Fatal(err)
+	}
+	entries, total, err := s.List(1, domain.AnimeListFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 || len(entries) != 1 || entries[0].AnimeID != published {
+		t.Fatalf("list = %+v (total %d), want only the published anime", entries, total)
+	}
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"backend/internal/adapters/repository"
+	"backend/internal/core/domain"
+	"errors"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// duplicateMinScore is the default score a pair needs to be flagged
+	duplicateMinScore = 0.75
+	// duplicateCommonToken skips title words shared by this many anime when
+	// pairing candidates ("season", "the", ...); they say nothing about identity
+	duplicateCommonToken = 200
+	// duplicateDateWindow is how many days apart two release dates may be and
+	// still count as the same release
+	duplicateDateWindow = 45
+)
+
+// AnimeMergeService flags likely duplicate anime and merges them
+type AnimeMergeService struct {
+	repo *repository.SQLiteRepository
+}
+
+func NewAnimeMergeService(repo *repository.SQLiteRepository) *AnimeMergeService {
+	return &AnimeMergeService{repo: repo}
+}
+
+// FindDuplicates returns candidate pairs scoring at least minScore, best first.
+// Only pairs sharing a distinctive title word are compared, which keeps the scan
+// far below comparing every pair.
+func (s *AnimeMergeService) FindDuplicates(minScore float64, limit int) ([]domain.DuplicateCandidate, error) {
+	if minScore <= 0 {
+		minScore = duplicateMinScore
+	}
+	animes, err := s.repo.GetDuplicateScanAnimes()
+	if err != nil {
+		return nil, err
+	}
+	dismissed, err := s.repo.GetDuplicateDismissals()
+	if err != nil {
+		return nil, err
+	}
+
+	titles := make([][]string, len(animes))
+	byToken := map[string][]int{}
+	for i, a := range animes {
+		seen := map[string]bool{}
+		for _, t := range []string{a.Title, a.TitleEn, a.Slug} {
+			n := normalizeTitle(t)
+			if n == "" || seen[n] {
+				continue
+			}
+			seen[n] = true
+			titles[i] = append(titles[i], n)
+		}
+		tokens := map[string]bool{}
+		for _, n := range titles[i] {
+			for _, token := range strings.Fields(n) {
+				if len([]rune(token)) >= 3 && !tokens[token] {
+					tokens[token] = true
+					byToken[token] = append(byToken[token], i)
+				}
+			}
+		}
+	}
+
+	compared := map[[2]int]bool{}
+	candidates := []domain.DuplicateCandidate{}
+	for _, group := range byToken {
+		if len(group) > duplicateCommonToken {
+			continue
+		}
+		for x := 0; x < len(group); x++ {
+			for y := x + 1; y < len(group); y++ {
+				i, j := group[x], group[y]
+				if compared[[2]int{i, j}] {
+					continue
+				}
+				compared[[2]int{i, j}] = true
+				a, b := animes[i], animes[j]
+				if dismissed[[2]uint{min(a.ID, b.ID), max(a.ID, b.ID)}] {
+					continue
+				}
+				score, reasons := duplicateScore(titles[i], titles[j], a, b)
+				if score >= minScore {
+					candidates = append(candidates, domain.DuplicateCandidate{A: a, B: b, Score: score, Reasons: reasons})
+				}
+			}
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		return candidates[i].A.ID < candidates[j].A.ID
+	})
+	if limit > 0 && len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	return candidates, nil
+}
+
+// duplicateScore is the best similarity between any titles of the two anime,
+// either language, raised when release dates and studios agree and lowered
+// when they clearly differ
+func duplicateScore(titlesA, titlesB []string, a, b domain.DuplicateAnime) (float64, []string) {
+	score := 0.0
+	for _, ta := range titlesA {
+		for _, tb := range titlesB {
+			if sim := titleSimilarity(ta, tb); sim > score {
+				score = sim
+			}
+		}
+	}
+	reasons := []string{"title"}
+
+	if a.ReleaseDate != nil && b.ReleaseDate != nil {
+		days := a.ReleaseDate.Sub(*b.ReleaseDate).Hours() / 24
+		if days < 0 {
+			days = -days
+		}
+		switch {
+		case days <= duplicateDateWindow:
+			score += 0.1
+			reasons = append(reasons, "release_date")
+		case days > 365:
+			score -= 0.2
+		}
+	}
+
+	switch {
+	case a.StudioID != nil && b.StudioID != nil && *a.StudioID == *b.StudioID,
+		a.StudioName != "" && normalizeTitle(a.StudioName) == normalizeTitle(b.StudioName):
+		score += 0.1
+		reasons = append(reasons, "studio")
+	case a.StudioName != "" && b.StudioName != "":
+		score -= 0.15
+	}
+
+	return max(0, min(1, score)), reasons
+}
+
+// Dismiss marks a pair as distinct so it is no longer flagged
+func (s *AnimeMergeService) Dismiss(animeID, otherID, userID uint) error {
+	if animeID == otherID {
+		return domain.ErrMergeSameAnime
+	}
+	for _, id := range []uint{animeID, otherID} {
+		if _, err := s.repo.GetAnimeByID(id); err != nil {
+			return animeLookupError(err)
+		}
+	}
+	return s.repo.DismissDuplicate(&domain.AnimeDuplicateDismissal{
+		AnimeID: min(animeID, otherID),
+		OtherID: max(animeID, otherID),
+		UserID:  &userID,
+	})
+}
+
+// Merge folds the loser into the survivor: episodes, comments, history, list
+// entries, watch progress, rating and category links move over, then the loser
+// is soft-deleted with a redirect to the survivor
+func (s *AnimeMergeService) Merge(survivorID, loserID, userID uint) (*domain.AnimeMergeReport, error) {
+	if survivorID == loserID {
+		return nil, domain.ErrMergeSameAnime
+	}
+	report, folded, err := s.repo.MergeAnime(survivorID, loserID, &userID)
+	if err != nil {
+		return nil, animeLookupError(err)
+	}
+	// Episodes that received servers need their legacy JSON rebuilt
+	for _, id := range folded {
+		episode, err := s.repo.GetEpisodeByID(id)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.repo.SetEpisodeVideoURLs(id, legacyVideoURLs(episode.Servers)); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// Redirect returns where a merged anime lives now, by ID or slug. It returns
+// (nil, nil) when the anime was not merged.
+func (s *AnimeMergeService) Redirect(id uint, slug string) (*domain.AnimeRedirect, error) {
+	return s.repo.GetAnimeRedirect(id, slug)
+}
+
+func animeLookupError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrAnimeNotFound
+	}
+	return err
+}
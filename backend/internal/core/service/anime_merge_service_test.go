@@ -0,0 +1,90 @@
+package service
+
+import (
+	"backend/internal/core/domain"
+	"math"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDuplicateScore(t *testing.T) {
+	date := func(s string) *time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return &d
+	}
+	studio := func(id uint) *uint { return &id }
+	tests := []struct {
+		name    string
+		a, b    domain.DuplicateAnime
+		score   float64
+		reasons []string
+	}{
+		{
+			name:    "same english title, other language's title differs",
+			a:       domain.DuplicateAnime{Title: "Shingeki no Kyojin", TitleEn: "Attack on Titan"},
+			b:       domain.DuplicateAnime{Title: "هجوم العمالقة", TitleEn: "Attack on Titan"},
+			score:   1,
+			reasons: []string{"title"},
+		},
+		{
+			name:    "close release dates and same studio",
+			a:       domain.DuplicateAnime{Title: "Naruto Shippuden", ReleaseDate: date("2007-02-15"), StudioID: studio(1)},
+			b:       domain.DuplicateAnime{Title: "Naruto Shipuden", ReleaseDate: date("2007-03-01"), StudioID: studio(1)},
+			score:   1,
+			reasons: []string{"title", "release_date", "studio"},
+		},
+		{
+			name:    "studio matched by name",
+			a:       domain.DuplicateAnime{Title: "Mob Psycho 100 Season 2", StudioName: "Bones"},
+			b:       domain.DuplicateAnime{Title: "Mob Psycho 100", StudioName: "BONES"},
+			score:   14.0/23 + 0.1,
+			reasons: []string{"title", "studio"},
+		},
+		{
+			name:    "years apart",
+			a:       domain.DuplicateAnime{Title: "Fruits Basket", ReleaseDate: date("2001-07-05")},
+			b:       domain.DuplicateAnime{Title: "Fruits Basket", ReleaseDate: date("2019-04-06")},
+			score:   0.8,
+			reasons: []string{"title"},
+		},
+		{
+			name:    "in between dates change nothing",
+			a:       domain.DuplicateAnime{Title: "Fruits Basket", ReleaseDate: date("2019-04-06")},
+			b:       domain.DuplicateAnime{Title: "Fruits Basket", ReleaseDate: date("2019-10-06")},
+			score:   1,
+			reasons: []string{"title"},
+		},
+		{
+			name:    "different studios",
+			a:       domain.DuplicateAnime{Title: "Hunter x Hunter", StudioName: "Nippon Animation"},
+			b:       domain.DuplicateAnime{Title: "Hunter x Hunter", StudioName: "Madhouse"},
+			score:   0.85,
+			reasons: []string{"title"},
+		},
+		{
+			name:    "never below zero",
+			a:       domain.DuplicateAnime{Title: "Bleach", ReleaseDate: date("2004-10-05"), StudioName: "Pierrot"},
+			b:       domain.DuplicateAnime{Title: "Monster", ReleaseDate: date("2020-01-01"), StudioName: "Madhouse"},
+			score:   0,
+			reasons: []string{"title"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			titles := func(a domain.DuplicateAnime) []string {
+				var titles []string
+				for _, title := range []string{a.Title, a.TitleEn, a.Slug} {
+					if n := normalizeTitle(title); n != "" {
+						titles = append(titles, n)
+					}
+				}
+				return titles
+			}
+			score, reasons := duplicateScore(titles(tt.a), titles(tt.b), tt.a, tt.b)
+			if math.Abs(score-tt.score) > 1e-9 || !slices.Equal(reasons, tt.reasons) {
+				t.Errorf("duplicateScore = %v %v, want %v %v", score, reasons, tt.score, tt.reasons)
+			}
+		})
+	}
+}
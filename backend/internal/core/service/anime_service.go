@@ -3,36 +3,82 @@ package service
 import (
 	"backend/internal/core/domain"
 	"backend/internal/core/port"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
 )
 
+var (
+	ErrAnimeNotFound    = errors.New("anime not found")
+	ErrInvalidReference = errors.New("referenced record does not exist")
+	ErrInvalidPatch     = errors.New("invalid merge patch")
+)
+
 type AnimeService struct {
-	repo port.AnimeRepository
+	repo         port.AnimeRepository
+	categoryRepo port.CategoryRepository
+	seasonRepo   port.SeasonRepository
+	studioRepo   port.StudioRepository
+	languageRepo port.LanguageRepository
+	revisionRepo port.RevisionRepository
+	media        *MediaService
+}
+
+func NewAnimeService(repo port.AnimeRepository, categoryRepo port.CategoryRepository, seasonRepo port.SeasonRepository, studioRepo port.StudioRepository, languageRepo port.LanguageRepository, revisionRepo port.RevisionRepository, media *MediaService) *AnimeService {
+	return &AnimeService{
+		repo:         repo,
+		categoryRepo: categoryRepo,
+		seasonRepo:   seasonRepo,
+		studioRepo:   studioRepo,
+		languageRepo: languageRepo,
+		revisionRepo: revisionRepo,
+		media:        media,
+	}
 }
 
-func NewAnimeService(repo port.AnimeRepository) *AnimeService {
-	return &AnimeService{repo: repo}
+// trackImages records the stored files an anime points at, releasing replaced ones
+func (s *AnimeService) trackImages(anime *domain.Anime) {
+	s.media.track(domain.MediaOwnerAnime, anime.ID, map[string]string{"image": anime.Image, "cover": anime.Cover})
 }
 
-func (s *AnimeService) Create(anime *domain.Anime) (*domain.Anime, error) {
+// Create saves a new anime in the given workflow state
+func (s *AnimeService) Create(anime *domain.Anime, state domain.ContentState, userID uint) (*domain.Anime, error) {
 	if anime.Status == "" {
 		anime.Status = "Ongoing"
 	}
 	if anime.Seasons == 0 {
 		anime.Seasons = 1
 	}
+	anime.ID = 0
+	anime.State = state
 	anime.IsActive = true
 	anime.CreatedAt = time.Now()
 	anime.UpdatedAt = time.Now()
 
-	if err := s.repo.CreateAnime(anime); err != nil {
+	categories, err := s.resolveRelations(anime)
+	if err != nil {
+		return nil, err
+	}
+	anime.Categories = categories
+	snapshot, err := animeSnapshot(anime)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SaveAnimeWithCategories(anime, categories, newRevision(domain.RevisionActionCreate, userID, snapshot)); err != nil {
 		return nil, err
 	}
-	return anime, nil
+	s.trackImages(anime)
+	return s.repo.GetAnimeByID(anime.ID)
 }
 
-func (s *AnimeService) GetAll() ([]domain.Anime, error) {
-	return s.repo.GetAllAnimes()
+// GetAll lists published anime, or every anime when includeUnpublished is set
+// for staff screens
+func (s *AnimeService) GetAll(includeUnpublished bool) ([]domain.Anime, error) {
+	if includeUnpublished {
+		return s.repo.GetAllAnimes()
+	}
+	return s.repo.GetPublishedAnimes()
 }
 
 func (s *AnimeService) GetLatest(limit int) ([]domain.Anime, error) {
@@ -43,12 +89,40 @@ func (s *AnimeService) GetByType(animeType string, limit int) ([]domain.Anime, e
 	return s.repo.GetAnimesByType(animeType, limit)
 }
 
-func (s *AnimeService) GetByID(id uint) (*domain.Anime, error) {
-	return s.repo.GetAnimeByID(id)
+// GetByID returns a published anime, or one in any state when includeUnpublished is set
+func (s *AnimeService) GetByID(id uint, includeUnpublished bool) (*domain.Anime, error) {
+	if includeUnpublished {
+		return s.repo.GetAnimeByID(id)
+	}
+	return s.repo.GetPublishedAnimeByID(id)
 }
 
-func (s *AnimeService) Update(anime *domain.Anime) (*domain.Anime, error) {
+// Update replaces every editable field of the anime, including its categories and
+// season/studio/language links. Missing relations in the request clear them.
+// anime.Version must be the stored version: domain.ErrVersionRequired when it is
+// zero, domain.ErrVersionConflict when someone saved a newer one.
+func (s *AnimeService) Update(anime *domain.Anime, userID uint) (*domain.Anime, error) {
+	return s.update(anime, newRevision(domain.RevisionActionUpdate, userID, nil))
+}
+
+func (s *AnimeService) update(anime *domain.Anime, revision *domain.Revision) (*domain.Anime, error) {
 	existing, err := s.repo.GetAnimeByID(anime.ID)
+	if err != nil {
+		return nil, ErrAnimeNotFound
+	}
+	if anime.Version == 0 {
+		return nil, domain.ErrVersionRequired
+	}
+	if anime.Version != existing.Version {
+		return nil, domain.ErrVersionConflict
+	}
+	if err := recordBaseline(s.revisionRepo, domain.RevisionEntityAnime, existing.ID, existing.Version, func() (json.RawMessage, error) {
+		return animeSnapshot(existing)
+	}); err != nil {
+		return nil, err
+	}
+
+	categories, err := s.resolveRelations(anime)
 	if err != nil {
 		return nil, err
 	}
@@ -60,6 +134,9 @@ func (s *AnimeService) Update(anime *domain.Anime) (*domain.Anime, error) {
 	existing.DescriptionEn = anime.DescriptionEn
 	existing.Category = anime.Category
 	existing.Seasons = anime.Seasons
+	existing.SeasonID = anime.SeasonID
+	existing.StudioID = anime.StudioID
+	existing.LanguageID = anime.LanguageID
 	existing.Status = anime.Status
 	existing.ReleaseDate = anime.ReleaseDate
 	existing.Rating = anime.Rating
@@ -72,12 +149,171 @@ func (s *AnimeService) Update(anime *domain.Anime) (*domain.Anime, error) {
 	existing.Language = anime.Language
 	existing.Trailer = anime.Trailer
 	existing.Type = anime.Type
+	existing.IsActive = anime.IsActive
+	existing.Categories = categories
 	existing.UpdatedAt = time.Now()
 
-	if err := s.repo.UpdateAnime(existing); err != nil {
+	if revision.Snapshot, err = animeSnapshot(existing); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SaveAnimeWithCategories(existing, categories, revision); err != nil {
 		return nil, err
 	}
-	return existing, nil
+	s.trackImages(existing)
+	return s.repo.GetAnimeByID(existing.ID)
+}
+
+// Patch applies an RFC 7386 JSON Merge Patch: absent keys keep their value and null clears it.
+// version is the If-Match version; when it is 0 the patch must carry a "version" key instead.
+func (s *AnimeService) Patch(id uint, patch []byte, version int, userID uint) (*domain.Anime, error) {
+	var patchDoc map[string]interface{}
+	if err := json.Unmarshal(patch, &patchDoc); err != nil || patchDoc == nil {
+		return nil, fmt.Errorf("%w: body must be a JSON object", ErrInvalidPatch)
+	}
+
+	if version == 0 {
+		// Only the client's own version counts, not the stored one merged in below
+		if v, ok := patchDoc["version"].(float64); ok {
+			version = int(v)
+		}
+	}
+
+	existing, err := s.repo.GetAnimeByID(id)
+	if err != nil {
+		return nil, ErrAnimeNotFound
+	}
+	current, err := json.Marshal(existing)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(current, &doc); err != nil {
+		return nil, err
+	}
+
+	merged, err := json.Marshal(mergePatch(doc, patchDoc))
+	if err != nil {
+		return nil, err
+	}
+	var anime domain.Anime
+	if err := json.Unmarshal(merged, &anime); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	anime.ID = id
+	anime.Version = version
+	return s.Update(&anime, userID)
+}
+
+// Revisions lists the anime's revisions, newest first
+func (s *AnimeService) Revisions(id uint) ([]domain.Revision, error) {
+	return s.revisionRepo.GetRevisions(domain.RevisionEntityAnime, id)
+}
+
+func (s *AnimeService) Revision(id uint, version int) (*domain.Revision, error) {
+	revision, err := s.revisionRepo.GetRevision(domain.RevisionEntityAnime, id, version)
+	if err != nil {
+		return nil, ErrRevisionNotFound
+	}
+	return revision, nil
+}
+
+// Diff compares two revisions field by field
+func (s *AnimeService) Diff(id uint, from, to int) (*domain.RevisionDiff, error) {
+	a, err := s.Revision(id, from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.Revision(id, to)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := diffSnapshots(a.Snapshot, b.Snapshot)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.RevisionDiff{EntityType: domain.RevisionEntityAnime, EntityID: id, From: from, To: to, Changes: changes}, nil
+}
+
+// Rollback restores the fields of an earlier revision as a new revision.
+// currentVersion guards against rolling back over an edit the caller hasn't seen and is required.
+func (s *AnimeService) Rollback(id uint, version int, currentVersion int, userID uint) (*domain.Anime, error) {
+	target, err := s.Revision(id, version)
+	if err != nil {
+		return nil, err
+	}
+	var anime domain.Anime
+	if err := json.Unmarshal(target.Snapshot, &anime); err != nil {
+		return nil, err
+	}
+	anime.ID = id
+	anime.Version = currentVersion
+
+	revision := newRevision(domain.RevisionActionRollback, userID, nil)
+	revision.RollbackOf = &version
+	return s.update(&anime, revision)
+}
+
+// resolveRelations checks that every referenced season, studio, language and category exists
+// and returns the categories to link (category_ids win over the categories array).
+func (s *AnimeService) resolveRelations(anime *domain.Anime) ([]domain.Category, error) {
+	// The admin form sends 0 for "none"
+	for _, id := range []**uint{&anime.SeasonID, &anime.StudioID, &anime.LanguageID} {
+		if *id != nil && **id == 0 {
+			*id = nil
+		}
+	}
+
+	if anime.SeasonID != nil {
+		if _, err := s.seasonRepo.GetSeasonByID(*anime.SeasonID); err != nil {
+			return nil, fmt.Errorf("%w: season %d", ErrInvalidReference, *anime.SeasonID)
+		}
+	}
+	if anime.StudioID != nil {
+		if _, err := s.studioRepo.GetStudioByID(*anime.StudioID); err != nil {
+			return nil, fmt.Errorf("%w: studio %d", ErrInvalidReference, *anime.StudioID)
+		}
+	}
+	if anime.LanguageID != nil {
+		if _, err := s.languageRepo.GetLanguageByID(*anime.LanguageID); err != nil {
+			return nil, fmt.Errorf("%w: language %d", ErrInvalidReference, *anime.LanguageID)
+		}
+	}
+
+	ids := anime.CategoryIDs
+	if ids == nil {
+		ids = make([]uint, 0, len(anime.Categories))
+		for _, c := range anime.Categories {
+			ids = append(ids, c.ID)
+		}
+	}
+	seen := map[uint]bool{}
+	unique := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	categories, err := s.categoryRepo.GetCategoriesByIDs(unique)
+	if err != nil {
+		return nil, err
+	}
+	if len(categories) != len(unique) {
+		found := map[uint]bool{}
+		for _, c := range categories {
+			found[c.ID] = true
+		}
+		for _, id := range unique {
+			if !found[id] {
+				return nil, fmt.Errorf("%w: category %d", ErrInvalidReference, id)
+			}
+		}
+	}
+	if categories == nil {
+		categories = []domain.Category{}
+	}
+	return categories, nil
 }
 
 func (s *AnimeService) Delete(id uint) error {
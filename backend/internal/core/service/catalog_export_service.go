@@ -0,0 +1,398 @@
+package service
+
+import (
+	"backend/internal/adapters/repository"
+	"backend/internal/core/domain"
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const catalogExportBatchSize = 200
+
+// catalogDeletedColumn is set on the tombstone rows of incremental CSV exports
+const catalogDeletedColumn = "deleted_at"
+
+// Same order as imports: dictionaries before the rows that reference them
+var catalogExportEntities = []string{
+	domain.ImportEntityCategory,
+	domain.ImportEntityType,
+	domain.ImportEntitySeason,
+	domain.ImportEntityStudio,
+	domain.ImportEntityLanguage,
+	domain.ImportEntityAnime,
+	domain.ImportEntityEpisode,
+}
+
+type CatalogExportService struct {
+	repo *repository.SQLiteRepository
+}
+
+func NewCatalogExportService(repo *repository.SQLiteRepository) *CatalogExportService {
+	return &CatalogExportService{repo: repo}
+}
+
+// catalogEpisode hides the empty embedded Anime and references it by slug instead
+type catalogEpisode struct {
+	domain.Episode
+	Anime     *domain.Anime `json:"anime,omitempty"`
+	AnimeSlug string        `json:"anime_slug"`
+}
+
+// Validate checks the export parameters before anything is written to the response
+func (s *CatalogExportService) Validate(format, entity string) error {
+	if entity != "" && !containsString(catalogExportEntities, entity) {
+		return fmt.Errorf("unknown export entity: %q", entity)
+	}
+	switch format {
+	case "jsonl":
+		return nil
+	case "csv":
+		if entity == "" {
+			return fmt.Errorf("entity is required for CSV exports")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported export format: %q (use jsonl or csv)", format)
+	}
+}
+
+// Export streams the catalog to w batch by batch.
+// JSON Lines carries full rows (anime with categories, episodes with servers) and, when since
+// is set, tombstones for rows deleted after it. CSV uses the import columns so files can be
+// fed back into POST /api/admin/import; incremental CSV adds a deleted_at column that is only
+// set on tombstone rows, which imports skip.
+func (s *CatalogExportService) Export(w io.Writer, format, entity string, since *time.Time) error {
+	if err := s.Validate(format, entity); err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	flush := func() error {
+		if err := bw.Flush(); err != nil {
+			return err
+		}
+		if f, ok := w.(interface{ Flush() }); ok {
+			f.Flush()
+		}
+		return nil
+	}
+
+	var err error
+	if format == "csv" {
+		err = s.exportCSV(bw, flush, entity, since)
+	} else {
+		entities := catalogExportEntities
+		if entity != "" {
+			entities = []string{entity}
+		}
+		for _, e := range entities {
+			if err = s.exportJSONL(bw, flush, e, since); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return flush()
+}
+
+func (s *CatalogExportService) exportJSONL(w io.Writer, flush func() error, entity string, since *time.Time) error {
+	enc := json.NewEncoder(w)
+	line := func(data interface{}) error {
+		return enc.Encode(domain.CatalogExportLine{Entity: entity, Data: data})
+	}
+
+	var err error
+	switch entity {
+	case domain.ImportEntityCategory:
+		err = s.repo.EachCategoryBatch(since, catalogExportBatchSize, func(rows []domain.Category) error {
+			return writeBatch(rows, line, flush)
+		})
+	case domain.ImportEntityType:
+		err = s.repo.EachTypeBatch(since, catalogExportBatchSize, func(rows []domain.Type) error {
+			return writeBatch(rows, line, flush)
+		})
+	case domain.ImportEntitySeason:
+		err = s.repo.EachSeasonBatch(since, catalogExportBatchSize, func(rows []domain.Season) error {
+			return writeBatch(rows, line, flush)
+		})
+	case domain.ImportEntityStudio:
+		err = s.repo.EachStudioBatch(since, catalogExportBatchSize, func(rows []domain.Studio) error {
+			return writeBatch(rows, line, flush)
+		})
+	case domain.ImportEntityLanguage:
+		err = s.repo.EachLanguageBatch(since, catalogExportBatchSize, func(rows []domain.Language) error {
+			return writeBatch(rows, line, flush)
+		})
+	case domain.ImportEntityAnime:
+		err = s.repo.EachAnimeBatch(since, catalogExportBatchSize, func(rows []domain.Anime) error {
+			return writeBatch(rows, line, flush)
+		})
+	case domain.ImportEntityEpisode:
+		err = s.repo.EachEpisodeBatch(since, catalogExportBatchSize, func(rows []domain.Episode) error {
+			episodes, err := s.withAnimeSlugs(rows)
+			if err != nil {
+				return err
+			}
+			return writeBatch(episodes, line, flush)
+		})
+	}
+	if err != nil || since == nil {
+		return err
+	}
+
+	deleted, err := s.repo.GetDeletedSince(catalogExportModel(entity), *since)
+	if err != nil {
+		return err
+	}
+	for _, t := range deleted {
+		if err := enc.Encode(domain.CatalogExportLine{Entity: entity, Deleted: true, Data: t}); err != nil {
+			return err
+		}
+	}
+	return flush()
+}
+
+// catalogExportModel returns the model whose table holds an entity
+func catalogExportModel(entity string) interface{} {
+	switch entity {
+	case domain.ImportEntityCategory:
+		return &domain.Category{}
+	case domain.ImportEntityType:
+		return &domain.Type{}
+	case domain.ImportEntitySeason:
+		return &domain.Season{}
+	case domain.ImportEntityStudio:
+		return &domain.Studio{}
+	case domain.ImportEntityLanguage:
+		return &domain.Language{}
+	case domain.ImportEntityAnime:
+		return &domain.Anime{}
+	default:
+		return &domain.Episode{}
+	}
+}
+
+func writeBatch[T any](rows []T, line func(interface{}) error, flush func() error) error {
+	for i := range rows {
+		if err := line(&rows[i]); err != nil {
+			return err
+		}
+	}
+	return flush()
+}
+
+func (s *CatalogExportService) withAnimeSlugs(rows []domain.Episode) ([]catalogEpisode, error) {
+	ids := make([]uint, 0, len(rows))
+	for _, ep := range rows {
+		ids = append(ids, ep.AnimeID)
+	}
+	slugs, err := s.repo.GetAnimeSlugs(ids)
+	if err != nil {
+		return nil, err
+	}
+	episodes := make([]catalogEpisode, len(rows))
+	for i, ep := range rows {
+		episodes[i] = catalogEpisode{Episode: ep, AnimeSlug: slugs[ep.AnimeID]}
+	}
+	return episodes, nil
+}
+
+func (s *CatalogExportService) exportCSV(w io.Writer, flush func() error, entity string, since *time.Time) error {
+	columns := importCSVColumns[entity]
+	if columns == nil {
+		columns = importCSVColumns["dictionary"]
+	}
+	if since != nil {
+		// Incremental exports end with a tombstone row per deleted row
+		columns = append(columns[:len(columns):len(columns)], catalogDeletedColumn)
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return err
+	}
+	write := func(values map[string]string) error {
+		record := make([]string, len(columns))
+		for i, col := range columns {
+			record[i] = values[col]
+		}
+		return cw.Write(record)
+	}
+	done := func() error {
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return err
+		}
+		return flush()
+	}
+	dictionaryRow := func(slug, name, nameEn, description string, isActive *bool) error {
+		values := map[string]string{"slug": slug, "name": name, "name_en": nameEn, "description": description}
+		if isActive != nil {
+			values["is_active"] = strconv.FormatBool(*isActive)
+		}
+		return write(values)
+	}
+
+	var err error
+	switch entity {
+	case domain.ImportEntityCategory:
+		err = s.repo.EachCategoryBatch(since, catalogExportBatchSize, func(rows []domain.Category) error {
+			for _, r := range rows {
+				if err := dictionaryRow(r.Slug, r.Name, r.NameEn, r.Description, nil); err != nil {
+					return err
+				}
+			}
+			return done()
+		})
+	case domain.ImportEntityType:
+		err = s.repo.EachTypeBatch(since, catalogExportBatchSize, func(rows []domain.Type) error {
+			for _, r := range rows {
+				if err := dictionaryRow(r.Slug, r.Name, r.NameEn, "", &r.IsActive); err != nil {
+					return err
+				}
+			}
+			return done()
+		})
+	case domain.ImportEntitySeason:
+		err = s.repo.EachSeasonBatch(since, catalogExportBatchSize, func(rows []domain.Season) error {
+			for _, r := range rows {
+				if err := dictionaryRow(r.Slug, r.Name, r.NameEn, "", &r.IsActive); err != nil {
+					return err
+				}
+			}
+			return done()
+		})
+	case domain.ImportEntityStudio:
+		err = s.repo.EachStudioBatch(since, catalogExportBatchSize, func(rows []domain.Studio) error {
+			for _, r := range rows {
+				if err := dictionaryRow(r.Slug, r.Name, r.NameEn, "", &r.IsActive); err != nil {
+					return err
+				}
+			}
+			return done()
+		})
+	case domain.ImportEntityLanguage:
+		err = s.repo.EachLanguageBatch(since, catalogExportBatchSize, func(rows []domain.Language) error {
+			for _, r := range rows {
+				if err := dictionaryRow(r.Slug, r.Name, r.NameEn, "", &r.IsActive); err != nil {
+					return err
+				}
+			}
+			return done()
+		})
+	case domain.ImportEntityAnime:
+		err = s.repo.EachAnimeBatch(since, catalogExportBatchSize, func(rows []domain.Anime) error {
+			for _, a := range rows {
+				if err := write(animeCSVValues(&a)); err != nil {
+					return err
+				}
+			}
+			return done()
+		})
+	case domain.ImportEntityEpisode:
+		err = s.repo.EachEpisodeBatch(since, catalogExportBatchSize, func(rows []domain.Episode) error {
+			episodes, err := s.withAnimeSlugs(rows)
+			if err != nil {
+				return err
+			}
+			for _, ep := range episodes {
+				values, err := episodeCSVValues(&ep)
+				if err != nil {
+					return err
+				}
+				if err := write(values); err != nil {
+					return err
+				}
+			}
+			return done()
+		})
+	}
+	if err != nil || since == nil {
+		return err
+	}
+
+	deleted, err := s.repo.GetDeletedSince(catalogExportModel(entity), *since)
+	if err != nil {
+		return err
+	}
+	for _, t := range deleted {
+		if err := write(map[string]string{"slug": t.Slug, catalogDeletedColumn: t.DeletedAt.UTC().Format(time.RFC3339)}); err != nil {
+			return err
+		}
+	}
+	return done()
+}
+
+func animeCSVValues(a *domain.Anime) map[string]string {
+	categories := make([]string, 0, len(a.Categories))
+	for _, c := range a.Categories {
+		categories = append(categories, c.Slug)
+	}
+	values := map[string]string{
+		"slug":           a.Slug,
+		"slug_en":        a.SlugEn,
+		"title":          a.Title,
+		"title_en":       a.TitleEn,
+		"description":    a.Description,
+		"description_en": a.DescriptionEn,
+		"status":         a.Status,
+		"type":           a.Type,
+		"rating":         strconv.FormatFloat(a.Rating, 'f', -1, 64),
+		"seasons_count":  strconv.Itoa(a.Seasons),
+		"duration":       strconv.Itoa(a.Duration),
+		"image":          a.Image,
+		"cover":          a.Cover,
+		"trailer":        a.Trailer,
+		"studio_name":    a.StudioName,
+		"language":       a.Language,
+		"is_active":      strconv.FormatBool(a.IsActive),
+		"categories":     strings.Join(categories, "|"),
+		"season":         a.Season.Slug,
+		"studio":         a.Studio.Slug,
+		"language_rel":   a.LanguageRel.Slug,
+	}
+	if a.ReleaseDate != nil {
+		values["release_date"] = a.ReleaseDate.Format("2006-01-02")
+	}
+	return values
+}
+
+func episodeCSVValues(ep *catalogEpisode) (map[string]string, error) {
+	servers := make([]domain.ServerImportRecord, 0, len(ep.Servers))
+	for _, sv := range ep.Servers {
+		servers = append(servers, domain.NewServerImportRecord(sv))
+	}
+	serversJSON, err := json.Marshal(servers)
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]string{
+		"anime_slug":     ep.AnimeSlug,
+		"episode_number": strconv.Itoa(ep.EpisodeNumber),
+		"slug":           ep.Slug,
+		"slug_en":        ep.SlugEn,
+		"title":          ep.Title,
+		"title_en":       ep.TitleEn,
+		"description":    ep.Description,
+		"description_en": ep.DescriptionEn,
+		"thumbnail":      ep.Thumbnail,
+		"banner":         ep.Banner,
+		"duration":       strconv.Itoa(ep.Duration),
+		"quality":        ep.Quality,
+		"video_format":   ep.VideoFormat,
+		"is_published":   strconv.FormatBool(ep.IsPublished),
+		"language":       ep.Language,
+		"servers":        string(serversJSON),
+	}
+	if !ep.ReleaseDate.IsZero() {
+		values["release_date"] = ep.ReleaseDate.Format("2006-01-02")
+	}
+	return values, nil
+}
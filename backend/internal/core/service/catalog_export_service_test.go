@@ -0,0 +1,164 @@
+package service
+
+import (
+	"backend/internal/adapters/repository"
+	"backend/internal/core/domain"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newExportTestRepo seeds anime around since = 2026-01-01T12:00:00Z, with one row
+// stored in another offset whose wall clock is later than since but whose instant is not
+func newExportTestRepo(t *testing.T) (*repository.SQLiteRepository, time.Time) {
+	t.Helper()
+	repo, err := repository.NewSQLiteRepository("file:" + filepath.Join(t.TempDir(), "export.db") + "?_busy_timeout=5000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	since := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tokyo := time.FixedZone("JST", 9*60*60)
+	rows := []struct {
+		slug      string
+		updatedAt time.Time
+		deletedAt *time.Time
+	}{
+		{"old", time.Date(2026, 1, 1, 20, 0, 0, 0, tokyo), nil}, // 11:00Z
+		{"new", since.Add(time.Hour), nil},
+		{"gone", since.Add(-time.Hour), timePtr(since.Add(12 * time.Hour))},
+		{"gone-early", since.Add(-48 * time.Hour), timePtr(since.Add(-24 * time.Hour))},
+	}
+	for _, row := range rows {
+		anime := domain.Anime{Slug: row.slug, Title: strings.ToUpper(row.slug), UpdatedAt: row.updatedAt}
+		if err := repo.DB().Create(&anime).Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.DB().Model(&anime).UpdateColumn("updated_at", row.updatedAt).Error; err != nil {
+			t.Fatal(err)
+		}
+		if row.deletedAt != nil {
+			if err := repo.DB().Model(&anime).UpdateColumn("deleted_at", *row.deletedAt).Error; err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return repo, since
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func TestCatalogExportJSONLSinceAndTombstones(t *testing.T) {
+	repo, since := newExportTestRepo(t)
+	s := NewCatalogExportService(repo)
+
+	var buf bytes.Buffer
+	if err := s.Export(&buf, "jsonl", domain.ImportEntityAnime, &since); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var line struct {
+			Entity  string `json:"entity"`
+			Deleted bool   `json:"deleted"`
+			Data    struct {
+				Slug string `json:"slug"`
+			} `json:"data"`
+		}
+		if err := dec.Decode(&line); err != nil {
+			t.Fatal(err)
+		}
+		if line.Entity != domain.ImportEntityAnime {
+			t.Fatalf("line entity = %q", line.Entity)
+		}
+		if line.Deleted {
+			got = append(got, "-"+line.Data.Slug)
+		} else {
+			got = append(got, line.Data.Slug)
+		}
+	}
+	if want := []string{"new", "-gone"}; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("incremental export = %v, want %v", got, want)
+	}
+
+	buf.Reset()
+	if err := s.Export(&buf, "jsonl", domain.ImportEntityAnime, nil); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(buf.String(), "\n"); n != 2 {
+		t.Fatalf("full export wrote %d lines, want the 2 live anime", n)
+	}
+	if strings.Contains(buf.String(), `"deleted"`) {
+		t.Fatal("full export wrote tombstones")
+	}
+}
+
+func TestCatalogExportCSVTombstonesReimport(t *testing.T) {
+	repo, since := newExportTestRepo(t)
+	s := NewCatalogExportService(repo)
+
+	var buf bytes.Buffer
+	if err := s.Export(&buf, "csv", domain.ImportEntityAnime, &since); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(bytes.NewReader(buf.Bytes())).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("incremental CSV has %d records, want header, new and gone", len(records))
+	}
+	header := records[0]
+	if header[len(header)-1] != catalogDeletedColumn {
+		t.Fatalf("last CSV column = %q, want %q", header[len(header)-1], catalogDeletedColumn)
+	}
+	if records[1][0] != "new" || records[1][len(header)-1] != "" {
+		t.Fatalf("live row = %v", records[1])
+	}
+	if records[2][0] != "gone" || records[2][len(header)-1] != "2026-01-02T00:00:00Z" {
+		t.Fatalf("tombstone row = %v", records[2])
+	}
+
+	// The file can be fed back to the importer, which skips the tombstone
+	imports := NewImportService(repo, nil)
+	batch, err := imports.Parse(bytes.NewReader(buf.Bytes()), "csv", domain.ImportEntityAnime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(batch.Animes) != 1 || batch.Animes[0].Slug != "new" {
+		t.Fatalf("reimported rows = %+v, want only new", batch.Animes)
+	}
+	report, err := imports.Import(batch, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Unchanged != 1 {
+		t.Fatalf("reimport = %+v, want the exported row unchanged", report)
+	}
+}
+
+func TestCatalogExportValidate(t *testing.T) {
+	s := NewCatalogExportService(nil)
+	cases := []struct {
+		format, entity string
+		ok             bool
+	}{
+		{"jsonl", "", true},
+		{"jsonl", domain.ImportEntityEpisode, true},
+		{"csv", domain.ImportEntityStudio, true},
+		{"csv", "", false},
+		{"xml", domain.ImportEntityAnime, false},
+		{"jsonl", "users", false},
+	}
+	for _, c := range cases {
+		if err := s.Validate(c.format, c.entity); (err == nil) != c.ok {
+			t.Errorf("Validate(%q, %q) = %v, want ok=%v", c.format, c.entity, err, c.ok)
+		}
+	}
+}
@@ -0,0 +1,441 @@
+package service
+
+import (
+	"backend/internal/adapters/repository"
+	"backend/internal/core/domain"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// CollectionInput creates or changes a collection; nil fields keep their value on update
+type CollectionInput struct {
+	Title       *string                      `json:"title"`
+	Description *string                      `json:"description"`
+	Visibility  *domain.CollectionVisibility `json:"visibility"`
+}
+
+// CollectionService manages user-curated collections. Owners manage the
+// collection itself and its collaborators; collaborators manage its items.
+// Admins can change or delete any collection. Private collections are only
+// visible to the owner, collaborators and admins, and look missing to others;
+// so do unlisted ones asked for by ID rather than share slug.
+type CollectionService struct {
+	repo *repository.SQLiteRepository
+}
+
+func NewCollectionService(repo *repository.SQLiteRepository) *CollectionService {
+	return &CollectionService{repo: repo}
+}
+
+// collectionCaller is who is acting on a collection; userID is 0 for guests
+type collectionCaller struct {
+	userID uint
+	admin  bool
+}
+
+func (c collectionCaller) isOwner(collection *domain.Collection) bool {
+	return c.userID != 0 && collection.UserID == c.userID
+}
+
+func (c collectionCaller) canEdit(collection *domain.Collection) bool {
+	if c.isOwner(collection) || c.admin {
+		return true
+	}
+	for _, collaborator := range collection.Collaborators {
+		if c.userID != 0 && collaborator.UserID == c.userID {
+			return true
+		}
+	}
+	return false
+}
+
+// canView reports whether the caller may see a collection. Unlisted collections
+// are shared by link, so others reach them by share slug only and cannot walk
+// the IDs.
+func (c collectionCaller) canView(collection *domain.Collection, bySlug bool) bool {
+	switch collection.Visibility {
+	case domain.CollectionPublic:
+		return true
+	case domain.CollectionUnlisted:
+		return bySlug || c.canEdit(collection)
+	default:
+		return c.canEdit(collection)
+	}
+}
+
+// load returns a collection by ID or share slug if the caller may see it
+func (s *CollectionService) load(caller collectionCaller, ref string) (*domain.Collection, error) {
+	var collection *domain.Collection
+	var err error
+	id, convErr := strconv.ParseUint(ref, 10, 64)
+	bySlug := convErr != nil
+	if bySlug {
+		collection, err = s.repo.GetCollectionBySlug(ref)
+	} else {
+		collection, err = s.repo.GetCollection(uint(id))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if collection == nil || !caller.canView(collection, bySlug) {
+		return nil, domain.ErrCollectionNotFound
+	}
+	return collection, nil
+}
+
+// loadForEdit returns a collection the caller may change the items of
+func (s *CollectionService) loadForEdit(caller collectionCaller, id uint) (*domain.Collection, error) {
+	collection, err := s.load(caller, strconv.FormatUint(uint64(id), 10))
+	if err != nil {
+		return nil, err
+	}
+	if !caller.canEdit(collection) {
+		return nil, domain.ErrCollectionForbidden
+	}
+	return collection, nil
+}
+
+// loadForOwner returns a collection the caller owns (or administers)
+func (s *CollectionService) loadForOwner(caller collectionCaller, id uint) (*domain.Collection, error) {
+	collection, err := s.load(caller, strconv.FormatUint(uint64(id), 10))
+	if err != nil {
+		return nil, err
+	}
+	if !caller.isOwner(collection) && !caller.admin {
+		return nil, domain.ErrCollectionForbidden
+	}
+	return collection, nil
+}
+
+func (s *CollectionService) Create(userID uint, input CollectionInput) (*domain.Collection, error) {
+	collection := &domain.Collection{UserID: userID, Visibility: domain.CollectionPrivate}
+	applyCollectionInput(collection, input)
+	if err := validateCollection(collection); err != nil {
+		return nil, err
+	}
+	collection.Slug = collectionSlug(collection.Title)
+	if err := s.repo.CreateCollection(collection); err != nil {
+		return nil, err
+	}
+	return s.Get(userID, "", strconv.FormatUint(uint64(collection.ID), 10))
+}
+
+func applyCollectionInput(collection *domain.Collection, input CollectionInput) {
+	if input.Title != nil {
+		collection.Title = strings.TrimSpace(*input.Title)
+	}
+	if input.Description != nil {
+		collection.Description = strings.TrimSpace(*input.Description)
+	}
+	if input.Visibility != nil {
+		collection.Visibility = *input.Visibility
+	}
+}
+
+func validateCollection(collection *domain.Collection) error {
+	if collection.Title == "" || utf8.RuneCountInString(collection.Title) > 120 ||
+		utf8.RuneCountInString(collection.Description) > 2000 || !collection.Visibility.Valid() {
+		return domain.ErrInvalidCollection
+	}
+	return nil
+}
+
+// collectionSlug builds a share slug from the title and a random suffix, so
+// slugs cannot be guessed from titles
+func collectionSlug(title string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+		if utf8.RuneCountInString(b.String()) >= 60 {
+			break
+		}
+	}
+	base := strings.Trim(b.String(), "-")
+	if base == "" {
+		base = "collection"
+	}
+	return base + "-" + strings.ReplaceAll(uuid.New().String(), "-", "")[:10]
+}
+
+// Get returns a collection by ID or share slug with its items and the caller's
+// relation to it. userID is 0 for guests.
+func (s *CollectionService) Get(userID uint, role string, ref string) (*domain.Collection, error) {
+	caller := collectionCaller{userID: userID, admin: role == "admin"}
+	collection, err := s.load(caller, ref)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.repo.GetCollectionItems(collection.ID)
+	if err != nil {
+		return nil, err
+	}
+	if collection.Items, err = s.attachContent(items); err != nil {
+		return nil, err
+	}
+
+	viewer := &domain.CollectionViewer{IsOwner: caller.isOwner(collection), CanEdit: caller.canEdit(collection)}
+	if userID != 0 {
+		if viewer.Followed, viewer.Liked, err = s.repo.GetCollectionViewer(collection.ID, userID); err != nil {
+			return nil, err
+		}
+	}
+	collection.Viewer = viewer
+	return collection, nil
+}
+
+// attachContent fills the anime, episode or model of each item and drops items
+// whose content is gone or unpublished
+func (s *CollectionService) attachContent(items []domain.CollectionItem) ([]domain.CollectionItem, error) {
+	ids := map[domain.CollectionItemType][]uint{}
+	for _, item := range items {
+		ids[item.ItemType] = append(ids[item.ItemType], item.ItemID)
+	}
+	animes := map[uint]*domain.Anime{}
+	episodes := map[uint]*domain.Episode{}
+	models := map[uint]*domain.Model{}
+	if len(ids[domain.CollectionItemAnime]) > 0 {
+		rows, err := s.repo.GetPublishedAnimesByIDs(ids[domain.CollectionItemAnime])
+		if err != nil {
+			return nil, err
+		}
+		for i := range rows {
+			animes[rows[i].ID] = &rows[i]
+		}
+	}
+	if len(ids[domain.CollectionItemEpisode]) > 0 {
+		rows, err := s.repo.GetPublishedEpisodesByIDs(ids[domain.CollectionItemEpisode])
+		if err != nil {
+			return nil, err
+		}
+		for i := range rows {
+			episodes[rows[i].ID] = &rows[i]
+		}
+	}
+	if len(ids[domain.CollectionItemModel]) > 0 {
+		rows, err := s.repo.GetModelsByIDs(ids[domain.CollectionItemModel])
+		if err != nil {
+			return nil, err
+		}
+		for i := range rows {
+			models[rows[i].ID] = &rows[i]
+		}
+	}
+
+	visible := make([]domain.CollectionItem, 0, len(items))
+	for _, item := range items {
+		switch item.ItemType {
+		case domain.CollectionItemAnime:
+			item.Anime = animes[item.ItemID]
+		case domain.CollectionItemEpisode:
+			item.Episode = episodes[item.ItemID]
+		case domain.CollectionItemModel:
+			item.Model = models[item.ItemID]
+		}
+		if item.Anime != nil || item.Episode != nil || item.Model != nil {
+			visible = append(visible, item)
+		}
+	}
+	return visible, nil
+}
+
+// Browse lists public, non-empty collections, most followed and liked first by default
+func (s *CollectionService) Browse(filter domain.CollectionFilter) ([]domain.Collection, int64, error) {
+	if filter.Limit <= 0 || filter.Limit > 200 {
+		filter.Limit = 50
+	}
+	filter.Offset = max(filter.Offset, 0)
+	filter.Query = strings.TrimSpace(filter.Query)
+	return s.repo.ListPublicCollections(filter)
+}
+
+// Mine lists the collections a user owns, collaborates on ("shared") or follows ("followed")
+func (s *CollectionService) Mine(userID uint, relation string, limit, offset int) ([]domain.Collection, int64, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	return s.repo.ListUserCollections(userID, relation, limit, max(offset, 0))
+}
+
+func (s *CollectionService) Update(userID uint, role string, id uint, input CollectionInput) (*domain.Collection, error) {
+	caller := collectionCaller{userID: userID, admin: role == "admin"}
+	collection, err := s.loadForOwner(caller, id)
+	if err != nil {
+		return nil, err
+	}
+	applyCollectionInput(collection, input)
+	if err := validateCollection(collection); err != nil {
+		return nil, err
+	}
+	err = s.repo.UpdateCollection(id, map[string]any{
+		"title":       collection.Title,
+		"description": collection.Description,
+		"visibility":  collection.Visibility,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(userID, role, strconv.FormatUint(uint64(id), 10))
+}
+
+// RegenerateSlug gives a collection a new share slug, so old links stop working
+func (s *CollectionService) RegenerateSlug(userID uint, role string, id uint) (string, error) {
+	collection, err := s.loadForOwner(collectionCaller{userID: userID, admin: role == "admin"}, id)
+	if err != nil {
+		return "", err
+	}
+	slug := collectionSlug(collection.Title)
+	if err := s.repo.UpdateCollection(id, map[string]any{"slug": slug}); err != nil {
+		return "", err
+	}
+	return slug, nil
+}
+
+func (s *CollectionService) Delete(userID uint, role string, id uint) error {
+	if _, err := s.loadForOwner(collectionCaller{userID: userID, admin: role == "admin"}, id); err != nil {
+		return err
+	}
+	return s.repo.DeleteCollection(id)
+}
+
+// AddItem appends an anime, episode or model to a collection
+func (s *CollectionService) AddItem(userID uint, role string, id uint, itemType domain.CollectionItemType, itemID uint, note string) (*domain.CollectionItem, error) {
+	if _, err := s.loadForEdit(collectionCaller{userID: userID, admin: role == "admin"}, id); err != nil {
+		return nil, err
+	}
+	if !itemType.Valid() {
+		return nil, domain.ErrCollectionItemNotFound
+	}
+	item := &domain.CollectionItem{CollectionID: id, ItemType: itemType, ItemID: itemID, Note: strings.TrimSpace(note), AddedBy: userID}
+	attached, err := s.attachContent([]domain.CollectionItem{*item})
+	if err != nil {
+		return nil, err
+	}
+	if len(attached) == 0 {
+		return nil, domain.ErrCollectionItemNotFound
+	}
+	if err := s.repo.AddCollectionItem(item); err != nil {
+		return nil, err
+	}
+	item.Anime, item.Episode, item.Model = attached[0].Anime, attached[0].Episode, attached[0].Model
+	return item, nil
+}
+
+// UpdateItem moves an item to a new position and/or changes its note
+func (s *CollectionService) UpdateItem(userID uint, role string, id, itemID uint, position *int, note *string) error {
+	collection, err := s.loadForEdit(collectionCaller{userID: userID, admin: role == "admin"}, id)
+	if err != nil {
+		return err
+	}
+	item, err := s.repo.GetCollectionItem(id, itemID)
+	if err != nil {
+		return err
+	}
+	if item == nil {
+		return domain.ErrCollectionItemNotFound
+	}
+	if note != nil {
+		if err := s.repo.UpdateCollectionItemNote(itemID, strings.TrimSpace(*note)); err != nil {
+			return err
+		}
+	}
+	if position != nil {
+		return s.repo.MoveCollectionItem(item, max(0, min(*position, collection.ItemsCount-1)))
+	}
+	return nil
+}
+
+func (s *CollectionService) RemoveItem(userID uint, role string, id, itemID uint) error {
+	if _, err := s.loadForEdit(collectionCaller{userID: userID, admin: role == "admin"}, id); err != nil {
+		return err
+	}
+	item, err := s.repo.GetCollectionItem(id, itemID)
+	if err != nil {
+		return err
+	}
+	if item == nil {
+		return domain.ErrCollectionItemNotFound
+	}
+	return s.repo.RemoveCollectionItem(item)
+}
+
+// Reorder sets the order of all items, e.g. after a drag and drop
+func (s *CollectionService) Reorder(userID uint, role string, id uint, itemIDs []uint) error {
+	if _, err := s.loadForEdit(collectionCaller{userID: userID, admin: role == "admin"}, id); err != nil {
+		return err
+	}
+	return s.repo.ReorderCollectionItems(id, itemIDs)
+}
+
+func (s *CollectionService) AddCollaborator(userID uint, role string, id, collaboratorID uint) (*domain.CollectionCollaborator, error) {
+	collection, err := s.loadForOwner(collectionCaller{userID: userID, admin: role == "admin"}, id)
+	if err != nil {
+		return nil, err
+	}
+	if collaboratorID == collection.UserID {
+		return nil, domain.ErrInvalidCollaborator
+	}
+	for _, existing := range collection.Collaborators {
+		if existing.UserID == collaboratorID {
+			return nil, domain.ErrInvalidCollaborator
+		}
+	}
+	user, err := s.repo.GetUserByID(collaboratorID)
+	if err != nil || user == nil {
+		return nil, domain.ErrInvalidCollaborator
+	}
+	collaborator := &domain.CollectionCollaborator{CollectionID: id, UserID: collaboratorID, AddedBy: userID}
+	if err := s.repo.AddCollectionCollaborator(collaborator); err != nil {
+		return nil, err
+	}
+	collaborator.User = &domain.User{ID: user.ID, Name: user.Name, Avatar: user.Avatar}
+	return collaborator, nil
+}
+
+// RemoveCollaborator is allowed to the owner and to collaborators leaving on their own
+func (s *CollectionService) RemoveCollaborator(userID uint, role string, id, collaboratorID uint) error {
+	caller := collectionCaller{userID: userID, admin: role == "admin"}
+	if collaboratorID != userID {
+		if _, err := s.loadForOwner(caller, id); err != nil {
+			return err
+		}
+	} else if _, err := s.load(caller, strconv.FormatUint(uint64(id), 10)); err != nil {
+		return err
+	}
+	removed, err := s.repo.RemoveCollectionCollaborator(id, collaboratorID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return domain.ErrInvalidCollaborator
+	}
+	return nil
+}
+
+// Follow follows or unfollows a collection the user can see, by ID or share slug
+func (s *CollectionService) Follow(userID uint, role string, ref string, follow bool) error {
+	collection, err := s.load(collectionCaller{userID: userID, admin: role == "admin"}, ref)
+	if err != nil {
+		return err
+	}
+	return s.repo.SetCollectionFollow(collection.ID, userID, follow)
+}
+
+// Like likes or unlikes a collection the user can see, by ID or share slug
+func (s *CollectionService) Like(userID uint, role string, ref string, like bool) error {
+	collection, err := s.load(collectionCaller{userID: userID, admin: role == "admin"}, ref)
+	if err != nil {
+		return err
+	}
+	return s.repo.SetCollectionLike(collection.ID, userID, like)
+}
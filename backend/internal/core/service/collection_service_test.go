@@ -0,0 +1,182 @@
+package service
+
+import (
+	"backend/internal/adapters/repository"
+	"backend/internal/core/domain"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+// Users of the collection tests
+const (
+	collectionOwner uint = iota + 1
+	collectionCollaborator
+	collectionStranger
+)
+
+func newCollectionTestService(t *testing.T) (*CollectionService, *repository.SQLiteRepository) {
+	t.Helper()
+	repo := newProgressTestRepo(t)
+	for _, name := range []string{"owner", "collaborator", "stranger"} {
+		if err := repo.DB().Create(&domain.User{Name: name, Email: name + "@example.com", Password: "x"}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewCollectionService(repo), repo
+}
+
+func createCollection(t *testing.T, s *CollectionService, visibility domain.CollectionVisibility) *domain.Collection {
+	t.Helper()
+	title := string(visibility) + " picks"
+	collection, err := s.Create(collectionOwner, CollectionInput{Title: &title, Visibility: &visibility})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.AddCollaborator(collectionOwner, "", collection.ID, collectionCollaborator); err != nil {
+		t.Fatal(err)
+	}
+	return collection
+}
+
+func TestCollectionVisibility(t *testing.T) {
+	s, _ := newCollectionTestService(t)
+	type viewer struct {
+		name   string
+		userID uint
+		role   string
+	}
+	viewers := []viewer{
+		{"owner", collectionOwner, ""},
+		{"collaborator", collectionCollaborator, ""},
+		{"admin", collectionStranger, "admin"},
+		{"stranger", collectionStranger, ""},
+		{"guest", 0, ""},
+	}
+	tests := []struct {
+		visibility domain.CollectionVisibility
+		byID       map[string]bool // Viewers who see the collection by ID
+		bySlug     map[string]bool // and by share slug
+	}{
+		{
+			visibility: domain.CollectionPublic,
+			byID:       map[string]bool{"owner": true, "collaborator": true, "admin": true, "stranger": true, "guest": true},
+			bySlug:     map[string]bool{"owner": true, "collaborator": true, "admin": true, "stranger": true, "guest": true},
+		},
+		{
+			visibility: domain.CollectionUnlisted,
+			byID:       map[string]bool{"owner": true, "collaborator": true, "admin": true},
+			bySlug:     map[string]bool{"owner": true, "collaborator": true, "admin": true, "stranger": true, "guest": true},
+		},
+		{
+			visibility: domain.CollectionPrivate,
+			byID:       map[string]bool{"owner": true, "collaborator": true, "admin": true},
+			bySlug:     map[string]bool{"owner": true, "collaborator": true, "admin": true},
+		},
+	}
+	for _, tt := range tests {
+		collection := createCollection(t, s, tt.visibility)
+		for _, v := range viewers {
+			for ref, want := range map[string]bool{
+				strconv.FormatUint(uint64(collection.ID), 10): tt.byID[v.name],
+				collection.Slug: tt.bySlug[v.name],
+			} {
+				_, err := s.Get(v.userID, v.role, ref)
+				if want && err != nil {
+					t.Errorf("%s %s collection by %q: %v", v.name, tt.visibility, ref, err)
+				}
+				if !want && !errors.Is(err, domain.ErrCollectionNotFound) {
+					t.Errorf("%s %s collection by %q = %v, want ErrCollectionNotFound", v.name, tt.visibility, ref, err)
+				}
+			}
+		}
+	}
+}
+
+func TestCollectionRegeneratedSlugRevokesLinks(t *testing.T) {
+	s, _ := newCollectionTestService(t)
+	collection := createCollection(t, s, domain.CollectionUnlisted)
+
+	slug, err := s.RegenerateSlug(collectionOwner, "", collection.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get(collectionStranger, "", collection.Slug); !errors.Is(err, domain.ErrCollectionNotFound) {
+		t.Fatalf("old link = %v, want ErrCollectionNotFound", err)
+	}
+	if _, err := s.Get(collectionStranger, "", slug); err != nil {
+		t.Fatalf("new link: %v", err)
+	}
+	if _, err := s.RegenerateSlug(collectionCollaborator, "", collection.ID); !errors.Is(err, domain.ErrCollectionForbidden) {
+		t.Fatalf("collaborator regenerating the slug = %v, want ErrCollectionForbidden", err)
+	}
+
+	// Following by link works, but the follow does not list a private collection
+	if err := s.Follow(collectionStranger, "", slug, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, total, err := s.Mine(collectionStranger, "followed", 10, 0); err != nil || total != 1 {
+		t.Fatalf("followed unlisted collections = %d, %v; want 1", total, err)
+	}
+	private := domain.CollectionPrivate
+	if _, err := s.Update(collectionOwner, "", collection.ID, CollectionInput{Visibility: &private}); err != nil {
+		t.Fatal(err)
+	}
+	if _, total, err := s.Mine(collectionStranger, "followed", 10, 0); err != nil || total != 0 {
+		t.Fatalf("followed collections after it turned private = %d, %v; want 0", total, err)
+	}
+}
+
+func TestCollectionItems(t *testing.T) {
+	s, repo := newCollectionTestService(t)
+	collection := createCollection(t, s, domain.CollectionPublic)
+	first, _ := seedAnime(t, repo, domain.ContentStatePublished, 0)
+	second, _ := seedAnime(t, repo, domain.ContentStatePublished, 0)
+	draft, _ := seedAnime(t, repo, domain.ContentStateDraft, 0)
+
+	if _, err := s.AddItem(collectionCollaborator, "", collection.ID, domain.CollectionItemAnime, first, ""); err != nil {
+		t.Fatal(err)
+	}
+	added, err := s.AddItem(collectionOwner, "", collection.ID, domain.CollectionItemAnime, second, " top ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.AddItem(collectionOwner, "", collection.ID, domain.CollectionItemAnime, draft, ""); !errors.Is(err, domain.ErrCollectionItemNotFound) {
+		t.Fatalf("adding a draft anime = %v, want ErrCollectionItemNotFound", err)
+	}
+	if _, err := s.AddItem(collectionStranger, "", collection.ID, domain.CollectionItemAnime, first, ""); !errors.Is(err, domain.ErrCollectionForbidden) {
+		t.Fatalf("stranger adding an item = %v, want ErrCollectionForbidden", err)
+	}
+
+	top := 0
+	if err := s.UpdateItem(collectionCollaborator, "", collection.ID, added.ID, &top, nil); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.Get(0, "", collection.Slug)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Items) != 2 || got.Items[0].ItemID != second || got.Items[0].Note != "top" {
+		t.Fatalf("items = %+v, want the moved anime first with its trimmed note", got.Items)
+	}
+
+	// An anime unpublished later stays in the collection but is not shown
+	if err := repo.DB().Model(&domain.Anime{}).Where("id = ?", first).UpdateColumn("state", domain.ContentStateDraft).Error; err != nil {
+		t.Fatal(err)
+	}
+	got, err = s.Get(0, "", collection.Slug)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Items) != 1 || got.Items[0].ItemID != second {
+		t.Fatalf("items = %+v, want only the published anime", got.Items)
+	}
+
+	browsed, _, err := s.Browse(domain.CollectionFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(browsed) != 1 || browsed[0].ID != collection.ID {
+		t.Fatalf("browse = %+v, want the public collection", browsed)
+	}
+}
@@ -3,38 +3,197 @@ package service
 import (
 	"backend/internal/adapters/repository"
 	"backend/internal/core/domain"
+	"encoding/json"
+	"errors"
+	"time"
 )
 
+var ErrEpisodeNotFound = errors.New("episode not found")
+
 type EpisodeService struct {
-	repo *repository.SQLiteRepository
+	repo       *repository.SQLiteRepository
+	embedHosts []string // Hosts allowed for embed sources
+	media      *MediaService
+}
+
+func NewEpisodeService(repo *repository.SQLiteRepository, embedHosts []string, media *MediaService) *EpisodeService {
+	return &EpisodeService{repo: repo, embedHosts: embedHosts, media: media}
 }
 
-func NewEpisodeService(repo *repository.SQLiteRepository) *EpisodeService {
-	return &EpisodeService{repo: repo}
+// trackImages records the stored thumbnail and banner files, releasing replaced ones
+func (s *EpisodeService) trackImages(episode *domain.Episode) {
+	s.media.track(domain.MediaOwnerEpisode, episode.ID, map[string]string{"thumbnail": episode.Thumbnail, "banner": episode.Banner})
 }
 
-func (s *EpisodeService) Create(episode *domain.Episode) error {
-	return s.repo.CreateEpisode(episode)
+// Create saves a new episode in the given workflow state
+func (s *EpisodeService) Create(episode *domain.Episode, state domain.ContentState, userID uint) error {
+	episode.ID = 0
+	episode.State = state
+	episode.IsPublished = state == domain.ContentStatePublished
+	servers := episode.Servers
+	if servers == nil {
+		servers = []domain.EpisodeServer{}
+	}
+	if err := normalizeSources(servers, nil, s.embedHosts); err != nil {
+		return err
+	}
+	episode.VideoURLs = legacyVideoURLs(servers)
+	snapshot, err := episodeSnapshot(episode)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.SaveEpisodeWithServers(episode, servers, newRevision(domain.RevisionActionCreate, userID, snapshot)); err != nil {
+		return err
+	}
+	s.trackImages(episode)
+	return nil
 }
 
-func (s *EpisodeService) GetAll() ([]domain.Episode, error) {
-	return s.repo.GetAllEpisodes()
+// GetAll lists published episodes of published anime, or every episode when
+// includeUnpublished is set for staff screens
+func (s *EpisodeService) GetAll(includeUnpublished bool) ([]domain.Episode, error) {
+	if includeUnpublished {
+		return s.repo.GetAllEpisodes()
+	}
+	return s.repo.GetPublishedEpisodes()
 }
 
 func (s *EpisodeService) GetLatest(limit int) ([]domain.Episode, error) {
 	return s.repo.GetLatestEpisodes(limit)
 }
 
-func (s *EpisodeService) GetByID(id uint) (*domain.Episode, error) {
-	return s.repo.GetEpisodeByID(id)
+// GetByID returns a published episode, or one in any state when includeUnpublished is set
+func (s *EpisodeService) GetByID(id uint, includeUnpublished bool) (*domain.Episode, error) {
+	if includeUnpublished {
+		return s.repo.GetEpisodeByID(id)
+	}
+	return s.repo.GetPublishedEpisodeByID(id)
 }
 
-func (s *EpisodeService) GetByAnimeID(animeID uint) ([]domain.Episode, error) {
+func (s *EpisodeService) GetByAnimeID(animeID uint, includeUnpublished bool) ([]domain.Episode, error) {
+	if includeUnpublished {
+		return s.repo.GetAllEpisodesByAnimeID(animeID)
+	}
 	return s.repo.GetEpisodesByAnimeID(animeID)
 }
 
-func (s *EpisodeService) Update(episode *domain.Episode) error {
-	return s.repo.UpdateEpisode(episode)
+// Update replaces the episode and its servers. episode.Version must be the stored version:
+// domain.ErrVersionRequired when it is zero, domain.ErrVersionConflict when it is older.
+func (s *EpisodeService) Update(episode *domain.Episode, userID uint) (*domain.Episode, error) {
+	return s.update(episode, newRevision(domain.RevisionActionUpdate, userID, nil))
+}
+
+func (s *EpisodeService) update(episode *domain.Episode, revision *domain.Revision) (*domain.Episode, error) {
+	existing, err := s.repo.GetEpisodeByID(episode.ID)
+	if err != nil {
+		return nil, ErrEpisodeNotFound
+	}
+	if episode.Version == 0 {
+		return nil, domain.ErrVersionRequired
+	}
+	if episode.Version != existing.Version {
+		return nil, domain.ErrVersionConflict
+	}
+	if err := recordBaseline(s.repo, domain.RevisionEntityEpisode, existing.ID, existing.Version, func() (json.RawMessage, error) {
+		return episodeSnapshot(existing)
+	}); err != nil {
+		return nil, err
+	}
+
+	episode.Anime = domain.Anime{}
+	episode.State = existing.State
+	episode.IsPublished = existing.State == domain.ContentStatePublished
+	episode.Version = existing.Version
+	episode.CreatedAt = existing.CreatedAt
+	episode.UpdatedAt = time.Now()
+	servers := episode.Servers
+	if servers == nil {
+		servers = []domain.EpisodeServer{}
+	}
+	if err := normalizeSources(servers, existing.Servers, s.embedHosts); err != nil {
+		return nil, err
+	}
+	episode.Servers = servers
+	episode.VideoURLs = legacyVideoURLs(servers)
+
+	if revision.Snapshot, err = episodeSnapshot(episode); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SaveEpisodeWithServers(episode, servers, revision); err != nil {
+		return nil, err
+	}
+	s.trackImages(episode)
+	return s.repo.GetEpisodeByID(episode.ID)
+}
+
+// OrderSources attaches the latest health to each episode's servers and sorts them
+// by the caller's locale chain, broken servers last
+func (s *EpisodeService) OrderSources(locales []string, episodes ...*domain.Episode) error {
+	var urls []string
+	for _, episode := range episodes {
+		for _, sv := range episode.Servers {
+			urls = append(urls, sv.URL)
+		}
+	}
+	healths, err := s.repo.GetServerHealths(urls)
+	if err != nil {
+		return err
+	}
+	for _, episode := range episodes {
+		for i := range episode.Servers {
+			episode.Servers[i].Health = healths[episode.Servers[i].URL]
+		}
+		orderSources(locales, episode.Servers)
+	}
+	return nil
+}
+
+// Revisions lists the episode's revisions, newest first
+func (s *EpisodeService) Revisions(id uint) ([]domain.Revision, error) {
+	return s.repo.GetRevisions(domain.RevisionEntityEpisode, id)
+}
+
+func (s *EpisodeService) Revision(id uint, version int) (*domain.Revision, error) {
+	revision, err := s.repo.GetRevision(domain.RevisionEntityEpisode, id, version)
+	if err != nil {
+		return nil, ErrRevisionNotFound
+	}
+	return revision, nil
+}
+
+// Diff compares two revisions field by field
+func (s *EpisodeService) Diff(id uint, from, to int) (*domain.RevisionDiff, error) {
+	a, err := s.Revision(id, from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.Revision(id, to)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := diffSnapshots(a.Snapshot, b.Snapshot)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.RevisionDiff{EntityType: domain.RevisionEntityEpisode, EntityID: id, From: from, To: to, Changes: changes}, nil
+}
+
+// Rollback restores the fields and servers of an earlier revision as a new revision
+func (s *EpisodeService) Rollback(id uint, version int, currentVersion int, userID uint) (*domain.Episode, error) {
+	target, err := s.Revision(id, version)
+	if err != nil {
+		return nil, err
+	}
+	var episode domain.Episode
+	if err := json.Unmarshal(target.Snapshot, &episode); err != nil {
+		return nil, err
+	}
+	episode.ID = id
+	episode.Version = currentVersion
+
+	revision := newRevision(domain.RevisionActionRollback, userID, nil)
+	revision.RollbackOf = &version
+	return s.update(&episode, revision)
 }
 
 func (s *EpisodeService) Delete(id uint) error {
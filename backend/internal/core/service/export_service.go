@@ -2,6 +2,7 @@ package service
 
 import (
 	"backend/internal/core/domain"
+	"backend/internal/core/port"
 	"context"
 	"fmt"
 	"io"
@@ -13,17 +14,24 @@ import (
 	"time"
 )
 
+// exportBlobDir is where finished exports are published in the blob store
+const exportBlobDir = "exports"
+
 type ExportService struct {
 	blenderPath   string
 	exportDir     string
 	exportTimeout int // in seconds
+	blobs         port.BlobStore
+	presign       time.Duration
 }
 
-func NewExportService(blenderPath, exportDir string, timeout int) *ExportService {
+func NewExportService(blenderPath, exportDir string, timeout int, blobs port.BlobStore, presign time.Duration) *ExportService {
 	return &ExportService{
 		blenderPath:   blenderPath,
 		exportDir:     exportDir,
 		exportTimeout: timeout,
+		blobs:         blobs,
+		presign:       presign,
 	}
 }
 
@@ -79,7 +87,7 @@ func (s *ExportService) RetargetAndExport(characterFile, animationFile *multipar
 
 	// 4. Prepare output path
 	outputFilename := fmt.Sprintf("export_%s.fbx", timestamp)
-	outputPath := filepath.Join(s.exportDir, outputFilename)
+	outputPath := filepath.Join(tempDir, outputFilename)
 
 	// 5. Execute Blender script
 	fmt.Println("Executing Blender...")
@@ -97,16 +105,24 @@ func (s *ExportService) RetargetAndExport(characterFile, animationFile *multipar
 
 	fmt.Printf("Export success! File: %s, Size: %d\n", outputFilename, info.Size())
 
-	// 7. Return result
-	result := &domain.ExportResult{
+	// 7. Publish and return result
+	return s.publish(outputPath, outputFilename, info.Size())
+}
+
+// publish moves a finished export into the blob store so any replica can serve it
+func (s *ExportService) publish(outputPath, outputFilename string, size int64) (*domain.ExportResult, error) {
+	key := exportBlobDir + "/" + outputFilename
+	if err := putFile(s.blobs, outputPath, key); err != nil {
+		os.Remove(outputPath)
+		return nil, fmt.Errorf("failed to store export: %w", err)
+	}
+	return &domain.ExportResult{
 		Filename:    outputFilename,
-		FilePath:    outputPath,
-		FileSize:    info.Size(),
+		FilePath:    key,
+		FileSize:    size,
 		DownloadURL: fmt.Sprintf("/api/export/download/%s", outputFilename),
 		CreatedAt:   time.Now(),
-	}
-
-	return result, nil
+	}, nil
 }
 
 // validateFile checks if the file is valid for export
@@ -255,7 +271,7 @@ func (s *ExportService) AutoRigCharacter(characterFile *multipart.FileHeader) (r
 
 	characterPath := filepath.Join(tempDir, fmt.Sprintf("char_%s_%s", timestamp, characterFile.Filename))
 	outputFilename := fmt.Sprintf("autorig_%s.fbx", timestamp)
-	outputPath := filepath.Join(s.exportDir, outputFilename)
+	outputPath := filepath.Join(tempDir, outputFilename)
 
 	// Save file
 	if err := s.saveFile(characterFile, characterPath); err != nil {
@@ -273,14 +289,11 @@ func (s *ExportService) AutoRigCharacter(characterFile *multipart.FileHeader) (r
 		return nil, err
 	}
 
-	info, _ := os.Stat(outputPath)
-	return &domain.ExportResult{
-		Filename:    outputFilename,
-		FilePath:    outputPath,
-		FileSize:    info.Size(),
-		DownloadURL: fmt.Sprintf("/api/export/download/%s", outputFilename),
-		CreatedAt:   time.Now(),
-	}, nil
+	info, err := os.Stat(outputPath)
+	if err != nil {
+		return nil, fmt.Errorf("export failed: output file not created")
+	}
+	return s.publish(outputPath, outputFilename, info.Size())
 }
 
 // GenerateSprintAnimation adds procedural sprint animation
@@ -301,7 +314,7 @@ func (s *ExportService) GenerateSprintAnimation(characterFile *multipart.FileHea
 
 	characterPath := filepath.Join(tempDir, fmt.Sprintf("char_%s_%s", timestamp, characterFile.Filename))
 	outputFilename := fmt.Sprintf("sprint_%s.fbx", timestamp)
-	outputPath := filepath.Join(s.exportDir, outputFilename)
+	outputPath := filepath.Join(tempDir, outputFilename)
 
 	if err := s.saveFile(characterFile, characterPath); err != nil {
 		return nil, err
@@ -318,14 +331,11 @@ func (s *ExportService) GenerateSprintAnimation(characterFile *multipart.FileHea
 		return nil, err
 	}
 
-	info, _ := os.Stat(outputPath)
-	return &domain.ExportResult{
-		Filename:    outputFilename,
-		FilePath:    outputPath,
-		FileSize:    info.Size(),
-		DownloadURL: fmt.Sprintf("/api/export/download/%s", outputFilename),
-		CreatedAt:   time.Now(),
-	}, nil
+	info, err := os.Stat(outputPath)
+	if err != nil {
+		return nil, fmt.Errorf("export failed: output file not created")
+	}
+	return s.publish(outputPath, outputFilename, info.Size())
 }
 
 // Shared helper for generic scripts
@@ -374,18 +384,18 @@ func (s *ExportService) runBlenderScript(scriptRelativePath, inputPath, outputPa
 	return nil
 }
 
-// GetExportFile returns the full path to an exported file
-func (s *ExportService) GetExportFile(filename string) (string, error) {
+// DownloadURL returns a link that downloads an exported file
+func (s *ExportService) DownloadURL(filename string) (string, error) {
 	// Security check: prevent directory traversal
 	if strings.Contains(filename, "..") || strings.Contains(filename, "/") || strings.Contains(filename, "\\") {
 		return "", fmt.Errorf("invalid filename")
 	}
 
-	path := filepath.Join(s.exportDir, filename)
-	if _, err := os.Stat(path); err != nil {
+	key := exportBlobDir + "/" + filename
+	if _, err := s.blobs.Stat(key); err != nil {
 		return "", err
 	}
-	return path, nil
+	return s.blobs.URL(key, filename, s.presign)
 }
 
 // getLastNLines returns the last N lines of a string
@@ -458,28 +468,22 @@ func (s *ExportService) resolveScriptPath(scriptName string) (string, error) {
 	return "", fmt.Errorf("script '%s' not found. Searched in: %v", scriptName, possiblePaths)
 }
 
-// CleanupOldExports removes export files older than specified duration
+// CleanupOldExports removes published exports older than specified duration
 func (s *ExportService) CleanupOldExports(maxAge time.Duration) error {
-	entries, err := os.ReadDir(s.exportDir)
+	now := time.Now()
+	var old []string
+	err := s.blobs.List(exportBlobDir+"/", func(info domain.BlobInfo) error {
+		if now.Sub(info.ModTime) > maxAge {
+			old = append(old, info.Key)
+		}
+		return nil
+	})
 	if err != nil {
 		return err
 	}
 
-	now := time.Now()
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-
-		info, err := entry.Info()
-		if err != nil {
-			continue
-		}
-
-		if now.Sub(info.ModTime()) > maxAge {
-			filePath := filepath.Join(s.exportDir, entry.Name())
-			os.Remove(filePath)
-		}
+	for _, key := range old {
+		s.blobs.Delete(key)
 	}
 
 	return nil
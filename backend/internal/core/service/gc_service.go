@@ -0,0 +1,244 @@
+package service
+
+import (
+	"backend/internal/adapters/repository"
+	"backend/internal/core/domain"
+	"fmt"
+	"log"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// gcSkipPrefixes are blob prefixes with their own cleanup: resumable uploads
+// expire in TusService and exports in ExportService.CleanupOldExports
+var gcSkipPrefixes = []string{TusBlobPrefix, "exports/", domain.GCQuarantinePrefix}
+
+// gcMaxListedOrphans caps the orphan keys listed in a report
+const gcMaxListedOrphans = 500
+
+// uploadRefPattern finds upload paths in column values, including JSON ones.
+// Paths may contain spaces, so a match only stops at quotes, queries and fragments.
+var uploadRefPattern = regexp.MustCompile(`uploads/[^"'?#\\]+`)
+
+// GCService finds uploads no row references any more, e.g. images uploaded but
+// never saved on a row or originals left behind by ImportLegacy. Orphans older
+// than the grace period are moved to quarantine and deleted after the retention
+// period, so a wrong call can still be undone with Restore.
+type GCService struct {
+	repo      *repository.SQLiteRepository
+	media     *MediaService
+	grace     time.Duration
+	retention time.Duration
+}
+
+func NewGCService(repo *repository.SQLiteRepository, media *MediaService, grace, retention time.Duration) *GCService {
+	return &GCService{repo: repo, media: media, grace: grace, retention: retention}
+}
+
+// Run collects orphaned uploads and purges expired quarantine. A dry run only
+// reports what would happen.
+func (s *GCService) Run(dryRun bool) (*domain.GCReport, error) {
+	report := &domain.GCReport{DryRun: dryRun, StartedAt: time.Now()}
+	cutoff := report.StartedAt.Add(-s.grace)
+
+	keys, dirs, err := s.referenced()
+	if err != nil {
+		return nil, err
+	}
+
+	var orphans []domain.BlobInfo
+	err = s.media.Blobs().List("", func(info domain.BlobInfo) error {
+		for _, prefix := range gcSkipPrefixes {
+			if strings.HasPrefix(info.Key, prefix) {
+				return nil
+			}
+		}
+		report.Scanned++
+		switch {
+		case keys[info.Key] || dirs[path.Dir(info.Key)]:
+			report.Referenced++
+		case info.ModTime.After(cutoff):
+			report.Young++
+		default:
+			orphans = append(orphans, info)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, info := range orphans {
+		if !dryRun {
+			quarantined, err := s.quarantine(info, cutoff)
+			if err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", info.Key, err))
+				continue
+			}
+			if !quarantined {
+				report.Young++ // Stored again during the run
+				continue
+			}
+		}
+		report.Quarantined++
+		report.QuarantinedBytes += info.Size
+		if len(report.Orphans) < gcMaxListedOrphans {
+			report.Orphans = append(report.Orphans, info.Key)
+		}
+	}
+
+	if err := s.purgeQuarantine(report); err != nil {
+		return nil, err
+	}
+	report.FinishedAt = time.Now()
+	return report, nil
+}
+
+// referenced returns the blob keys rows point at and the variant directories of
+// referenced images
+func (s *GCService) referenced() (map[string]bool, map[string]bool, error) {
+	values, err := s.repo.GetUploadRefs()
+	if err != nil {
+		return nil, nil, err
+	}
+	keys := map[string]bool{}
+	for _, value := range values {
+		for _, ref := range uploadRefPattern.FindAllString(value, -1) {
+			ref = strings.TrimSpace(ref)
+			keys[blobKey(ref)] = true
+			if unescaped, err := url.PathUnescape(ref); err == nil {
+				keys[blobKey(unescaped)] = true
+			}
+		}
+	}
+
+	sources, err := s.repo.GetImageAssetSources()
+	if err != nil {
+		return nil, nil, err
+	}
+	dirs := map[string]bool{}
+	for _, source := range sources {
+		if keys[blobKey(source)] {
+			dirs[blobKey(variantDir(source))] = true
+		}
+	}
+	return keys, dirs, nil
+}
+
+// quarantine moves an orphan under GCQuarantinePrefix. Stored media is only
+// moved once the store claimed its row, so content stored again during the run,
+// on any replica, is kept. It reports whether the blob was moved.
+func (s *GCService) quarantine(info domain.BlobInfo, cutoff time.Time) (bool, error) {
+	move := func() error {
+		return s.moveBlob(info.Key, domain.GCQuarantinePrefix+info.Key)
+	}
+	if id, ok := domain.MediaIDFromURL("uploads/" + info.Key); ok {
+		moved, err := s.media.RemoveOrphan(id, info.Key, cutoff, move)
+		if err != nil || !moved {
+			return false, err
+		}
+	} else if err := move(); err != nil {
+		return false, err
+	}
+	// Its variants are orphaned with it; drop the record so they are not served
+	if err := s.repo.DeleteImageAsset("uploads/" + info.Key); err != nil {
+		log.Printf("GC: failed to drop image variants of %s: %v", info.Key, err)
+	}
+	return true, nil
+}
+
+// purgeQuarantine deletes quarantined blobs older than the retention period
+func (s *GCService) purgeQuarantine(report *domain.GCReport) error {
+	cutoff := report.StartedAt.Add(-s.retention)
+	var expired []domain.BlobInfo
+	err := s.media.Blobs().List(domain.GCQuarantinePrefix, func(info domain.BlobInfo) error {
+		if info.ModTime.Before(cutoff) {
+			expired = append(expired, info)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, info := range expired {
+		if !report.DryRun {
+			if err := s.media.Blobs().Delete(info.Key); err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", info.Key, err))
+				continue
+			}
+		}
+		report.Purged++
+		report.ReclaimedBytes += info.Size
+	}
+	return nil
+}
+
+// Restore moves a quarantined blob back to its key. Stored media is stored
+// again so its row is recreated, then references are recounted.
+func (s *GCService) Restore(key string) error {
+	key = strings.TrimPrefix(key, domain.GCQuarantinePrefix)
+	quarantined := domain.GCQuarantinePrefix + key
+	if _, ok := domain.MediaIDFromURL("uploads/" + key); !ok {
+		return s.moveBlob(quarantined, key)
+	}
+
+	r, err := s.media.Blobs().Get(quarantined)
+	if err != nil {
+		return err
+	}
+	media, err := s.media.Store(r, path.Ext(key))
+	r.Close()
+	if err != nil {
+		return err
+	}
+	if blobKey(media.Path) != key {
+		return fmt.Errorf("restored content of %s hashes to %s", key, media.ID)
+	}
+	if err := s.media.Blobs().Delete(quarantined); err != nil {
+		return err
+	}
+	return s.media.Reconcile()
+}
+
+// moveBlob copies a blob to a new key and deletes the original
+func (s *GCService) moveBlob(from, to string) error {
+	r, err := s.media.Blobs().Get(from)
+	if err != nil {
+		return err
+	}
+	info, err := s.media.Blobs().Stat(from)
+	if err != nil {
+		r.Close()
+		return err
+	}
+	err = s.media.Blobs().Put(to, r, info.Size, info.ContentType)
+	r.Close()
+	if err != nil {
+		return err
+	}
+	return s.media.Blobs().Delete(from)
+}
+
+// RunCollection collects orphaned uploads every interval until the process exits
+func (s *GCService) RunCollection(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		<-ticker.C
+		report, err := s.Run(false)
+		if err != nil {
+			log.Printf("GC: %v", err)
+			continue
+		}
+		if report.Quarantined > 0 || report.Purged > 0 || len(report.Errors) > 0 {
+			log.Printf("GC: quarantined %d orphaned uploads (%d bytes), purged %d (%d bytes reclaimed), %d errors",
+				report.Quarantined, report.QuarantinedBytes, report.Purged, report.ReclaimedBytes, len(report.Errors))
+		}
+	}
+}
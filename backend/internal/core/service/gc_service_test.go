@@ -0,0 +1,211 @@
+package service
+
+import (
+	"backend/internal/adapters/repository"
+	"backend/internal/adapters/storage"
+	"backend/internal/core/domain"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newGCTestService returns a collector without a grace period over a local
+// store rooted at the returned directory
+func newGCTestService(t *testing.T) (*GCService, *repository.SQLiteRepository, string) {
+	t.Helper()
+	repo := newProgressTestRepo(t)
+	root := t.TempDir()
+	media := NewMediaService(repo, storage.NewLocalStore(root), 0)
+	return NewGCService(repo, media, 0, 24*time.Hour), repo, root
+}
+
+func putBlob(t *testing.T, s *GCService, key string, age time.Duration) {
+	t.Helper()
+	if err := s.media.Blobs().Put(key, strings.NewReader(key), int64(len(key)), ""); err != nil {
+		t.Fatal(err)
+	}
+	ageBlob(t, s, key, age)
+}
+
+// ageBlob sets the modification time of a local blob
+func ageBlob(t *testing.T, s *GCService, key string, age time.Duration) {
+	t.Helper()
+	file, err := s.media.Blobs().(*storage.LocalStore).File(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	when := time.Now().Add(-age)
+	if err := os.Chtimes(file, when, when); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func blobExists(t *testing.T, s *GCService, key string) bool {
+	t.Helper()
+	_, err := s.media.Blobs().Stat(key)
+	if err != nil && !errors.Is(err, domain.ErrBlobNotFound) {
+		t.Fatal(err)
+	}
+	return err == nil
+}
+
+func TestGCQuarantinesOrphans(t *testing.T) {
+	s, repo, _ := newGCTestService(t)
+	animeID, _ := seedAnime(t, repo, domain.ContentStatePublished, 0)
+	if err := repo.DB().Model(&domain.Anime{}).Where("id = ?", animeID).UpdateColumn("cover", "/uploads/covers/kept.jpg").Error; err != nil {
+		t.Fatal(err)
+	}
+	putBlob(t, s, "covers/kept.jpg", time.Hour)
+	putBlob(t, s, "covers/orphan.jpg", time.Hour)
+	putBlob(t, s, TusBlobPrefix+"upload.info", time.Hour)
+	s.grace = time.Minute
+	putBlob(t, s, "covers/young.jpg", 0)
+
+	report, err := s.Run(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Scanned != 3 || report.Referenced != 1 || report.Young != 1 || report.Quarantined != 1 {
+		t.Fatalf("dry run = %+v", report)
+	}
+	if !blobExists(t, s, "covers/orphan.jpg") {
+		t.Fatal("dry run moved the orphan")
+	}
+
+	report, err = s.Run(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Quarantined != 1 || len(report.Orphans) != 1 || report.Orphans[0] != "covers/orphan.jpg" {
+		t.Fatalf("run = %+v", report)
+	}
+	if blobExists(t, s, "covers/orphan.jpg") || !blobExists(t, s, domain.GCQuarantinePrefix+"covers/orphan.jpg") {
+		t.Fatal("orphan was not moved to quarantine")
+	}
+	for _, key := range []string{"covers/kept.jpg", "covers/young.jpg", TusBlobPrefix + "upload.info"} {
+		if !blobExists(t, s, key) {
+			t.Errorf("%s was collected", key)
+		}
+	}
+
+	// Quarantine is purged after the retention period
+	ageBlob(t, s, domain.GCQuarantinePrefix+"covers/orphan.jpg", 25*time.Hour)
+	report, err = s.Run(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Purged != 1 || blobExists(t, s, domain.GCQuarantinePrefix+"covers/orphan.jpg") {
+		t.Fatalf("expired quarantine was not purged: %+v", report)
+	}
+}
+
+func TestGCQuarantinesOrphanMediaOnlyOnceClaimed(t *testing.T) {
+	s, repo, _ := newGCTestService(t)
+	orphan, err := s.media.Store(strings.NewReader("orphan"), ".jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	busy, err := s.media.Store(strings.NewReader("busy"), ".jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Another replica is deleting the second file: it is left to that replica
+	if err := repo.DB().Model(&domain.Media{}).Where("id = ?", busy.ID).UpdateColumn("deleting", true).Error; err != nil {
+		t.Fatal(err)
+	}
+	ageBlob(t, s, blobKey(orphan.Path), time.Hour)
+	ageBlob(t, s, blobKey(busy.Path), time.Hour)
+
+	report, err := s.Run(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Quarantined != 1 || report.Young != 1 {
+		t.Fatalf("run = %+v, want the orphan quarantined and the claimed file skipped", report)
+	}
+	if !blobExists(t, s, domain.GCQuarantinePrefix+blobKey(orphan.Path)) {
+		t.Fatal("orphaned media was not quarantined")
+	}
+	if _, err := repo.GetMedia(orphan.ID); !errors.Is(err, domain.ErrMediaNotFound) {
+		t.Fatalf("row of quarantined media: %v, want it deleted", err)
+	}
+	if !blobExists(t, s, blobKey(busy.Path)) {
+		t.Fatal("file claimed by another replica was moved")
+	}
+}
+
+func TestGCMediaWithoutRowIsClaimedWhileMoved(t *testing.T) {
+	s, repo, _ := newGCTestService(t)
+	media, err := s.media.Store(strings.NewReader("left behind"), ".jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.DB().Delete(&domain.Media{}, "id = ?", media.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	moved, err := s.media.RemoveOrphan(media.ID, blobKey(media.Path), time.Now(), func() error {
+		// Storing the content meanwhile must wait for the move
+		if err := repo.SaveMedia(&domain.Media{ID: media.ID, Path: media.Path, URL: media.URL}); !errors.Is(err, domain.ErrMediaDeleting) {
+			t.Errorf("storing during the move = %v, want ErrMediaDeleting", err)
+		}
+		return nil
+	})
+	if err != nil || !moved {
+		t.Fatalf("RemoveOrphan = %v, %v", moved, err)
+	}
+	if _, err := repo.GetMedia(media.ID); !errors.Is(err, domain.ErrMediaNotFound) {
+		t.Fatalf("claim row left behind: %v", err)
+	}
+
+	// A failed move releases the claim
+	moved, err = s.media.RemoveOrphan(media.ID, blobKey(media.Path), time.Now(), func() error { return errors.New("copy failed") })
+	if err == nil || moved {
+		t.Fatalf("RemoveOrphan with a failing move = %v, %v", moved, err)
+	}
+	if got, err := repo.GetMedia(media.ID); err != nil || got.Deleting {
+		t.Fatalf("row after a failed move = %+v, %v; want it unclaimed", got, err)
+	}
+}
+
+func TestGCRestore(t *testing.T) {
+	s, repo, root := newGCTestService(t)
+	animeID, _ := seedAnime(t, repo, domain.ContentStatePublished, 0)
+	media, err := s.media.Store(strings.NewReader("cover"), ".jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ageBlob(t, s, blobKey(media.Path), time.Hour)
+	putBlob(t, s, "covers/plain.jpg", time.Hour)
+	if _, err := s.Run(false); err != nil {
+		t.Fatal(err)
+	}
+
+	// The quarantined file turned out to be in use
+	if err := repo.DB().Model(&domain.Anime{}).Where("id = ?", animeID).UpdateColumn("cover", media.URL).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Restore(domain.GCQuarantinePrefix + blobKey(media.Path)); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := repo.GetMedia(media.ID)
+	if err != nil || restored.RefCount != 1 {
+		t.Fatalf("restored media = %+v, %v; want its row back with the reference", restored, err)
+	}
+	if !blobExists(t, s, blobKey(media.Path)) || blobExists(t, s, domain.GCQuarantinePrefix+blobKey(media.Path)) {
+		t.Fatal("media was not moved back")
+	}
+
+	if err := s.Restore("covers/plain.jpg"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "covers", "plain.jpg")); err != nil {
+		t.Fatalf("plain upload was not moved back: %v", err)
+	}
+	if err := s.Restore("covers/missing.jpg"); !errors.Is(err, domain.ErrBlobNotFound) {
+		t.Fatalf("restoring a missing blob = %v, want ErrBlobNotFound", err)
+	}
+}
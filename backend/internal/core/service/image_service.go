@@ -0,0 +1,479 @@
+package service
+
+import (
+	"backend/internal/adapters/repository"
+	"backend/internal/core/domain"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"log"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	imageVariantDir = "uploads/variants"
+	// maxImageFileSize caps uploaded images before decoding
+	maxImageFileSize = 25 << 20
+	// maxOriginalSide caps the longest side originals are stored at
+	maxOriginalSide = 2560
+	// imageBackfillBatch caps the existing images processed per backfill round
+	imageBackfillBatch = 200
+)
+
+type ImageService struct {
+	repo  *repository.SQLiteRepository
+	media *MediaService
+}
+
+func NewImageService(repo *repository.SQLiteRepository, media *MediaService) *ImageService {
+	return &ImageService{repo: repo, media: media}
+}
+
+// imageSource maps an image reference as stored on a row ("/uploads/x.jpg",
+// "uploads/x.jpg" or a legacy localhost URL) to its upload path. ok is false for
+// remote URLs and for the generated variants themselves.
+func imageSource(ref string) (string, bool) {
+	ref = strings.TrimPrefix(strings.TrimSpace(ref), "http://localhost:8080")
+	ref = strings.TrimPrefix(ref, "/")
+	if !strings.HasPrefix(ref, "uploads/") || strings.HasPrefix(ref, imageVariantDir+"/") || strings.Contains(ref, "..") {
+		return "", false
+	}
+	return ref, true
+}
+
+// Store normalises an uploaded image, moves it into the media store and returns
+// it with its variants. Content that is already stored reuses its variants.
+func (s *ImageService) Store(r io.Reader, ext string) (*domain.Media, *domain.ImageAsset, error) {
+	tmp, err := s.media.TempFile(ext)
+	if err != nil {
+		return nil, nil, err
+	}
+	_, err = io.Copy(tmp, io.LimitReader(r, maxImageFileSize+1))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return nil, nil, err
+	}
+	return s.StoreFile(tmp.Name(), ext)
+}
+
+// StoreFile is Store for an image already on disk. The file is moved, or
+// deleted when it is not a valid image.
+func (s *ImageService) StoreFile(path, ext string) (*domain.Media, *domain.ImageAsset, error) {
+	if err := normalizeImage(path); err != nil {
+		os.Remove(path)
+		return nil, nil, err
+	}
+	media, err := s.media.StoreFile(path, ext)
+	if err != nil {
+		return nil, nil, err
+	}
+	assets, err := s.repo.GetImageAssets([]string{media.Path})
+	if err != nil {
+		return nil, nil, err
+	}
+	if asset := assets[media.Path]; asset != nil && asset.Error == "" {
+		return media, asset, nil
+	}
+	asset, err := s.Process(media.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return media, asset, nil
+}
+
+// normalizeImage rewrites the image at path upright, capped to maxOriginalSide
+// and re-encoded in its own format, which drops EXIF. Animated GIFs are kept as
+// is. Encoding is deterministic, so identical uploads still hash the same.
+func normalizeImage(path string) error {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if stat.Size() > maxImageFileSize {
+		return fmt.Errorf("%w: larger than %d MB", domain.ErrInvalidImage, maxImageFileSize>>20)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	img, format, err := decodeImage(data)
+	if err != nil || format == "gif" {
+		return err
+	}
+	b := img.Bounds()
+	if b.Dx() > maxOriginalSide || b.Dy() > maxOriginalSide {
+		width := maxOriginalSide
+		if b.Dy() > b.Dx() {
+			width = b.Dx() * maxOriginalSide / b.Dy()
+		}
+		img = resizeImage(img, width)
+	}
+	return rewriteOriginal(path, img, format)
+}
+
+// Process generates the variants of an existing image without touching it.
+// Failures are recorded so the backfill does not retry them.
+func (s *ImageService) Process(ref string) (*domain.ImageAsset, error) {
+	source, ok := imageSource(ref)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q is not a local upload", domain.ErrInvalidImage, ref)
+	}
+	data, err := s.readBlob(source)
+	if err == nil && len(data) > maxImageFileSize {
+		err = fmt.Errorf("%w: larger than %d MB", domain.ErrInvalidImage, maxImageFileSize>>20)
+	}
+	var img image.Image
+	if err == nil {
+		img, _, err = decodeImage(data)
+	}
+	if err != nil {
+		asset := &domain.ImageAsset{Source: source, Error: err.Error(), Variants: []domain.ImageVariant{}}
+		if saveErr := s.repo.SaveImageAsset(asset); saveErr != nil {
+			return nil, saveErr
+		}
+		return nil, err
+	}
+	return s.build(source, img)
+}
+
+// readBlob reads an upload, stopping just past maxImageFileSize
+func (s *ImageService) readBlob(source string) ([]byte, error) {
+	r, err := s.media.Blobs().Get(blobKey(source))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(io.LimitReader(r, maxImageFileSize+1))
+}
+
+// build writes the variants of img under uploads/variants/<hash of source>/ and
+// records them. WebP variants are lossless, so they are only kept when smaller
+// than the JPEG of the same width.
+func (s *ImageService) build(source string, img image.Image) (*domain.ImageAsset, error) {
+	dir := variantDir(source)
+	if err := s.removeVariants(dir); err != nil {
+		return nil, err
+	}
+
+	b := img.Bounds()
+	asset := &domain.ImageAsset{Source: source, Width: b.Dx(), Height: b.Dy()}
+	var jpegs, webps []domain.ImageVariant
+	for _, width := range variantWidths(b.Dx()) {
+		resized := resizeImage(img, width)
+		jpegData, err := encodeJPEG(resized, jpegQuality)
+		if err != nil {
+			return nil, err
+		}
+		jpegVariant, err := s.writeVariant(dir, width, resized.Bounds().Dy(), "jpeg", ".jpg", jpegData)
+		if err != nil {
+			return nil, err
+		}
+		jpegs = append(jpegs, jpegVariant)
+
+		webpData, err := encodeWebP(resized)
+		if err != nil {
+			return nil, err
+		}
+		if len(webpData) < len(jpegData) {
+			webpVariant, err := s.writeVariant(dir, width, resized.Bounds().Dy(), "webp", ".webp", webpData)
+			if err != nil {
+				return nil, err
+			}
+			webps = append(webps, webpVariant)
+		}
+	}
+	asset.Variants = append(jpegs, webps...)
+
+	uri, lqipData, err := lqip(img)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.putBlob(path.Join(dir, "lqip.jpg"), lqipData); err != nil {
+		return nil, err
+	}
+	asset.LQIP = uri
+	asset.BlurHash = blurHash(img)
+
+	if err := s.repo.SaveImageAsset(asset); err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
+
+// MiniBlurPath is where the LQIP of an ingested image is written, in the
+// relative form Model.MiniBlurPath uses
+func MiniBlurPath(asset *domain.ImageAsset) string {
+	return path.Join(variantDir(asset.Source), "lqip.jpg")
+}
+
+// variantDir is the directory holding the variants of an image source
+func variantDir(source string) string {
+	sum := sha1.Sum([]byte(source))
+	return path.Join(imageVariantDir, hex.EncodeToString(sum[:8]))
+}
+
+// variantWidths lists the variant widths for a source width, adding the source
+// width itself when it falls between two standard widths
+func variantWidths(sourceWidth int) []int {
+	var widths []int
+	for _, w := range domain.ImageVariantWidths {
+		if w < sourceWidth {
+			widths = append(widths, w)
+		}
+	}
+	if largest := domain.ImageVariantWidths[len(domain.ImageVariantWidths)-1]; sourceWidth <= largest {
+		widths = append(widths, sourceWidth)
+	}
+	return widths
+}
+
+func (s *ImageService) writeVariant(dir string, width, height int, format, ext string, data []byte) (domain.ImageVariant, error) {
+	file := path.Join(dir, fmt.Sprintf("%d%s", width, ext))
+	if err := s.putBlob(file, data); err != nil {
+		return domain.ImageVariant{}, err
+	}
+	return domain.ImageVariant{
+		Width:  width,
+		Height: height,
+		Format: format,
+		URL:    "/" + file,
+		Size:   int64(len(data)),
+	}, nil
+}
+
+func (s *ImageService) putBlob(file string, data []byte) error {
+	return s.media.Blobs().Put(blobKey(file), bytes.NewReader(data), int64(len(data)), mime.TypeByExtension(path.Ext(file)))
+}
+
+// removeVariants deletes the variants previously generated into dir
+func (s *ImageService) removeVariants(dir string) error {
+	var keys []string
+	err := s.media.Blobs().List(blobKey(dir)+"/", func(info domain.BlobInfo) error {
+		keys = append(keys, info.Key)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := s.media.Blobs().Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rewriteOriginal replaces the file at path with img encoded in format
+func rewriteOriginal(path string, img image.Image, format string) error {
+	var data []byte
+	var err error
+	switch format {
+	case "jpeg":
+		data, err = encodeJPEG(img, 90)
+	case "webp":
+		data, err = encodeWebP(img)
+	default:
+		var buf bytes.Buffer
+		err = png.Encode(&buf, img)
+		data = buf.Bytes()
+	}
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Responsive returns the srcset-ready variants of each processed reference
+func (s *ImageService) Responsive(refs ...string) (map[string]*domain.ResponsiveImage, error) {
+	sourceOf := map[string]string{}
+	var sources []string
+	for _, ref := range refs {
+		if source, ok := imageSource(ref); ok {
+			sourceOf[ref] = source
+			sources = append(sources, source)
+		}
+	}
+	assets, err := s.repo.GetImageAssets(sources)
+	if err != nil {
+		return nil, err
+	}
+	images := map[string]*domain.ResponsiveImage{}
+	for ref, source := range sourceOf {
+		if asset := assets[source]; asset != nil && asset.Error == "" && len(asset.Variants) > 0 {
+			images[ref] = ResponsiveImage(asset)
+		}
+	}
+	return images, nil
+}
+
+// ResponsiveImage returns the srcset-ready form of a processed image
+func ResponsiveImage(asset *domain.ImageAsset) *domain.ResponsiveImage {
+	img := &domain.ResponsiveImage{Width: asset.Width, Height: asset.Height, BlurHash: asset.BlurHash, LQIP: asset.LQIP}
+	var jpegs, webps []string
+	for _, v := range asset.Variants {
+		entry := fmt.Sprintf("%s %dw", v.URL, v.Width)
+		if v.Format == "webp" {
+			webps = append(webps, entry)
+		} else {
+			jpegs = append(jpegs, entry)
+			img.Src = v.URL
+		}
+	}
+	img.SrcSet = strings.Join(jpegs, ", ")
+	img.WebPSrcSet = strings.Join(webps, ", ")
+	return img
+}
+
+// AttachAnimes fills ImageSet and CoverSet
+func (s *ImageService) AttachAnimes(animes ...*domain.Anime) error {
+	var refs []string
+	for _, a := range animes {
+		refs = append(refs, a.Image, a.Cover)
+	}
+	images, err := s.Responsive(refs...)
+	if err != nil {
+		return err
+	}
+	for _, a := range animes {
+		a.ImageSet, a.CoverSet = images[a.Image], images[a.Cover]
+	}
+	return nil
+}
+
+// AttachEpisodes fills ThumbnailSet and the image sets of the preloaded anime
+func (s *ImageService) AttachEpisodes(episodes ...*domain.Episode) error {
+	var refs []string
+	for _, e := range episodes {
+		refs = append(refs, e.Thumbnail, e.Anime.Image, e.Anime.Cover)
+	}
+	images, err := s.Responsive(refs...)
+	if err != nil {
+		return err
+	}
+	for _, e := range episodes {
+		e.ThumbnailSet = images[e.Thumbnail]
+		e.Anime.ImageSet, e.Anime.CoverSet = images[e.Anime.Image], images[e.Anime.Cover]
+	}
+	return nil
+}
+
+// AttachModels fills ImageSet
+func (s *ImageService) AttachModels(models ...*domain.Model) error {
+	var refs []string
+	for _, m := range models {
+		refs = append(refs, m.Image)
+	}
+	images, err := s.Responsive(refs...)
+	if err != nil {
+		return err
+	}
+	for _, m := range models {
+		m.ImageSet = images[m.Image]
+	}
+	return nil
+}
+
+// AttachUsers fills AvatarSet
+func (s *ImageService) AttachUsers(users ...*domain.User) error {
+	var refs []string
+	for _, u := range users {
+		refs = append(refs, u.Avatar)
+	}
+	images, err := s.Responsive(refs...)
+	if err != nil {
+		return err
+	}
+	for _, u := range users {
+		u.AvatarSet = images[u.Avatar]
+	}
+	return nil
+}
+
+// UploadTarget stores completed resumable image uploads like the files of the
+// multipart upload endpoint
+func (s *ImageService) UploadTarget() TusTarget {
+	return TusTarget{
+		Validate: func(metadata map[string]string) error {
+			return checkExtension(metadata["filename"], imageExtensions)
+		},
+		Finalize: func(upload *domain.TusUpload, path string) (any, error) {
+			filename := upload.Metadata["filename"]
+			if err := checkExtension(filename, imageExtensions); err != nil {
+				return nil, err
+			}
+			media, asset, err := s.StoreFile(path, filepath.Ext(filename))
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{"url": media.URL, "media_id": media.ID, "image_set": ResponsiveImage(asset)}, nil
+		},
+	}
+}
+
+// RunBackfill processes images referenced by rows but not processed yet, every
+// interval until the process exits
+func (s *ImageService) RunBackfill(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if n, err := s.Backfill(imageBackfillBatch); err != nil {
+			log.Printf("Images: backfill failed: %v", err)
+		} else if n > 0 {
+			log.Printf("Images: processed %d images", n)
+		}
+		<-ticker.C
+	}
+}
+
+// Backfill processes up to limit unprocessed local images and returns how many it tried
+func (s *ImageService) Backfill(limit int) (int, error) {
+	refs, err := s.repo.GetImageRefs()
+	if err != nil {
+		return 0, err
+	}
+	done, err := s.repo.GetImageAssetSources()
+	if err != nil {
+		return 0, err
+	}
+	processed := map[string]bool{}
+	for _, source := range done {
+		processed[source] = true
+	}
+
+	tried := 0
+	for _, ref := range refs {
+		if tried >= limit {
+			break
+		}
+		source, ok := imageSource(ref)
+		if !ok || processed[source] {
+			continue
+		}
+		processed[source] = true
+		if _, err := s.media.Blobs().Stat(blobKey(source)); errors.Is(err, domain.ErrBlobNotFound) {
+			continue
+		}
+		tried++
+		if _, err := s.Process(source); err != nil {
+			log.Printf("Images: %s: %v", source, err)
+		}
+	}
+	return tried, nil
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"strings"
+	"testing"
+)
+
+// exifSegment builds an APP1 Exif segment holding only an Orientation tag
+func exifSegment(order binary.ByteOrder, orientation uint16) []byte {
+	tiff := make([]byte, 8+2+12+4)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8)
+	order.PutUint16(tiff[8:], 1)
+	order.PutUint16(tiff[10:], 0x0112)
+	order.PutUint16(tiff[12:], 3) // SHORT
+	order.PutUint32(tiff[14:], 1)
+	order.PutUint16(tiff[18:], orientation)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xff, 0xe1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// withExif inserts an Exif segment right after the SOI marker of a JPEG
+func withExif(t *testing.T, img image.Image, segment []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 90}); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	out := append([]byte{}, data[:2]...)
+	out = append(out, segment...)
+	return append(out, data[2:]...)
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 8, 4))
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"little endian", withExif(t, img, exifSegment(binary.LittleEndian, 6)), 6},
+		{"big endian", withExif(t, img, exifSegment(binary.BigEndian, 8)), 8},
+		{"out of range", withExif(t, img, exifSegment(binary.LittleEndian, 9)), 1},
+		{"no exif", withExif(t, img, nil), 1},
+		{"truncated exif", withExif(t, img, exifSegment(binary.LittleEndian, 3))[:20], 1},
+		{"not a jpeg", []byte("\x89PNG\r\n\x1a\n"), 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jpegOrientation(tt.data); got != tt.want {
+				t.Errorf("jpegOrientation = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrientImage(t *testing.T) {
+	// A 3x2 image whose pixels are numbered 1-6 in reading order:
+	//   1 2 3
+	//   4 5 6
+	src := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	for i := 0; i < 6; i++ {
+		src.SetNRGBA(i%3, i/3, color.NRGBA{R: uint8(i + 1), A: 255})
+	}
+	tests := []struct {
+		orientation int
+		want        []string // Rows of the upright image
+	}{
+		{1, []string{"123", "456"}},
+		{2, []string{"321", "654"}},
+		{3, []string{"654", "321"}},
+		{4, []string{"456", "123"}},
+		{5, []string{"14", "25", "36"}},
+		{6, []string{"41", "52", "63"}},
+		{7, []string{"63", "52", "41"}},
+		{8, []string{"36", "25", "14"}},
+	}
+	for _, tt := range tests {
+		for _, in := range []struct {
+			name string
+			img  image.Image
+		}{{"nrgba", src}, {"rgba", toRGBA(src)}} {
+			got := orientImage(in.img, tt.orientation)
+			b := got.Bounds()
+			var rows []string
+			for y := b.Min.Y; y < b.Max.Y; y++ {
+				var row strings.Builder
+				for x := b.Min.X; x < b.Max.X; x++ {
+					r, _, _, _ := got.At(x, y).RGBA()
+					row.WriteByte(byte('0' + r>>8))
+				}
+				rows = append(rows, row.String())
+			}
+			if strings.Join(rows, "/") != strings.Join(tt.want, "/") {
+				t.Errorf("orientation %d (%s): got %v, want %v", tt.orientation, in.name, rows, tt.want)
+			}
+		}
+	}
+}
+
+func toRGBA(img image.Image) *image.RGBA {
+	dst := image.NewRGBA(img.Bounds())
+	for y := img.Bounds().Min.Y; y < img.Bounds().Max.Y; y++ {
+		for x := img.Bounds().Min.X; x < img.Bounds().Max.X; x++ {
+			dst.Set(x, y, img.At(x, y))
+		}
+	}
+	return dst
+}
+
+func TestDecodeImageAppliesOrientation(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 40, 20))
+	decoded, format, err := decodeImage(withExif(t, img, exifSegment(binary.BigEndian, 6)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format != "jpeg" {
+		t.Errorf("format = %q", format)
+	}
+	if b := decoded.Bounds(); b.Dx() != 20 || b.Dy() != 40 {
+		t.Errorf("bounds = %v, want 20x40", b)
+	}
+}
+
+func TestBlurHash(t *testing.T) {
+	solid := func(c color.NRGBA) image.Image {
+		img := image.NewNRGBA(image.Rect(0, 0, 64, 48))
+		for i := 0; i < len(img.Pix); i += 4 {
+			img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+		}
+		return img
+	}
+	// Expected hashes come from the reference encoder; the basis functions don't sum
+	// to zero over whole pixels, so only black has flat AC components
+	flatAC := strings.Repeat("fQ", blurHashX*blurHashY-1)
+	tests := []struct {
+		name string
+		img  image.Image
+		want string
+	}{
+		{"black", solid(color.NRGBA{A: 255}), "L00000" + flatAC},
+		{"white", solid(color.NRGBA{R: 255, G: 255, B: 255, A: 255}), "LDTSUA_3fQ_3~qoffQoffQfQfQfQ"},
+		{"red", solid(color.NRGBA{R: 255, A: 255}), "LDTI:j]9fQ]9|co1fQo1fQfQfQfQ"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := blurHash(tt.img); got != tt.want {
+				t.Errorf("blurHash = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// A gradient has AC components, so neither the maximum nor the factors are flat
+	gradient := image.NewNRGBA(image.Rect(0, 0, 64, 48))
+	for x := 0; x < 64; x++ {
+		for y := 0; y < 48; y++ {
+			gradient.SetNRGBA(x, y, color.NRGBA{R: uint8(x * 4), G: uint8(y * 5), B: 128, A: 255})
+		}
+	}
+	hash := blurHash(gradient)
+	if len(hash) != 4+2*blurHashX*blurHashY || hash[0] != 'L' {
+		t.Fatalf("blurHash = %q: wrong size or size flag", hash)
+	}
+	if hash[1] == '0' || strings.HasSuffix(hash, flatAC) {
+		t.Errorf("blurHash = %q: expected AC components", hash)
+	}
+}
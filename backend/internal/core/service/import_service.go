@@ -0,0 +1,854 @@
+package service
+
+import (
+	"backend/internal/adapters/repository"
+	"backend/internal/core/domain"
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// errImportRollback aborts the import transaction on dry runs and on failed rows
+var errImportRollback = errors.New("import rolled back")
+
+type ImportService struct {
+	repo       *repository.SQLiteRepository
+	embedHosts []string // Hosts allowed for embed sources
+}
+
+func NewImportService(repo *repository.SQLiteRepository, embedHosts []string) *ImportService {
+	return &ImportService{repo: repo, embedHosts: embedHosts}
+}
+
+// Parse reads a JSON or CSV source into a normalized batch.
+// JSON accepts either a full catalog object or, when entity is set, a plain array of rows.
+// CSV always needs an entity and uses the header row for column names.
+func (s *ImportService) Parse(r io.Reader, format, entity string) (*domain.CatalogImport, error) {
+	switch strings.ToLower(format) {
+	case "json":
+		return parseImportJSON(r, entity)
+	case "csv":
+		return parseImportCSV(r, entity)
+	default:
+		return nil, fmt.Errorf("unsupported import format: %q (use csv or json)", format)
+	}
+}
+
+// Import validates and applies a batch inside a single transaction.
+// The transaction is rolled back for dry runs and whenever any row fails.
+func (s *ImportService) Import(batch *domain.CatalogImport, dryRun bool) (*domain.ImportReport, error) {
+	report := &domain.ImportReport{DryRun: dryRun}
+
+	err := s.repo.Transaction(func(tx *repository.SQLiteRepository) error {
+		// Dictionaries first so anime rows in the same batch can reference them
+		dictionaries := []struct {
+			entity string
+			rows   []domain.DictionaryImportRecord
+		}{
+			{domain.ImportEntityCategory, batch.Categories},
+			{domain.ImportEntityType, batch.Types},
+			{domain.ImportEntitySeason, batch.Seasons},
+			{domain.ImportEntityStudio, batch.Studios},
+			{domain.ImportEntityLanguage, batch.Languages},
+		}
+		for _, d := range dictionaries {
+			for _, rec := range d.rows {
+				res, err := importDictionary(tx, d.entity, rec)
+				if err != nil {
+					return err
+				}
+				report.Add(res)
+			}
+		}
+		for _, rec := range batch.Animes {
+			res, err := importAnime(tx, rec)
+			if err != nil {
+				return err
+			}
+			report.Add(res)
+		}
+		for _, rec := range batch.Episodes {
+			res, err := importEpisode(tx, rec, s.embedHosts)
+			if err != nil {
+				return err
+			}
+			report.Add(res)
+		}
+
+		if dryRun || report.Failed > 0 {
+			return errImportRollback
+		}
+		return nil
+	})
+
+	if err != nil && !errors.Is(err, errImportRollback) {
+		return nil, err
+	}
+	report.Committed = err == nil
+	return report, nil
+}
+
+// --- Row importers ---
+
+func newImportRow(row int, entity, slug string, parseErrors []string) domain.ImportRowResult {
+	return domain.ImportRowResult{
+		Row:     row,
+		Entity:  entity,
+		Slug:    slug,
+		Changes: map[string]domain.ImportFieldChange{},
+		Errors:  append([]string(nil), parseErrors...),
+	}
+}
+
+// finishImportRow decides the row action. It returns false when nothing should be saved.
+func finishImportRow(res *domain.ImportRowResult, isNew bool) bool {
+	switch {
+	case len(res.Errors) > 0:
+		res.Action = domain.ImportActionError
+		res.Changes = nil
+		return false
+	case isNew:
+		res.Action = domain.ImportActionCreate
+	case len(res.Changes) == 0:
+		res.Action = domain.ImportActionUnchanged
+		res.Changes = nil
+		return false
+	default:
+		res.Action = domain.ImportActionUpdate
+	}
+	return true
+}
+
+func importDictionary(tx *repository.SQLiteRepository, entity string, rec domain.DictionaryImportRecord) (domain.ImportRowResult, error) {
+	rec.Slug = strings.TrimSpace(rec.Slug)
+	res := newImportRow(rec.Row, entity, rec.Slug, rec.Errors)
+	if rec.Slug == "" {
+		res.Errors = append(res.Errors, "slug is required")
+		finishImportRow(&res, false)
+		return res, nil
+	}
+
+	var (
+		row      interface{}
+		isNew    bool
+		name     *string
+		nameEn   *string
+		isActive *bool
+		err      error
+	)
+	switch entity {
+	case domain.ImportEntityCategory:
+		var c *domain.Category
+		if c, err = tx.GetCategoryBySlug(rec.Slug); c == nil && err == nil {
+			c, isNew = &domain.Category{Slug: rec.Slug, Status: "active"}, true
+		}
+		if c != nil {
+			setImportString(res.Changes, "description", &c.Description, rec.Description)
+			row, name, nameEn = c, &c.Name, &c.NameEn
+		}
+	case domain.ImportEntityType:
+		var t *domain.Type
+		if t, err = tx.GetTypeBySlug(rec.Slug); t == nil && err == nil {
+			t, isNew = &domain.Type{Slug: rec.Slug, IsActive: true}, true
+		}
+		if t != nil {
+			row, name, nameEn, isActive = t, &t.Name, &t.NameEn, &t.IsActive
+		}
+	case domain.ImportEntitySeason:
+		var se *domain.Season
+		if se, err = tx.GetSeasonBySlug(rec.Slug); se == nil && err == nil {
+			se, isNew = &domain.Season{Slug: rec.Slug, IsActive: true}, true
+		}
+		if se != nil {
+			row, name, nameEn, isActive = se, &se.Name, &se.NameEn, &se.IsActive
+		}
+	case domain.ImportEntityStudio:
+		var st *domain.Studio
+		if st, err = tx.GetStudioBySlug(rec.Slug); st == nil && err == nil {
+			st, isNew = &domain.Studio{Slug: rec.Slug, IsActive: true}, true
+		}
+		if st != nil {
+			row, name, nameEn, isActive = st, &st.Name, &st.NameEn, &st.IsActive
+		}
+	case domain.ImportEntityLanguage:
+		var l *domain.Language
+		if l, err = tx.GetLanguageBySlug(rec.Slug); l == nil && err == nil {
+			l, isNew = &domain.Language{Slug: rec.Slug, IsActive: true}, true
+		}
+		if l != nil {
+			row, name, nameEn, isActive = l, &l.Name, &l.NameEn, &l.IsActive
+		}
+	default:
+		return res, fmt.Errorf("unknown dictionary entity: %s", entity)
+	}
+	if err != nil {
+		return res, err
+	}
+
+	setImportString(res.Changes, "name", name, rec.Name)
+	setImportString(res.Changes, "name_en", nameEn, rec.NameEn)
+	if isActive != nil {
+		setImportBool(res.Changes, "is_active", isActive, rec.IsActive)
+	}
+	if isNew && *name == "" {
+		res.Errors = append(res.Errors, "name is required for new rows")
+	}
+
+	if !finishImportRow(&res, isNew) {
+		return res, nil
+	}
+	if entity == domain.ImportEntityCategory {
+		// Title mirrors Name for categories (see seeder)
+		c := row.(*domain.Category)
+		c.Title, c.TitleEn = c.Name, c.NameEn
+	}
+	return res, tx.SaveDictionary(row)
+}
+
+func importAnime(tx *repository.SQLiteRepository, rec domain.AnimeImportRecord) (domain.ImportRowResult, error) {
+	rec.Slug = strings.TrimSpace(rec.Slug)
+	res := newImportRow(rec.Row, domain.ImportEntityAnime, rec.Slug, rec.Errors)
+	if rec.Slug == "" {
+		res.Errors = append(res.Errors, "slug is required")
+		finishImportRow(&res, false)
+		return res, nil
+	}
+
+	anime, err := tx.GetAnimeBySlug(rec.Slug)
+	if err != nil {
+		return res, err
+	}
+	isNew := anime == nil
+	if isNew {
+		anime = &domain.Anime{Slug: rec.Slug, Status: "Ongoing", Seasons: 1, IsActive: true}
+	}
+	baseline, err := animeSnapshot(anime)
+	if err != nil {
+		return res, err
+	}
+
+	setImportString(res.Changes, "slug_en", &anime.SlugEn, rec.SlugEn)
+	setImportString(res.Changes, "title", &anime.Title, rec.Title)
+	setImportString(res.Changes, "title_en", &anime.TitleEn, rec.TitleEn)
+	setImportString(res.Changes, "description", &anime.Description, rec.Description)
+	setImportString(res.Changes, "description_en", &anime.DescriptionEn, rec.DescriptionEn)
+	setImportString(res.Changes, "status", &anime.Status, rec.Status)
+	setImportString(res.Changes, "type", &anime.Type, rec.Type)
+	setImportString(res.Changes, "image", &anime.Image, rec.Image)
+	setImportString(res.Changes, "cover", &anime.Cover, rec.Cover)
+	setImportString(res.Changes, "trailer", &anime.Trailer, rec.Trailer)
+	setImportString(res.Changes, "studio_name", &anime.StudioName, rec.StudioName)
+	setImportString(res.Changes, "language", &anime.Language, rec.Language)
+	setImportInt(res.Changes, "seasons_count", &anime.Seasons, rec.Seasons)
+	setImportInt(res.Changes, "duration", &anime.Duration, rec.Duration)
+	setImportFloat(res.Changes, "rating", &anime.Rating, rec.Rating)
+	setImportBool(res.Changes, "is_active", &anime.IsActive, rec.IsActive)
+	if err := setImportDate(res.Changes, "release_date", &anime.ReleaseDate, rec.ReleaseDate); err != nil {
+		res.Errors = append(res.Errors, err.Error())
+	}
+	if rec.Rating != nil && (*rec.Rating < 0 || *rec.Rating > 10) {
+		res.Errors = append(res.Errors, "rating must be between 0 and 10")
+	}
+	if isNew && anime.Title == "" {
+		res.Errors = append(res.Errors, "title is required for new anime")
+	}
+
+	// Belongs-to relations by slug
+	if rec.Season != "" {
+		if se, err := tx.GetSeasonBySlug(rec.Season); err != nil {
+			return res, err
+		} else if se == nil {
+			res.Errors = append(res.Errors, fmt.Sprintf("season %q not found", rec.Season))
+		} else {
+			setImportID(res.Changes, "season_id", &anime.SeasonID, se.ID)
+		}
+	}
+	if rec.Studio != "" {
+		if st, err := tx.GetStudioBySlug(rec.Studio); err != nil {
+			return res, err
+		} else if st == nil {
+			res.Errors = append(res.Errors, fmt.Sprintf("studio %q not found", rec.Studio))
+		} else {
+			setImportID(res.Changes, "studio_id", &anime.StudioID, st.ID)
+		}
+	}
+	if rec.LanguageRel != "" {
+		if l, err := tx.GetLanguageBySlug(rec.LanguageRel); err != nil {
+			return res, err
+		} else if l == nil {
+			res.Errors = append(res.Errors, fmt.Sprintf("language %q not found", rec.LanguageRel))
+		} else {
+			setImportID(res.Changes, "language_id", &anime.LanguageID, l.ID)
+		}
+	}
+
+	// Categories replace the existing links when the column is present
+	var categories []domain.Category
+	if rec.Categories != nil {
+		categories = []domain.Category{}
+		newSlugs := make([]string, 0, len(rec.Categories))
+		for _, slug := range rec.Categories {
+			slug = strings.TrimSpace(slug)
+			if slug == "" {
+				continue
+			}
+			cat, err := tx.GetCategoryBySlug(slug)
+			if err != nil {
+				return res, err
+			}
+			if cat == nil {
+				res.Errors = append(res.Errors, fmt.Sprintf("category %q not found", slug))
+				continue
+			}
+			categories = append(categories, *cat)
+			newSlugs = append(newSlugs, cat.Slug)
+		}
+		oldSlugs := make([]string, 0, len(anime.Categories))
+		for _, cat := range anime.Categories {
+			oldSlugs = append(oldSlugs, cat.Slug)
+		}
+		sort.Strings(oldSlugs)
+		sort.Strings(newSlugs)
+		if before, after := strings.Join(oldSlugs, "|"), strings.Join(newSlugs, "|"); before != after {
+			res.Changes["categories"] = domain.ImportFieldChange{Old: before, New: after}
+		} else {
+			categories = nil // Nothing to replace
+		}
+	}
+
+	if !finishImportRow(&res, isNew) {
+		return res, nil
+	}
+	anime.UpdatedAt = time.Now()
+
+	if !isNew {
+		if err := recordBaseline(tx, domain.RevisionEntityAnime, anime.ID, anime.Version, func() (json.RawMessage, error) {
+			return baseline, nil
+		}); err != nil {
+			return res, err
+		}
+	}
+	saved := *anime
+	if categories != nil {
+		saved.Categories = categories
+	}
+	snapshot, err := animeSnapshot(&saved)
+	if err != nil {
+		return res, err
+	}
+	return res, tx.SaveAnimeWithCategories(anime, categories, newRevision(domain.RevisionActionImport, 0, snapshot))
+}
+
+func importEpisode(tx *repository.SQLiteRepository, rec domain.EpisodeImportRecord, embedHosts []string) (domain.ImportRowResult, error) {
+	rec.AnimeSlug = strings.TrimSpace(rec.AnimeSlug)
+	rec.Slug = strings.TrimSpace(rec.Slug)
+	if rec.Slug == "" && rec.AnimeSlug != "" && rec.EpisodeNumber > 0 {
+		rec.Slug = fmt.Sprintf("%s-%d", rec.AnimeSlug, rec.EpisodeNumber)
+	}
+	res := newImportRow(rec.Row, domain.ImportEntityEpisode, rec.Slug, rec.Errors)
+	if rec.Slug == "" {
+		res.Errors = append(res.Errors, "slug, or anime_slug with episode_number, is required")
+		finishImportRow(&res, false)
+		return res, nil
+	}
+
+	episode, err := tx.GetEpisodeBySlug(rec.Slug)
+	if err != nil {
+		return res, err
+	}
+	isNew := episode == nil
+	if isNew {
+		episode = &domain.Episode{Slug: rec.Slug}
+	}
+	baseline, err := episodeSnapshot(episode)
+	if err != nil {
+		return res, err
+	}
+
+	if rec.AnimeSlug != "" {
+		anime, err := tx.GetAnimeBySlug(rec.AnimeSlug)
+		if err != nil {
+			return res, err
+		}
+		if anime == nil {
+			res.Errors = append(res.Errors, fmt.Sprintf("anime %q not found", rec.AnimeSlug))
+		} else {
+			setImportID(res.Changes, "anime_id", &episode.AnimeID, anime.ID)
+		}
+	} else if isNew {
+		res.Errors = append(res.Errors, "anime_slug is required for new episodes")
+	}
+
+	if rec.EpisodeNumber < 0 {
+		res.Errors = append(res.Errors, "episode_number must be positive")
+	} else if rec.EpisodeNumber > 0 {
+		n := rec.EpisodeNumber
+		setImportInt(res.Changes, "episode_number", &episode.EpisodeNumber, &n)
+	} else if isNew {
+		res.Errors = append(res.Errors, "episode_number is required for new episodes")
+	}
+	if isNew && rec.Title == "" && rec.EpisodeNumber > 0 {
+		rec.Title = fmt.Sprintf("Episode %d", rec.EpisodeNumber)
+	}
+
+	setImportString(res.Changes, "slug_en", &episode.SlugEn, rec.SlugEn)
+	setImportString(res.Changes, "title", &episode.Title, rec.Title)
+	setImportString(res.Changes, "title_en", &episode.TitleEn, rec.TitleEn)
+	setImportString(res.Changes, "description", &episode.Description, rec.Description)
+	setImportString(res.Changes, "description_en", &episode.DescriptionEn, rec.DescriptionEn)
+	setImportString(res.Changes, "thumbnail", &episode.Thumbnail, rec.Thumbnail)
+	setImportString(res.Changes, "banner", &episode.Banner, rec.Banner)
+	setImportString(res.Changes, "quality", &episode.Quality, rec.Quality)
+	setImportString(res.Changes, "video_format", &episode.VideoFormat, rec.VideoFormat)
+	setImportString(res.Changes, "language", &episode.Language, rec.Language)
+	setImportInt(res.Changes, "duration", &episode.Duration, rec.Duration)
+	setImportBool(res.Changes, "is_published", &episode.IsPublished, rec.IsPublished)
+	if rec.IsPublished != nil {
+		// Imports are admin-only, so is_published maps straight onto the workflow state
+		if episode.IsPublished {
+			episode.State = domain.ContentStatePublished
+		} else if episode.State == domain.ContentStatePublished || isNew {
+			episode.State = domain.ContentStateDraft
+		}
+	}
+	if rec.ReleaseDate != "" {
+		var releaseDate *time.Time
+		if !episode.ReleaseDate.IsZero() {
+			releaseDate = &episode.ReleaseDate
+		}
+		if err := setImportDate(res.Changes, "release_date", &releaseDate, rec.ReleaseDate); err != nil {
+			res.Errors = append(res.Errors, err.Error())
+		} else if releaseDate == nil {
+			episode.ReleaseDate = time.Time{}
+		} else {
+			episode.ReleaseDate = *releaseDate
+		}
+	}
+
+	// Servers replace the existing ones when present
+	var servers []domain.EpisodeServer
+	if rec.Servers != nil {
+		servers = []domain.EpisodeServer{}
+		known := knownSourceURLs(episode.Servers)
+		for i, sv := range rec.Servers {
+			server := sv.EpisodeServer()
+			if err := normalizeSource(&server, embedHosts, known); err != nil {
+				res.Errors = append(res.Errors, fmt.Sprintf("servers[%d]: %v", i, err))
+				continue
+			}
+			if server.Name == "" {
+				server.Name = fmt.Sprintf("Server %d", i+1)
+			}
+			servers = append(servers, server)
+		}
+		if before, after := describeServers(episode.Servers), describeServers(servers); before != after {
+			res.Changes["servers"] = domain.ImportFieldChange{Old: before, New: after}
+			episode.VideoURLs = legacyVideoURLs(servers)
+		} else {
+			servers = nil
+		}
+	}
+
+	if !finishImportRow(&res, isNew) {
+		return res, nil
+	}
+	episode.UpdatedAt = time.Now()
+
+	if !isNew {
+		if err := recordBaseline(tx, domain.RevisionEntityEpisode, episode.ID, episode.Version, func() (json.RawMessage, error) {
+			return baseline, nil
+		}); err != nil {
+			return res, err
+		}
+	}
+	saved := *episode
+	if servers != nil {
+		saved.Servers = servers
+	}
+	snapshot, err := episodeSnapshot(&saved)
+	if err != nil {
+		return res, err
+	}
+	return res, tx.SaveEpisodeWithServers(episode, servers, newRevision(domain.RevisionActionImport, 0, snapshot))
+}
+
+func describeServers(servers []domain.EpisodeServer) string {
+	parts := make([]string, 0, len(servers))
+	for _, sv := range servers {
+		parts = append(parts, fmt.Sprintf("%s|%s|%s|%s|%s|%d|%s", sv.Name, sv.Language, sv.Kind, sv.Quality, sv.Provider, sv.Priority, sv.URL))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// --- Field setters: apply a non-empty imported value and record the change ---
+
+func setImportString(changes map[string]domain.ImportFieldChange, field string, dst *string, val string) {
+	if val == "" || val == *dst {
+		return
+	}
+	changes[field] = domain.ImportFieldChange{Old: *dst, New: val}
+	*dst = val
+}
+
+func setImportInt(changes map[string]domain.ImportFieldChange, field string, dst *int, val *int) {
+	if val == nil || *val == *dst {
+		return
+	}
+	changes[field] = domain.ImportFieldChange{Old: strconv.Itoa(*dst), New: strconv.Itoa(*val)}
+	*dst = *val
+}
+
+func setImportFloat(changes map[string]domain.ImportFieldChange, field string, dst *float64, val *float64) {
+	if val == nil || *val == *dst {
+		return
+	}
+	changes[field] = domain.ImportFieldChange{
+		Old: strconv.FormatFloat(*dst, 'f', -1, 64),
+		New: strconv.FormatFloat(*val, 'f', -1, 64),
+	}
+	*dst = *val
+}
+
+func setImportBool(changes map[string]domain.ImportFieldChange, field string, dst *bool, val *bool) {
+	if val == nil || *val == *dst {
+		return
+	}
+	changes[field] = domain.ImportFieldChange{Old: strconv.FormatBool(*dst), New: strconv.FormatBool(*val)}
+	*dst = *val
+}
+
+func setImportID(changes map[string]domain.ImportFieldChange, field string, dst interface{}, id uint) {
+	switch d := dst.(type) {
+	case *uint:
+		if *d != id {
+			changes[field] = domain.ImportFieldChange{Old: strconv.FormatUint(uint64(*d), 10), New: strconv.FormatUint(uint64(id), 10)}
+			*d = id
+		}
+	case **uint:
+		old := ""
+		if *d != nil {
+			if **d == id {
+				return
+			}
+			old = strconv.FormatUint(uint64(**d), 10)
+		}
+		changes[field] = domain.ImportFieldChange{Old: old, New: strconv.FormatUint(uint64(id), 10)}
+		*d = &id
+	}
+}
+
+func setImportDate(changes map[string]domain.ImportFieldChange, field string, dst **time.Time, val string) error {
+	val = strings.TrimSpace(val)
+	if val == "" {
+		return nil
+	}
+	old := ""
+	if *dst != nil {
+		old = (*dst).Format("2006-01-02")
+	}
+	if val == domain.ImportClearValue {
+		if *dst != nil {
+			changes[field] = domain.ImportFieldChange{Old: old, New: ""}
+			*dst = nil
+		}
+		return nil
+	}
+	t, err := time.Parse("2006-01-02", val)
+	if err != nil {
+		return fmt.Errorf("%s must be formatted as YYYY-MM-DD, or %q to clear it", field, domain.ImportClearValue)
+	}
+	if old == val {
+		return nil
+	}
+	changes[field] = domain.ImportFieldChange{Old: old, New: val}
+	*dst = &t
+	return nil
+}
+
+// --- Parsers ---
+
+func parseImportJSON(r io.Reader, entity string) (*domain.CatalogImport, error) {
+	br := bufio.NewReader(r)
+	first, err := firstNonSpace(br)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := &domain.CatalogImport{}
+	dec := json.NewDecoder(br)
+	if first == '[' {
+		if entity == "" {
+			return nil, errors.New("entity is required when importing a JSON array")
+		}
+		switch entity {
+		case domain.ImportEntityAnime:
+			err = dec.Decode(&batch.Animes)
+		case domain.ImportEntityEpisode:
+			err = dec.Decode(&batch.Episodes)
+		default:
+			var rows []domain.DictionaryImportRecord
+			err = dec.Decode(&rows)
+			if err == nil {
+				err = setDictionaryRows(batch, entity, rows)
+			}
+		}
+	} else {
+		err = dec.Decode(batch)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	for i := range batch.Animes {
+		batch.Animes[i].Row = i + 1
+	}
+	for i := range batch.Episodes {
+		batch.Episodes[i].Row = i + 1
+	}
+	for _, rows := range [][]domain.DictionaryImportRecord{batch.Categories, batch.Types, batch.Seasons, batch.Studios, batch.Languages} {
+		for i := range rows {
+			rows[i].Row = i + 1
+		}
+	}
+	return batch, nil
+}
+
+func firstNonSpace(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			if err == io.EOF {
+				return 0, errors.New("empty import file")
+			}
+			return 0, err
+		}
+		switch b {
+		case ' ', '\t', '\r', '\n', 0xEF, 0xBB, 0xBF: // Whitespace and UTF-8 BOM
+			continue
+		}
+		return b, br.UnreadByte()
+	}
+}
+
+func setDictionaryRows(batch *domain.CatalogImport, entity string, rows []domain.DictionaryImportRecord) error {
+	switch entity {
+	case domain.ImportEntityCategory:
+		batch.Categories = rows
+	case domain.ImportEntityType:
+		batch.Types = rows
+	case domain.ImportEntitySeason:
+		batch.Seasons = rows
+	case domain.ImportEntityStudio:
+		batch.Studios = rows
+	case domain.ImportEntityLanguage:
+		batch.Languages = rows
+	default:
+		return fmt.Errorf("unknown import entity: %q", entity)
+	}
+	return nil
+}
+
+var importCSVColumns = map[string][]string{
+	domain.ImportEntityAnime: {
+		"slug", "slug_en", "title", "title_en", "description", "description_en", "status", "type",
+		"release_date", "rating", "seasons_count", "duration", "image", "cover", "trailer",
+		"studio_name", "language", "is_active", "categories", "season", "studio", "language_rel",
+	},
+	domain.ImportEntityEpisode: {
+		"anime_slug", "episode_number", "slug", "slug_en", "title", "title_en", "description",
+		"description_en", "thumbnail", "banner", "duration", "quality", "video_format",
+		"release_date", "is_published", "language", "servers",
+	},
+	"dictionary": {"slug", "name", "name_en", "description", "is_active"},
+}
+
+func parseImportCSV(r io.Reader, entity string) (*domain.CatalogImport, error) {
+	if entity == "" {
+		return nil, errors.New("entity is required for CSV imports")
+	}
+	allowed := importCSVColumns[entity]
+	if allowed == nil {
+		allowed = importCSVColumns["dictionary"]
+	}
+
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("empty import file")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV header: %w", err)
+	}
+	for i, col := range header {
+		col = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(col, "\ufeff")))
+		header[i] = col
+		if !containsString(allowed, col) && col != catalogDeletedColumn {
+			return nil, fmt.Errorf("unknown column %q for %s import", col, entity)
+		}
+	}
+
+	batch := &domain.CatalogImport{}
+	var dictionary []domain.DictionaryImportRecord
+	line := 1
+	for {
+		record, err := reader.Read()
+		line++
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV at line %d: %w", line, err)
+		}
+		row := csvRow{values: map[string]string{}}
+		for i, col := range header {
+			if i < len(record) {
+				row.values[col] = strings.TrimSpace(record[i])
+			}
+		}
+		if row.values[catalogDeletedColumn] != "" {
+			continue // Tombstone of an incremental export
+		}
+
+		switch entity {
+		case domain.ImportEntityAnime:
+			rec := domain.AnimeImportRecord{
+				Row:           line,
+				Slug:          row.values["slug"],
+				SlugEn:        row.values["slug_en"],
+				Title:         row.values["title"],
+				TitleEn:       row.values["title_en"],
+				Description:   row.values["description"],
+				DescriptionEn: row.values["description_en"],
+				Status:        row.values["status"],
+				Type:          row.values["type"],
+				ReleaseDate:   row.values["release_date"],
+				Rating:        row.float("rating"),
+				Seasons:       row.int("seasons_count"),
+				Duration:      row.int("duration"),
+				Image:         row.values["image"],
+				Cover:         row.values["cover"],
+				Trailer:       row.values["trailer"],
+				StudioName:    row.values["studio_name"],
+				Language:      row.values["language"],
+				IsActive:      row.bool("is_active"),
+				Season:        row.values["season"],
+				Studio:        row.values["studio"],
+				LanguageRel:   row.values["language_rel"],
+			}
+			if cats, ok := row.values["categories"]; ok && cats != "" {
+				rec.Categories = strings.Split(cats, "|")
+			}
+			rec.Errors = row.errors
+			batch.Animes = append(batch.Animes, rec)
+		case domain.ImportEntityEpisode:
+			rec := domain.EpisodeImportRecord{
+				Row:           line,
+				AnimeSlug:     row.values["anime_slug"],
+				Slug:          row.values["slug"],
+				SlugEn:        row.values["slug_en"],
+				Title:         row.values["title"],
+				TitleEn:       row.values["title_en"],
+				Description:   row.values["description"],
+				DescriptionEn: row.values["description_en"],
+				Thumbnail:     row.values["thumbnail"],
+				Banner:        row.values["banner"],
+				Duration:      row.int("duration"),
+				Quality:       row.values["quality"],
+				VideoFormat:   row.values["video_format"],
+				ReleaseDate:   row.values["release_date"],
+				IsPublished:   row.bool("is_published"),
+				Language:      row.values["language"],
+			}
+			if n := row.int("episode_number"); n != nil {
+				rec.EpisodeNumber = *n
+			}
+			if servers := row.values["servers"]; servers != "" {
+				// Servers are a JSON array in a single cell: [{"name","language","url","type"}]
+				if err := json.Unmarshal([]byte(servers), &rec.Servers); err != nil {
+					row.errors = append(row.errors, "servers must be a JSON array of {name, language, url, type}")
+				}
+			}
+			rec.Errors = row.errors
+			batch.Episodes = append(batch.Episodes, rec)
+		default:
+			dictionary = append(dictionary, domain.DictionaryImportRecord{
+				Row:         line,
+				Slug:        row.values["slug"],
+				Name:        row.values["name"],
+				NameEn:      row.values["name_en"],
+				Description: row.values["description"],
+				IsActive:    row.bool("is_active"),
+				Errors:      row.errors,
+			})
+		}
+	}
+
+	if entity != domain.ImportEntityAnime && entity != domain.ImportEntityEpisode {
+		if err := setDictionaryRows(batch, entity, dictionary); err != nil {
+			return nil, err
+		}
+	}
+	return batch, nil
+}
+
+// csvRow converts optional typed cells, collecting errors instead of failing the whole file
+type csvRow struct {
+	values map[string]string
+	errors []string
+}
+
+func (r *csvRow) int(col string) *int {
+	v := r.values[col]
+	if v == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		r.errors = append(r.errors, fmt.Sprintf("%s must be an integer", col))
+		return nil
+	}
+	return &n
+}
+
+func (r *csvRow) float(col string) *float64 {
+	v := r.values[col]
+	if v == "" {
+		return nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		r.errors = append(r.errors, fmt.Sprintf("%s must be a number", col))
+		return nil
+	}
+	return &f
+}
+
+func (r *csvRow) bool(col string) *bool {
+	v := r.values[col]
+	if v == "" {
+		return nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		r.errors = append(r.errors, fmt.Sprintf("%s must be true or false", col))
+		return nil
+	}
+	return &b
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"backend/internal/adapters/repository"
+	"backend/internal/core/domain"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newImportTestService(t *testing.T) (*ImportService, *repository.SQLiteRepository) {
+	t.Helper()
+	repo, err := repository.NewSQLiteRepository("file:" + filepath.Join(t.TempDir(), "import.db") + "?_busy_timeout=5000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewImportService(repo, nil), repo
+}
+
+func parseImport(t *testing.T, s *ImportService, format, entity, body string) *domain.CatalogImport {
+	t.Helper()
+	batch, err := s.Parse(strings.NewReader(body), format, entity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return batch
+}
+
+func countRows(t *testing.T, repo *repository.SQLiteRepository, model interface{}) int64 {
+	t.Helper()
+	var n int64
+	if err := repo.DB().Model(model).Count(&n).Error; err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+const importCatalogJSON = `{
+	"categories": [{"slug": "action", "name": "Action"}],
+	"animes": [{"slug": "cowboy-bebop", "title": "Cowboy Bebop", "release_date": "1998-04-03", "categories": ["action"]}],
+	"episodes": [{"anime_slug": "cowboy-bebop", "episode_number": 1, "is_published": true}]
+}`
+
+func TestImportDryRunWritesNothing(t *testing.T) {
+	s, repo := newImportTestService(t)
+
+	report, err := s.Import(parseImport(t, s, "json", "", importCatalogJSON), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.DryRun || report.Committed {
+		t.Fatalf("dry run reported dry_run=%v committed=%v", report.DryRun, report.Committed)
+	}
+	if report.Created != 3 || report.Failed != 0 {
+		t.Fatalf("dry run created %d and failed %d rows, want 3 and 0", report.Created, report.Failed)
+	}
+	// Later rows see earlier ones of the same batch even though nothing is kept
+	if ep := report.Rows[2]; ep.Action != domain.ImportActionCreate || ep.Slug != "cowboy-bebop-1" {
+		t.Fatalf("episode row = %+v, want a created cowboy-bebop-1", ep)
+	}
+	for _, model := range []interface{}{&domain.Category{}, &domain.Anime{}, &domain.Episode{}, &domain.Revision{}} {
+		if n := countRows(t, repo, model); n != 0 {
+			t.Fatalf("dry run left %d %T rows", n, model)
+		}
+	}
+}
+
+func TestImportCommitsAndReportsUnchangedRows(t *testing.T) {
+	s, repo := newImportTestService(t)
+
+	report, err := s.Import(parseImport(t, s, "json", "", importCatalogJSON), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Committed || report.Created != 3 {
+		t.Fatalf("import committed=%v created=%d, want true and 3", report.Committed, report.Created)
+	}
+	anime, err := repo.GetAnimeBySlug("cowboy-bebop")
+	if err != nil || anime == nil {
+		t.Fatalf("imported anime not found: %v", err)
+	}
+	if len(anime.Categories) != 1 || anime.Categories[0].Slug != "action" {
+		t.Fatalf("imported anime categories = %+v, want action", anime.Categories)
+	}
+	episode, err := repo.GetEpisodeBySlug("cowboy-bebop-1")
+	if err != nil || episode == nil {
+		t.Fatalf("imported episode not found: %v", err)
+	}
+	if episode.Title != "Episode 1" || episode.State != domain.ContentStatePublished {
+		t.Fatalf("imported episode title=%q state=%q", episode.Title, episode.State)
+	}
+
+	// The same file again changes nothing
+	report, err = s.Import(parseImport(t, s, "json", "", importCatalogJSON), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Unchanged != 3 || report.Created+report.Updated+report.Failed != 0 {
+		t.Fatalf("reimport = %+v, want 3 unchanged rows", report)
+	}
+}
+
+func TestImportFailedRowRollsBackTheBatch(t *testing.T) {
+	s, repo := newImportTestService(t)
+	body := "slug,title,rating\n" +
+		"trigun,Trigun,8\n" +
+		"monster,Monster,11\n"
+
+	report, err := s.Import(parseImport(t, s, "csv", domain.ImportEntityAnime, body), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Committed || report.Created != 1 || report.Failed != 1 {
+		t.Fatalf("import = %+v, want one created, one failed and no commit", report)
+	}
+	if row := report.Rows[1]; row.Row != 3 || len(row.Errors) != 1 || !strings.Contains(row.Errors[0], "rating") {
+		t.Fatalf("failed row = %+v, want a rating error on line 3", row)
+	}
+	if n := countRows(t, repo, &domain.Anime{}); n != 0 {
+		t.Fatalf("failed import left %d anime", n)
+	}
+}
+
+func TestImportUpdatesAndClearsDates(t *testing.T) {
+	s, repo := newImportTestService(t)
+	if _, err := s.Import(parseImport(t, s, "json", "", importCatalogJSON), false); err != nil {
+		t.Fatal(err)
+	}
+
+	// Blank cells keep the stored value, "-" clears it
+	body := "slug,title,release_date\n" +
+		"cowboy-bebop, Cowboy Bebop ,  \n"
+	report, err := s.Import(parseImport(t, s, "csv", domain.ImportEntityAnime, body), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Unchanged != 1 {
+		t.Fatalf("padded row = %+v, want unchanged", report.Rows[0])
+	}
+
+	body = "slug,release_date\n" +
+		"cowboy-bebop,-\n"
+	report, err = s.Import(parseImport(t, s, "csv", domain.ImportEntityAnime, body), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	change, ok := report.Rows[0].Changes["release_date"]
+	if report.Updated != 1 || !ok || change.Old != "1998-04-03" || change.New != "" {
+		t.Fatalf("clearing row = %+v, want release_date 1998-04-03 -> empty", report.Rows[0])
+	}
+	anime, err := repo.GetAnimeBySlug("cowboy-bebop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if anime.ReleaseDate != nil {
+		t.Fatalf("release date = %v after clearing", anime.ReleaseDate)
+	}
+}
+
+func TestParseImportCSVRejectsUnknownColumns(t *testing.T) {
+	s, _ := newImportTestService(t)
+	if _, err := s.Parse(strings.NewReader("slug,colour\nx,red\n"), "csv", domain.ImportEntityAnime); err == nil {
+		t.Fatal("unknown column accepted")
+	}
+	if _, err := s.Parse(strings.NewReader("slug\nx\n"), "csv", ""); err == nil {
+		t.Fatal("CSV without an entity accepted")
+	}
+}
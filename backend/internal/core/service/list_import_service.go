@@ -0,0 +1,426 @@
+package service
+
+import (
+	"backend/internal/adapters/repository"
+	"backend/internal/core/domain"
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	listImportAutoMatch     = 0.85 // Minimum similarity to match without asking the user
+	listImportCandidateMin  = 0.5  // Minimum similarity to suggest a candidate
+	listImportMaxCandidates = 5
+)
+
+type ListImportService struct {
+	repo  *repository.SQLiteRepository
+	lists *AnimeListService
+}
+
+func NewListImportService(repo *repository.SQLiteRepository, lists *AnimeListService) *ListImportService {
+	return &ListImportService{repo: repo, lists: lists}
+}
+
+// --- Parsers ---
+
+type malExport struct {
+	Anime []struct {
+		ID       string  `xml:"series_animedb_id"`
+		Title    string  `xml:"series_title"`
+		Watched  int     `xml:"my_watched_episodes"`
+		Score    float64 `xml:"my_score"`
+		Status   string  `xml:"my_status"`
+		Started  string  `xml:"my_start_date"`
+		Finished string  `xml:"my_finish_date"`
+		Rewatch  int     `xml:"my_times_watched"`
+		Comments string  `xml:"my_comments"`
+	} `xml:"anime"`
+}
+
+// ParseMAL reads a MyAnimeList XML export (plain or gzipped as downloaded from MAL)
+func (s *ListImportService) ParseMAL(r io.Reader) ([]domain.ExternalListEntry, error) {
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip file: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	} else {
+		r = br
+	}
+
+	var export malExport
+	if err := xml.NewDecoder(r).Decode(&export); err != nil {
+		return nil, fmt.Errorf("invalid MyAnimeList XML: %w", err)
+	}
+
+	entries := make([]domain.ExternalListEntry, 0, len(export.Anime))
+	for _, a := range export.Anime {
+		entries = append(entries, domain.ExternalListEntry{
+			ExternalID: a.ID,
+			Titles:     []string{a.Title},
+			Status:     malStatus(a.Status),
+			Progress:   a.Watched,
+			Score:      a.Score,
+			StartedAt:  malDate(a.Started),
+			FinishedAt: malDate(a.Finished),
+			Rewatches:  a.Rewatch,
+			Notes:      a.Comments,
+		})
+	}
+	return entries, nil
+}
+
+// malDate parses a YYYY-MM-DD export date; unknown dates are 0000-00-00
+func malDate(value string) *time.Time {
+	t, err := time.Parse("2006-01-02", strings.TrimSpace(value))
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+func malStatus(status string) domain.ListStatus {
+	// Older exports use numeric codes
+	switch strings.ToLower(strings.TrimSpace(status)) {
+	case "watching", "1":
+		return domain.ListStatusWatching
+	case "completed", "2":
+		return domain.ListStatusCompleted
+	case "on-hold", "on hold", "3":
+		return domain.ListStatusOnHold
+	case "dropped", "4":
+		return domain.ListStatusDropped
+	default:
+		return domain.ListStatusPlanToWatch
+	}
+}
+
+type aniListDate struct {
+	Year  int `json:"year"`
+	Month int `json:"month"`
+	Day   int `json:"day"`
+}
+
+// time returns the date, or nil when AniList does not know the year
+func (d aniListDate) time() *time.Time {
+	if d.Year == 0 {
+		return nil
+	}
+	t := time.Date(d.Year, time.Month(max(d.Month, 1)), max(d.Day, 1), 0, 0, 0, 0, time.UTC)
+	return &t
+}
+
+type aniListEntry struct {
+	MediaID     int         `json:"mediaId"`
+	Status      string      `json:"status"`
+	Progress    int         `json:"progress"`
+	Score       float64     `json:"score"`
+	Repeat      int         `json:"repeat"`
+	Notes       string      `json:"notes"`
+	StartedAt   aniListDate `json:"startedAt"`
+	CompletedAt aniListDate `json:"completedAt"`
+	Media       struct {
+		ID    int `json:"id"`
+		Title struct {
+			Romaji  string `json:"romaji"`
+			English string `json:"english"`
+			Native  string `json:"native"`
+		} `json:"title"`
+		Synonyms []string `json:"synonyms"`
+	} `json:"media"`
+}
+
+type aniListList struct {
+	Name    string         `json:"name"`
+	Status  string         `json:"status"`
+	Entries []aniListEntry `json:"entries"`
+}
+
+// ParseAniList reads an AniList JSON export. Both the raw GraphQL MediaListCollection
+// response and its bare {"lists": [...]} payload are accepted.
+func (s *ListImportService) ParseAniList(r io.Reader) ([]domain.ExternalListEntry, error) {
+	var export struct {
+		Lists []aniListList `json:"lists"`
+		Data  struct {
+			MediaListCollection struct {
+				Lists []aniListList `json:"lists"`
+			} `json:"MediaListCollection"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return nil, fmt.Errorf("invalid AniList JSON: %w", err)
+	}
+	lists := append(export.Lists, export.Data.MediaListCollection.Lists...)
+
+	var entries []domain.ExternalListEntry
+	for _, list := range lists {
+		for _, e := range list.Entries {
+			status := e.Status
+			if status == "" {
+				status = list.Status
+			}
+			if status == "" {
+				status = list.Name
+			}
+			id := e.Media.ID
+			if id == 0 {
+				id = e.MediaID
+			}
+			score := e.Score
+			if score > 10 { // POINT_100 scoring
+				score /= 10
+			}
+			var titles []string
+			for _, t := range append([]string{e.Media.Title.Romaji, e.Media.Title.English, e.Media.Title.Native}, e.Media.Synonyms...) {
+				if t != "" {
+					titles = append(titles, t)
+				}
+			}
+			entries = append(entries, domain.ExternalListEntry{
+				ExternalID: strconv.Itoa(id),
+				Titles:     titles,
+				Status:     aniListStatus(status),
+				Progress:   e.Progress,
+				Score:      score,
+				StartedAt:  e.StartedAt.time(),
+				FinishedAt: e.CompletedAt.time(),
+				Rewatches:  e.Repeat,
+				Notes:      e.Notes,
+			})
+		}
+	}
+	return entries, nil
+}
+
+func aniListStatus(status string) domain.ListStatus {
+	switch strings.ToUpper(strings.TrimSpace(status)) {
+	case "CURRENT", "REPEATING", "WATCHING", "REWATCHING":
+		return domain.ListStatusWatching
+	case "COMPLETED":
+		return domain.ListStatusCompleted
+	case "PAUSED":
+		return domain.ListStatusOnHold
+	case "DROPPED":
+		return domain.ListStatusDropped
+	default:
+		return domain.ListStatusPlanToWatch
+	}
+}
+
+// --- Import ---
+
+type titleIndexEntry struct {
+	anime    domain.Anime
+	variants []string // Normalized title, title_en, slug, slug_en
+}
+
+// Import matches entries to our catalog and records the confident matches for the user.
+// Entries below the auto-match threshold are returned as unmatched with suggested candidates.
+func (s *ListImportService) Import(userID uint, source string, entries []domain.ExternalListEntry, dryRun bool) (*domain.ListImportReport, error) {
+	animes, err := s.repo.GetAnimeTitles()
+	if err != nil {
+		return nil, err
+	}
+
+	index := make([]titleIndexEntry, len(animes))
+	exact := map[string]int{}
+	for i, a := range animes {
+		index[i] = titleIndexEntry{anime: a}
+		for _, t := range []string{a.Title, a.TitleEn, a.Slug, a.SlugEn} {
+			if n := normalizeTitle(t); n != "" {
+				index[i].variants = append(index[i].variants, n)
+				if _, ok := exact[n]; !ok {
+					exact[n] = i
+				}
+			}
+		}
+	}
+
+	report := &domain.ListImportReport{
+		Source:    source,
+		DryRun:    dryRun,
+		Total:     len(entries),
+		Matched:   []domain.ListImportMatch{},
+		Unmatched: []domain.ListImportUnmatched{},
+	}
+	err = s.transaction(func(tx *ListImportService) error {
+		for _, entry := range entries {
+			best, confidence, candidates := matchTitles(entry.Titles, index, exact)
+			if confidence < listImportAutoMatch {
+				report.Unmatched = append(report.Unmatched, domain.ListImportUnmatched{ExternalListEntry: entry, Candidates: candidates})
+				continue
+			}
+			match, err := tx.apply(userID, source, entry, best, dryRun)
+			if err != nil {
+				return err
+			}
+			match.Confidence = confidence
+			report.Matched = append(report.Matched, *match)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// Resolve records entries the user matched manually from the unmatched report
+func (s *ListImportService) Resolve(userID uint, source string, resolutions []domain.ListImportResolution) (*domain.ListImportReport, error) {
+	report := &domain.ListImportReport{
+		Source:    source,
+		Total:     len(resolutions),
+		Matched:   []domain.ListImportMatch{},
+		Unmatched: []domain.ListImportUnmatched{},
+	}
+	err := s.transaction(func(tx *ListImportService) error {
+		for _, res := range resolutions {
+			anime, err := tx.repo.GetPublishedAnimeByID(res.AnimeID)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				report.Unmatched = append(report.Unmatched, domain.ListImportUnmatched{ExternalListEntry: res.ExternalListEntry})
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if res.Status == "" {
+				res.Status = domain.ListStatusPlanToWatch
+			}
+			match, err := tx.apply(userID, source, res.ExternalListEntry, anime, false)
+			if err != nil {
+				return err
+			}
+			match.Confidence = 1
+			report.Matched = append(report.Matched, *match)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// transaction runs fn with a service bound to one transaction, so an import is
+// recorded entirely or not at all
+func (s *ListImportService) transaction(fn func(tx *ListImportService) error) error {
+	return s.repo.Transaction(func(repo *repository.SQLiteRepository) error {
+		return fn(&ListImportService{repo: repo, lists: NewAnimeListService(repo)})
+	})
+}
+
+func matchTitles(titles []string, index []titleIndexEntry, exact map[string]int) (*domain.Anime, float64, []domain.ListImportCandidate) {
+	normalized := make([]string, 0, len(titles))
+	for _, t := range titles {
+		if n := normalizeTitle(t); n != "" {
+			if i, ok := exact[n]; ok {
+				return &index[i].anime, 1, nil
+			}
+			normalized = append(normalized, n)
+		}
+	}
+
+	candidates := []domain.ListImportCandidate{}
+	bestIdx, bestScore := -1, 0.0
+	for i, entry := range index {
+		score := 0.0
+		for _, n := range normalized {
+			for _, v := range entry.variants {
+				if sim := titleSimilarity(n, v); sim > score {
+					score = sim
+				}
+			}
+		}
+		if score > bestScore {
+			bestIdx, bestScore = i, score
+		}
+		if score >= listImportCandidateMin {
+			candidates = append(candidates, domain.ListImportCandidate{
+				AnimeID: entry.anime.ID,
+				Title:   entry.anime.Title,
+				TitleEn: entry.anime.TitleEn,
+				Slug:    entry.anime.Slug,
+				Score:   score,
+			})
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Score > candidates[j].Score })
+	if len(candidates) > listImportMaxCandidates {
+		candidates = candidates[:listImportMaxCandidates]
+	}
+	if bestIdx < 0 {
+		return nil, 0, candidates
+	}
+	return &index[bestIdx].anime, bestScore, candidates
+}
+
+// apply writes a matched entry to the user's list. Started entries are also recorded
+// in history as a list import, pointing at the last watched episode when known; the
+// list entry is written last so it is newer than the imported history.
+func (s *ListImportService) apply(userID uint, source string, entry domain.ExternalListEntry, anime *domain.Anime, dryRun bool) (*domain.ListImportMatch, error) {
+	match := &domain.ListImportMatch{ExternalListEntry: entry, AnimeID: anime.ID, Title: anime.Title}
+	if entry.Status != domain.ListStatusPlanToWatch {
+		if err := s.recordHistory(userID, source, entry, anime, dryRun); err != nil {
+			return nil, err
+		}
+		match.History = true
+	}
+	if !dryRun {
+		if err := s.lists.Import(userID, anime.ID, entry); err != nil {
+			return nil, err
+		}
+	}
+	return match, nil
+}
+
+// recordHistory records an imported entry in history, once per source. It uses its own
+// activity type, so views, streaks, feeds and the continue-watching rail never count it.
+func (s *ListImportService) recordHistory(userID uint, source string, entry domain.ExternalListEntry, anime *domain.Anime, dryRun bool) error {
+	animeID := anime.ID
+	imported, err := s.repo.HasImportedHistory(userID, animeID, source)
+	if err != nil || imported || dryRun {
+		return err
+	}
+
+	history := &domain.History{
+		UserID:       userID,
+		ActivityType: domain.ActivityListImport,
+		AnimeID:      &animeID,
+		Image:        anime.Image,
+		ImportSource: source,
+	}
+	if entry.Progress > 0 {
+		episode, err := s.repo.GetPublishedEpisodeByNumber(animeID, entry.Progress)
+		if err != nil {
+			return err
+		}
+		if episode != nil {
+			history.EpisodeID = &episode.ID
+		}
+	}
+	metadata, _ := json.Marshal(map[string]interface{}{
+		"import_source": source,
+		"external_id":   entry.ExternalID,
+		"status":        entry.Status,
+		"progress":      entry.Progress,
+		"score":         entry.Score,
+	})
+	history.Metadata = string(metadata)
+	return s.repo.CreateHistory(history)
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"backend/internal/core/domain"
+	"testing"
+)
+
+func TestListImportRecordsOneListImportPerSource(t *testing.T) {
+	repo := newProgressTestRepo(t)
+	animeID, episodes := seedAnime(t, repo, domain.ContentStatePublished, 3)
+	s := NewListImportService(repo, NewAnimeListService(repo))
+	entries := []domain.ExternalListEntry{
+		{ExternalID: "1", Titles: []string{"Anime 1"}, Status: domain.ListStatusWatching, Progress: 2},
+	}
+
+	for i := 0; i < 2; i++ {
+		report, err := s.Import(1, "mal", entries, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(report.Matched) != 1 || report.Matched[0].AnimeID != animeID || !report.Matched[0].History {
+			t.Fatalf("import %d = %+v, want Anime 1 matched with history", i+1, report)
+		}
+	}
+
+	var rows []domain.History
+	if err := repo.DB().Where("user_id = ?", 1).Find(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("%d history rows after importing twice, want 1", len(rows))
+	}
+	row := rows[0]
+	if row.ActivityType != domain.ActivityListImport || row.ImportSource != "mal" {
+		t.Fatalf("history row type=%q source=%q, want a mal list import", row.ActivityType, row.ImportSource)
+	}
+	if row.EpisodeID == nil || *row.EpisodeID != episodes[1] {
+		t.Fatalf("history row episode = %v, want the last watched episode %d", row.EpisodeID, episodes[1])
+	}
+
+	entry, err := repo.GetListEntry(1, animeID)
+	if err != nil || entry == nil {
+		t.Fatalf("list entry not written: %v", err)
+	}
+	if entry.Status != domain.ListStatusWatching || entry.EpisodesWatched != 2 {
+		t.Fatalf("list entry status=%q watched=%d", entry.Status, entry.EpisodesWatched)
+	}
+}
+
+func TestListImportMatchesOnlyPublishedAnime(t *testing.T) {
+	repo := newProgressTestRepo(t)
+	seedAnime(t, repo, domain.ContentStatePublished, 1)
+	draftID, _ := seedAnime(t, repo, domain.ContentStateDraft, 1)
+	if err := repo.DB().Model(&domain.Anime{}).Where("id = ?", draftID).Updates(map[string]interface{}{"title": "Monster", "slug": "monster"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	s := NewListImportService(repo, NewAnimeListService(repo))
+
+	report, err := s.Import(1, "anilist", []domain.ExternalListEntry{
+		{ExternalID: "1", Titles: []string{"Anime 1"}, Status: domain.ListStatusPlanToWatch},
+		{ExternalID: "2", Titles: []string{"Monster"}, Status: domain.ListStatusPlanToWatch},
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Matched) != 1 || len(report.Unmatched) != 1 {
+		t.Fatalf("import matched %d and left %d unmatched, want 1 and 1", len(report.Matched), len(report.Unmatched))
+	}
+	for _, c := range report.Unmatched[0].Candidates {
+		if c.AnimeID == draftID {
+			t.Fatal("draft anime suggested as a candidate")
+		}
+	}
+
+	// Resolving to a draft by ID is reported as unmatched
+	report, err = s.Resolve(1, "anilist", []domain.ListImportResolution{
+		{ExternalListEntry: domain.ExternalListEntry{ExternalID: "2", Status: domain.ListStatusCompleted}, AnimeID: draftID},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Matched) != 0 || len(report.Unmatched) != 1 {
+		t.Fatalf("resolve to a draft = %+v, want unmatched", report)
+	}
+	if entry, err := repo.GetListEntry(1, draftID); err != nil || entry != nil {
+		t.Fatalf("draft anime added to the list: %+v, %v", entry, err)
+	}
+}
+
+func TestListImportDryRunWritesNothing(t *testing.T) {
+	repo := newProgressTestRepo(t)
+	seedAnime(t, repo, domain.ContentStatePublished, 1)
+	s := NewListImportService(repo, NewAnimeListService(repo))
+
+	report, err := s.Import(1, "mal", []domain.ExternalListEntry{
+		{ExternalID: "1", Titles: []string{"anime-1"}, Status: domain.ListStatusCompleted, Progress: 1},
+	}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.DryRun || len(report.Matched) != 1 {
+		t.Fatalf("dry run = %+v, want one match", report)
+	}
+	for _, model := range []interface{}{&domain.History{}, &domain.AnimeListEntry{}} {
+		var n int64
+		repo.DB().Model(model).Count(&n)
+		if n != 0 {
+			t.Fatalf("dry run wrote %d %T rows", n, model)
+		}
+	}
+}
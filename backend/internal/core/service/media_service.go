@@ -0,0 +1,409 @@
+package service
+
+import (
+	"backend/internal/adapters/repository"
+	"backend/internal/core/domain"
+	"backend/internal/core/port"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+var mediaExtPattern = regexp.MustCompile(`^\.[a-z0-9]{1,10}$`)
+
+const (
+	// mediaClaimPoll and mediaClaimWait bound how long storing waits for another
+	// replica to finish deleting the same content
+	mediaClaimPoll = 50 * time.Millisecond
+	mediaClaimWait = 2 * time.Second
+	// mediaStaleClaim is when a deletion claim is taken to be abandoned by a
+	// replica that stopped, and the deletion is finished by another
+	mediaStaleClaim = 10 * time.Minute
+)
+
+// MediaService stores uploaded files by the SHA-256 of their content, so
+// identical uploads share one file, and deletes a file once the last row
+// referencing it is gone
+type MediaService struct {
+	repo  *repository.SQLiteRepository
+	blobs port.BlobStore
+	// presign is how long download links handed to clients stay valid
+	presign time.Duration
+	// mu serialises storing and sweeping so a file is never deleted while the
+	// same content is being stored again
+	mu sync.Mutex
+}
+
+func NewMediaService(repo *repository.SQLiteRepository, blobs port.BlobStore, presign time.Duration) *MediaService {
+	return &MediaService{repo: repo, blobs: blobs, presign: presign}
+}
+
+// Blobs is the store holding the files, for services that keep their own files
+// next to the stored media
+func (s *MediaService) Blobs() port.BlobStore {
+	return s.blobs
+}
+
+// mediaExt normalises the extension a file is stored under
+func mediaExt(ext string) string {
+	ext = strings.ToLower(ext)
+	if !mediaExtPattern.MatchString(ext) {
+		return ".bin"
+	}
+	return ext
+}
+
+// TempFile creates a local file for content that is about to be stored, so it
+// can be hashed and inspected before it is written to the blob store
+func (s *MediaService) TempFile(ext string) (*os.File, error) {
+	return os.CreateTemp("", "media-*"+mediaExt(ext))
+}
+
+// Store writes r to the store. ext is used when the content is new; stored
+// content keeps the extension of its first upload.
+func (s *MediaService) Store(r io.Reader, ext string) (*domain.Media, error) {
+	tmp, err := s.TempFile(ext)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return nil, err
+	}
+	return s.StoreFile(tmp.Name(), ext)
+}
+
+// StoreFile moves the local file into the store, or deletes it when the same
+// content is already stored. Content another replica is deleting is stored
+// again once the deletion finished.
+func (s *MediaService) StoreFile(file, ext string) (*domain.Media, error) {
+	id, size, mimeType, err := hashFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for wait := mediaClaimPoll; ; wait *= 2 {
+		media, err := s.storeFile(file, ext, id, size, mimeType)
+		if !errors.Is(err, domain.ErrMediaDeleting) || wait > mediaClaimWait {
+			return media, err
+		}
+		time.Sleep(wait)
+	}
+}
+
+func (s *MediaService) storeFile(file, ext, id string, size int64, mimeType string) (*domain.Media, error) {
+	media, err := s.repo.GetMedia(id)
+	if err == nil {
+		if media.Deleting {
+			return nil, domain.ErrMediaDeleting
+		}
+		if _, statErr := s.blobs.Stat(blobKey(media.Path)); statErr == nil {
+			// Reviving the row keeps a sweep from claiming it
+			if err := s.repo.SaveMedia(media); err != nil {
+				return nil, err
+			}
+			os.Remove(file)
+			return media, nil
+		}
+		// The row outlived its file: store the content again under the old name
+		ext = media.Ext
+	} else if !errors.Is(err, domain.ErrMediaNotFound) {
+		return nil, err
+	}
+
+	ext = mediaExt(ext)
+	dst := path.Join(domain.MediaDir, id[:2], id+ext)
+	media = &domain.Media{
+		ID:       id,
+		Size:     size,
+		MimeType: mimeType,
+		Ext:      ext,
+		Path:     dst,
+		URL:      "/" + dst,
+	}
+	// The row goes first: once it is saved no sweep deletes the file being written
+	if err := s.repo.SaveMedia(media); err != nil {
+		return nil, err
+	}
+	if err := putFile(s.blobs, file, blobKey(dst)); err != nil {
+		return nil, err
+	}
+	return media, nil
+}
+
+// hashFile returns the hex SHA-256, size and sniffed MIME type of a file
+func hashFile(path string) (string, int64, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, "", err
+	}
+	defer f.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", 0, "", err
+	}
+	hash := sha256.New()
+	hash.Write(head[:n])
+	rest, err := io.Copy(hash, f)
+	if err != nil {
+		return "", 0, "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), int64(n) + rest, http.DetectContentType(head[:n]), nil
+}
+
+// Get returns a stored file by its hash
+func (s *MediaService) Get(id string) (*domain.Media, error) {
+	return s.repo.GetMedia(id)
+}
+
+// DownloadURL returns a link that downloads the upload at ref as filename. On
+// S3 it is presigned and expires; local links go through the /uploads route.
+func (s *MediaService) DownloadURL(ref, filename string) (string, error) {
+	source, ok := legacyMediaPath(ref)
+	if !ok {
+		return "", domain.ErrBlobNotFound
+	}
+	key := blobKey(source)
+	if _, err := s.blobs.Stat(key); err != nil {
+		return "", err
+	}
+	return s.blobs.URL(key, filename, s.presign)
+}
+
+// Track records which stored files the fields of a row point at, given as the
+// URLs saved on the row. URLs outside the store drop the field's reference.
+// Files left without references are deleted unless a revision still holds them.
+func (s *MediaService) Track(ownerType string, ownerID uint, fields map[string]string) error {
+	refs := make(map[string]string, len(fields))
+	for field, url := range fields {
+		id, _ := domain.MediaIDFromURL(url)
+		refs[field] = id
+	}
+	if err := s.repo.SetMediaRefs(ownerType, ownerID, refs); err != nil {
+		return err
+	}
+	_, err := s.Sweep()
+	return err
+}
+
+// track is Track for services that must not fail a save over bookkeeping:
+// errors are logged and Reconcile repairs the references later
+func (s *MediaService) track(ownerType string, ownerID uint, fields map[string]string) {
+	if err := s.Track(ownerType, ownerID, fields); err != nil {
+		log.Printf("Media: references of %s %d: %v", ownerType, ownerID, err)
+	}
+}
+
+// Sweep deletes the files whose last reference was dropped and returns how many
+// were removed. Files a revision snapshot points at are kept for rollbacks and
+// left to the garbage collector once the revision is gone. Each row is claimed
+// before its file is deleted, so replicas sweeping at once delete a file once
+// and none stores the same content again in between.
+func (s *MediaService) Sweep() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	staleClaim := time.Now().Add(-mediaStaleClaim)
+	released, err := s.repo.GetReleasedMedia(staleClaim)
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, media := range released {
+		ok, err := s.repo.ClaimReleasedMedia(media.ID, staleClaim)
+		if err != nil {
+			return removed, err
+		}
+		if !ok {
+			continue
+		}
+		if err := s.blobs.Delete(blobKey(media.Path)); err != nil {
+			log.Printf("Media: failed to remove %s: %v", media.Path, err)
+			if err := s.repo.UnclaimMedia(media.ID); err != nil {
+				return removed, err
+			}
+			continue
+		}
+		if err := s.repo.DeleteClaimedMedia(media.ID); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// RemoveOrphan hands the file of stored content that no row references and that
+// was not stored again since before to remove, e.g. to quarantine it. The row is
+// claimed first, so no replica stores the content again meanwhile, and deleted
+// once remove succeeded. It reports whether remove ran.
+func (s *MediaService) RemoveOrphan(id, key string, before time.Time, remove func() error) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dst := "uploads/" + key
+	claimed, err := s.repo.ClaimOrphanMedia(&domain.Media{ID: id, Ext: path.Ext(key), Path: dst, URL: "/" + dst}, before)
+	if err != nil || !claimed {
+		return false, err
+	}
+	if err := remove(); err != nil {
+		if unclaimErr := s.repo.UnclaimMedia(id); unclaimErr != nil {
+			log.Printf("Media: failed to release the claim on %s: %v", id, unclaimErr)
+		}
+		return false, err
+	}
+	return true, s.repo.DeleteClaimedMedia(id)
+}
+
+// Reconcile rebuilds the references and counts from the URLs stored on the rows,
+// correcting any drift from writes that bypassed Track
+func (s *MediaService) Reconcile() error {
+	refs, err := s.repo.CollectMediaRefs()
+	if err != nil {
+		return err
+	}
+	return s.repo.ReplaceMediaRefs(refs)
+}
+
+// ImportLegacy copies uploads referenced by rows but stored outside the store
+// into it and points the rows at the stored copies. Originals are left in
+// place for garbage collection. It returns how many files were imported.
+func (s *MediaService) ImportLegacy() (int, error) {
+	imported := 0
+	for _, column := range domain.MediaColumns {
+		values, err := s.repo.GetLegacyMediaValues(column)
+		if err != nil {
+			return imported, err
+		}
+		for _, value := range values {
+			source, ok := legacyMediaPath(value)
+			if !ok {
+				continue
+			}
+			src, err := s.blobs.Get(blobKey(source))
+			if errors.Is(err, domain.ErrBlobNotFound) {
+				continue
+			}
+			if err != nil {
+				return imported, err
+			}
+			media, err := s.Store(src, path.Ext(source))
+			src.Close()
+			if err != nil {
+				return imported, fmt.Errorf("import %s: %w", source, err)
+			}
+			// Keep the column's style: model paths have no leading slash
+			url := media.URL
+			if !strings.HasPrefix(value, "/") && !strings.HasPrefix(value, "http") {
+				url = media.Path
+			}
+			if err := s.replaceLegacyValue(column, value, url); err != nil {
+				return imported, err
+			}
+			imported++
+		}
+	}
+	return imported, nil
+}
+
+// replaceLegacyValue points the rows holding a legacy value at its stored copy.
+// Anime and episodes are saved as a new version with an import revision, like
+// any other change to them; trashed ones keep the legacy value, whose file the
+// garbage collector leaves alone while they can be restored.
+func (s *MediaService) replaceLegacyValue(column domain.MediaColumn, value, url string) error {
+	if column.OwnerType != domain.MediaOwnerAnime && column.OwnerType != domain.MediaOwnerEpisode {
+		return s.repo.ReplaceMediaValue(column, value, url)
+	}
+	ids, err := s.repo.GetMediaValueOwners(column, value)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		err := s.repo.Transaction(func(tx *repository.SQLiteRepository) error {
+			if column.OwnerType == domain.MediaOwnerAnime {
+				return replaceAnimeMedia(tx, id, column.Field, url)
+			}
+			return replaceEpisodeMedia(tx, id, column.Field, url)
+		})
+		if err != nil {
+			return fmt.Errorf("%s %d: %w", column.OwnerType, id, err)
+		}
+	}
+	return nil
+}
+
+func replaceAnimeMedia(tx *repository.SQLiteRepository, id uint, field, url string) error {
+	anime, err := tx.GetAnimeByID(id)
+	if err != nil {
+		return err
+	}
+	if err := recordBaseline(tx, domain.RevisionEntityAnime, anime.ID, anime.Version, func() (json.RawMessage, error) {
+		return animeSnapshot(anime)
+	}); err != nil {
+		return err
+	}
+	switch field {
+	case "image":
+		anime.Image = url
+	case "cover":
+		anime.Cover = url
+	}
+	snapshot, err := animeSnapshot(anime)
+	if err != nil {
+		return err
+	}
+	return tx.SaveAnimeWithCategories(anime, nil, newRevision(domain.RevisionActionImport, 0, snapshot))
+}
+
+func replaceEpisodeMedia(tx *repository.SQLiteRepository, id uint, field, url string) error {
+	episode, err := tx.GetEpisodeByID(id)
+	if err != nil {
+		return err
+	}
+	if err := recordBaseline(tx, domain.RevisionEntityEpisode, episode.ID, episode.Version, func() (json.RawMessage, error) {
+		return episodeSnapshot(episode)
+	}); err != nil {
+		return err
+	}
+	switch field {
+	case "thumbnail":
+		episode.Thumbnail = url
+	case "banner":
+		episode.Banner = url
+	}
+	snapshot, err := episodeSnapshot(episode)
+	if err != nil {
+		return err
+	}
+	return tx.SaveEpisodeWithServers(episode, nil, newRevision(domain.RevisionActionImport, 0, snapshot))
+}
+
+// legacyMediaPath maps a column value to an upload path under uploads/
+func legacyMediaPath(value string) (string, bool) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "http://localhost:8080")
+	value = strings.TrimPrefix(value, "/")
+	if !strings.HasPrefix(value, "uploads/") || strings.Contains(value, "..") {
+		return "", false
+	}
+	return value, true
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"backend/internal/adapters/repository"
+	"backend/internal/adapters/storage"
+	"backend/internal/core/domain"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newMediaTestService(t *testing.T) (*MediaService, *repository.SQLiteRepository) {
+	t.Helper()
+	repo := newProgressTestRepo(t)
+	return NewMediaService(repo, storage.NewLocalStore(t.TempDir()), 0), repo
+}
+
+func storeMedia(t *testing.T, s *MediaService, content string) *domain.Media {
+	t.Helper()
+	media, err := s.Store(strings.NewReader(content), ".jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return media
+}
+
+func storedMedia(t *testing.T, repo *repository.SQLiteRepository, id string) domain.Media {
+	t.Helper()
+	var media domain.Media
+	if err := repo.DB().Where("id = ?", id).First(&media).Error; err != nil {
+		t.Fatal(err)
+	}
+	return media
+}
+
+func TestMediaStoreSharesIdenticalContent(t *testing.T) {
+	s, repo := newMediaTestService(t)
+	first := storeMedia(t, s, "cover")
+	second, err := s.Store(strings.NewReader("cover"), ".png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.ID != first.ID || second.Ext != ".jpg" {
+		t.Fatalf("second store = %+v, want the first file %s", second, first.Path)
+	}
+	if n := countRows(t, repo, &domain.Media{}); n != 1 {
+		t.Fatalf("%d media rows for identical content", n)
+	}
+}
+
+func TestMediaRefCountsAndSweep(t *testing.T) {
+	s, repo := newMediaTestService(t)
+	media := storeMedia(t, s, "cover")
+	animeID, _ := seedAnime(t, repo, domain.ContentStatePublished, 0)
+	otherID, _ := seedAnime(t, repo, domain.ContentStatePublished, 0)
+
+	if err := s.Track(domain.MediaOwnerAnime, animeID, map[string]string{"cover": media.URL, "image": media.URL}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Track(domain.MediaOwnerAnime, otherID, map[string]string{"cover": media.URL}); err != nil {
+		t.Fatal(err)
+	}
+	if got := storedMedia(t, repo, media.ID); got.RefCount != 3 || got.ReleasedAt != nil {
+		t.Fatalf("ref_count = %d released = %v, want 3 and live", got.RefCount, got.ReleasedAt)
+	}
+
+	// Dropping one field keeps the file, dropping the last reference deletes it
+	if err := s.Track(domain.MediaOwnerAnime, animeID, map[string]string{"cover": "", "image": ""}); err != nil {
+		t.Fatal(err)
+	}
+	if got := storedMedia(t, repo, media.ID); got.RefCount != 1 {
+		t.Fatalf("ref_count = %d, want 1", got.RefCount)
+	}
+	if err := s.Track(domain.MediaOwnerAnime, otherID, map[string]string{"cover": "https://example.com/cover.jpg"}); err != nil {
+		t.Fatal(err)
+	}
+	if n := countRows(t, repo, &domain.Media{}); n != 0 {
+		t.Fatal("released media row was not swept")
+	}
+	if _, err := s.blobs.Stat(blobKey(media.Path)); !errors.Is(err, domain.ErrBlobNotFound) {
+		t.Fatalf("released file: %v, want it deleted", err)
+	}
+}
+
+func TestMediaSweepKeepsFilesHeldBySnapshots(t *testing.T) {
+	s, repo := newMediaTestService(t)
+	media := storeMedia(t, s, "cover")
+	animeID, _ := seedAnime(t, repo, domain.ContentStatePublished, 0)
+	revision := domain.Revision{EntityType: domain.RevisionEntityAnime, EntityID: animeID, Version: 1, Snapshot: []byte(`{"cover":"` + media.URL + `"}`)}
+	if err := repo.DB().Create(&revision).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Track(domain.MediaOwnerAnime, animeID, map[string]string{"cover": media.URL}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Track(domain.MediaOwnerAnime, animeID, map[string]string{"cover": ""}); err != nil {
+		t.Fatal(err)
+	}
+	if got := storedMedia(t, repo, media.ID); got.ReleasedAt == nil {
+		t.Fatal("unreferenced media was not marked released")
+	}
+	if _, err := s.blobs.Stat(blobKey(media.Path)); err != nil {
+		t.Fatalf("file held by a revision was deleted: %v", err)
+	}
+}
+
+func TestMediaSweepClaimsBeforeDeleting(t *testing.T) {
+	s, repo := newMediaTestService(t)
+	media := storeMedia(t, s, "cover")
+	released := time.Now().Add(-time.Minute)
+	if err := repo.DB().Model(&domain.Media{}).Where("id = ?", media.ID).UpdateColumn("released_at", released).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// Another replica claimed the row: this one leaves the file to it
+	claimed, err := repo.ClaimReleasedMedia(media.ID, time.Now().Add(-mediaStaleClaim))
+	if err != nil || !claimed {
+		t.Fatalf("claim = %v, %v", claimed, err)
+	}
+	if again, _ := repo.ClaimReleasedMedia(media.ID, time.Now().Add(-mediaStaleClaim)); again {
+		t.Fatal("a claimed row was claimed twice")
+	}
+	if n, err := s.Sweep(); err != nil || n != 0 {
+		t.Fatalf("Sweep = %d, %v; want the claimed file left alone", n, err)
+	}
+	if err := repo.SaveMedia(&domain.Media{ID: media.ID, Path: media.Path, URL: media.URL}); !errors.Is(err, domain.ErrMediaDeleting) {
+		t.Fatalf("reviving a claimed row = %v, want ErrMediaDeleting", err)
+	}
+
+	// Storing the same content waits until the other replica finished
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		s.blobs.Delete(blobKey(media.Path))
+		repo.DeleteClaimedMedia(media.ID)
+	}()
+	stored := storeMedia(t, s, "cover")
+	if got := storedMedia(t, repo, stored.ID); got.Deleting || got.ReleasedAt != nil {
+		t.Fatalf("stored again as %+v, want a live row", got)
+	}
+	if _, err := s.blobs.Stat(blobKey(stored.Path)); err != nil {
+		t.Fatalf("file stored after the deletion: %v", err)
+	}
+}
+
+func TestMediaSweepFinishesAbandonedClaims(t *testing.T) {
+	s, repo := newMediaTestService(t)
+	media := storeMedia(t, s, "cover")
+	abandoned := time.Now().Add(-2 * mediaStaleClaim)
+	if err := repo.DB().Model(&domain.Media{}).Where("id = ?", media.ID).
+		UpdateColumns(map[string]any{"deleting": true, "released_at": abandoned, "updated_at": abandoned}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if n, err := s.Sweep(); err != nil || n != 1 {
+		t.Fatalf("Sweep = %d, %v; want the abandoned claim finished", n, err)
+	}
+	if _, err := s.blobs.Stat(blobKey(media.Path)); !errors.Is(err, domain.ErrBlobNotFound) {
+		t.Fatalf("file of the abandoned claim: %v, want it deleted", err)
+	}
+}
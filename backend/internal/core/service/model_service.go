@@ -4,26 +4,77 @@ import (
 	"backend/internal/core/domain"
 	"backend/internal/core/port"
 	"errors"
-	"io"
 	"mime/multipart"
-	"os"
 	"path/filepath"
 	"time"
 )
 
+var errModelType = errors.New("invalid file type. Only FBX, GLB/GLTF and BVH allowed")
+
+func isModelExtension(ext string) bool {
+	return ext == ".fbx" || ext == ".glb" || ext == ".gltf" || ext == ".bvh"
+}
+
 type ModelService struct {
-	repo port.ModelRepository
+	repo   port.ModelRepository
+	images *ImageService
+	media  *MediaService
 }
 
-func NewModelService(repo port.ModelRepository) *ModelService {
-	return &ModelService{repo: repo}
+func NewModelService(repo port.ModelRepository, images *ImageService, media *MediaService) *ModelService {
+	return &ModelService{repo: repo, images: images, media: media}
+}
+
+// storeFile puts an uploaded file in the media store and returns its path
+func (s *ModelService) storeFile(file *multipart.FileHeader) (string, error) {
+	src, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+	media, err := s.media.Store(src, filepath.Ext(file.Filename))
+	if err != nil {
+		return "", err
+	}
+	return media.Path, nil
 }
 
+// storeImage stores a model's preview image with its variants. Without an
+// uploaded mini blur, the server-side LQIP takes its place.
+func (s *ModelService) storeImage(model *domain.Model, image *multipart.FileHeader, miniBlurUploaded bool) error {
+	src, err := image.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	media, asset, err := s.images.Store(src, filepath.Ext(image.Filename))
+	if err != nil {
+		return err
+	}
+	model.Image = media.Path
+	if !miniBlurUploaded {
+		model.MiniBlurPath = MiniBlurPath(asset)
+	}
+	return nil
+}
+
+// track records the stored files a model points at, releasing replaced ones
+func (s *ModelService) track(model *domain.Model) {
+	s.media.track(domain.MediaOwnerModel, model.ID, map[string]string{
+		"file":      model.Path,
+		"image":     model.Image,
+		"mini_blur": model.MiniBlurPath,
+	})
+}
+
+// Upload stores the model file, image and mini blur by content, so re-uploads
+// share files instead of overwriting each other by name. Files of a failed
+// upload stay unreferenced until garbage collection.
 func (s *ModelService) Upload(name string, title string, file *multipart.FileHeader, image *multipart.FileHeader, miniBlur *multipart.FileHeader, category string) (*domain.Model, error) {
 	// 1. Validate extension
 	ext := filepath.Ext(file.Filename)
-	if ext != ".fbx" && ext != ".glb" && ext != ".gltf" && ext != ".bvh" {
-		return nil, errors.New("invalid file type. Only FBX, GLB/GLTF and BVH allowed")
+	if !isModelExtension(ext) {
+		return nil, errModelType
 	}
 
 	// 2. Validate size (e.g., max 100MB)
@@ -31,135 +82,83 @@ func (s *ModelService) Upload(name string, title string, file *multipart.FileHea
 		return nil, errors.New("file too large. Max 100MB allowed")
 	}
 
-	// 3. Create upload directory
-	uploadDir := "uploads/models"
-	if err := os.MkdirAll(uploadDir, os.ModePerm); err != nil {
-		return nil, err
-	}
-
-	// 4. Save model file
-	filename := time.Now().Format("20060102150405") + "_" + file.Filename
-	path := filepath.Join(uploadDir, filename)
-
-	src, err := file.Open()
+	// 3. Store model file
+	path, err := s.storeFile(file)
 	if err != nil {
 		return nil, err
 	}
-	defer src.Close()
 
-	dst, err := os.Create(path)
-	if err != nil {
-		return nil, err
-	}
-	defer dst.Close()
-
-	if _, err = io.Copy(dst, src); err != nil {
-		return nil, err
+	model := &domain.Model{
+		Name:     name,
+		Title:    title,
+		Path:     path,
+		Category: category,
+		Size:     file.Size,
+		Type:     ext[1:], // remove dot
 	}
 
-	// 5. Save image file (if provided)
-	var imagePath string
+	// 4. Store image (if provided)
 	if image != nil {
-		imageDir := "uploads/images"
-		if err := os.MkdirAll(imageDir, os.ModePerm); err != nil {
-			os.Remove(path) // Cleanup model file
-			return nil, err
-		}
-
-		// Use the filename as-is (frontend already renamed it to title.ext)
-		imageFilename := image.Filename
-		imagePath = filepath.Join(imageDir, imageFilename)
-
-		imgSrc, err := image.Open()
-		if err != nil {
-			os.Remove(path)
-			return nil, err
-		}
-		defer imgSrc.Close()
-
-		imgDst, err := os.Create(imagePath)
-		if err != nil {
-			os.Remove(path)
-			return nil, err
-		}
-		defer imgDst.Close()
-
-		if _, err = io.Copy(imgDst, imgSrc); err != nil {
-			os.Remove(path)
+		if err := s.storeImage(model, image, miniBlur != nil); err != nil {
 			return nil, err
 		}
 	}
 
-	// 5.5 Save mini blur file (if provided)
-	var miniBlurPath string
+	// 5. Store mini blur (if provided)
 	if miniBlur != nil {
-		imageDir := "uploads/images"
-		// Ensure dir exists (already done above, but safe to call again or check)
-		if err := os.MkdirAll(imageDir, os.ModePerm); err != nil {
-			// cleanup checks...
-			os.Remove(path)
-			if imagePath != "" {
-				os.Remove(imagePath)
-			}
+		if model.MiniBlurPath, err = s.storeFile(miniBlur); err != nil {
 			return nil, err
 		}
+	}
 
-		// Use the filename as-is (frontend already renamed it to title-blur.jpg)
-		blurFilename := miniBlur.Filename
-		miniBlurPath = filepath.Join(imageDir, blurFilename)
+	// 6. Save to DB
+	if err := s.repo.CreateModel(model); err != nil {
+		return nil, err
+	}
+	s.track(model)
 
-		blurSrc, err := miniBlur.Open()
-		if err != nil {
-			os.Remove(path)
-			if imagePath != "" {
-				os.Remove(imagePath)
-			}
-			return nil, err
-		}
-		defer blurSrc.Close()
+	return model, nil
+}
 
-		blurDst, err := os.Create(miniBlurPath)
-		if err != nil {
-			os.Remove(path)
-			if imagePath != "" {
-				os.Remove(imagePath)
-			}
-			return nil, err
-		}
-		defer blurDst.Close()
+// UploadTarget creates models from resumable uploads whose metadata carries
+// filename, name, title and category. Unlike Upload there is no 100MB cap: the
+// file is streamed to disk and the upload endpoint enforces its own maximum.
+func (s *ModelService) UploadTarget() TusTarget {
+	return TusTarget{Validate: validateModelUpload, Finalize: s.finalizeUpload}
+}
 
-		if _, err = io.Copy(blurDst, blurSrc); err != nil {
-			os.Remove(path)
-			if imagePath != "" {
-				os.Remove(imagePath)
-			}
-			return nil, err
-		}
+func validateModelUpload(metadata map[string]string) error {
+	if !isModelExtension(filepath.Ext(metadata["filename"])) {
+		return errModelType
+	}
+	if metadata["name"] == "" {
+		return errors.New("name is required")
 	}
+	return nil
+}
 
-	// 6. Save to DB
-	model := &domain.Model{
-		Name:         name,
-		Title:        title,
-		Path:         path,
-		Image:        imagePath,
-		MiniBlurPath: miniBlurPath,
-		Category:     category,
-		Size:         file.Size,
-		Type:         ext[1:], // remove dot
+func (s *ModelService) finalizeUpload(upload *domain.TusUpload, path string) (any, error) {
+	if err := validateModelUpload(upload.Metadata); err != nil {
+		return nil, err
+	}
+	ext := filepath.Ext(upload.Metadata["filename"])
+	media, err := s.media.StoreFile(path, ext)
+	if err != nil {
+		return nil, err
 	}
 
+	model := &domain.Model{
+		Name:     upload.Metadata["name"],
+		Title:    upload.Metadata["title"],
+		Path:     media.Path,
+		Category: upload.Metadata["category"],
+		Size:     upload.Length,
+		Type:     ext[1:], // remove dot
+	}
 	if err := s.repo.CreateModel(model); err != nil {
-		os.Remove(path)
-		if imagePath != "" {
-			os.Remove(imagePath)
-		}
-		if miniBlurPath != "" {
-			os.Remove(miniBlurPath)
-		}
 		return nil, err
 	}
-
+	s.track(model)
 	return model, nil
 }
 
@@ -185,71 +184,18 @@ func (s *ModelService) Update(id uint, name string, title string, image *multipa
 		model.Category = category
 	}
 
-	// 3. Handle Image Update
-	imageDir := "uploads/images"
-	if image != nil || miniBlur != nil {
-		if err := os.MkdirAll(imageDir, os.ModePerm); err != nil {
-			return nil, err
-		}
-	}
-
+	// 3. Handle Image Update. Replaced files are deleted once nothing else uses them.
 	if image != nil {
-		// Save new image (use filename as-is from frontend)
-		imageFilename := image.Filename
-		newImagePath := filepath.Join(imageDir, imageFilename)
-
-		src, err := image.Open()
-		if err != nil {
-			return nil, err
-		}
-		defer src.Close()
-
-		dst, err := os.Create(newImagePath)
-		if err != nil {
+		if err := s.storeImage(model, image, miniBlur != nil); err != nil {
 			return nil, err
 		}
-		defer dst.Close()
-
-		if _, err = io.Copy(dst, src); err != nil {
-			return nil, err
-		}
-
-		// Delete old image if it exists
-		if model.Image != "" {
-			os.Remove(model.Image)
-		}
-
-		model.Image = newImagePath
 	}
 
 	// 3.5 Handle Mini Blur Update
 	if miniBlur != nil {
-		// Save new blur (use filename as-is from frontend)
-		blurFilename := miniBlur.Filename
-		newBlurPath := filepath.Join(imageDir, blurFilename)
-
-		src, err := miniBlur.Open()
-		if err != nil {
-			return nil, err
-		}
-		defer src.Close()
-
-		dst, err := os.Create(newBlurPath)
-		if err != nil {
-			return nil, err
-		}
-		defer dst.Close()
-
-		if _, err = io.Copy(dst, src); err != nil {
+		if model.MiniBlurPath, err = s.storeFile(miniBlur); err != nil {
 			return nil, err
 		}
-
-		// Delete old blur if it exists
-		if model.MiniBlurPath != "" {
-			os.Remove(model.MiniBlurPath)
-		}
-
-		model.MiniBlurPath = newBlurPath
 	}
 
 	model.UpdatedAt = time.Now()
@@ -258,31 +204,24 @@ func (s *ModelService) Update(id uint, name string, title string, image *multipa
 	if err := s.repo.UpdateModel(model); err != nil {
 		return nil, err
 	}
+	s.track(model)
 
 	return model, nil
 }
 
+// Delete only soft-deletes the model. Its files stay on disk until it is purged from the trash.
 func (s *ModelService) Delete(id uint) error {
-	model, err := s.repo.GetModelByID(id)
-	if err != nil {
+	if _, err := s.repo.GetModelByID(id); err != nil {
 		return err
 	}
-
-	// Delete file
-	if err := os.Remove(model.Path); err != nil && !os.IsNotExist(err) {
-		// Log error but continue
-	}
-
-	// Delete image
-	if model.Image != "" {
-		if err := os.Remove(model.Image); err != nil && !os.IsNotExist(err) {
-			// Log error but continue
-		}
-	}
-
 	return s.repo.DeleteModel(id)
 }
 
 func (s *ModelService) GetByID(id uint) (*domain.Model, error) {
 	return s.repo.GetModelByID(id)
 }
+
+// DownloadURL returns a link that downloads the model file as <name>.<type>
+func (s *ModelService) DownloadURL(model *domain.Model) (string, error) {
+	return s.media.DownloadURL(model.Path, model.Name+"."+model.Type)
+}
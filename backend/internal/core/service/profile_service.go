@@ -0,0 +1,194 @@
+package service
+
+import (
+	"backend/internal/adapters/repository"
+	"backend/internal/core/domain"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	profileActivity  = 10
+	profileFavorites = 10
+	profileGenres    = 5
+)
+
+// ProfileService builds the public profile of a user from their list, history
+// and follows, leaving out what their privacy settings keep to themselves
+type ProfileService struct {
+	repo   *repository.SQLiteRepository
+	lists  *AnimeListService
+	images *ImageService
+}
+
+func NewProfileService(repo *repository.SQLiteRepository, lists *AnimeListService, images *ImageService) *ProfileService {
+	return &ProfileService{repo: repo, lists: lists, images: images}
+}
+
+// Get returns the profile of a user by ID or handle, with or without the @.
+// viewerID is 0 for guests.
+func (s *ProfileService) Get(viewerID uint, ref string) (*domain.UserProfile, error) {
+	user, err := s.resolve(ref)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, domain.ErrUserNotFound
+	}
+	if err := s.images.AttachUsers(user); err != nil {
+		log.Printf("Profiles: attach avatar: %v", err)
+	}
+
+	profile := &domain.UserProfile{
+		ID:        user.ID,
+		Name:      user.Name,
+		Avatar:    user.Avatar,
+		AvatarSet: user.AvatarSet,
+		JoinedAt:  user.CreatedAt,
+	}
+	if user.Username != nil {
+		profile.Username = *user.Username
+	}
+	if profile.Follow, err = s.repo.GetUserFollowStatus(viewerID, user.ID); err != nil {
+		return nil, err
+	}
+
+	privacies, err := s.repo.GetUserPrivacies([]uint{user.ID})
+	if err != nil {
+		return nil, err
+	}
+	privacy, ok := privacies[user.ID]
+	if !ok {
+		privacy = domain.DefaultUserPrivacy(user.ID)
+	}
+	profile.RecentActivity = []domain.History{}
+	if types := privacy.PublicActivityTypes(); len(types) > 0 {
+		source := domain.FeedSource{UserIDs: []uint{user.ID}, Types: types}
+		if profile.RecentActivity, err = s.repo.GetFeed([]domain.FeedSource{source}, nil, profileActivity); err != nil {
+			return nil, err
+		}
+	}
+
+	profile.ListHidden = !privacy.ShowList
+	profile.Favorites = []domain.FavoriteAnime{}
+	profile.TopGenres = []domain.GenreCount{}
+	if privacy.ShowList {
+		if profile.ListStats, err = s.lists.Stats(user.ID); err != nil {
+			return nil, err
+		}
+		if profile.Favorites, err = s.repo.GetFavoriteListEntries(user.ID, profileFavorites); err != nil {
+			return nil, err
+		}
+		if profile.TopGenres, err = s.repo.GetTopGenres(user.ID, profileGenres); err != nil {
+			return nil, err
+		}
+		animes := make([]*domain.Anime, 0, len(profile.Favorites))
+		for _, favorite := range profile.Favorites {
+			animes = append(animes, favorite.Anime)
+		}
+		if err := s.images.AttachAnimes(animes...); err != nil {
+			log.Printf("Profiles: attach anime images: %v", err)
+		}
+	}
+
+	if profile.Badges, err = s.badges(user, profile); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// resolve finds a user by numeric ID or by handle. Handles always have a
+// letter, so a number is never a handle.
+func (s *ProfileService) resolve(ref string) (*domain.User, error) {
+	if id, err := strconv.ParseUint(ref, 10, 64); err == nil {
+		user, err := s.repo.GetUserByID(uint(id))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return user, err
+	}
+	username := domain.NormalizeUsername(ref)
+	if !domain.ValidUsername(username) {
+		return nil, nil
+	}
+	return s.repo.GetUserByUsername(username)
+}
+
+// badgeTier is a badge earned once a count reaches a threshold
+type badgeTier struct {
+	min   int64
+	badge domain.Badge
+}
+
+var (
+	completedBadges = []badgeTier{
+		{10, domain.Badge{Key: "completed_10", Name: "Getting Started", Description: "Completed 10 anime"}},
+		{50, domain.Badge{Key: "completed_50", Name: "Seasoned Viewer", Description: "Completed 50 anime"}},
+		{100, domain.Badge{Key: "completed_100", Name: "Centurion", Description: "Completed 100 anime"}},
+	}
+	episodeBadges = []badgeTier{
+		{100, domain.Badge{Key: "episodes_100", Name: "Binge Watcher", Description: "Watched 100 episodes"}},
+		{1000, domain.Badge{Key: "episodes_1000", Name: "Marathoner", Description: "Watched 1000 episodes"}},
+	}
+	criticBadges = []badgeTier{
+		{25, domain.Badge{Key: "critic", Name: "Critic", Description: "Scored 25 anime"}},
+	}
+	commentBadges = []badgeTier{
+		{50, domain.Badge{Key: "commentator", Name: "Commentator", Description: "Wrote 50 comments"}},
+	}
+	followerBadges = []badgeTier{
+		{10, domain.Badge{Key: "followers_10", Name: "Rising Star", Description: "Followed by 10 users"}},
+		{100, domain.Badge{Key: "followers_100", Name: "Popular", Description: "Followed by 100 users"}},
+	}
+)
+
+// highestTier returns the best badge a count earns, if any
+func highestTier(tiers []badgeTier, count int64) (domain.Badge, bool) {
+	for i := len(tiers) - 1; i >= 0; i-- {
+		if count >= tiers[i].min {
+			return tiers[i].badge, true
+		}
+	}
+	return domain.Badge{}, false
+}
+
+// badges works out the badges of a profile. Badges that give away what is on
+// a hidden list are left out.
+func (s *ProfileService) badges(user *domain.User, profile *domain.UserProfile) ([]domain.Badge, error) {
+	badges := []domain.Badge{}
+	earn := func(tiers []badgeTier, count int64) {
+		if badge, ok := highestTier(tiers, count); ok {
+			badges = append(badges, badge)
+		}
+	}
+
+	if years := int(time.Since(user.CreatedAt).Hours() / (24 * 365)); years >= 1 {
+		badges = append(badges, domain.Badge{
+			Key:         "veteran",
+			Name:        "Veteran",
+			Description: fmt.Sprintf("Member for %d+ years", years),
+		})
+	}
+	earn(followerBadges, profile.Follow.FollowersCount)
+	comments, err := s.repo.CountUserComments(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	earn(commentBadges, comments)
+
+	if stats := profile.ListStats; stats != nil {
+		episodes, err := s.repo.CountCompletedEpisodes(user.ID)
+		if err != nil {
+			return nil, err
+		}
+		earn(completedBadges, stats.ByStatus[domain.ListStatusCompleted])
+		earn(episodeBadges, episodes)
+		earn(criticBadges, stats.Scored)
+	}
+	return badges, nil
+}
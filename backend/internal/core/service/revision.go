@@ -0,0 +1,118 @@
+package service
+
+import (
+	"backend/internal/core/domain"
+	"backend/internal/core/port"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sort"
+)
+
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// Fields left out of snapshots: bookkeeping, workflow state (changed through transitions only)
+// and preloaded relations (kept as IDs instead)
+var (
+	animeSnapshotOmit   = []string{"id", "version", "state", "created_at", "updated_at", "user_id", "season", "studio", "language_rel", "categories", "category_ids"}
+	episodeSnapshotOmit = []string{"id", "version", "state", "is_published", "created_at", "updated_at", "anime", "servers"}
+)
+
+// snapshotOf encodes v as a flat JSON object without the omitted keys, then lets extra add
+// normalized replacements (category IDs, server lists).
+func snapshotOf(v interface{}, omit []string, extra map[string]interface{}) (json.RawMessage, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	for _, key := range omit {
+		delete(fields, key)
+	}
+	for key, value := range extra {
+		fields[key] = value
+	}
+	return json.Marshal(fields)
+}
+
+func animeSnapshot(a *domain.Anime) (json.RawMessage, error) {
+	ids := make([]uint, 0, len(a.Categories))
+	for _, c := range a.Categories {
+		ids = append(ids, c.ID)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return snapshotOf(a, animeSnapshotOmit, map[string]interface{}{"category_ids": ids})
+}
+
+func episodeSnapshot(e *domain.Episode) (json.RawMessage, error) {
+	servers := make([]domain.ServerImportRecord, 0, len(e.Servers))
+	for _, sv := range e.Servers {
+		servers = append(servers, domain.NewServerImportRecord(sv))
+	}
+	return snapshotOf(e, episodeSnapshotOmit, map[string]interface{}{"servers": servers})
+}
+
+func newRevision(action string, userID uint, snapshot json.RawMessage) *domain.Revision {
+	revision := &domain.Revision{Action: action, Snapshot: snapshot}
+	if userID != 0 {
+		revision.UserID = &userID
+	}
+	return revision
+}
+
+// recordBaseline stores the current state as the first revision of rows created before
+// revisions were tracked, so their original values can still be diffed and restored.
+// Two first edits racing both get here; the second baseline is dropped and the losing
+// save fails its version check.
+func recordBaseline(repo port.RevisionRepository, entityType string, entityID uint, version int, snapshot func() (json.RawMessage, error)) error {
+	count, err := repo.CountRevisions(entityType, entityID)
+	if err != nil || count > 0 {
+		return err
+	}
+	revision := &domain.Revision{EntityType: entityType, EntityID: entityID, Version: version, Action: domain.RevisionActionBaseline}
+	if revision.Snapshot, err = snapshot(); err != nil {
+		return err
+	}
+	return repo.CreateBaselineRevision(revision)
+}
+
+// diffSnapshots lists every top-level field whose value differs, sorted by name
+func diffSnapshots(from, to json.RawMessage) ([]domain.RevisionChange, error) {
+	var a, b map[string]json.RawMessage
+	if err := json.Unmarshal(from, &a); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(to, &b); err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	changes := []domain.RevisionChange{}
+	for _, k := range keys {
+		oldValue, newValue := a[k], b[k]
+		if bytes.Equal(oldValue, newValue) {
+			continue
+		}
+		if oldValue == nil {
+			oldValue = json.RawMessage("null")
+		}
+		if newValue == nil {
+			newValue = json.RawMessage("null")
+		}
+		changes = append(changes, domain.RevisionChange{Field: k, Old: oldValue, New: newValue})
+	}
+	return changes, nil
+}
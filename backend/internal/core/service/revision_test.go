@@ -0,0 +1,95 @@
+package service
+
+import (
+	"backend/internal/adapters/repository"
+	"backend/internal/adapters/storage"
+	"backend/internal/core/domain"
+	"errors"
+	"testing"
+)
+
+func newRevisionTestServices(t *testing.T) (*AnimeService, *EpisodeService, *repository.SQLiteRepository) {
+	t.Helper()
+	repo := newProgressTestRepo(t)
+	media := NewMediaService(repo, storage.NewLocalStore(t.TempDir()), 0)
+	return NewAnimeService(repo, repo, repo, repo, repo, repo, media), NewEpisodeService(repo, nil, media), repo
+}
+
+func TestAnimeUpdateRequiresTheCurrentVersion(t *testing.T) {
+	animes, _, repo := newRevisionTestServices(t)
+	animeID, _ := seedAnime(t, repo, domain.ContentStatePublished, 0)
+
+	edit := func(version int, title string) error {
+		anime, err := repo.GetAnimeByID(animeID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		anime.Title, anime.Version = title, version
+		_, err = animes.Update(anime, 1)
+		return err
+	}
+	if err := edit(0, "Unconditional"); !errors.Is(err, domain.ErrVersionRequired) {
+		t.Fatalf("edit without a version = %v, want ErrVersionRequired", err)
+	}
+	if err := edit(1, "First"); err != nil {
+		t.Fatal(err)
+	}
+	if err := edit(1, "Stale"); !errors.Is(err, domain.ErrVersionConflict) {
+		t.Fatalf("edit of a stale version = %v, want ErrVersionConflict", err)
+	}
+
+	// The baseline and the edit are both recorded
+	revisions, err := animes.Revisions(animeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != 2 || revisions[0].Version != 2 {
+		t.Fatalf("revisions = %+v, want versions 2 and 1", revisions)
+	}
+}
+
+func TestAnimePatchNeedsItsOwnVersion(t *testing.T) {
+	animes, _, repo := newRevisionTestServices(t)
+	animeID, _ := seedAnime(t, repo, domain.ContentStatePublished, 0)
+
+	// The stored version is merged into the document, but does not make the patch conditional
+	if _, err := animes.Patch(animeID, []byte(`{"title": "Patched"}`), 0, 1); !errors.Is(err, domain.ErrVersionRequired) {
+		t.Fatalf("patch without a version = %v, want ErrVersionRequired", err)
+	}
+	anime, err := animes.Patch(animeID, []byte(`{"title": "Patched", "version": 1}`), 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if anime.Title != "Patched" || anime.Version != 2 {
+		t.Fatalf("patched anime title=%q version=%d", anime.Title, anime.Version)
+	}
+	if _, err := animes.Patch(animeID, []byte(`{"title": "Again"}`), 1, 1); !errors.Is(err, domain.ErrVersionConflict) {
+		t.Fatalf("patch of a stale If-Match version = %v, want ErrVersionConflict", err)
+	}
+}
+
+func TestRollbackRequiresTheCurrentVersion(t *testing.T) {
+	_, episodes, repo := newRevisionTestServices(t)
+	_, episodeIDs := seedAnime(t, repo, domain.ContentStatePublished, 1)
+	episodeID := episodeIDs[0]
+
+	episode, err := repo.GetEpisodeByID(episodeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	episode.Title = "Renamed"
+	if _, err := episodes.Update(episode, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := episodes.Rollback(episodeID, 1, 0, 1); !errors.Is(err, domain.ErrVersionRequired) {
+		t.Fatalf("rollback without a version = %v, want ErrVersionRequired", err)
+	}
+	restored, err := episodes.Rollback(episodeID, 1, 2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.Title != "Episode 1" || restored.Version != 3 {
+		t.Fatalf("rolled back episode title=%q version=%d", restored.Title, restored.Version)
+	}
+}
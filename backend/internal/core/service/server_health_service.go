@@ -0,0 +1,301 @@
+package service
+
+import (
+	"backend/internal/adapters/repository"
+	"backend/internal/core/domain"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	// brokenAfterFailures consecutive failed probes demote a server to broken
+	brokenAfterFailures = 3
+	// serverCheckBatch caps the URLs probed per round
+	serverCheckBatch = 500
+	// maxConcurrentChecks caps probes in flight across all hosts
+	maxConcurrentChecks = 32
+	// maxProbeRedirects caps the redirects followed by one probe
+	maxProbeRedirects = 5
+)
+
+var errNonPublicAddress = errors.New("refusing to probe a non-public address")
+
+type ServerHealthService struct {
+	repo    *repository.SQLiteRepository
+	client  *http.Client
+	timeout time.Duration
+	perHost int
+}
+
+func NewServerHealthService(repo *repository.SQLiteRepository, timeout time.Duration, perHost int) *ServerHealthService {
+	if perHost < 1 {
+		perHost = 1
+	}
+	return &ServerHealthService{
+		repo:    repo,
+		client:  newProbeClient(nonPublicAddr),
+		timeout: timeout,
+		perHost: perHost,
+	}
+}
+
+// newProbeClient returns the client used for probes. Server URLs are entered by
+// editors, so every connection, including those of redirects, is checked after DNS
+// resolution and refused when blocked reports its address
+func newProbeClient(blocked func(netip.AddrPort) bool) *http.Client {
+	dialer := &net.Dialer{
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addr, err := netip.ParseAddrPort(address)
+			if err != nil || blocked(addr) {
+				return fmt.Errorf("%w %s", errNonPublicAddress, address)
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would make the connection for us, out of reach of the address check
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxProbeRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxProbeRedirects)
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("redirect to unsupported scheme %q", req.URL.Scheme)
+			}
+			return nil
+		},
+	}
+}
+
+// nonPublicAddr reports whether addr is loopback, private, link-local, multicast or
+// unspecified, or otherwise not a public unicast address
+func nonPublicAddr(addr netip.AddrPort) bool {
+	ip := addr.Addr().Unmap()
+	return !ip.IsGlobalUnicast() || ip.IsPrivate() || (ip.Is4() && ip.As4()[0] == 0)
+}
+
+// RunChecks probes servers not checked within interval, every interval, until the
+// process exits
+func (s *ServerHealthService) RunChecks(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for {
+			n, err := s.CheckStale(time.Now().Add(-interval))
+			if err != nil {
+				log.Printf("Server health: %v", err)
+				break
+			}
+			if n > 0 {
+				log.Printf("Server health: checked %d URLs", n)
+			}
+			if n < serverCheckBatch {
+				break
+			}
+		}
+		if n, err := s.repo.PruneServerHealth(); err != nil {
+			log.Printf("Server health: prune: %v", err)
+		} else if n > 0 {
+			log.Printf("Server health: pruned %d unused URLs", n)
+		}
+		<-ticker.C
+	}
+}
+
+// CheckStale probes one batch of URLs last checked before cutoff and returns how
+// many were probed
+func (s *ServerHealthService) CheckStale(cutoff time.Time) (int, error) {
+	servers, err := s.repo.GetServersToCheck(cutoff, serverCheckBatch)
+	if err != nil {
+		return 0, err
+	}
+	s.checkAll(servers)
+	return len(servers), nil
+}
+
+// checkAll probes servers concurrently, at most perHost at a time against one host
+func (s *ServerHealthService) checkAll(servers []domain.EpisodeServer) {
+	byHost := map[string][]domain.EpisodeServer{}
+	for _, sv := range servers {
+		host := sv.URL
+		if u, err := url.Parse(sv.URL); err == nil {
+			host = strings.ToLower(u.Hostname())
+		}
+		byHost[host] = append(byHost[host], sv)
+	}
+
+	var wg sync.WaitGroup
+	inFlight := make(chan struct{}, maxConcurrentChecks)
+	for _, queue := range byHost {
+		jobs := make(chan domain.EpisodeServer)
+		workers := s.perHost
+		if workers > len(queue) {
+			workers = len(queue)
+		}
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for sv := range jobs {
+					inFlight <- struct{}{}
+					if _, err := s.check(sv); err != nil {
+						log.Printf("Server health: %s: %v", sv.URL, err)
+					}
+					<-inFlight
+				}
+			}()
+		}
+		go func(queue []domain.EpisodeServer) {
+			for _, sv := range queue {
+				jobs <- sv
+			}
+			close(jobs)
+		}(queue)
+	}
+	wg.Wait()
+}
+
+// check probes a server and records the result against its URL
+func (s *ServerHealthService) check(sv domain.EpisodeServer) (*domain.ServerHealth, error) {
+	statusCode, latency, probeErr := s.probe(sv.URL, sv.Headers)
+	return s.repo.RecordServerProbe(probeResult(sv.URL, statusCode, latency, probeErr, time.Now()), brokenAfterFailures)
+}
+
+// probeResult is the health of a URL after a probe, as if it were its first one
+func probeResult(rawURL string, statusCode int, latency time.Duration, probeErr error, now time.Time) *domain.ServerHealth {
+	health := &domain.ServerHealth{
+		URL:        rawURL,
+		StatusCode: statusCode,
+		LatencyMs:  latency.Milliseconds(),
+		CheckedAt:  &now,
+	}
+	if u, err := url.Parse(rawURL); err == nil {
+		health.Host = strings.ToLower(u.Hostname())
+	}
+	if probeErr == nil {
+		health.Status = domain.ServerHealthOK
+		health.LastOKAt = &now
+		return health
+	}
+	health.Error = probeErr.Error()
+	health.ConsecutiveFailures = 1
+	health.Status = domain.ServerHealthFailing
+	if health.ConsecutiveFailures >= brokenAfterFailures {
+		health.Status = domain.ServerHealthBroken
+	}
+	return health
+}
+
+// probe sends a HEAD request and falls back to a one-byte ranged GET for servers
+// that refuse HEAD. Any final status below 400 counts as reachable.
+func (s *ServerHealthService) probe(rawURL string, headers map[string]string) (int, time.Duration, error) {
+	start := time.Now()
+	status, err := s.request(http.MethodHead, rawURL, headers)
+	if err != nil || status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented || status == http.StatusForbidden {
+		status, err = s.request(http.MethodGet, rawURL, headers)
+	}
+	latency := time.Since(start)
+	if err != nil {
+		return status, latency, err
+	}
+	if status >= 400 {
+		return status, latency, fmt.Errorf("HTTP %d", status)
+	}
+	return status, latency, nil
+}
+
+func (s *ServerHealthService) request(method, rawURL string, headers map[string]string) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; server-health-check)")
+	if method == http.MethodGet {
+		req.Header.Set("Range", "bytes=0-0")
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	return resp.StatusCode, nil
+}
+
+// Recheck probes one server immediately
+func (s *ServerHealthService) Recheck(serverID uint) (*domain.ServerHealth, error) {
+	server, err := s.repo.GetEpisodeServer(0, serverID)
+	if err != nil {
+		return nil, err
+	}
+	return s.check(*server)
+}
+
+// Report records a user's broken server report and re-probes the server in the background
+func (s *ServerHealthService) Report(userID, episodeID, serverID uint, reason string) (*domain.ServerReport, error) {
+	server, err := s.repo.GetEpisodeServer(episodeID, serverID)
+	if err != nil {
+		return nil, err
+	}
+	report := &domain.ServerReport{
+		ServerID:  server.ID,
+		EpisodeID: server.EpisodeID,
+		URL:       server.URL,
+		UserID:    userID,
+		Reason:    strings.TrimSpace(reason),
+	}
+	if r := []rune(report.Reason); len(r) > 500 {
+		report.Reason = string(r[:500])
+	}
+	if err := s.repo.CreateServerReport(report); err != nil {
+		return nil, err
+	}
+	go func() {
+		if _, err := s.check(*server); err != nil {
+			log.Printf("Server health: %s: %v", server.URL, err)
+		}
+	}()
+	return report, nil
+}
+
+// Resolve closes the open reports of a server's URL
+func (s *ServerHealthService) Resolve(serverID uint) (int64, error) {
+	server, err := s.repo.GetEpisodeServer(0, serverID)
+	if err != nil {
+		return 0, err
+	}
+	return s.repo.ResolveServerReports(server.URL)
+}
+
+// Broken lists servers that are demoted or reported by users
+func (s *ServerHealthService) Broken(limit, offset int) ([]domain.BrokenServer, int64, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.repo.GetBrokenServers(limit, offset)
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"backend/internal/adapters/repository"
+	"backend/internal/core/domain"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newHealthTestService(t *testing.T) *ServerHealthService {
+	t.Helper()
+	repo, err := repository.NewSQLiteRepository("file:" + filepath.Join(t.TempDir(), "health.db") + "?_busy_timeout=5000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return allowLoopback(NewServerHealthService(repo, time.Second, 4))
+}
+
+// allowLoopback lets s probe httptest servers, which listen on loopback
+func allowLoopback(s *ServerHealthService) *ServerHealthService {
+	s.client = newProbeClient(func(netip.AddrPort) bool { return false })
+	return s
+}
+
+func TestServerHealthProbe(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		headers map[string]string
+		status  int
+		ok      bool
+	}{
+		{
+			name:    "head ok",
+			handler: func(w http.ResponseWriter, r *http.Request) {},
+			status:  http.StatusOK,
+			ok:      true,
+		},
+		{
+			name: "head refused falls back to ranged get",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodHead {
+					w.WriteHeader(http.StatusMethodNotAllowed)
+					return
+				}
+				if r.Header.Get("Range") != "bytes=0-0" {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				w.WriteHeader(http.StatusPartialContent)
+			},
+			status: http.StatusPartialContent,
+			ok:     true,
+		},
+		{
+			name: "forbidden head retried with get",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodHead {
+					w.WriteHeader(http.StatusForbidden)
+				}
+			},
+			status: http.StatusOK,
+			ok:     true,
+		},
+		{
+			name: "source headers are sent",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Referer") != "https://example.com/" {
+					w.WriteHeader(http.StatusForbidden)
+				}
+			},
+			headers: map[string]string{"Referer": "https://example.com/"},
+			status:  http.StatusOK,
+			ok:      true,
+		},
+		{
+			name:    "not found",
+			handler: func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNotFound) },
+			status:  http.StatusNotFound,
+		},
+		{
+			name:    "server error",
+			handler: func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusBadGateway) },
+			status:  http.StatusBadGateway,
+		},
+		{
+			name:    "timeout",
+			handler: func(w http.ResponseWriter, r *http.Request) { time.Sleep(300 * time.Millisecond) },
+		},
+	}
+	s := allowLoopback(NewServerHealthService(nil, 100*time.Millisecond, 1))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(tt.handler)
+			defer srv.Close()
+
+			status, _, err := s.probe(srv.URL+"/video.mp4", tt.headers)
+			if status != tt.status {
+				t.Errorf("status = %d, want %d", status, tt.status)
+			}
+			if (err == nil) != tt.ok {
+				t.Errorf("err = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestServerHealthCheckDemotesAndRecovers(t *testing.T) {
+	s := newHealthTestService(t)
+	var failing atomic.Bool
+	failing.Store(true)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+	server := domain.EpisodeServer{URL: srv.URL + "/embed/1"}
+
+	steps := []struct {
+		failing  bool
+		status   domain.ServerHealthStatus
+		failures int
+	}{
+		{true, domain.ServerHealthFailing, 1},
+		{true, domain.ServerHealthFailing, 2},
+		{true, domain.ServerHealthBroken, 3},
+		{true, domain.ServerHealthBroken, 4},
+		{false, domain.ServerHealthOK, 0},
+		{true, domain.ServerHealthFailing, 1},
+	}
+	for i, step := range steps {
+		failing.Store(step.failing)
+		health, err := s.check(server)
+		if err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+		if health.Status != step.status || health.ConsecutiveFailures != step.failures {
+			t.Fatalf("step %d: got %s with %d failures, want %s with %d",
+				i, health.Status, health.ConsecutiveFailures, step.status, step.failures)
+		}
+		if step.failing && health.Error == "" {
+			t.Errorf("step %d: failed probe without an error", i)
+		}
+		if i >= 4 && health.LastOKAt == nil {
+			t.Errorf("step %d: last_ok_at was lost", i)
+		}
+	}
+}
+
+func TestServerHealthConcurrentChecksCountEveryFailure(t *testing.T) {
+	s := newHealthTestService(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	server := domain.EpisodeServer{URL: srv.URL + "/embed/1"}
+
+	const checks = 8
+	var wg sync.WaitGroup
+	for i := 0; i < checks; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.check(server); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	health, err := s.repo.GetServerHealth(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if health.ConsecutiveFailures != checks || health.Status != domain.ServerHealthBroken {
+		t.Errorf("got %s with %d failures, want broken with %d", health.Status, health.ConsecutiveFailures, checks)
+	}
+}
+
+func TestServerHealthRefusesNonPublicAddresses(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer target.Close()
+	redirect := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusFound)
+	}))
+	defer redirect.Close()
+
+	s := NewServerHealthService(nil, time.Second, 1)
+	if _, _, err := s.probe(target.URL, nil); !errors.Is(err, errNonPublicAddress) {
+		t.Fatalf("probe of a loopback server = %v, want errNonPublicAddress", err)
+	}
+
+	// Only the redirecting server is allowed, the address it points to is checked again
+	allowed := netip.MustParseAddrPort(redirect.Listener.Addr().String())
+	s.client = newProbeClient(func(addr netip.AddrPort) bool { return addr != allowed })
+	if _, _, err := s.probe(redirect.URL, nil); !errors.Is(err, errNonPublicAddress) {
+		t.Fatalf("probe redirected to a blocked server = %v, want errNonPublicAddress", err)
+	}
+
+	for addr, blocked := range map[string]bool{
+		"127.0.0.1:80":         true,
+		"10.1.2.3:80":          true,
+		"172.16.0.1:80":        true,
+		"192.168.1.1:443":      true,
+		"169.254.169.254:80":   true,
+		"0.0.0.0:80":           true,
+		"[::1]:80":             true,
+		"[fe80::1]:80":         true,
+		"[fd00::1]:80":         true,
+		"[::ffff:10.0.0.1]:80": true,
+		"93.184.216.34:443":    false,
+		"[2606:4700::1]:443":   false,
+	} {
+		if got := nonPublicAddr(netip.MustParseAddrPort(addr)); got != blocked {
+			t.Errorf("nonPublicAddr(%s) = %v, want %v", addr, got, blocked)
+		}
+	}
+}
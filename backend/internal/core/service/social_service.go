@@ -0,0 +1,282 @@
+package service
+
+import (
+	"backend/internal/adapters/repository"
+	"backend/internal/core/domain"
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// feedCacheMax bounds how many feed pages are cached before expired ones are swept
+const feedCacheMax = 10000
+
+// feedKey identifies a cached feed page
+type feedKey struct {
+	userID uint
+	cursor string
+	limit  int
+}
+
+// feedEntry is a cached feed page, with the users it was built from
+type feedEntry struct {
+	page      *domain.FeedPage
+	following []uint // Sorted
+	expires   time.Time
+}
+
+// SocialService handles users following each other, what activity they share
+// and the feed of followed users' shared activity. The feed is built on read
+// from the followed users' history and cached per page for a short time;
+// following or unfollowing drops the follower's cached pages and a privacy
+// change drops the cached pages built from the user's activity.
+type SocialService struct {
+	repo     *repository.SQLiteRepository
+	cacheTTL time.Duration
+
+	mu    sync.Mutex
+	cache map[feedKey]feedEntry
+}
+
+func NewSocialService(repo *repository.SQLiteRepository, cacheTTL time.Duration) *SocialService {
+	return &SocialService{repo: repo, cacheTTL: cacheTTL, cache: map[feedKey]feedEntry{}}
+}
+
+// Follow makes userID follow targetID
+func (s *SocialService) Follow(userID, targetID uint) (*domain.UserFollowStatus, error) {
+	if userID == targetID {
+		return nil, domain.ErrCannotFollowSelf
+	}
+	if user, err := s.repo.GetUserByID(targetID); err != nil || user == nil {
+		return nil, domain.ErrUserNotFound
+	}
+	if _, err := s.repo.FollowUser(userID, targetID); err != nil {
+		return nil, err
+	}
+	s.dropFeed(userID)
+	return s.repo.GetUserFollowStatus(userID, targetID)
+}
+
+func (s *SocialService) Unfollow(userID, targetID uint) (*domain.UserFollowStatus, error) {
+	if _, err := s.repo.UnfollowUser(userID, targetID); err != nil {
+		return nil, err
+	}
+	s.dropFeed(userID)
+	return s.repo.GetUserFollowStatus(userID, targetID)
+}
+
+// Status returns how the caller and another user follow each other
+func (s *SocialService) Status(userID, targetID uint) (*domain.UserFollowStatus, error) {
+	if user, err := s.repo.GetUserByID(targetID); err != nil || user == nil {
+		return nil, domain.ErrUserNotFound
+	}
+	return s.repo.GetUserFollowStatus(userID, targetID)
+}
+
+// Followers lists who follows a user
+func (s *SocialService) Followers(userID uint, limit, offset int) ([]domain.User, int64, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	return s.repo.GetFollowers(userID, limit, max(offset, 0))
+}
+
+// Following lists who a user follows
+func (s *SocialService) Following(userID uint, limit, offset int) ([]domain.User, int64, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	return s.repo.GetFollowing(userID, limit, max(offset, 0))
+}
+
+// Privacy returns a user's privacy settings, the defaults if never changed
+func (s *SocialService) Privacy(userID uint) (*domain.UserPrivacy, error) {
+	privacies, err := s.repo.GetUserPrivacies([]uint{userID})
+	if err != nil {
+		return nil, err
+	}
+	privacy, ok := privacies[userID]
+	if !ok {
+		privacy = domain.DefaultUserPrivacy(userID)
+	}
+	return &privacy, nil
+}
+
+func (s *SocialService) UpdatePrivacy(userID uint, update domain.UserPrivacyUpdate) (*domain.UserPrivacy, error) {
+	privacy, err := s.Privacy(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range []struct {
+		dst *bool
+		val *bool
+	}{
+		{&privacy.EpisodeViews, update.EpisodeViews},
+		{&privacy.AnimeViews, update.AnimeViews},
+		{&privacy.Comments, update.Comments},
+		{&privacy.Replies, update.Replies},
+		{&privacy.Likes, update.Likes},
+		{&privacy.ShowList, update.ShowList},
+	} {
+		if f.val != nil {
+			*f.dst = *f.val
+		}
+	}
+	if err := s.repo.SaveUserPrivacy(privacy); err != nil {
+		return nil, err
+	}
+	s.dropFeedsFollowing(userID)
+	return privacy, nil
+}
+
+// Feed returns a page of the shared activity of the users userID follows,
+// newest first. cursor is the NextCursor of the previous page, or empty.
+func (s *SocialService) Feed(userID uint, cursor string, limit int) (*domain.FeedPage, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	var after *domain.FeedCursor
+	if cursor != "" {
+		c, err := decodeFeedCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		after = c
+	}
+
+	key := feedKey{userID: userID, cursor: cursor, limit: limit}
+	s.mu.Lock()
+	entry, ok := s.cache[key]
+	s.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.page, nil
+	}
+
+	following, err := s.repo.GetFollowingIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+	page, err := s.buildFeed(following, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	if s.cacheTTL > 0 {
+		slices.Sort(following)
+		s.mu.Lock()
+		if len(s.cache) >= feedCacheMax {
+			s.sweepFeed()
+		}
+		s.cache[key] = feedEntry{page: page, following: following, expires: time.Now().Add(s.cacheTTL)}
+		s.mu.Unlock()
+	}
+	return page, nil
+}
+
+// buildFeed reads the page from the history of the followed users, grouping
+// them by the activity types they share
+func (s *SocialService) buildFeed(following []uint, after *domain.FeedCursor, limit int) (*domain.FeedPage, error) {
+	privacies, err := s.repo.GetUserPrivacies(following)
+	if err != nil {
+		return nil, err
+	}
+	bySharing := map[string]*domain.FeedSource{}
+	var sources []domain.FeedSource
+	for _, id := range following {
+		privacy, ok := privacies[id]
+		if !ok {
+			privacy = domain.DefaultUserPrivacy(id)
+		}
+		types := privacy.PublicActivityTypes()
+		if len(types) == 0 {
+			continue
+		}
+		slices.Sort(types)
+		sharing := fmt.Sprint(types)
+		if source, ok := bySharing[sharing]; ok {
+			source.UserIDs = append(source.UserIDs, id)
+			continue
+		}
+		bySharing[sharing] = &domain.FeedSource{UserIDs: []uint{id}, Types: types}
+	}
+	for _, source := range bySharing {
+		sources = append(sources, *source)
+	}
+
+	// Read one extra row to know whether there is a next page
+	items, err := s.repo.GetFeed(sources, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	page := &domain.FeedPage{Items: items}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		last := page.Items[limit-1]
+		page.NextCursor = encodeFeedCursor(domain.FeedCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+	return page, nil
+}
+
+// dropFeed forgets the cached feed pages of a user
+func (s *SocialService) dropFeed(userID uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key := range s.cache {
+		if key.userID == userID {
+			delete(s.cache, key)
+		}
+	}
+}
+
+// dropFeedsFollowing forgets the cached feed pages built from a user's activity,
+// after their privacy changed
+func (s *SocialService) dropFeedsFollowing(userID uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, entry := range s.cache {
+		if _, ok := slices.BinarySearch(entry.following, userID); ok {
+			delete(s.cache, key)
+		}
+	}
+}
+
+// sweepFeed removes expired pages, and everything if that is not enough.
+// Callers hold s.mu.
+func (s *SocialService) sweepFeed() {
+	now := time.Now()
+	for key, entry := range s.cache {
+		if now.After(entry.expires) {
+			delete(s.cache, key)
+		}
+	}
+	if len(s.cache) >= feedCacheMax {
+		s.cache = map[feedKey]feedEntry{}
+	}
+}
+
+func encodeFeedCursor(c domain.FeedCursor) string {
+	return base64.RawURLEncoding.EncodeToString(fmt.Appendf(nil, "%d.%d", c.CreatedAt.UnixNano(), c.ID))
+}
+
+func decodeFeedCursor(s string) (*domain.FeedCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, domain.ErrInvalidFeedCursor
+	}
+	at, id, ok := strings.Cut(string(raw), ".")
+	if !ok {
+		return nil, domain.ErrInvalidFeedCursor
+	}
+	nanos, err := strconv.ParseInt(at, 10, 64)
+	if err != nil {
+		return nil, domain.ErrInvalidFeedCursor
+	}
+	rowID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return nil, domain.ErrInvalidFeedCursor
+	}
+	return &domain.FeedCursor{CreatedAt: time.Unix(0, nanos), ID: uint(rowID)}, nil
+}
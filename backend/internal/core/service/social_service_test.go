@@ -0,0 +1,87 @@
+package service
+
+import (
+	"backend/internal/core/domain"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFeedCursor(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor domain.FeedCursor
+	}{
+		{"now", domain.FeedCursor{CreatedAt: time.Now(), ID: 42}},
+		{"nanoseconds kept", domain.FeedCursor{CreatedAt: time.Unix(1700000000, 123456789), ID: 1}},
+		{"epoch", domain.FeedCursor{CreatedAt: time.Unix(0, 0), ID: 0}},
+		{"before epoch", domain.FeedCursor{CreatedAt: time.Unix(-5, 0), ID: 7}},
+		{"large id", domain.FeedCursor{CreatedAt: time.Unix(1, 0), ID: 1<<32 + 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded := encodeFeedCursor(tt.cursor)
+			decoded, err := decodeFeedCursor(encoded)
+			if err != nil {
+				t.Fatalf("decodeFeedCursor(%q): %v", encoded, err)
+			}
+			if !decoded.CreatedAt.Equal(tt.cursor.CreatedAt) || decoded.ID != tt.cursor.ID {
+				t.Errorf("round trip = %+v, want %+v", decoded, tt.cursor)
+			}
+			if encodeFeedCursor(*decoded) != encoded {
+				t.Errorf("re-encoding changed the cursor")
+			}
+		})
+	}
+}
+
+func TestDecodeFeedCursorRejects(t *testing.T) {
+	raw := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"empty", ""},
+		{"not base64", "!!!"},
+		{"padded base64", base64.URLEncoding.EncodeToString([]byte("1.23"))},
+		{"no separator", raw("12")},
+		{"missing id", raw("1.")},
+		{"missing time", raw(".2")},
+		{"extra part", raw("1.2.3")},
+		{"trailing text", raw("1.2x")},
+		{"spaces", raw("1. 2")},
+		{"negative id", raw("1.-2")},
+		{"time out of range", raw("99999999999999999999.1")},
+	}
+	for _, tt := range tests {
+		if c, err := decodeFeedCursor(tt.cursor); !errors.Is(err, domain.ErrInvalidFeedCursor) {
+			t.Errorf("%s: decodeFeedCursor(%q) = %+v, %v; want ErrInvalidFeedCursor", tt.name, tt.cursor, c, err)
+		}
+	}
+}
+
+func TestFeedLeavesOutImportedAndHiddenActivity(t *testing.T) {
+	repo := newProgressTestRepo(t)
+	animeID, episodes := seedAnime(t, repo, domain.ContentStatePublished, 1)
+	draftID, draftEpisodes := seedAnime(t, repo, domain.ContentStateDraft, 1)
+	rows := []domain.History{
+		{UserID: 2, ActivityType: domain.ActivityEpisodeView, AnimeID: &animeID, EpisodeID: &episodes[0]},
+		{UserID: 2, ActivityType: domain.ActivityEpisodeView, AnimeID: &animeID, EpisodeID: &episodes[0], ImportSource: "mal"},
+		{UserID: 2, ActivityType: domain.ActivityEpisodeView, AnimeID: &draftID, EpisodeID: &draftEpisodes[0]},
+		{UserID: 3, ActivityType: domain.ActivityEpisodeView, AnimeID: &animeID, EpisodeID: &episodes[0]},
+	}
+	for i := range rows {
+		if err := repo.DB().Create(&rows[i]).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	items, err := repo.GetFeed([]domain.FeedSource{{UserIDs: []uint{2}, Types: []domain.ActivityType{domain.ActivityEpisodeView}}}, nil, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].ID != rows[0].ID {
+		t.Fatalf("feed = %d items, want only the own view of the published episode", len(items))
+	}
+}
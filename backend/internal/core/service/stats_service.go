@@ -0,0 +1,238 @@
+package service
+
+import (
+	"backend/internal/adapters/repository"
+	"backend/internal/core/domain"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// statsCacheMax bounds how many users' stats are cached before expired ones are swept
+	statsCacheMax = 10000
+	// minStatsYear is the earliest year a wrapped summary can be asked for
+	minStatsYear = 2000
+
+	statsBreakdowns  = 10
+	wrappedTopAnime  = 5
+	wrappedTopGenres = 3
+)
+
+// statsKey identifies cached stats
+type statsKey struct {
+	userID uint
+	tz     string
+	year   int
+}
+
+type statsEntry struct {
+	stats   *domain.ViewingStats
+	expires time.Time
+}
+
+// StatsService builds a user's viewing dashboard. Totals and breakdowns come
+// from completed watch progress, the heatmap from episode views in the history
+// and streaks from both. Everything is aggregated in SQL, times grouped in
+// quarter hours that are read in the user's time zone, and the result cached
+// per user, time zone and year for a few minutes.
+type StatsService struct {
+	repo     *repository.SQLiteRepository
+	cacheTTL time.Duration
+
+	mu    sync.Mutex
+	cache map[statsKey]statsEntry
+}
+
+func NewStatsService(repo *repository.SQLiteRepository, cacheTTL time.Duration) *StatsService {
+	return &StatsService{repo: repo, cacheTTL: cacheTTL, cache: map[statsKey]statsEntry{}}
+}
+
+// Get returns a user's stats with days and hours in the time zone tz (UTC when
+// empty) and the wrapped summary of year (the current year when 0)
+func (s *StatsService) Get(userID uint, tz string, year int) (*domain.ViewingStats, error) {
+	loc := time.UTC
+	if tz != "" {
+		l, err := time.LoadLocation(tz)
+		if err != nil {
+			return nil, domain.ErrInvalidTimezone
+		}
+		loc = l
+	}
+	now := time.Now().In(loc)
+	if year == 0 {
+		year = now.Year()
+	}
+	if year < minStatsYear || year > now.Year() {
+		return nil, domain.ErrInvalidStatsYear
+	}
+
+	key := statsKey{userID: userID, tz: loc.String(), year: year}
+	s.mu.Lock()
+	entry, ok := s.cache[key]
+	s.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.stats, nil
+	}
+
+	stats, err := s.build(userID, now, year)
+	if err != nil {
+		return nil, err
+	}
+	if s.cacheTTL > 0 {
+		s.mu.Lock()
+		if len(s.cache) >= statsCacheMax {
+			s.sweep()
+		}
+		s.cache[key] = statsEntry{stats: stats, expires: time.Now().Add(s.cacheTTL)}
+		s.mu.Unlock()
+	}
+	return stats, nil
+}
+
+func (s *StatsService) build(userID uint, now time.Time, year int) (*domain.ViewingStats, error) {
+	all := domain.StatsScope{UserID: userID, Location: now.Location()}
+	inYear := domain.StatsScope{UserID: userID, Year: year, Location: now.Location()}
+	stats := &domain.ViewingStats{Timezone: now.Location().String(), GeneratedAt: now}
+	wrapped := &stats.Wrapped
+	wrapped.Year = year
+
+	var err error
+	if stats.WatchTotals, err = s.repo.GetWatchTotals(all); err != nil {
+		return nil, err
+	}
+	if stats.Genres, err = s.repo.GetGenreBreakdown(all, statsBreakdowns); err != nil {
+		return nil, err
+	}
+	if stats.Studios, err = s.repo.GetStudioBreakdown(all, statsBreakdowns); err != nil {
+		return nil, err
+	}
+	if stats.Heatmap, err = s.repo.GetViewHeatmap(all); err != nil {
+		return nil, err
+	}
+	days, err := s.repo.GetActiveDays(all)
+	if err != nil {
+		return nil, err
+	}
+	today := now.Format(time.DateOnly)
+	stats.Streaks = watchStreaks(days, today)
+
+	if wrapped.WatchTotals, err = s.repo.GetWatchTotals(inYear); err != nil {
+		return nil, err
+	}
+	if wrapped.MonthlyMinutes, err = s.repo.GetMonthlyMinutes(inYear); err != nil {
+		return nil, err
+	}
+	for i, minutes := range wrapped.MonthlyMinutes {
+		if minutes > 0 && (wrapped.BusiestMonth == 0 || minutes > wrapped.MonthlyMinutes[wrapped.BusiestMonth-1]) {
+			wrapped.BusiestMonth = i + 1
+		}
+	}
+	heatmap, err := s.repo.GetViewHeatmap(inYear)
+	if err != nil {
+		return nil, err
+	}
+	wrapped.FavoriteWeekday, wrapped.FavoriteHour = heatmapPeaks(heatmap)
+	if wrapped.TopAnime, err = s.repo.GetTopWatchedAnime(inYear, wrappedTopAnime); err != nil {
+		return nil, err
+	}
+	if wrapped.TopGenres, err = s.repo.GetGenreBreakdown(inYear, wrappedTopGenres); err != nil {
+		return nil, err
+	}
+	studios, err := s.repo.GetStudioBreakdown(inYear, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(studios) > 0 {
+		wrapped.TopStudio = &studios[0]
+	}
+
+	// Only days of the year count; a streak running on at the end of a past year is not current
+	prefix := fmt.Sprintf("%d-", year)
+	var yearDays []string
+	for _, day := range days {
+		if strings.HasPrefix(day, prefix) {
+			yearDays = append(yearDays, day)
+		}
+	}
+	if year != now.Year() {
+		today = ""
+	}
+	wrapped.Streaks = watchStreaks(yearDays, today)
+	return stats, nil
+}
+
+// watchStreaks finds the runs of consecutive days in sorted YYYY-MM-DD days.
+// The last run is current when it reaches today or yesterday; an empty today
+// means no run is current.
+func watchStreaks(days []string, today string) domain.WatchStreaks {
+	streaks := domain.WatchStreaks{ActiveDays: len(days)}
+	if len(days) == 0 {
+		return streaks
+	}
+	var prev time.Time
+	run, start := 0, ""
+	for _, day := range days {
+		d, err := time.Parse(time.DateOnly, day)
+		if err != nil {
+			continue
+		}
+		if run > 0 && d.Equal(prev.AddDate(0, 0, 1)) {
+			run++
+		} else {
+			run, start = 1, day
+		}
+		if run > streaks.Longest {
+			streaks.Longest, streaks.LongestStart, streaks.LongestEnd = run, start, day
+		}
+		prev = d
+	}
+	streaks.LastActive = prev.Format(time.DateOnly)
+	if t, err := time.Parse(time.DateOnly, today); err == nil && !prev.Before(t.AddDate(0, 0, -1)) {
+		streaks.Current = run
+	}
+	return streaks
+}
+
+// heatmapPeaks returns the weekday and the hour with the most views, -1 for both without views
+func heatmapPeaks(heatmap domain.WatchHeatmap) (int, int) {
+	var byWeekday [7]int64
+	var byHour [24]int64
+	var total int64
+	for weekday, hours := range heatmap {
+		for hour, views := range hours {
+			byWeekday[weekday] += views
+			byHour[hour] += views
+			total += views
+		}
+	}
+	if total == 0 {
+		return -1, -1
+	}
+	weekday, hour := 0, 0
+	for i, views := range byWeekday {
+		if views > byWeekday[weekday] {
+			weekday = i
+		}
+	}
+	for i, views := range byHour {
+		if views > byHour[hour] {
+			hour = i
+		}
+	}
+	return weekday, hour
+}
+
+// sweep removes expired stats, and everything if that is not enough. Callers hold s.mu.
+func (s *StatsService) sweep() {
+	now := time.Now()
+	for key, entry := range s.cache {
+		if now.After(entry.expires) {
+			delete(s.cache, key)
+		}
+	}
+	if len(s.cache) >= statsCacheMax {
+		s.cache = map[statsKey]statsEntry{}
+	}
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"backend/internal/core/domain"
+	"testing"
+	"time"
+)
+
+func TestWatchStreaks(t *testing.T) {
+	tests := []struct {
+		name  string
+		days  []string
+		today string
+		want  domain.WatchStreaks
+	}{
+		{
+			name:  "no activity",
+			today: "2026-03-10",
+			want:  domain.WatchStreaks{},
+		},
+		{
+			name:  "single day today",
+			days:  []string{"2026-03-10"},
+			today: "2026-03-10",
+			want:  domain.WatchStreaks{Current: 1, Longest: 1, LongestStart: "2026-03-10", LongestEnd: "2026-03-10", ActiveDays: 1, LastActive: "2026-03-10"},
+		},
+		{
+			name:  "current run ending yesterday",
+			days:  []string{"2026-03-01", "2026-03-07", "2026-03-08", "2026-03-09"},
+			today: "2026-03-10",
+			want:  domain.WatchStreaks{Current: 3, Longest: 3, LongestStart: "2026-03-07", LongestEnd: "2026-03-09", ActiveDays: 4, LastActive: "2026-03-09"},
+		},
+		{
+			name:  "broken two days ago",
+			days:  []string{"2026-03-07", "2026-03-08"},
+			today: "2026-03-10",
+			want:  domain.WatchStreaks{Longest: 2, LongestStart: "2026-03-07", LongestEnd: "2026-03-08", ActiveDays: 2, LastActive: "2026-03-08"},
+		},
+		{
+			name:  "longest run kept over a later shorter one",
+			days:  []string{"2026-01-01", "2026-01-02", "2026-01-03", "2026-01-05", "2026-01-06"},
+			today: "2026-01-06",
+			want:  domain.WatchStreaks{Current: 2, Longest: 3, LongestStart: "2026-01-01", LongestEnd: "2026-01-03", ActiveDays: 5, LastActive: "2026-01-06"},
+		},
+		{
+			name:  "first of equal runs is the longest",
+			days:  []string{"2026-01-01", "2026-01-02", "2026-01-04", "2026-01-05"},
+			today: "2026-02-01",
+			want:  domain.WatchStreaks{Longest: 2, LongestStart: "2026-01-01", LongestEnd: "2026-01-02", ActiveDays: 4, LastActive: "2026-01-05"},
+		},
+		{
+			name:  "across month, year and leap day",
+			days:  []string{"2023-12-31", "2024-01-01", "2024-02-28", "2024-02-29", "2024-03-01"},
+			today: "2024-03-01",
+			want:  domain.WatchStreaks{Current: 3, Longest: 3, LongestStart: "2024-02-28", LongestEnd: "2024-03-01", ActiveDays: 5, LastActive: "2024-03-01"},
+		},
+		{
+			name:  "no today, as in a past year",
+			days:  []string{"2025-12-30", "2025-12-31"},
+			today: "",
+			want:  domain.WatchStreaks{Longest: 2, LongestStart: "2025-12-30", LongestEnd: "2025-12-31", ActiveDays: 2, LastActive: "2025-12-31"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := watchStreaks(tt.days, tt.today); got != tt.want {
+				t.Errorf("watchStreaks = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHeatmapPeaks(t *testing.T) {
+	heatmap := func(cells ...[3]int) domain.WatchHeatmap {
+		var h domain.WatchHeatmap
+		for _, c := range cells {
+			h[c[0]][c[1]] = int64(c[2])
+		}
+		return h
+	}
+	tests := []struct {
+		name    string
+		heatmap domain.WatchHeatmap
+		weekday int
+		hour    int
+	}{
+		{"no views", heatmap(), -1, -1},
+		{"single cell", heatmap([3]int{3, 21, 1}), 3, 21},
+		// The busiest cell is not the busiest weekday or hour
+		{"sums per weekday and hour", heatmap([3]int{1, 9, 5}, [3]int{6, 20, 3}, [3]int{6, 22, 3}, [3]int{2, 22, 3}), 6, 22},
+		{"ties go to the earlier weekday and hour", heatmap([3]int{4, 18, 2}, [3]int{2, 7, 2}), 2, 7},
+		{"sunday midnight", heatmap([3]int{0, 0, 1}), 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			weekday, hour := heatmapPeaks(tt.heatmap)
+			if weekday != tt.weekday || hour != tt.hour {
+				t.Errorf("heatmapPeaks = %d, %d; want %d, %d", weekday, hour, tt.weekday, tt.hour)
+			}
+		})
+	}
+}
+
+func TestStatsReadTimesAtTheirOwnOffset(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("time zone data unavailable:", err)
+	}
+	repo := newProgressTestRepo(t)
+	animeID, episodes := seedAnime(t, repo, domain.ContentStatePublished, 1)
+	local := func(month time.Month, day, hour, min int) time.Time {
+		return time.Date(2025, month, day, hour, min, 0, 0, newYork)
+	}
+	views := []struct {
+		at     time.Time
+		source string
+	}{
+		{local(time.January, 15, 20, 0).UTC(), ""},                          // EST, past midnight in UTC
+		{local(time.July, 16, 20, 0).In(time.FixedZone("JST", 9*3600)), ""}, // EDT, stored at another offset
+		{local(time.December, 31, 23, 30).UTC(), ""},                        // 2026 in UTC
+		{local(time.March, 5, 20, 0).UTC(), "mal"},                          // Imported, not a view here
+	}
+	for _, v := range views {
+		row := domain.History{UserID: 1, ActivityType: domain.ActivityEpisodeView, AnimeID: &animeID, EpisodeID: &episodes[0], ImportSource: v.source, CreatedAt: v.at}
+		if err := repo.DB().Create(&row).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	completed := local(time.March, 31, 23, 30).UTC() // April in UTC
+	progress := domain.WatchProgress{UserID: 1, EpisodeID: episodes[0], AnimeID: animeID, Position: 1500, Duration: 1500, Completed: true, CompletedAt: &completed}
+	if err := repo.DB().Create(&progress).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := NewStatsService(repo, 0).Get(1, "America/New_York", 2025)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrapped := stats.Wrapped
+	if wrapped.Episodes != 1 || wrapped.MonthlyMinutes[time.March-1] != 25 || wrapped.BusiestMonth != 3 {
+		t.Errorf("wrapped episodes=%d monthly=%v busiest=%d, want 25 minutes in March", wrapped.Episodes, wrapped.MonthlyMinutes, wrapped.BusiestMonth)
+	}
+	heatmap := stats.Heatmap
+	if heatmap[time.Wednesday][20] != 2 || heatmap[time.Wednesday][23] != 1 {
+		t.Errorf("heatmap wednesday = %v, want 2 views at 20h and 1 at 23h", heatmap[time.Wednesday])
+	}
+	if wrapped.FavoriteWeekday != int(time.Wednesday) || wrapped.FavoriteHour != 20 {
+		t.Errorf("favorite weekday %d hour %d, want wednesday at 20h", wrapped.FavoriteWeekday, wrapped.FavoriteHour)
+	}
+	if streaks := stats.Streaks; streaks.ActiveDays != 4 || streaks.LastActive != "2025-12-31" {
+		t.Errorf("streaks = %+v, want 4 active days up to 2025-12-31", streaks)
+	}
+}
@@ -0,0 +1,384 @@
+package service
+
+import (
+	"backend/internal/core/domain"
+	"bytes"
+	"fmt"
+	"html"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/htmlindex"
+	xunicode "golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/unicode/norm"
+)
+
+const maxSubtitleCues = 20000
+
+// rlm is the Unicode RIGHT-TO-LEFT MARK. A leading RLM makes players lay out an
+// Arabic line right to left even when it starts with Latin text or punctuation.
+const rlm = "\u200f"
+
+var (
+	timingPattern    = regexp.MustCompile(`^\s*(\S+)\s*-->\s*(\S+)(.*)$`)
+	timestampPattern = regexp.MustCompile(`^(?:(\d+):)?(\d{1,2}):(\d{1,2})[.,:](\d{1,3})$`)
+	assOverride      = regexp.MustCompile(`\{[^}]*\}`)
+	assDrawing       = regexp.MustCompile(`\\p[1-9]`)
+	cueTag           = regexp.MustCompile(`</?([a-zA-Z]+)[^>]*>`)
+	trailingSpace    = regexp.MustCompile(`(?m)[ \t]+$`)
+	// bidiEmbeddings are the legacy embedding and override controls some editors
+	// scatter through Arabic subtitles. They are replaced by a single RLM per line.
+	bidiEmbeddings = strings.NewReplacer("\u202a", "", "\u202b", "", "\u202c", "", "\u202d", "", "\u202e", "")
+)
+
+// subtitleCue is one timed block of text
+type subtitleCue struct {
+	Start    time.Duration
+	End      time.Duration
+	Settings string // WebVTT cue settings, e.g. "line:0 align:start"
+	Text     string
+}
+
+// detectSubtitleFormat picks the format from the file extension, then sniffs the content
+func detectSubtitleFormat(filename, text string) (domain.SubtitleFormat, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".srt":
+		return domain.SubtitleFormatSRT, nil
+	case ".ass", ".ssa":
+		return domain.SubtitleFormatASS, nil
+	case ".vtt":
+		return domain.SubtitleFormatVTT, nil
+	}
+	head := strings.TrimSpace(text)
+	switch {
+	case strings.HasPrefix(head, "WEBVTT"):
+		return domain.SubtitleFormatVTT, nil
+	case strings.HasPrefix(head, "[Script Info]"):
+		return domain.SubtitleFormatASS, nil
+	case strings.Contains(head, "-->"):
+		return domain.SubtitleFormatSRT, nil
+	}
+	return "", fmt.Errorf("%w: unsupported format, expected SRT, ASS/SSA or VTT", domain.ErrInvalidSubtitle)
+}
+
+// decodeSubtitleText converts raw subtitle bytes to normalised UTF-8 text and
+// names the charset it was decoded from. A BOM wins; otherwise valid UTF-8 is kept
+// as is, and anything else is decoded with charset, or with the usual legacy
+// code page of the track language when charset is empty.
+func decodeSubtitleText(data []byte, charset, language string) (string, string, error) {
+	var enc encoding.Encoding
+	name := ""
+	switch {
+	case bytes.HasPrefix(data, []byte{0xef, 0xbb, 0xbf}):
+		data, name = data[3:], "utf-8"
+	case bytes.HasPrefix(data, []byte{0xff, 0xfe}):
+		enc, name = xunicode.UTF16(xunicode.LittleEndian, xunicode.ExpectBOM), "utf-16le"
+	case bytes.HasPrefix(data, []byte{0xfe, 0xff}):
+		enc, name = xunicode.UTF16(xunicode.BigEndian, xunicode.ExpectBOM), "utf-16be"
+	case charset != "":
+		var err error
+		if enc, err = htmlindex.Get(charset); err != nil {
+			return "", "", fmt.Errorf("%w: unknown charset %q", domain.ErrInvalidSubtitle, charset)
+		}
+		name, _ = htmlindex.Name(enc)
+	case utf8.Valid(data):
+		name = "utf-8"
+	case strings.HasPrefix(language, "ar") || strings.HasPrefix(language, "fa") || strings.HasPrefix(language, "ur"):
+		enc, name = charmap.Windows1256, "windows-1256"
+	default:
+		enc, name = charmap.Windows1252, "windows-1252"
+	}
+
+	if enc != nil {
+		decoded, err := enc.NewDecoder().Bytes(data)
+		if err != nil {
+			return "", "", fmt.Errorf("%w: cannot decode as %s", domain.ErrInvalidSubtitle, name)
+		}
+		data = decoded
+	}
+	if !utf8.Valid(data) {
+		return "", "", fmt.Errorf("%w: not valid %s", domain.ErrInvalidSubtitle, name)
+	}
+
+	text := norm.NFC.String(string(data))
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	text = strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' || !unicode.IsControl(r) {
+			return r
+		}
+		return -1
+	}, text)
+	return strings.TrimPrefix(text, "\ufeff"), name, nil
+}
+
+// parseSubtitle parses text in the given format into cues sorted by start time.
+// Cues without text or with an end before their start are dropped.
+func parseSubtitle(format domain.SubtitleFormat, text string) ([]subtitleCue, error) {
+	var cues []subtitleCue
+	var err error
+	switch format {
+	case domain.SubtitleFormatSRT, domain.SubtitleFormatVTT:
+		cues, err = parseTimedBlocks(text, format == domain.SubtitleFormatVTT)
+	case domain.SubtitleFormatASS:
+		cues, err = parseASS(text)
+	default:
+		err = fmt.Errorf("%w: unsupported format %q", domain.ErrInvalidSubtitle, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	valid := cues[:0]
+	for _, cue := range cues {
+		if strings.TrimSpace(cue.Text) != "" && cue.End > cue.Start {
+			valid = append(valid, cue)
+		}
+	}
+	if len(valid) == 0 {
+		return nil, fmt.Errorf("%w: no cues found", domain.ErrInvalidSubtitle)
+	}
+	if len(valid) > maxSubtitleCues {
+		return nil, fmt.Errorf("%w: more than %d cues", domain.ErrInvalidSubtitle, maxSubtitleCues)
+	}
+	sort.SliceStable(valid, func(i, j int) bool { return valid[i].Start < valid[j].Start })
+	return valid, nil
+}
+
+// parseTimedBlocks parses SRT and WebVTT, which both separate cues by blank lines
+// and put an optional identifier line before the timing line
+func parseTimedBlocks(text string, vtt bool) ([]subtitleCue, error) {
+	blocks := strings.Split(strings.TrimSpace(trailingSpace.ReplaceAllString(text, "")), "\n\n")
+	if vtt {
+		if len(blocks) == 0 || !strings.HasPrefix(blocks[0], "WEBVTT") {
+			return nil, fmt.Errorf("%w: missing WEBVTT header", domain.ErrInvalidSubtitle)
+		}
+		blocks = blocks[1:]
+	}
+
+	var cues []subtitleCue
+	for n, block := range blocks {
+		lines := strings.Split(strings.Trim(block, "\n"), "\n")
+		if len(lines) == 0 || lines[0] == "" {
+			continue
+		}
+		if vtt && (strings.HasPrefix(lines[0], "NOTE") || lines[0] == "STYLE" || lines[0] == "REGION") {
+			continue
+		}
+		timing := 0
+		if !strings.Contains(lines[0], "-->") {
+			timing = 1
+		}
+		if timing >= len(lines) || !strings.Contains(lines[timing], "-->") {
+			// Text that ran on past a blank line belongs to the previous cue
+			if len(cues) > 0 {
+				cues[len(cues)-1].Text += "\n" + sanitizeCueText(strings.Join(lines, "\n"))
+				continue
+			}
+			return nil, fmt.Errorf("%w: block %d has no timing line", domain.ErrInvalidSubtitle, n+1)
+		}
+		cue, err := parseTiming(lines[timing])
+		if err != nil {
+			return nil, fmt.Errorf("%w: block %d: %v", domain.ErrInvalidSubtitle, n+1, err)
+		}
+		if !vtt {
+			cue.Settings = ""
+		}
+		cue.Text = sanitizeCueText(strings.Join(lines[timing+1:], "\n"))
+		cues = append(cues, cue)
+	}
+	return cues, nil
+}
+
+func parseTiming(line string) (subtitleCue, error) {
+	m := timingPattern.FindStringSubmatch(line)
+	if m == nil {
+		return subtitleCue{}, fmt.Errorf("invalid timing line %q", line)
+	}
+	start, err := parseTimestamp(m[1])
+	if err != nil {
+		return subtitleCue{}, err
+	}
+	end, err := parseTimestamp(m[2])
+	if err != nil {
+		return subtitleCue{}, err
+	}
+	return subtitleCue{Start: start, End: end, Settings: strings.Join(strings.Fields(m[3]), " ")}, nil
+}
+
+// parseTimestamp accepts hh:mm:ss,mmm, hh:mm:ss.mmm and mm:ss.mmm. A fraction of
+// fewer than three digits is read as a decimal fraction, as SSA centiseconds are.
+func parseTimestamp(s string) (time.Duration, error) {
+	m := timestampPattern.FindStringSubmatch(s)
+	if m == nil {
+		return 0, fmt.Errorf("invalid timestamp %q", s)
+	}
+	hours, _ := strconv.Atoi(m[1])
+	minutes, _ := strconv.Atoi(m[2])
+	seconds, _ := strconv.Atoi(m[3])
+	fraction := m[4] + strings.Repeat("0", 3-len(m[4]))
+	millis, _ := strconv.Atoi(fraction)
+	if minutes > 59 || seconds > 59 {
+		return 0, fmt.Errorf("invalid timestamp %q", s)
+	}
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute +
+		time.Duration(seconds)*time.Second + time.Duration(millis)*time.Millisecond, nil
+}
+
+// parseASS reads the Dialogue lines of the [Events] section of an ASS or SSA
+// script. Override blocks are stripped and vector drawings are skipped.
+func parseASS(text string) ([]subtitleCue, error) {
+	var cues []subtitleCue
+	inEvents := false
+	var fields []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			inEvents = strings.EqualFold(line, "[Events]")
+			continue
+		}
+		if !inEvents {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "Format":
+			fields = nil
+			for _, f := range strings.Split(value, ",") {
+				fields = append(fields, strings.ToLower(strings.TrimSpace(f)))
+			}
+		case "Dialogue":
+			if fields == nil {
+				return nil, fmt.Errorf("%w: Dialogue before Format in [Events]", domain.ErrInvalidSubtitle)
+			}
+			values := strings.SplitN(strings.TrimSpace(value), ",", len(fields))
+			if len(values) != len(fields) {
+				continue
+			}
+			event := map[string]string{}
+			for i, f := range fields {
+				event[f] = values[i]
+			}
+			body := event["text"]
+			if assDrawing.MatchString(body) {
+				continue
+			}
+			start, err := parseTimestamp(strings.TrimSpace(event["start"]))
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", domain.ErrInvalidSubtitle, err)
+			}
+			end, err := parseTimestamp(strings.TrimSpace(event["end"]))
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", domain.ErrInvalidSubtitle, err)
+			}
+			body = assOverride.ReplaceAllString(body, "")
+			body = strings.NewReplacer(`\N`, "\n", `\n`, "\n", `\h`, " ").Replace(body)
+			cues = append(cues, subtitleCue{Start: start, End: end, Text: sanitizeCueText(body)})
+		}
+	}
+	if !inEvents && len(cues) == 0 && fields == nil {
+		return nil, fmt.Errorf("%w: missing [Events] section", domain.ErrInvalidSubtitle)
+	}
+	return cues, nil
+}
+
+// sanitizeCueText keeps the <i>, <b> and <u> tags WebVTT shares with SRT, drops
+// other tags such as <font>, re-escapes stray markup and fixes the direction of
+// right-to-left lines. It is idempotent so converted tracks can be parsed again.
+func sanitizeCueText(text string) string {
+	text = cueTag.ReplaceAllStringFunc(text, func(tag string) string {
+		name := strings.ToLower(cueTag.FindStringSubmatch(tag)[1])
+		if name == "i" || name == "b" || name == "u" {
+			if strings.HasPrefix(tag, "</") {
+				return "\x00/" + name + "\x01"
+			}
+			return "\x00" + name + "\x01"
+		}
+		return ""
+	})
+	text = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(html.UnescapeString(text))
+	text = strings.NewReplacer("\x00", "<", "\x01", ">").Replace(text)
+
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(bidiEmbeddings.Replace(line))
+		if line == "" {
+			continue
+		}
+		if isRTLLine(line) && !strings.HasPrefix(line, rlm) {
+			line = rlm + line
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// isRTLLine reports whether most letters of line are Arabic or Hebrew
+func isRTLLine(line string) bool {
+	rtl, ltr := 0, 0
+	for _, r := range line {
+		switch {
+		case unicode.In(r, unicode.Arabic, unicode.Hebrew):
+			if unicode.IsLetter(r) {
+				rtl++
+			}
+		case unicode.IsLetter(r):
+			ltr++
+		}
+	}
+	return rtl > 0 && rtl >= ltr
+}
+
+// shiftCues moves cues starting at or after from by offset. Cues pushed entirely
+// before zero are dropped and the rest are clamped to start at zero.
+func shiftCues(cues []subtitleCue, offset, from time.Duration) []subtitleCue {
+	shifted := cues[:0]
+	for _, cue := range cues {
+		if cue.Start >= from {
+			cue.Start += offset
+			cue.End += offset
+		}
+		if cue.End <= 0 {
+			continue
+		}
+		if cue.Start < 0 {
+			cue.Start = 0
+		}
+		shifted = append(shifted, cue)
+	}
+	return shifted
+}
+
+// renderVTT writes cues as a WebVTT file
+func renderVTT(cues []subtitleCue) []byte {
+	var b bytes.Buffer
+	b.WriteString("WEBVTT\n\n")
+	for _, cue := range cues {
+		b.WriteString(formatVTTTimestamp(cue.Start))
+		b.WriteString(" --> ")
+		b.WriteString(formatVTTTimestamp(cue.End))
+		if cue.Settings != "" {
+			b.WriteString(" " + cue.Settings)
+		}
+		b.WriteString("\n")
+		b.WriteString(cue.Text)
+		b.WriteString("\n\n")
+	}
+	return b.Bytes()
+}
+
+func formatVTTTimestamp(d time.Duration) string {
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"backend/internal/adapters/repository"
+	"backend/internal/core/domain"
+	"backend/internal/core/port"
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	subtitleDir     = "uploads/subtitles"
+	maxSubtitleSize = 5 * 1024 * 1024
+)
+
+type SubtitleService struct {
+	repo  *repository.SQLiteRepository
+	blobs port.BlobStore
+}
+
+func NewSubtitleService(repo *repository.SQLiteRepository, blobs port.BlobStore) *SubtitleService {
+	return &SubtitleService{repo: repo, blobs: blobs}
+}
+
+// SubtitleUpload describes an uploaded subtitle file
+type SubtitleUpload struct {
+	Language  string
+	Label     string
+	IsDefault bool
+	Charset   string // Overrides encoding detection, e.g. "windows-1256"
+	OffsetMs  int64  // Applied to every cue while converting
+}
+
+// SubtitleTrackInput is a partial update of a track's metadata
+type SubtitleTrackInput struct {
+	Language  *string `json:"language"`
+	Label     *string `json:"label"`
+	IsDefault *bool   `json:"is_default"`
+}
+
+// List returns an episode's tracks ordered for the locale chain. Episodes that are
+// not published have none unless includeUnpublished is set.
+func (s *SubtitleService) List(locales []string, episodeID uint, includeUnpublished bool) ([]domain.SubtitleTrack, error) {
+	if !includeUnpublished {
+		if _, err := s.repo.GetPublishedEpisodeByID(episodeID); err != nil {
+			return nil, ErrEpisodeNotFound
+		}
+	}
+	episode := &domain.Episode{ID: episodeID}
+	if err := s.Attach(locales, episode); err != nil {
+		return nil, err
+	}
+	return episode.Subtitles, nil
+}
+
+// Attach fills the Subtitles of each episode, ordered by the position of their
+// language in the locale chain
+func (s *SubtitleService) Attach(locales []string, episodes ...*domain.Episode) error {
+	ids := make([]uint, 0, len(episodes))
+	for _, episode := range episodes {
+		ids = append(ids, episode.ID)
+	}
+	tracks, err := s.repo.GetSubtitleTracks(ids)
+	if err != nil {
+		return err
+	}
+	byEpisode := map[uint][]domain.SubtitleTrack{}
+	for _, track := range tracks {
+		byEpisode[track.EpisodeID] = append(byEpisode[track.EpisodeID], track)
+	}
+	for _, episode := range episodes {
+		episode.Subtitles = byEpisode[episode.ID]
+		if episode.Subtitles == nil {
+			episode.Subtitles = []domain.SubtitleTrack{}
+		}
+		orderSubtitles(locales, episode.Subtitles)
+	}
+	return nil
+}
+
+// Upload converts a SRT, ASS/SSA or WebVTT file to WebVTT and adds it to the episode
+func (s *SubtitleService) Upload(episodeID uint, file *multipart.FileHeader, input SubtitleUpload) (*domain.SubtitleTrack, error) {
+	if _, err := s.repo.GetEpisodeByID(episodeID); err != nil {
+		return nil, ErrEpisodeNotFound
+	}
+	if file.Size > maxSubtitleSize {
+		return nil, fmt.Errorf("%w: file too large, max %d MB", domain.ErrInvalidSubtitle, maxSubtitleSize>>20)
+	}
+	language, err := domain.NormalizeLocale(input.Language)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid language %q", domain.ErrInvalidSubtitle, input.Language)
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+	data, err := io.ReadAll(io.LimitReader(src, maxSubtitleSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	text, charset, err := decodeSubtitleText(data, strings.TrimSpace(input.Charset), language)
+	if err != nil {
+		return nil, err
+	}
+	format, err := detectSubtitleFormat(file.Filename, text)
+	if err != nil {
+		return nil, err
+	}
+	cues, err := parseSubtitle(format, text)
+	if err != nil {
+		return nil, err
+	}
+	if input.OffsetMs != 0 {
+		cues = shiftCues(cues, time.Duration(input.OffsetMs)*time.Millisecond, 0)
+	}
+
+	track := &domain.SubtitleTrack{
+		EpisodeID:      episodeID,
+		Language:       language,
+		Label:          strings.TrimSpace(input.Label),
+		IsDefault:      input.IsDefault,
+		SourceFormat:   format,
+		SourceEncoding: charset,
+		OffsetMs:       input.OffsetMs,
+	}
+	if track.Label == "" {
+		track.Label = language
+	}
+	if !track.IsDefault {
+		// The first track of an episode is its default
+		existing, err := s.repo.GetSubtitleTracks([]uint{episodeID})
+		if err != nil {
+			return nil, err
+		}
+		track.IsDefault = len(existing) == 0
+	}
+	if err := s.writeCues(track, cues); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SaveSubtitleTrack(track); err != nil {
+		s.removeFile(track.Path)
+		return nil, err
+	}
+	return track, nil
+}
+
+// Update changes a track's language, label or default flag. Clearing the flag of
+// the default track leaves the episode without a default.
+func (s *SubtitleService) Update(episodeID, trackID uint, input SubtitleTrackInput) (*domain.SubtitleTrack, error) {
+	track, err := s.repo.GetSubtitleTrack(episodeID, trackID)
+	if err != nil {
+		return nil, err
+	}
+	if input.Language != nil {
+		language, err := domain.NormalizeLocale(*input.Language)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid language %q", domain.ErrInvalidSubtitle, *input.Language)
+		}
+		track.Language = language
+	}
+	if input.Label != nil {
+		track.Label = strings.TrimSpace(*input.Label)
+	}
+	if input.IsDefault != nil {
+		track.IsDefault = *input.IsDefault
+	}
+	if err := s.repo.SaveSubtitleTrack(track); err != nil {
+		return nil, err
+	}
+	return track, nil
+}
+
+// Offset shifts the cues of a track starting at or after fromMs by offsetMs, which
+// may be negative. The shifted file gets a new URL so players and caches reload it.
+func (s *SubtitleService) Offset(episodeID, trackID uint, offsetMs, fromMs int64) (*domain.SubtitleTrack, error) {
+	track, err := s.repo.GetSubtitleTrack(episodeID, trackID)
+	if err != nil {
+		return nil, err
+	}
+	r, err := s.blobs.Get(blobKey(track.Path))
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(io.LimitReader(r, maxSubtitleSize))
+	r.Close()
+	if err != nil {
+		return nil, err
+	}
+	cues, err := parseSubtitle(domain.SubtitleFormatVTT, string(data))
+	if err != nil {
+		return nil, err
+	}
+	cues = shiftCues(cues, time.Duration(offsetMs)*time.Millisecond, time.Duration(fromMs)*time.Millisecond)
+	if len(cues) == 0 {
+		return nil, fmt.Errorf("%w: offset removes every cue", domain.ErrInvalidSubtitle)
+	}
+
+	oldPath := track.Path
+	if err := s.writeCues(track, cues); err != nil {
+		return nil, err
+	}
+	track.OffsetMs += offsetMs
+	if err := s.repo.SaveSubtitleTrack(track); err != nil {
+		s.removeFile(track.Path)
+		return nil, err
+	}
+	s.removeFile(oldPath)
+	return track, nil
+}
+
+// Delete removes a track and its file
+func (s *SubtitleService) Delete(episodeID, trackID uint) error {
+	track, err := s.repo.GetSubtitleTrack(episodeID, trackID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteSubtitleTrack(track); err != nil {
+		return err
+	}
+	s.removeFile(track.Path)
+	return nil
+}
+
+// writeCues renders cues to a new .vtt file and points the track at it
+func (s *SubtitleService) writeCues(track *domain.SubtitleTrack, cues []subtitleCue) error {
+	filename := fmt.Sprintf("%d_%s.vtt", track.EpisodeID, uuid.New().String())
+	path := subtitleDir + "/" + filename
+	data := renderVTT(cues)
+	if err := s.blobs.Put(blobKey(path), bytes.NewReader(data), int64(len(data)), "text/vtt"); err != nil {
+		return err
+	}
+	track.Path = path
+	track.URL = "/" + path
+	track.CueCount = len(cues)
+	return nil
+}
+
+func (s *SubtitleService) removeFile(path string) {
+	if err := s.blobs.Delete(blobKey(path)); err != nil {
+		log.Printf("Subtitles: failed to remove %s: %v", path, err)
+	}
+}
+
+// orderSubtitles sorts tracks by the position of their language in the locale
+// chain, the default track first within a language
+func orderSubtitles(locales []string, tracks []domain.SubtitleTrack) {
+	sort.SliceStable(tracks, func(i, j int) bool {
+		ri, rj := localeRank(locales, tracks[i].Language), localeRank(locales, tracks[j].Language)
+		if ri != rj {
+			return ri < rj
+		}
+		if tracks[i].IsDefault != tracks[j].IsDefault {
+			return tracks[i].IsDefault
+		}
+		return tracks[i].ID < tracks[j].ID
+	})
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"backend/internal/core/domain"
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+const assHeader = "[Script Info]\nScriptType: v4.00+\n\n[V4+ Styles]\nFormat: Name, Fontname\nStyle: Default,Arial\n\n[Events]\n" +
+	"Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text\n"
+
+func TestSubtitleToVTT(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		text     string
+		want     string
+	}{
+		{
+			name:     "srt",
+			filename: "ep1.srt",
+			text:     "1\n00:00:01,000 --> 00:00:02,500\nHello\n\n2\n00:00:03,000 --> 00:00:04,000\n<font color=\"red\"><i>Two</i></font>\nlines\n",
+			want:     "WEBVTT\n\n00:00:01.000 --> 00:00:02.500\nHello\n\n00:00:03.000 --> 00:00:04.000\n<i>Two</i>\nlines\n\n",
+		},
+		{
+			name:     "srt sorted, without identifiers and with run-on text",
+			filename: "ep1.srt",
+			text:     "00:00:05,000 --> 00:00:06,000\nLater\n\ncontinued\n\n00:00:01,000 --> 00:00:02,000\nFirst\n",
+			want:     "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\nFirst\n\n00:00:05.000 --> 00:00:06.000\nLater\ncontinued\n\n",
+		},
+		{
+			name:     "srt drops empty and backwards cues and srt settings",
+			filename: "ep1.srt",
+			text:     "1\n00:00:01,000 --> 00:00:02,000 X1:10\nKept\n\n2\n00:00:03,000 --> 00:00:02,000\nBackwards\n\n3\n00:00:04,000 --> 00:00:05,000\n<font color=\"red\"> </font>\n",
+			want:     "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\nKept\n\n",
+		},
+		{
+			name:     "ass",
+			filename: "ep1.ass",
+			text: assHeader +
+				"Dialogue: 0,0:00:01.50,0:00:03.00,Default,,0,0,0,,{\\an8}Top, with comma\\NSecond line\n" +
+				"Dialogue: 0,0:00:04.00,0:00:05.00,Default,,0,0,0,,{\\p1}m 0 0 l 100 0 100 100\n" +
+				"Comment: 0,0:00:06.00,0:00:07.00,Default,,0,0,0,,Not shown\n",
+			want: "WEBVTT\n\n00:00:01.500 --> 00:00:03.000\nTop, with comma\nSecond line\n\n",
+		},
+		{
+			name:     "vtt keeps settings and skips notes",
+			filename: "ep1.vtt",
+			text:     "WEBVTT\n\nNOTE made by hand\n\nintro\n01:02.000 --> 01:03.000 line:0 align:start\nA &amp; B < C\n",
+			want:     "WEBVTT\n\n00:01:02.000 --> 00:01:03.000 line:0 align:start\nA &amp; B &lt; C\n\n",
+		},
+		{
+			name:     "sniffed without extension",
+			filename: "upload",
+			text:     "1\n00:00:01,000 --> 00:00:02,000\nSniffed\n",
+			want:     "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\nSniffed\n\n",
+		},
+		{
+			name:     "arabic lines get a right-to-left mark",
+			filename: "ar.srt",
+			text:     "1\n00:00:01,000 --> 00:00:02,000\n‫مرحبا OK‬\nHello\n",
+			want:     "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\n‏مرحبا OK\nHello\n\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, err := detectSubtitleFormat(tt.filename, tt.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cues, err := parseSubtitle(format, tt.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := string(renderVTT(cues))
+			if got != tt.want {
+				t.Fatalf("got\n%q\nwant\n%q", got, tt.want)
+			}
+
+			// Converted tracks are parsed again when they are shifted, so the
+			// conversion must be stable
+			again, err := parseSubtitle(domain.SubtitleFormatVTT, got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(renderVTT(again)) != got {
+				t.Errorf("re-rendering changed the track:\n%q", renderVTT(again))
+			}
+		})
+	}
+}
+
+func TestParseSubtitleErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		format domain.SubtitleFormat
+		text   string
+	}{
+		{"no cues", domain.SubtitleFormatSRT, "\n\n"},
+		{"no timing line", domain.SubtitleFormatSRT, "1\nHello\n"},
+		{"bad timestamp", domain.SubtitleFormatSRT, "1\n00:00:01,000 --> 00:61:00,000\nHello\n"},
+		{"vtt without header", domain.SubtitleFormatVTT, "00:00:01.000 --> 00:00:02.000\nHello\n"},
+		{"ass without events", domain.SubtitleFormatASS, "[Script Info]\nTitle: x\n"},
+		{"ass dialogue before format", domain.SubtitleFormatASS, "[Events]\nDialogue: 0,0:00:01.00,0:00:02.00,Default,,0,0,0,,Hi\n"},
+		{"unknown format", "sub", "hello"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseSubtitle(tt.format, tt.text); !errors.Is(err, domain.ErrInvalidSubtitle) {
+				t.Errorf("err = %v, want ErrInvalidSubtitle", err)
+			}
+		})
+	}
+	if _, err := detectSubtitleFormat("ep1.txt", "just text"); !errors.Is(err, domain.ErrInvalidSubtitle) {
+		t.Errorf("detectSubtitleFormat err = %v, want ErrInvalidSubtitle", err)
+	}
+}
+
+func TestParseTimestamp(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+		ok   bool
+	}{
+		{"00:00:01,000", time.Second, true},
+		{"01:02:03.456", time.Hour + 2*time.Minute + 3*time.Second + 456*time.Millisecond, true},
+		{"02:03.4", 2*time.Minute + 3*time.Second + 400*time.Millisecond, true},
+		{"0:00:01.50", 1500 * time.Millisecond, true},
+		{"100:00:00.000", 100 * time.Hour, true},
+		{"00:00:01:20", time.Second + 200*time.Millisecond, true},
+		{"00:60:00.000", 0, false},
+		{"00:00:60.000", 0, false},
+		{"00:01", 0, false},
+		{"1.5", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := parseTimestamp(tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("parseTimestamp(%q) = %v, %v; want %v, ok %v", tt.in, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestDecodeSubtitleText(t *testing.T) {
+	arabic, _ := charmap.Windows1256.NewEncoder().String("مرحبا")
+	latin, _ := charmap.Windows1252.NewEncoder().String("café")
+	tests := []struct {
+		name     string
+		data     []byte
+		charset  string
+		language string
+		want     string
+		wantName string
+	}{
+		{"utf-8", []byte("a\r\nb\rc"), "", "en", "a\nb\nc", "utf-8"},
+		{"utf-8 bom", []byte("\xef\xbb\xbfhi"), "", "en", "hi", "utf-8"},
+		{"utf-16le bom", []byte{0xff, 0xfe, 'h', 0, 'i', 0}, "", "en", "hi", "utf-16le"},
+		{"utf-16be bom", []byte{0xfe, 0xff, 0, 'h', 0, 'i'}, "", "en", "hi", "utf-16be"},
+		{"arabic code page from language", []byte(arabic), "", "ar", "مرحبا", "windows-1256"},
+		{"latin code page by default", []byte(latin), "", "fr", "café", "windows-1252"},
+		{"explicit charset", []byte(arabic), "cp1256", "en", "مرحبا", "windows-1256"},
+		{"control characters dropped", []byte("a\x00b\tc"), "", "en", "ab\tc", "utf-8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, name, err := decodeSubtitleText(tt.data, tt.charset, tt.language)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want || name != tt.wantName {
+				t.Errorf("got %q as %s, want %q as %s", got, name, tt.want, tt.wantName)
+			}
+		})
+	}
+	if _, _, err := decodeSubtitleText([]byte("hi"), "klingon", "en"); !errors.Is(err, domain.ErrInvalidSubtitle) {
+		t.Errorf("unknown charset: err = %v, want ErrInvalidSubtitle", err)
+	}
+}
+
+func TestShiftCues(t *testing.T) {
+	cue := func(start, end int) subtitleCue {
+		return subtitleCue{Start: time.Duration(start) * time.Second, End: time.Duration(end) * time.Second, Text: "x"}
+	}
+	tests := []struct {
+		name   string
+		offset int
+		from   int
+		want   [][2]int
+	}{
+		{"later", 2, 0, [][2]int{{3, 4}, {5, 7}, {12, 14}}},
+		{"earlier drops and clamps", -4, 0, [][2]int{{0, 1}, {6, 8}}},
+		{"from a point", 5, 3, [][2]int{{1, 2}, {8, 10}, {15, 17}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cues := []subtitleCue{cue(1, 2), cue(3, 5), cue(10, 12)}
+			got := shiftCues(cues, time.Duration(tt.offset)*time.Second, time.Duration(tt.from)*time.Second)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d cues, want %d", len(got), len(tt.want))
+			}
+			for i, w := range tt.want {
+				if got[i].Start != time.Duration(w[0])*time.Second || got[i].End != time.Duration(w[1])*time.Second {
+					t.Errorf("cue %d = %v-%v, want %ds-%ds", i, got[i].Start, got[i].End, w[0], w[1])
+				}
+			}
+		})
+	}
+}
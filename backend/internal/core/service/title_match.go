@@ -0,0 +1,108 @@
+package service
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Romanized Japanese titles are written with and without macrons ("Shōnen" / "Shounen" / "Shonen")
+var titleFolding = strings.NewReplacer(
+	"ā", "a", "ē", "e", "ī", "i", "ō", "o", "ū", "u",
+	"â", "a", "ê", "e", "î", "i", "ô", "o", "û", "u",
+	"á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u",
+	"à", "a", "è", "e", "ì", "i", "ò", "o", "ù", "u",
+	"ä", "a", "ë", "e", "ï", "i", "ö", "o", "ü", "u",
+	"ou", "o", "uu", "u",
+	"&", " and ",
+)
+
+// normalizeTitle lowercases, folds accents and turns punctuation, dashes and underscores into
+// single spaces, so "Shingeki no Kyōjin: Season 3" and "shingeki-no-kyojin_season_3" are equal.
+func normalizeTitle(s string) string {
+	s = titleFolding.Replace(strings.ToLower(s))
+	var b strings.Builder
+	space := false
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteRune(r)
+			space = false
+		} else {
+			space = true
+		}
+	}
+	return b.String()
+}
+
+// titleSimilarity compares two normalized titles and returns a score between 0 and 1.
+// It takes the better of an edit-distance ratio and a word-overlap ratio, so both typos and
+// reordered/extra words ("Season 3 Part 2") are tolerated.
+func titleSimilarity(a, b string) float64 {
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+	ra, rb := []rune(a), []rune(b)
+	longest, shortest := len(ra), len(rb)
+	if shortest > longest {
+		longest, shortest = shortest, longest
+	}
+
+	best := tokenOverlap(a, b)
+	// The edit ratio can't beat shortest/longest, skip the quadratic work when it can't win
+	if float64(shortest)/float64(longest) > best {
+		if ratio := 1 - float64(levenshtein(ra, rb))/float64(longest); ratio > best {
+			best = ratio
+		}
+	}
+	return best
+}
+
+func tokenOverlap(a, b string) float64 {
+	ta, tb := strings.Fields(a), strings.Fields(b)
+	set := make(map[string]bool, len(ta))
+	for _, t := range ta {
+		set[t] = true
+	}
+	common, union := 0, len(set)
+	seen := map[string]bool{}
+	for _, t := range tb {
+		if seen[t] {
+			continue
+		}
+		seen[t] = true
+		if set[t] {
+			common++
+		} else {
+			union++
+		}
+	}
+	if union == 0 {
+		return 0
+	}
+	return float64(common) / float64(union)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"backend/internal/core/domain"
+	"math"
+	"testing"
+)
+
+func TestNormalizeTitle(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Shingeki no Kyōjin: Season 3", "shingeki no kyojin season 3"},
+		{"shingeki-no-kyojin_season_3", "shingeki no kyojin season 3"},
+		{"Shounen  Ashibe", "shonen ashibe"},
+		{"Kaguya-sama wa Kokurasetai? ~Ultra Romantic~", "kaguya sama wa kokurasetai ultra romantic"},
+		{"Fire & Ice", "fire and ice"},
+		{"  ...  ", ""},
+		{"ONE PIECE", "one piece"},
+		{"هجوم العمالقة", "هجوم العمالقة"},
+	}
+	for _, tt := range tests {
+		if got := normalizeTitle(tt.in); got != tt.want {
+			t.Errorf("normalizeTitle(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTitleSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want float64
+	}{
+		{"equal", "death note", "death note", 1},
+		{"empty", "", "death note", 0},
+		{"typo", "naruto shippuden", "naruto shipuden", 1 - 1.0/16},
+		{"extra words", "mob psycho 100 season 2", "mob psycho 100", 14.0 / 23},
+		{"shared words", "one piece film red", "one piece", 2.0 / 4},
+		{"reordered words", "season 2 overlord", "overlord season 2", 1},
+		{"unrelated", "bleach", "monster", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := titleSimilarity(tt.a, tt.b)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("titleSimilarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+			if back := titleSimilarity(tt.b, tt.a); math.Abs(back-got) > 1e-9 {
+				t.Errorf("titleSimilarity is not symmetric: %v and %v", got, back)
+			}
+		})
+	}
+}
+
+func TestMatchTitles(t *testing.T) {
+	animes := []domain.Anime{
+		{ID: 1, Title: "Shingeki no Kyojin", TitleEn: "Attack on Titan", Slug: "shingeki-no-kyojin"},
+		{ID: 2, Title: "Death Note", Slug: "death-note"},
+		{ID: 3, Title: "Naruto Shippuden", Slug: "naruto-shippuden"},
+	}
+	index := make([]titleIndexEntry, len(animes))
+	exact := map[string]int{}
+	for i, a := range animes {
+		index[i] = titleIndexEntry{anime: a}
+		for _, title := range []string{a.Title, a.TitleEn, a.Slug} {
+			if n := normalizeTitle(title); n != "" {
+				index[i].variants = append(index[i].variants, n)
+				exact[n] = i
+			}
+		}
+	}
+
+	tests := []struct {
+		name       string
+		titles     []string
+		want       uint
+		confidence float64
+		autoMatch  bool
+		candidates int
+	}{
+		{"exact english title", []string{"Attack on Titan"}, 1, 1, true, 0},
+		{"exact after folding", []string{"Shingeki no Kyōjin"}, 1, 1, true, 0},
+		{"any title matches", []string{"", "Unknown", "death-note"}, 2, 1, true, 0},
+		{"typo", []string{"Naruto Shipuden"}, 3, 1 - 1.0/16, true, 1},
+		{"suggested only", []string{"Naruto"}, 3, 1.0 / 2, false, 1},
+		{"no candidates", []string{"Cowboy Bebop"}, 0, 0, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			anime, confidence, candidates := matchTitles(tt.titles, index, exact)
+			if (confidence >= listImportAutoMatch) != tt.autoMatch {
+				t.Errorf("confidence %v: auto match %v, want %v", confidence, !tt.autoMatch, tt.autoMatch)
+			}
+			if len(candidates) != tt.candidates {
+				t.Errorf("%d candidates, want %d", len(candidates), tt.candidates)
+			}
+			if tt.want == 0 {
+				return
+			}
+			if anime == nil || anime.ID != tt.want || math.Abs(confidence-tt.confidence) > 1e-9 {
+				t.Errorf("matched %+v with %v, want anime %d with %v", anime, confidence, tt.want, tt.confidence)
+			}
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"backend/internal/core/domain"
+	"backend/internal/core/port"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var ErrUnknownTranslationEntity = errors.New("unknown translation entity")
+
+type TranslationService struct {
+	repo         port.TranslationRepository
+	revisionRepo port.RevisionRepository
+}
+
+func NewTranslationService(repo port.TranslationRepository, revisionRepo port.RevisionRepository) *TranslationService {
+	return &TranslationService{repo: repo, revisionRepo: revisionRepo}
+}
+
+func (s *TranslationService) validate(entity string) error {
+	if _, ok := domain.TranslatableFields[entity]; !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownTranslationEntity, entity)
+	}
+	return nil
+}
+
+// Localize fills Localized on each item with the first non-empty value found by
+// walking the locale chain. Legacy locales are read from the item itself.
+func (s *TranslationService) Localize(locales []string, items ...domain.Translatable) error {
+	if len(locales) == 0 || len(items) == 0 {
+		return nil
+	}
+
+	ids := map[string][]uint{}
+	for _, item := range items {
+		entity, id := item.TranslationRef()
+		if id != 0 {
+			ids[entity] = append(ids[entity], id)
+		}
+	}
+
+	// entity -> id -> field -> locale -> value
+	values := map[string]map[uint]map[string]map[string]string{}
+	for entity, entityIDs := range ids {
+		translations, err := s.repo.GetTranslations(entity, entityIDs, locales)
+		if err != nil {
+			return err
+		}
+		byID := map[uint]map[string]map[string]string{}
+		for _, t := range translations {
+			if byID[t.EntityID] == nil {
+				byID[t.EntityID] = map[string]map[string]string{}
+			}
+			if byID[t.EntityID][t.Field] == nil {
+				byID[t.EntityID][t.Field] = map[string]string{}
+			}
+			byID[t.EntityID][t.Field][t.Locale] = t.Value
+		}
+		values[entity] = byID
+	}
+
+	for _, item := range items {
+		entity, id := item.TranslationRef()
+		if id == 0 {
+			continue
+		}
+		for _, field := range domain.TranslatableFields[entity] {
+			for _, locale := range locales {
+				var value string
+				if column, ok := domain.LegacyColumn(entity, field, locale); ok {
+					value = item.LegacyValue(column)
+				} else {
+					value = values[entity][id][field][locale]
+				}
+				if value != "" {
+					item.SetLocalized(field, value)
+					break
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// LocalizeAnimes localizes animes together with their embedded dictionaries
+func (s *TranslationService) LocalizeAnimes(locales []string, animes []domain.Anime) error {
+	var items []domain.Translatable
+	for i := range animes {
+		items = append(items, animeTranslatables(&animes[i])...)
+	}
+	return s.Localize(locales, items...)
+}
+
+// LocalizeEpisodes localizes episodes together with their preloaded anime
+func (s *TranslationService) LocalizeEpisodes(locales []string, episodes []domain.Episode) error {
+	var items []domain.Translatable
+	for i := range episodes {
+		items = append(items, &episodes[i])
+		items = append(items, animeTranslatables(&episodes[i].Anime)...)
+	}
+	return s.Localize(locales, items...)
+}
+
+// LocalizeAnime localizes one anime together with its embedded dictionaries
+func (s *TranslationService) LocalizeAnime(locales []string, anime *domain.Anime) error {
+	return s.Localize(locales, animeTranslatables(anime)...)
+}
+
+// LocalizeEpisode localizes one episode together with its preloaded anime
+func (s *TranslationService) LocalizeEpisode(locales []string, episode *domain.Episode) error {
+	return s.Localize(locales, append([]domain.Translatable{episode}, animeTranslatables(&episode.Anime)...)...)
+}
+
+func animeTranslatables(anime *domain.Anime) []domain.Translatable {
+	items := []domain.Translatable{anime, &anime.Season, &anime.Studio, &anime.LanguageRel}
+	for i := range anime.Categories {
+		items = append(items, &anime.Categories[i])
+	}
+	return items
+}
+
+// Get returns every translation of a row as locale -> field -> value, legacy
+// columns included
+func (s *TranslationService) Get(entity string, id uint) (map[string]map[string]string, error) {
+	if err := s.validate(entity); err != nil {
+		return nil, err
+	}
+	item, err := s.repo.GetTranslatable(entity, id)
+	if err != nil {
+		return nil, err
+	}
+	translations, err := s.repo.GetTranslations(entity, []uint{id}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]map[string]string{}
+	set := func(locale, field, value string) {
+		if value == "" {
+			return
+		}
+		if result[locale] == nil {
+			result[locale] = map[string]string{}
+		}
+		result[locale][field] = value
+	}
+	for _, field := range domain.TranslatableFields[entity] {
+		for _, locale := range []string{domain.LocaleArabic, domain.LocaleEnglish} {
+			if column, ok := domain.LegacyColumn(entity, field, locale); ok {
+				set(locale, field, item.LegacyValue(column))
+			}
+		}
+	}
+	for _, t := range translations {
+		set(t.Locale, t.Field, t.Value)
+	}
+	return result, nil
+}
+
+// Save writes the given fields of a row in one locale and returns the updated set.
+// Legacy columns of anime and episodes are saved as a new revision by userID.
+func (s *TranslationService) Save(entity string, id uint, locale string, values map[string]string, userID uint) (map[string]map[string]string, error) {
+	if err := s.validate(entity); err != nil {
+		return nil, err
+	}
+	normalized, err := domain.NormalizeLocale(locale)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", err, locale)
+	}
+	locale = normalized
+	for field := range values {
+		if !domain.IsTranslatableField(entity, field) {
+			return nil, fmt.Errorf("%w: %s.%s", domain.ErrUnknownTranslationField, entity, field)
+		}
+	}
+	item, err := s.repo.GetTranslatable(entity, id)
+	if err != nil {
+		return nil, err
+	}
+	revision, version, err := s.legacyRevision(item, locale, values, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SaveTranslations(entity, id, locale, values, revision, version); err != nil {
+		return nil, err
+	}
+	return s.Get(entity, id)
+}
+
+// legacyRevision builds the revision of an anime or episode whose legacy columns are
+// about to change, along with the version it was loaded at. Other rows, and writes
+// that only touch the translations table, are not versioned and get a nil revision.
+func (s *TranslationService) legacyRevision(item domain.Translatable, locale string, values map[string]string, userID uint) (*domain.Revision, int, error) {
+	entity, id := item.TranslationRef()
+	legacy := map[string]string{}
+	for field, value := range values {
+		if column, ok := domain.LegacyColumn(entity, field, locale); ok {
+			legacy[column] = value
+		}
+	}
+	if len(legacy) == 0 {
+		return nil, 0, nil
+	}
+	// Legacy columns are also the rows' JSON keys, so decoding them over a copy gives the saved state
+	patch, err := json.Marshal(legacy)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var version int
+	var before, after func() (json.RawMessage, error)
+	switch row := item.(type) {
+	case *domain.Anime:
+		updated := *row
+		if err := json.Unmarshal(patch, &updated); err != nil {
+			return nil, 0, err
+		}
+		version = row.Version
+		before = func() (json.RawMessage, error) { return animeSnapshot(row) }
+		after = func() (json.RawMessage, error) { return animeSnapshot(&updated) }
+	case *domain.Episode:
+		updated := *row
+		if err := json.Unmarshal(patch, &updated); err != nil {
+			return nil, 0, err
+		}
+		version = row.Version
+		before = func() (json.RawMessage, error) { return episodeSnapshot(row) }
+		after = func() (json.RawMessage, error) { return episodeSnapshot(&updated) }
+	default:
+		return nil, 0, nil
+	}
+
+	if err := recordBaseline(s.revisionRepo, entity, id, version, before); err != nil {
+		return nil, 0, err
+	}
+	snapshot, err := after()
+	if err != nil {
+		return nil, 0, err
+	}
+	return newRevision(domain.RevisionActionUpdate, userID, snapshot), version, nil
+}
+
+// Delete removes a row's translations in one locale. Legacy locales can only be
+// cleared field by field through Save.
+func (s *TranslationService) Delete(entity string, id uint, locale string) error {
+	if err := s.validate(entity); err != nil {
+		return err
+	}
+	locale, err := domain.NormalizeLocale(locale)
+	if err != nil {
+		return err
+	}
+	if locale == domain.LocaleArabic || locale == domain.LocaleEnglish {
+		return domain.ErrLegacyLocale
+	}
+	if _, err := s.repo.GetTranslatable(entity, id); err != nil {
+		return err
+	}
+	return s.repo.DeleteTranslations(entity, id, locale)
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"backend/internal/core/domain"
+	"backend/internal/core/port"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+var ErrUnknownTrashEntity = errors.New("unknown trash entity")
+
+type TrashService struct {
+	repo          port.TrashRepository
+	media         *MediaService
+	retentionDays int // 0 disables automatic purging
+}
+
+func NewTrashService(repo port.TrashRepository, media *MediaService, retentionDays int) *TrashService {
+	return &TrashService{repo: repo, media: media, retentionDays: retentionDays}
+}
+
+func (s *TrashService) validate(entity string) error {
+	if !containsString(domain.TrashEntities, entity) {
+		return fmt.Errorf("%w: %q", ErrUnknownTrashEntity, entity)
+	}
+	return nil
+}
+
+// List returns deleted rows of entity with the date the retention job will purge them
+func (s *TrashService) List(entity string, limit, offset int) ([]domain.TrashItem, int64, error) {
+	if err := s.validate(entity); err != nil {
+		return nil, 0, err
+	}
+	items, total, err := s.repo.ListTrash(entity, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	if s.retentionDays > 0 {
+		for i := range items {
+			purgeAt := items[i].DeletedAt.AddDate(0, 0, s.retentionDays)
+			items[i].PurgeAt = &purgeAt
+		}
+	}
+	return items, total, nil
+}
+
+// Restore undeletes a row together with the dependents that were deleted with it
+func (s *TrashService) Restore(entity string, id uint) error {
+	if err := s.validate(entity); err != nil {
+		return err
+	}
+	return s.repo.RestoreTrash(entity, id)
+}
+
+// Purge permanently deletes a row from the trash. Files are removed only here,
+// never on soft delete, so restored models keep working. Stored media is deleted once
+// no other row references it; legacy model files outside the store are removed directly.
+func (s *TrashService) Purge(entity string, id uint) error {
+	if err := s.validate(entity); err != nil {
+		return err
+	}
+
+	var files []string
+	if entity == domain.TrashEntityModel {
+		model, err := s.repo.GetDeletedModel(id)
+		if err != nil {
+			return err
+		}
+		for _, f := range []string{model.Path, model.Image, model.MiniBlurPath} {
+			if _, stored := domain.MediaIDFromURL(f); !stored {
+				files = append(files, f)
+			}
+		}
+	}
+
+	if err := s.repo.PurgeTrash(entity, id); err != nil {
+		return err
+	}
+	if _, err := s.media.Sweep(); err != nil {
+		log.Printf("Trash: failed to sweep released media: %v", err)
+	}
+
+	for _, f := range files {
+		source, ok := legacyMediaPath(f)
+		if !ok {
+			continue
+		}
+		if err := s.media.Blobs().Delete(blobKey(source)); err != nil {
+			log.Printf("Trash: failed to remove %s: %v", f, err)
+		}
+	}
+	return nil
+}
+
+// PurgeExpired purges every row deleted more than retentionDays ago and returns how many were removed
+func (s *TrashService) PurgeExpired() (int, error) {
+	if s.retentionDays <= 0 {
+		return 0, nil
+	}
+	cutoff := time.Now().AddDate(0, 0, -s.retentionDays)
+
+	purged := 0
+	for _, entity := range domain.TrashEntities {
+		ids, err := s.repo.GetTrashIDsBefore(entity, cutoff)
+		if err != nil {
+			return purged, err
+		}
+		for _, id := range ids {
+			// Episodes may already be gone with their anime
+			err := s.Purge(entity, id)
+			if err == domain.ErrNotInTrash {
+				continue
+			}
+			if err != nil {
+				return purged, err
+			}
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// RunRetention purges expired trash every interval until the process exits
+func (s *TrashService) RunRetention(interval time.Duration) {
+	if s.retentionDays <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if n, err := s.PurgeExpired(); err != nil {
+			log.Printf("Trash retention: %v", err)
+		} else if n > 0 {
+			log.Printf("Trash retention: purged %d items older than %d days", n, s.retentionDays)
+		}
+		<-ticker.C
+	}
+}
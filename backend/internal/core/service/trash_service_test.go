@@ -0,0 +1,158 @@
+package service
+
+import (
+	"backend/internal/adapters/repository"
+	"backend/internal/adapters/storage"
+	"backend/internal/core/domain"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTrashTestService(t *testing.T, retentionDays int) (*TrashService, *repository.SQLiteRepository) {
+	t.Helper()
+	repo := newProgressTestRepo(t)
+	media := NewMediaService(repo, storage.NewLocalStore(t.TempDir()), 0)
+	return NewTrashService(repo, media, retentionDays), repo
+}
+
+// trash soft-deletes rows of model at deletedAt
+func trash(t *testing.T, repo *repository.SQLiteRepository, model interface{}, deletedAt time.Time, query string, args ...interface{}) {
+	t.Helper()
+	if err := repo.DB().Model(model).Where(query, args...).UpdateColumn("deleted_at", deletedAt).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func liveCount(t *testing.T, repo *repository.SQLiteRepository, model interface{}, query string, args ...interface{}) int64 {
+	t.Helper()
+	var n int64
+	if err := repo.DB().Model(model).Where(query, args...).Count(&n).Error; err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestTrashRestoreBringsBackDependentsDeletedWithTheParent(t *testing.T) {
+	s, repo := newTrashTestService(t, 30)
+	animeID, episodes := seedAnime(t, repo, domain.ContentStatePublished, 2)
+	for _, id := range episodes {
+		if err := repo.DB().Create(&domain.EpisodeServer{EpisodeID: id, Name: "Main", URL: "https://example.com/v.mp4"}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deletedAt := time.Now().Add(-time.Hour)
+	// Episode 1 was trashed on its own before the anime went
+	trash(t, repo, &domain.Episode{}, deletedAt.Add(-time.Hour), "id = ?", episodes[0])
+	trash(t, repo, &domain.EpisodeServer{}, deletedAt.Add(-time.Hour), "episode_id = ?", episodes[0])
+	trash(t, repo, &domain.Anime{}, deletedAt, "id = ?", animeID)
+	trash(t, repo, &domain.Episode{}, deletedAt, "id = ?", episodes[1])
+	trash(t, repo, &domain.EpisodeServer{}, deletedAt, "episode_id = ?", episodes[1])
+
+	if err := s.Restore(domain.TrashEntityEpisode, episodes[1]); !errors.Is(err, domain.ErrParentInTrash) {
+		t.Fatalf("restoring an episode of a trashed anime = %v, want ErrParentInTrash", err)
+	}
+	if err := s.Restore(domain.TrashEntityAnime, animeID); err != nil {
+		t.Fatal(err)
+	}
+	if n := liveCount(t, repo, &domain.Episode{}, "anime_id = ?", animeID); n != 1 {
+		t.Fatalf("%d live episodes after restore, want only the one deleted with the anime", n)
+	}
+	if n := liveCount(t, repo, &domain.EpisodeServer{}, "episode_id = ?", episodes[1]); n != 1 {
+		t.Fatalf("%d live servers on the restored episode, want 1", n)
+	}
+	if n := liveCount(t, repo, &domain.EpisodeServer{}, "episode_id = ?", episodes[0]); n != 0 {
+		t.Fatal("server of the earlier deleted episode was restored")
+	}
+
+	if err := s.Restore(domain.TrashEntityAnime, animeID); !errors.Is(err, domain.ErrNotInTrash) {
+		t.Fatalf("restoring a live anime = %v, want ErrNotInTrash", err)
+	}
+	if err := s.Restore("comment", 1); !errors.Is(err, ErrUnknownTrashEntity) {
+		t.Fatalf("restoring an unknown entity = %v, want ErrUnknownTrashEntity", err)
+	}
+}
+
+func TestTrashPurgeCascades(t *testing.T) {
+	s, repo := newTrashTestService(t, 30)
+	animeID, episodes := seedAnime(t, repo, domain.ContentStatePublished, 1)
+	episodeID := episodes[0]
+	comment := domain.Comment{UserID: 2, EpisodeID: episodeID, Content: "nice"}
+	rows := []interface{}{
+		&domain.EpisodeServer{EpisodeID: episodeID, Name: "Main", URL: "https://example.com/v.mp4"},
+		&comment,
+		&domain.History{UserID: 2, ActivityType: domain.ActivityEpisodeView, EpisodeID: &episodeID, AnimeID: &animeID},
+		&domain.History{UserID: 2, ActivityType: domain.ActivityAnimeView, AnimeID: &animeID},
+		&domain.WatchProgress{UserID: 2, EpisodeID: episodeID, AnimeID: animeID, Position: 10, Duration: 100},
+		&domain.AnimeListEntry{UserID: 2, AnimeID: animeID, Status: domain.ListStatusWatching},
+	}
+	for _, row := range rows {
+		if err := repo.DB().Create(row).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := s.Purge(domain.TrashEntityAnime, animeID); !errors.Is(err, domain.ErrNotInTrash) {
+		t.Fatalf("purging a live anime = %v, want ErrNotInTrash", err)
+	}
+	trash(t, repo, &domain.Anime{}, time.Now(), "id = ?", animeID)
+	trash(t, repo, &domain.Episode{}, time.Now(), "anime_id = ?", animeID)
+	if err := s.Purge(domain.TrashEntityAnime, animeID); err != nil {
+		t.Fatal(err)
+	}
+
+	checks := []struct {
+		model interface{}
+		query string
+		arg   uint
+	}{
+		{&domain.Anime{}, "id = ?", animeID},
+		{&domain.Episode{}, "id = ?", episodeID},
+		{&domain.EpisodeServer{}, "episode_id = ?", episodeID},
+		{&domain.Comment{}, "id = ?", comment.ID},
+		{&domain.History{}, "user_id = ?", 2},
+		{&domain.WatchProgress{}, "user_id = ?", 2},
+		{&domain.AnimeListEntry{}, "user_id = ?", 2},
+	}
+	for _, c := range checks {
+		var n int64
+		if err := repo.DB().Unscoped().Model(c.model).Where(c.query, c.arg).Count(&n).Error; err != nil {
+			t.Fatal(err)
+		}
+		if n != 0 {
+			t.Errorf("%d %T rows left after purge", n, c.model)
+		}
+	}
+}
+
+func TestTrashPurgeExpired(t *testing.T) {
+	s, repo := newTrashTestService(t, 30)
+	oldID, _ := seedAnime(t, repo, domain.ContentStatePublished, 1)
+	recentID, _ := seedAnime(t, repo, domain.ContentStatePublished, 1)
+	trash(t, repo, &domain.Anime{}, time.Now().AddDate(0, 0, -31), "id = ?", oldID)
+	trash(t, repo, &domain.Episode{}, time.Now().AddDate(0, 0, -31), "anime_id = ?", oldID)
+	trash(t, repo, &domain.Anime{}, time.Now().AddDate(0, 0, -29), "id = ?", recentID)
+
+	items, total, err := s.List(domain.TrashEntityAnime, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 2 || items[0].ID != recentID || items[0].PurgeAt == nil {
+		t.Fatalf("trash listing = %+v (total %d), want the recent anime first with a purge date", items, total)
+	}
+
+	// The episode goes with its anime and is not counted twice
+	n, err := s.PurgeExpired()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Fatalf("PurgeExpired purged %d rows, want 1", n)
+	}
+	var ids []uint
+	repo.DB().Unscoped().Model(&domain.Anime{}).Pluck("id", &ids)
+	if len(ids) != 1 || ids[0] != recentID {
+		t.Fatalf("anime left after retention = %v, want only %d", ids, recentID)
+	}
+}
@@ -0,0 +1,478 @@
+package service
+
+import (
+	"backend/internal/core/domain"
+	"backend/internal/core/port"
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TusChecksumAlgorithms are the Upload-Checksum algorithms accepted by the checksum extension
+var TusChecksumAlgorithms = []string{"md5", "sha1", "sha256"}
+
+// TusTarget is a pipeline completed uploads are handed to. Validate checks the
+// Upload-Metadata when the upload is created, so a bad file type is refused before
+// any bytes are sent. Finalize receives the completed file at path and returns what
+// it created; it may move the file, and whatever remains at path is removed.
+type TusTarget struct {
+	Validate func(metadata map[string]string) error
+	Finalize func(upload *domain.TusUpload, path string) (any, error)
+}
+
+// TusService keeps resumable uploads in the blob store, so the chunks of one
+// upload may reach different replicas: tus/<id>.info holds the JSON state and
+// every chunk is stored as tus/<id>/<offset>. The chunks are joined into a
+// local file for the target pipeline once the last one arrives.
+type TusService struct {
+	blobs   port.BlobStore
+	maxSize int64
+	expiry  time.Duration
+	targets map[domain.UploadTarget]TusTarget
+
+	mu    sync.Mutex
+	locks map[string]*uploadLock // Serialises chunks of one upload on this replica
+}
+
+// uploadLock is dropped from TusService.locks once nobody holds or waits for it
+type uploadLock struct {
+	sync.Mutex
+	holders int
+}
+
+// TusBlobPrefix is where uploads live in the blob store. The /uploads route
+// never serves it and the garbage collector leaves it alone.
+const TusBlobPrefix = "tus/"
+
+func NewTusService(blobs port.BlobStore, maxSize int64, expiry time.Duration) *TusService {
+	return &TusService{
+		blobs:   blobs,
+		maxSize: maxSize,
+		expiry:  expiry,
+		targets: map[domain.UploadTarget]TusTarget{},
+		locks:   map[string]*uploadLock{},
+	}
+}
+
+// RegisterTarget sets the pipeline uploads whose "target" metadata is name are handed to
+func (s *TusService) RegisterTarget(name domain.UploadTarget, target TusTarget) {
+	s.targets[name] = target
+}
+
+// MaxSize is the largest accepted Upload-Length in bytes
+func (s *TusService) MaxSize() int64 {
+	return s.maxSize
+}
+
+// Create starts an upload of length bytes. metadata must name a registered target.
+func (s *TusService) Create(userID uint, length int64, metadata map[string]string) (*domain.TusUpload, error) {
+	if length < 0 {
+		return nil, fmt.Errorf("%w: Upload-Length must not be negative", domain.ErrInvalidUpload)
+	}
+	if length > s.maxSize {
+		return nil, domain.ErrUploadTooLarge
+	}
+	target := domain.UploadTarget(metadata["target"])
+	pipeline, ok := s.targets[target]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown target %q", domain.ErrInvalidUpload, target)
+	}
+	if pipeline.Validate != nil {
+		if err := pipeline.Validate(metadata); err != nil {
+			return nil, fmt.Errorf("%w: %v", domain.ErrInvalidUpload, err)
+		}
+	}
+
+	now := time.Now()
+	upload := &domain.TusUpload{
+		ID:        strings.ReplaceAll(uuid.New().String(), "-", ""),
+		UserID:    userID,
+		Target:    target,
+		Length:    length,
+		Metadata:  metadata,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.expiry),
+	}
+	if err := s.save(upload); err != nil {
+		return nil, err
+	}
+	if length == 0 {
+		return upload, s.finalize(upload)
+	}
+	return upload, nil
+}
+
+// Get returns the state of one of the user's uploads
+func (s *TusService) Get(id string, userID uint) (*domain.TusUpload, error) {
+	upload, err := s.load(id)
+	if err != nil {
+		return nil, err
+	}
+	if upload.UserID != userID {
+		return nil, domain.ErrUploadNotFound
+	}
+	if upload.CompletedAt == nil && time.Now().After(upload.ExpiresAt) {
+		return nil, domain.ErrUploadExpired
+	}
+	return upload, nil
+}
+
+// Write appends a chunk that must start at the upload's current offset. With a
+// checksum ("<algorithm> <base64 digest>") a mismatching chunk is discarded;
+// without one, the bytes received before a dropped connection are kept so the
+// client can resume from there. The last chunk triggers the target pipeline.
+func (s *TusService) Write(id string, userID uint, offset int64, body io.Reader, checksum string) (*domain.TusUpload, error) {
+	if !isUploadID(id) {
+		return nil, domain.ErrUploadNotFound
+	}
+	defer s.lock(id)()
+
+	upload, err := s.Get(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if offset != upload.Offset || upload.CompletedAt != nil {
+		return nil, domain.ErrUploadOffsetMismatch
+	}
+
+	var digest hash.Hash
+	var want []byte
+	if checksum != "" {
+		if digest, want, err = parseChecksum(checksum); err != nil {
+			return nil, err
+		}
+	}
+
+	// The chunk is spooled locally first: the blob store needs its size, and a
+	// chunk failing its checksum must never be stored
+	tmp, err := os.CreateTemp("", "tus-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+	var w io.Writer = tmp
+	if digest != nil {
+		w = io.MultiWriter(tmp, digest)
+	}
+	n, copyErr := io.Copy(w, io.LimitReader(body, upload.Length-offset))
+	if digest != nil && (copyErr != nil || string(digest.Sum(nil)) != string(want)) {
+		if copyErr != nil {
+			return nil, copyErr
+		}
+		return nil, domain.ErrChecksumMismatch
+	}
+
+	if n > 0 {
+		if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		if err := s.blobs.Put(tusPartKey(id, offset), tmp, n, "application/octet-stream"); err != nil {
+			return nil, err
+		}
+	}
+	upload.Offset += n
+	upload.ExpiresAt = time.Now().Add(s.expiry)
+	if err := s.save(upload); err != nil {
+		return nil, err
+	}
+	if copyErr != nil {
+		return nil, copyErr
+	}
+	if upload.Complete() {
+		return upload, s.finalize(upload)
+	}
+	return upload, nil
+}
+
+// Terminate discards an upload and its data
+func (s *TusService) Terminate(id string, userID uint) error {
+	if !isUploadID(id) {
+		return domain.ErrUploadNotFound
+	}
+	defer s.lock(id)()
+
+	upload, err := s.load(id)
+	if err != nil {
+		return err
+	}
+	if upload.UserID != userID {
+		return domain.ErrUploadNotFound
+	}
+	s.remove(id)
+	return nil
+}
+
+// RunExpiry removes expired uploads every interval until the process exits
+func (s *TusService) RunExpiry(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if n, err := s.PurgeExpired(); err != nil {
+			log.Printf("Uploads: expiry failed: %v", err)
+		} else if n > 0 {
+			log.Printf("Uploads: removed %d expired uploads", n)
+		}
+		<-ticker.C
+	}
+}
+
+// PurgeExpired removes every upload past its expiry, completed ones included, and
+// returns how many were removed
+func (s *TusService) PurgeExpired() (int, error) {
+	var ids []string
+	err := s.blobs.List(TusBlobPrefix, func(info domain.BlobInfo) error {
+		if id, ok := strings.CutSuffix(strings.TrimPrefix(info.Key, TusBlobPrefix), ".info"); ok && isUploadID(id) {
+			ids = append(ids, id)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	removed := 0
+	for _, id := range ids {
+		upload, err := s.load(id)
+		if err != nil && !errors.Is(err, domain.ErrUploadNotFound) {
+			log.Printf("Uploads: %s: %v", id, err)
+			continue
+		}
+		if upload != nil && now.Before(upload.ExpiresAt) {
+			continue
+		}
+		unlock := s.lock(id)
+		s.remove(id)
+		unlock()
+		removed++
+	}
+	return removed, nil
+}
+
+// finalize joins the chunks, hands the file to the target pipeline and records
+// the outcome. The state is kept until expiry so clients can fetch the result.
+// When the chunks cannot be read the upload stays incomplete, and an empty
+// chunk at the final offset retries.
+func (s *TusService) finalize(upload *domain.TusUpload) error {
+	path, err := s.assemble(upload)
+	if err != nil {
+		return err
+	}
+	result, err := s.targets[upload.Target].Finalize(upload, path)
+	now := time.Now()
+	upload.CompletedAt = &now
+	upload.ExpiresAt = now.Add(s.expiry)
+	upload.Result = result
+	if err != nil {
+		upload.Error = err.Error()
+	}
+	if rmErr := os.Remove(path); rmErr != nil && !errors.Is(rmErr, os.ErrNotExist) {
+		log.Printf("Uploads: failed to remove %s: %v", path, rmErr)
+	}
+	s.removeParts(upload.ID)
+	if saveErr := s.save(upload); saveErr != nil {
+		return saveErr
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrUploadFinalize, err)
+	}
+	return nil
+}
+
+// assemble copies the chunks of a complete upload, in order, into a local file
+// and returns its path
+func (s *TusService) assemble(upload *domain.TusUpload) (string, error) {
+	_, parts, err := s.received(upload.ID)
+	if err != nil {
+		return "", err
+	}
+	f, err := os.CreateTemp("", "tus-*")
+	if err != nil {
+		return "", err
+	}
+	err = func() error {
+		for _, part := range parts {
+			r, err := s.blobs.Get(part.Key)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(f, r)
+			r.Close()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}()
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// received returns how many bytes of an upload are stored and the chunks
+// holding them. Only chunks continuing the previous one count, so a chunk left
+// behind by a failed save is overwritten by the retry.
+func (s *TusService) received(id string) (int64, []domain.BlobInfo, error) {
+	var parts []domain.BlobInfo
+	err := s.blobs.List(TusBlobPrefix+id+"/", func(info domain.BlobInfo) error {
+		parts = append(parts, info)
+		return nil
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	sort.Slice(parts, func(i, j int) bool { return parts[i].Key < parts[j].Key })
+	var offset int64
+	for i, part := range parts {
+		if part.Key != tusPartKey(id, offset) {
+			return offset, parts[:i], nil
+		}
+		offset += part.Size
+	}
+	return offset, parts, nil
+}
+
+// load reads an upload's state. The stored chunks are authoritative for the
+// offset, since a crash may land between storing a chunk and saving the state.
+func (s *TusService) load(id string) (*domain.TusUpload, error) {
+	if !isUploadID(id) {
+		return nil, domain.ErrUploadNotFound
+	}
+	r, err := s.blobs.Get(tusInfoKey(id))
+	if errors.Is(err, domain.ErrBlobNotFound) {
+		return nil, domain.ErrUploadNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	var upload domain.TusUpload
+	if err := json.NewDecoder(r).Decode(&upload); err != nil {
+		return nil, err
+	}
+	if upload.CompletedAt == nil {
+		if upload.Offset, _, err = s.received(id); err != nil {
+			return nil, err
+		}
+	}
+	return &upload, nil
+}
+
+// save writes the state; blob stores replace objects atomically
+func (s *TusService) save(upload *domain.TusUpload) error {
+	data, err := json.Marshal(upload)
+	if err != nil {
+		return err
+	}
+	return s.blobs.Put(tusInfoKey(upload.ID), bytes.NewReader(data), int64(len(data)), "application/json")
+}
+
+func (s *TusService) remove(id string) {
+	s.removeParts(id)
+	if err := s.blobs.Delete(tusInfoKey(id)); err != nil {
+		log.Printf("Uploads: failed to remove %s: %v", tusInfoKey(id), err)
+	}
+}
+
+func (s *TusService) removeParts(id string) {
+	var keys []string
+	err := s.blobs.List(TusBlobPrefix+id+"/", func(info domain.BlobInfo) error {
+		keys = append(keys, info.Key)
+		return nil
+	})
+	if err != nil {
+		log.Printf("Uploads: failed to list the chunks of %s: %v", id, err)
+	}
+	for _, key := range keys {
+		if err := s.blobs.Delete(key); err != nil {
+			log.Printf("Uploads: failed to remove %s: %v", key, err)
+		}
+	}
+}
+
+// lock takes the lock of an upload and returns the function releasing it. The
+// entry is removed with its last holder, so requests for uploads that fail
+// validation or belong to someone else leave nothing behind.
+func (s *TusService) lock(id string) func() {
+	s.mu.Lock()
+	lock, ok := s.locks[id]
+	if !ok {
+		lock = &uploadLock{}
+		s.locks[id] = lock
+	}
+	lock.holders++
+	s.mu.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+		s.mu.Lock()
+		if lock.holders--; lock.holders == 0 {
+			delete(s.locks, id)
+		}
+		s.mu.Unlock()
+	}
+}
+
+func tusInfoKey(id string) string {
+	return TusBlobPrefix + id + ".info"
+}
+
+// tusPartKey names a chunk by its zero-padded offset, so chunks list in order
+func tusPartKey(id string, offset int64) string {
+	return fmt.Sprintf("%s%s/%020d", TusBlobPrefix, id, offset)
+}
+
+// isUploadID keeps ids to the 32 hex characters Create generates, so they are safe in paths
+func isUploadID(id string) bool {
+	if len(id) != 32 {
+		return false
+	}
+	for _, r := range id {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// parseChecksum parses an Upload-Checksum header value
+func parseChecksum(header string) (hash.Hash, []byte, error) {
+	algorithm, encoded, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: malformed Upload-Checksum", domain.ErrInvalidUpload)
+	}
+	want, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: Upload-Checksum digest is not base64", domain.ErrInvalidUpload)
+	}
+	switch strings.ToLower(algorithm) {
+	case "md5":
+		return md5.New(), want, nil
+	case "sha1":
+		return sha1.New(), want, nil
+	case "sha256":
+		return sha256.New(), want, nil
+	}
+	return nil, nil, fmt.Errorf("%w: unsupported checksum algorithm %q", domain.ErrInvalidUpload, algorithm)
+}
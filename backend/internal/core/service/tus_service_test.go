@@ -0,0 +1,312 @@
+package service
+
+import (
+	"backend/internal/adapters/storage"
+	"backend/internal/core/domain"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+// newTusTestService returns a service over a local blob store whose "image"
+// target records the contents of every finalized upload
+func newTusTestService(t *testing.T, maxSize int64) (*TusService, map[string]string) {
+	t.Helper()
+	s := NewTusService(storage.NewLocalStore(t.TempDir()), maxSize, time.Hour)
+	finalized := map[string]string{}
+	s.RegisterTarget(domain.UploadTargetImage, TusTarget{
+		Finalize: func(upload *domain.TusUpload, path string) (any, error) {
+			data, err := os.ReadFile(path)
+			finalized[upload.ID] = string(data)
+			return len(data), err
+		},
+	})
+	return s, finalized
+}
+
+func tusChecksum(algorithm, data string) string {
+	var sum []byte
+	switch algorithm {
+	case "md5":
+		s := md5.Sum([]byte(data))
+		sum = s[:]
+	case "sha1":
+		s := sha1.Sum([]byte(data))
+		sum = s[:]
+	case "sha256":
+		s := sha256.Sum256([]byte(data))
+		sum = s[:]
+	}
+	return algorithm + " " + base64.StdEncoding.EncodeToString(sum)
+}
+
+func TestTusWrite(t *testing.T) {
+	type chunk struct {
+		offset   int64
+		data     string
+		checksum string
+		err      error
+		want     int64 // Offset after the chunk
+	}
+	tests := []struct {
+		name   string
+		length int64
+		chunks []chunk
+		result string // Finalized contents; empty while incomplete
+	}{
+		{
+			name:   "single chunk",
+			length: 5,
+			chunks: []chunk{{0, "hello", "", nil, 5}},
+			result: "hello",
+		},
+		{
+			name:   "chunks with every checksum algorithm",
+			length: 9,
+			chunks: []chunk{
+				{0, "abc", tusChecksum("md5", "abc"), nil, 3},
+				{3, "def", tusChecksum("sha1", "def"), nil, 6},
+				{6, "ghi", tusChecksum("sha256", "ghi"), nil, 9},
+			},
+			result: "abcdefghi",
+		},
+		{
+			name:   "mismatching checksum is discarded and retried",
+			length: 6,
+			chunks: []chunk{
+				{0, "abc", "", nil, 3},
+				{3, "dXf", tusChecksum("sha1", "def"), domain.ErrChecksumMismatch, 3},
+				{3, "def", tusChecksum("sha1", "def"), nil, 6},
+			},
+			result: "abcdef",
+		},
+		{
+			name:   "wrong offset",
+			length: 6,
+			chunks: []chunk{
+				{0, "abc", "", nil, 3},
+				{0, "abc", "", domain.ErrUploadOffsetMismatch, 3},
+				{4, "ef", "", domain.ErrUploadOffsetMismatch, 3},
+			},
+		},
+		{
+			name:   "bytes past the length are ignored",
+			length: 4,
+			chunks: []chunk{{0, "abcdefgh", "", nil, 4}},
+			result: "abcd",
+		},
+		{
+			name:   "empty chunk keeps the offset",
+			length: 4,
+			chunks: []chunk{{0, "ab", "", nil, 2}, {2, "", "", nil, 2}},
+		},
+		{
+			name:   "completed upload takes no more chunks",
+			length: 2,
+			chunks: []chunk{{0, "ab", "", nil, 2}, {2, "", "", domain.ErrUploadOffsetMismatch, 2}},
+			result: "ab",
+		},
+		{
+			name:   "malformed checksums",
+			length: 4,
+			chunks: []chunk{
+				{0, "ab", "md5", domain.ErrInvalidUpload, 0},
+				{0, "ab", "md5 !!!", domain.ErrInvalidUpload, 0},
+				{0, "ab", "crc32 AAAA", domain.ErrInvalidUpload, 0},
+				{0, "ab", "SHA256 " + strings.TrimPrefix(tusChecksum("sha256", "ab"), "sha256 "), nil, 2},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, finalized := newTusTestService(t, 100)
+			upload, err := s.Create(1, tt.length, map[string]string{"target": string(domain.UploadTargetImage)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, c := range tt.chunks {
+				_, err := s.Write(upload.ID, 1, c.offset, strings.NewReader(c.data), c.checksum)
+				if !errors.Is(err, c.err) {
+					t.Fatalf("chunk %d: err = %v, want %v", i, err, c.err)
+				}
+				state, err := s.Get(upload.ID, 1)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if state.Offset != c.want {
+					t.Fatalf("chunk %d: offset = %d, want %d", i, state.Offset, c.want)
+				}
+			}
+			if got := finalized[upload.ID]; got != tt.result {
+				t.Errorf("finalized %q, want %q", got, tt.result)
+			}
+		})
+	}
+}
+
+func TestTusWriteKeepsBytesOfDroppedChunk(t *testing.T) {
+	s, finalized := newTusTestService(t, 100)
+	upload, err := s.Create(1, 6, map[string]string{"target": string(domain.UploadTargetImage)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A connection dropped after 2 bytes keeps them without a checksum...
+	dropped := io.MultiReader(strings.NewReader("ab"), iotest.ErrReader(io.ErrUnexpectedEOF))
+	if _, err := s.Write(upload.ID, 1, 0, dropped, ""); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("err = %v, want the read error", err)
+	}
+	// ...and discards them with one
+	dropped = io.MultiReader(strings.NewReader("cd"), iotest.ErrReader(io.ErrUnexpectedEOF))
+	if _, err := s.Write(upload.ID, 1, 2, dropped, tusChecksum("md5", "cdef")); err == nil {
+		t.Fatal("checksummed chunk with a read error was accepted")
+	}
+	if state, _ := s.Get(upload.ID, 1); state.Offset != 2 {
+		t.Fatalf("offset = %d, want 2", state.Offset)
+	}
+	if _, err := s.Write(upload.ID, 1, 2, strings.NewReader("cdef"), tusChecksum("md5", "cdef")); err != nil {
+		t.Fatal(err)
+	}
+	if finalized[upload.ID] != "abcdef" {
+		t.Errorf("finalized %q", finalized[upload.ID])
+	}
+}
+
+func TestTusOffsetComesFromStoredChunks(t *testing.T) {
+	s, _ := newTusTestService(t, 100)
+	upload, err := s.Create(1, 10, map[string]string{"target": string(domain.UploadTargetImage)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Write(upload.ID, 1, 0, strings.NewReader("abc"), ""); err != nil {
+		t.Fatal(err)
+	}
+	// A replica that stored a chunk but crashed before saving the state
+	if err := s.blobs.Put(tusPartKey(upload.ID, 3), strings.NewReader("de"), 2, "application/octet-stream"); err != nil {
+		t.Fatal(err)
+	}
+	// A chunk that does not continue the stored ones does not count
+	if err := s.blobs.Put(tusPartKey(upload.ID, 7), strings.NewReader("hi"), 2, "application/octet-stream"); err != nil {
+		t.Fatal(err)
+	}
+	state, err := s.Get(upload.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Offset != 5 {
+		t.Errorf("offset = %d, want 5", state.Offset)
+	}
+}
+
+func TestTusCreateAndAccess(t *testing.T) {
+	s, finalized := newTusTestService(t, 10)
+	image := map[string]string{"target": string(domain.UploadTargetImage)}
+	tests := []struct {
+		name     string
+		length   int64
+		metadata map[string]string
+		err      error
+	}{
+		{"ok", 10, image, nil},
+		{"too large", 11, image, domain.ErrUploadTooLarge},
+		{"negative", -1, image, domain.ErrInvalidUpload},
+		{"unknown target", 1, map[string]string{"target": "model"}, domain.ErrInvalidUpload},
+	}
+	for _, tt := range tests {
+		if _, err := s.Create(1, tt.length, tt.metadata); !errors.Is(err, tt.err) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.err)
+		}
+	}
+
+	empty, err := s.Create(1, 0, image)
+	if err != nil || empty.CompletedAt == nil {
+		t.Fatalf("empty upload: %+v, %v", empty, err)
+	}
+	if _, ok := finalized[empty.ID]; !ok {
+		t.Error("empty upload was not finalized")
+	}
+
+	upload, err := s.Create(1, 4, image)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"../etc/passwd", "ABC", upload.ID + "0"} {
+		if _, err := s.Get(id, 1); !errors.Is(err, domain.ErrUploadNotFound) {
+			t.Errorf("Get(%q): err = %v, want not found", id, err)
+		}
+	}
+	if _, err := s.Get(upload.ID, 2); !errors.Is(err, domain.ErrUploadNotFound) {
+		t.Errorf("other user: err = %v, want not found", err)
+	}
+	if err := s.Terminate(upload.ID, 2); !errors.Is(err, domain.ErrUploadNotFound) {
+		t.Errorf("other user terminated: %v", err)
+	}
+	if _, err := s.Write(upload.ID, 1, 0, strings.NewReader("ab"), ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Terminate(upload.ID, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get(upload.ID, 1); !errors.Is(err, domain.ErrUploadNotFound) {
+		t.Errorf("terminated upload: err = %v, want not found", err)
+	}
+	if _, err := s.blobs.Get(tusPartKey(upload.ID, 0)); !errors.Is(err, domain.ErrBlobNotFound) {
+		t.Errorf("terminated upload kept its chunk: %v", err)
+	}
+}
+
+func TestTusPurgeExpired(t *testing.T) {
+	s, _ := newTusTestService(t, 10)
+	image := map[string]string{"target": string(domain.UploadTargetImage)}
+	live, err := s.Create(1, 4, image)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.expiry = -time.Minute
+	expired, err := s.Create(1, 4, image)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Write(expired.ID, 1, 0, strings.NewReader("ab"), ""); !errors.Is(err, domain.ErrUploadExpired) {
+		t.Errorf("write to expired upload: err = %v, want expired", err)
+	}
+
+	n, err := s.PurgeExpired()
+	if err != nil || n != 1 {
+		t.Fatalf("PurgeExpired = %d, %v; want 1", n, err)
+	}
+	if _, err := s.load(expired.ID); !errors.Is(err, domain.ErrUploadNotFound) {
+		t.Errorf("expired upload: err = %v, want not found", err)
+	}
+	if _, err := s.load(live.ID); err != nil {
+		t.Errorf("live upload was purged: %v", err)
+	}
+}
+
+func TestTusLocksDoNotOutliveRequests(t *testing.T) {
+	s, _ := newTusTestService(t, 10)
+	upload, err := s.Create(1, 4, map[string]string{"target": string(domain.UploadTargetImage)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Rejected ids, other users' uploads and finished requests all leave the map empty
+	s.Write("../etc/passwd", 1, 0, strings.NewReader("ab"), "")
+	s.Write(strings.Repeat("f", 32), 1, 0, strings.NewReader("ab"), "")
+	s.Write(upload.ID, 2, 0, strings.NewReader("ab"), "")
+	s.Terminate(strings.Repeat("e", 32), 1)
+	if _, err := s.Write(upload.ID, 1, 0, strings.NewReader("ab"), ""); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(s.locks); n != 0 {
+		t.Fatalf("%d upload locks left after the requests finished", n)
+	}
+}
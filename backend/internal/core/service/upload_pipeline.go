@@ -0,0 +1,89 @@
+package service
+
+import (
+	"backend/internal/core/domain"
+	"backend/internal/core/port"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	imageExtensions = map[string]bool{".jpg": true, ".jpeg": true, ".png": true, ".webp": true, ".gif": true}
+	videoExtensions = map[string]bool{".mp4": true, ".webm": true, ".mkv": true, ".mov": true, ".m4v": true}
+)
+
+// VideoUploadTarget stores completed video uploads under videos/ in blobs
+func VideoUploadTarget(blobs port.BlobStore) TusTarget {
+	return fileUploadTarget(blobs, "videos", videoExtensions)
+}
+
+func fileUploadTarget(blobs port.BlobStore, dir string, extensions map[string]bool) TusTarget {
+	return TusTarget{
+		Validate: func(metadata map[string]string) error {
+			return checkExtension(metadata["filename"], extensions)
+		},
+		Finalize: func(upload *domain.TusUpload, path string) (any, error) {
+			url, err := storeUpload(blobs, upload, path, dir, extensions)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]string{"url": url}, nil
+		},
+	}
+}
+
+func checkExtension(filename string, extensions map[string]bool) error {
+	if ext := strings.ToLower(filepath.Ext(filename)); !extensions[ext] {
+		return fmt.Errorf("file type %q is not allowed", ext)
+	}
+	return nil
+}
+
+// storeUpload moves a completed upload into dir under a unique name and returns its URL
+func storeUpload(blobs port.BlobStore, upload *domain.TusUpload, path, dir string, extensions map[string]bool) (string, error) {
+	if err := checkExtension(upload.Metadata["filename"], extensions); err != nil {
+		return "", err
+	}
+	ext := strings.ToLower(filepath.Ext(upload.Metadata["filename"]))
+	key := fmt.Sprintf("%s/%d_%s%s", dir, time.Now().Unix(), uuid.New().String(), ext)
+	if err := putFile(blobs, path, key); err != nil {
+		return "", err
+	}
+	return blobURL(key), nil
+}
+
+// blobKey maps a path under uploads/, as kept on rows, to its key in the blob store
+func blobKey(path string) string {
+	return strings.TrimPrefix(filepath.ToSlash(path), "uploads/")
+}
+
+// blobURL is the URL rows store for a blob; the /uploads route resolves it
+// against the configured backend
+func blobURL(key string) string {
+	return "/uploads/" + key
+}
+
+// putFile moves a local file into the blob store under key
+func putFile(blobs port.BlobStore, path, key string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	err = blobs.Put(key, f, stat.Size(), mime.TypeByExtension(filepath.Ext(key)))
+	f.Close()
+	if err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
@@ -11,10 +11,16 @@ import (
 type UserService struct {
 	repo     port.UserRepository
 	roleRepo port.RoleRepository
+	media    *MediaService
 }
 
-func NewUserService(repo port.UserRepository, roleRepo port.RoleRepository) *UserService {
-	return &UserService{repo: repo, roleRepo: roleRepo}
+func NewUserService(repo port.UserRepository, roleRepo port.RoleRepository, media *MediaService) *UserService {
+	return &UserService{repo: repo, roleRepo: roleRepo, media: media}
+}
+
+// trackAvatar records the stored avatar file, releasing a replaced one
+func (s *UserService) trackAvatar(user *domain.User) {
+	s.media.track(domain.MediaOwnerUser, user.ID, map[string]string{"avatar": user.Avatar})
 }
 
 func (s *UserService) Create(name, email, password string, roleID uint, avatarPath string) error {
@@ -36,7 +42,11 @@ func (s *UserService) Create(name, email, password string, roleID uint, avatarPa
 		Avatar:   avatarPath,
 	}
 
-	return s.repo.CreateUser(user)
+	if err := s.repo.CreateUser(user); err != nil {
+		return err
+	}
+	s.trackAvatar(user)
+	return nil
 }
 
 func (s *UserService) GetAll() ([]domain.User, error) {
@@ -71,7 +81,11 @@ func (s *UserService) Update(id uint, name, email string, password string, roleI
 	// instead of trying to save the old Role association.
 	user.Role = domain.Role{}
 
-	return s.repo.UpdateUser(user)
+	if err := s.repo.UpdateUser(user); err != nil {
+		return err
+	}
+	s.trackAvatar(user)
+	return nil
 }
 
 func (s *UserService) Delete(id uint) error {
@@ -82,7 +96,7 @@ func (s *UserService) Search(query string) ([]domain.User, error) {
 	return s.repo.SearchUsers(query)
 }
 
-func (s *UserService) UpdateProfile(id uint, name, currentPassword, newPassword string, avatarPath string) (*domain.User, error) {
+func (s *UserService) UpdateProfile(id uint, name, username, currentPassword, newPassword string, avatarPath string) (*domain.User, error) {
 	user, err := s.repo.GetUserByID(id)
 	if err != nil {
 		return nil, err
@@ -90,6 +104,22 @@ func (s *UserService) UpdateProfile(id uint, name, currentPassword, newPassword
 
 	user.Name = name
 
+	// An empty username keeps the current handle
+	if username != "" {
+		username = domain.NormalizeUsername(username)
+		if !domain.ValidUsername(username) {
+			return nil, domain.ErrInvalidUsername
+		}
+		taken, err := s.repo.UsernameTaken(username, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if taken {
+			return nil, domain.ErrUsernameTaken
+		}
+		user.Username = &username
+	}
+
 	if avatarPath != "" {
 		user.Avatar = avatarPath
 	}
@@ -116,6 +146,7 @@ func (s *UserService) UpdateProfile(id uint, name, currentPassword, newPassword
 	if err := s.repo.UpdateUser(user); err != nil {
 		return nil, err
 	}
+	s.trackAvatar(user)
 
 	return user, nil
 }
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"backend/internal/core/domain"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const maxSourceHeaders = 10
+
+var (
+	qualityPattern     = regexp.MustCompile(`^(\d{3,4}p|[248]k|sd|hd|fhd|auto)$`)
+	headerNamePattern  = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+	forbiddenHeaderSet = map[string]bool{"host": true, "cookie": true, "authorization": true, "content-length": true}
+)
+
+// validateServerURL accepts absolute http(s) URLs only
+func validateServerURL(raw string) error {
+	if strings.TrimSpace(raw) == "" {
+		return errors.New("url is required")
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid url %q", raw)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url %q must be an absolute http(s) URL", raw)
+	}
+	return nil
+}
+
+// normalizeSources validates every server and fills the derived fields in place.
+// Servers already stored with the same URL in existing keep their embed host even
+// when it is no longer allowlisted, so unrelated edits don't fail on them.
+func normalizeSources(servers []domain.EpisodeServer, existing []domain.EpisodeServer, embedHosts []string) error {
+	known := knownSourceURLs(existing)
+	for i := range servers {
+		if err := normalizeSource(&servers[i], embedHosts, known); err != nil {
+			return fmt.Errorf("%w: servers[%d]: %v", domain.ErrInvalidSource, i, err)
+		}
+	}
+	return nil
+}
+
+// knownSourceURLs is the set of URLs of stored servers
+func knownSourceURLs(servers []domain.EpisodeServer) map[string]bool {
+	known := make(map[string]bool, len(servers))
+	for _, sv := range servers {
+		known[strings.TrimSpace(sv.URL)] = true
+	}
+	return known
+}
+
+// normalizeSource checks the URL scheme, the kind and, for embeds of URLs not in
+// known, the host allowlist. An empty Kind is inferred from the URL, then from the
+// legacy Type.
+func normalizeSource(sv *domain.EpisodeServer, embedHosts []string, known map[string]bool) error {
+	sv.URL = strings.TrimSpace(sv.URL)
+	if err := validateServerURL(sv.URL); err != nil {
+		return err
+	}
+	u, _ := url.Parse(sv.URL)
+	host := strings.ToLower(u.Hostname())
+
+	if sv.Kind == "" {
+		if kind, ok := domain.InferSourceKind(sv.URL); ok {
+			sv.Kind = kind
+		} else if domain.SourceKind(sv.Type).IsValid() {
+			sv.Kind = domain.SourceKind(sv.Type)
+		} else {
+			sv.Kind = domain.SourceKindEmbed
+		}
+	}
+	sv.Kind = domain.SourceKind(strings.ToLower(string(sv.Kind)))
+	if !sv.Kind.IsValid() {
+		return fmt.Errorf("unknown kind %q", sv.Kind)
+	}
+	if sv.Kind == domain.SourceKindEmbed && !known[sv.URL] && !hostAllowed(host, embedHosts) {
+		return fmt.Errorf("embed host %q is not allowed", host)
+	}
+	sv.Type = string(sv.Kind)
+
+	if sv.Language != "" {
+		language, err := domain.NormalizeLocale(sv.Language)
+		if err != nil {
+			return fmt.Errorf("invalid language %q", sv.Language)
+		}
+		sv.Language = language
+	}
+
+	sv.Quality = strings.ToLower(strings.TrimSpace(sv.Quality))
+	if sv.Quality != "" && !qualityPattern.MatchString(sv.Quality) {
+		return fmt.Errorf("invalid quality %q", sv.Quality)
+	}
+
+	if len(sv.Headers) > maxSourceHeaders {
+		return fmt.Errorf("at most %d headers are allowed", maxSourceHeaders)
+	}
+	for name, value := range sv.Headers {
+		if !headerNamePattern.MatchString(name) || forbiddenHeaderSet[strings.ToLower(name)] {
+			return fmt.Errorf("header %q is not allowed", name)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("header %q has an invalid value", name)
+		}
+	}
+
+	sv.Provider = strings.ToLower(strings.TrimSpace(sv.Provider))
+	if sv.Provider == "" {
+		sv.Provider = domain.ProviderFromHost(host)
+	}
+	return nil
+}
+
+// hostAllowed matches host against allowlist entries and their subdomains.
+// A "*" entry allows every host.
+func hostAllowed(host string, allowlist []string) bool {
+	for _, allowed := range allowlist {
+		if allowed == "*" || host == allowed || strings.HasSuffix(host, "."+allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// legacyVideoURLs renders servers in the legacy Episode.VideoURLs format
+func legacyVideoURLs(servers []domain.EpisodeServer) string {
+	if len(servers) == 0 {
+		return ""
+	}
+	legacy := make([]domain.LegacyVideoURL, 0, len(servers))
+	for _, sv := range servers {
+		legacy = append(legacy, domain.LegacyVideoURL{URL: sv.URL, Type: sv.Language, Name: sv.Name})
+	}
+	data, _ := json.Marshal(legacy)
+	return string(data)
+}
+
+// localeRank is the position of language in the locale chain, a regional language
+// matching its base locale, or len(locales) when the chain doesn't include it
+func localeRank(locales []string, language string) int {
+	for i, locale := range locales {
+		if language == locale || strings.HasPrefix(language, locale+"-") {
+			return i
+		}
+	}
+	return len(locales)
+}
+
+// orderSources sorts servers by the position of their language in the locale
+// chain, then by descending priority. Broken servers go last and servers without a
+// language come after the requested ones.
+func orderSources(locales []string, servers []domain.EpisodeServer) {
+	broken := func(sv domain.EpisodeServer) bool {
+		return sv.Health != nil && sv.Health.Status == domain.ServerHealthBroken
+	}
+	sort.SliceStable(servers, func(i, j int) bool {
+		if bi, bj := broken(servers[i]), broken(servers[j]); bi != bj {
+			return bj
+		}
+		ri, rj := localeRank(locales, servers[i].Language), localeRank(locales, servers[j].Language)
+		if ri != rj {
+			return ri < rj
+		}
+		if servers[i].Priority != servers[j].Priority {
+			return servers[i].Priority > servers[j].Priority
+		}
+		return servers[i].ID < servers[j].ID
+	})
+}
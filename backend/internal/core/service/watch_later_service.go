@@ -1,44 +1,82 @@
 package service
 
 import (
+	"backend/internal/adapters/repository"
 	"backend/internal/core/domain"
-	"backend/internal/core/port"
-	"time"
+	"errors"
+
+	"gorm.io/gorm"
 )
 
+// WatchLaterService keeps the old watch later endpoints working on top of the
+// anime list: saving is a plan_to_watch entry, and saving an episode saves its
+// anime. Anime tracked with another status count as saved and are left alone
+// by Toggle.
 type WatchLaterService struct {
-	repo port.WatchLaterRepository
+	repo  *repository.SQLiteRepository
+	lists *AnimeListService
+}
+
+func NewWatchLaterService(repo *repository.SQLiteRepository, lists *AnimeListService) *WatchLaterService {
+	return &WatchLaterService{repo: repo, lists: lists}
 }
 
-func NewWatchLaterService(repo port.WatchLaterRepository) *WatchLaterService {
-	return &WatchLaterService{repo: repo}
+// animeOf resolves the anime a watch later request is about
+func (s *WatchLaterService) animeOf(animeID *uint, episodeID *uint) (uint, error) {
+	if animeID != nil {
+		return *animeID, nil
+	}
+	id, err := s.repo.GetEpisodeAnimeID(*episodeID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, ErrEpisodeNotFound
+	}
+	return id, err
 }
 
 func (s *WatchLaterService) Toggle(userID uint, animeID *uint, episodeID *uint) (bool, error) {
-	exists, err := s.repo.IsWatchLater(userID, animeID, episodeID)
+	id, err := s.animeOf(animeID, episodeID)
 	if err != nil {
 		return false, err
 	}
-
-	if exists {
-		err = s.repo.RemoveFromWatchLater(userID, animeID, episodeID)
+	entry, err := s.repo.GetListEntry(userID, id)
+	if err != nil {
 		return false, err
-	} else {
-		entry := &domain.WatchLater{
-			UserID:    userID,
-			AnimeID:   animeID,
-			EpisodeID: episodeID,
-			CreatedAt: time.Now(),
-		}
-		err = s.repo.AddToWatchLater(entry)
+	}
+
+	switch {
+	case entry == nil:
+		status := domain.ListStatusPlanToWatch
+		_, _, err = s.lists.Save(userID, id, domain.AnimeListUpdate{Status: &status})
 		return true, err // true means added
+	case entry.Status == domain.ListStatusPlanToWatch:
+		return false, s.lists.Remove(userID, id)
+	default:
+		return true, nil
 	}
 }
 
+// GetByUser returns the plan_to_watch entries in the legacy watch later shape
 func (s *WatchLaterService) GetByUser(userID uint) ([]domain.WatchLater, error) {
-	return s.repo.GetWatchLaterByUser(userID)
+	entries, _, err := s.repo.GetListEntries(userID, domain.AnimeListFilter{Status: domain.ListStatusPlanToWatch, Limit: -1})
+	if err != nil {
+		return nil, err
+	}
+	items := make([]domain.WatchLater, len(entries))
+	for i, entry := range entries {
+		animeID := entry.AnimeID
+		items[i] = domain.WatchLater{ID: entry.ID, UserID: userID, AnimeID: &animeID, Anime: entry.Anime, CreatedAt: entry.CreatedAt}
+	}
+	return items, nil
 }
 
 func (s *WatchLaterService) IsSaved(userID uint, animeID *uint, episodeID *uint) (bool, error) {
-	return s.repo.IsWatchLater(userID, animeID, episodeID)
+	id, err := s.animeOf(animeID, episodeID)
+	if errors.Is(err, ErrEpisodeNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	entry, err := s.repo.GetListEntry(userID, id)
+	return entry != nil, err
 }
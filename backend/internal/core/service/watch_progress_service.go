@@ -0,0 +1,349 @@
+package service
+
+import (
+	"backend/internal/adapters/repository"
+	"backend/internal/core/domain"
+	"errors"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// continueWatchingScan is how many recent progress rows and episode views are
+// read to build the continue-watching rail
+const continueWatchingScan = 1000
+
+// progressKey identifies the progress of a user on an episode
+type progressKey struct {
+	userID, episodeID uint
+}
+
+// progressEntry is the latest progress reported for a key and when it was last written
+type progressEntry struct {
+	progress domain.WatchProgress
+	written  time.Time
+	dirty    bool
+}
+
+// WatchProgressService records where users stop in episodes. Players report
+// every few seconds, so a key is written at most once per throttle interval;
+// reports in between are held in memory, served by reads and flushed by
+// RunFlush and on shutdown. Reaching the completion threshold is always written
+// at once. With several replicas, each holds the reports it received: reads on
+// another replica lag by at most one throttle interval, and a late flush never
+// overwrites a newer report.
+type WatchProgressService struct {
+	repo      *repository.SQLiteRepository
+	threshold float64 // Percent watched that marks an episode completed
+	throttle  time.Duration
+
+	mu     sync.Mutex
+	recent map[progressKey]*progressEntry
+}
+
+func NewWatchProgressService(repo *repository.SQLiteRepository, thresholdPercent int, throttle time.Duration) *WatchProgressService {
+	if thresholdPercent <= 0 || thresholdPercent > 100 {
+		thresholdPercent = 90
+	}
+	return &WatchProgressService{
+		repo:      repo,
+		threshold: float64(thresholdPercent),
+		throttle:  throttle,
+		recent:    map[progressKey]*progressEntry{},
+	}
+}
+
+// Save records the position of a user in an episode, both in seconds
+func (s *WatchProgressService) Save(userID, episodeID uint, position, duration float64) (*domain.WatchProgress, error) {
+	if position < 0 || duration <= 0 {
+		return nil, domain.ErrInvalidProgress
+	}
+	position = min(position, duration)
+	key := progressKey{userID, episodeID}
+
+	s.mu.Lock()
+	entry, ok := s.recent[key]
+	s.mu.Unlock()
+	animeID := uint(0)
+	if ok {
+		animeID = entry.progress.AnimeID
+	} else {
+		id, err := s.repo.GetPublishedEpisodeAnimeID(episodeID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEpisodeNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		animeID = id
+	}
+
+	now := time.Now()
+	progress := domain.WatchProgress{
+		UserID:    userID,
+		EpisodeID: episodeID,
+		AnimeID:   animeID,
+		Position:  position,
+		Duration:  duration,
+		UpdatedAt: now,
+	}
+	if progress.Percent() >= s.threshold {
+		progress.Completed = true
+		progress.CompletedAt = &now
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok = s.recent[key]
+	if ok && entry.progress.Completed {
+		progress.Completed = true
+		progress.CompletedAt = entry.progress.CompletedAt
+	}
+	newlyCompleted := progress.Completed && (!ok || !entry.progress.Completed)
+	if ok && !newlyCompleted && now.Sub(entry.written) < s.throttle {
+		progress.ID = entry.progress.ID
+		progress.CreatedAt = entry.progress.CreatedAt
+		entry.progress = progress
+		entry.dirty = true
+		return &progress, nil
+	}
+
+	if err := s.repo.SaveWatchProgress(&progress); err != nil {
+		return nil, err
+	}
+	s.recent[key] = &progressEntry{progress: progress, written: now}
+	return &progress, nil
+}
+
+// ByAnime returns the progress of a user on every episode of an anime they
+// started, including reports not written yet
+func (s *WatchProgressService) ByAnime(userID, animeID uint) ([]domain.WatchProgress, error) {
+	rows, err := s.repo.GetWatchProgressByAnime(userID, animeID)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range rows {
+		if entry, ok := s.recent[progressKey{userID, rows[i].EpisodeID}]; ok && entry.dirty {
+			entry.progress.ID = rows[i].ID
+			entry.progress.CreatedAt = rows[i].CreatedAt
+			entry.progress.Completed = entry.progress.Completed || rows[i].Completed
+			rows[i] = entry.progress
+		}
+	}
+	return rows, nil
+}
+
+// ContinueWatching returns one entry per series the user has been watching,
+// latest first: the episode they stopped in, or else the next one they have
+// not finished. Series with nothing left to watch are left out, as are those
+// the user marked completed or dropped on their list unless watched since.
+func (s *WatchProgressService) ContinueWatching(userID uint, limit int) ([]domain.ContinueWatchingEntry, error) {
+	rows, err := s.repo.GetRecentWatchProgress(userID, continueWatchingScan)
+	if err != nil {
+		return nil, err
+	}
+	views, err := s.repo.GetRecentEpisodeViews(userID, continueWatchingScan)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := make(map[uint]domain.WatchProgress, len(rows))
+	for _, row := range rows {
+		progress[row.EpisodeID] = row
+	}
+	s.mu.Lock()
+	for key, entry := range s.recent {
+		if key.userID == userID && entry.dirty {
+			held := entry.progress
+			held.Completed = held.Completed || progress[key.episodeID].Completed
+			progress[key.episodeID] = held
+		}
+	}
+	s.mu.Unlock()
+
+	type seriesActivity struct {
+		animeID   uint
+		episodeID uint
+		at        time.Time
+	}
+	activity := map[uint]*seriesActivity{}
+	touch := func(animeID, episodeID uint, at time.Time) {
+		if a, ok := activity[animeID]; !ok || at.After(a.at) {
+			activity[animeID] = &seriesActivity{animeID: animeID, episodeID: episodeID, at: at}
+		}
+	}
+	for _, p := range progress {
+		touch(p.AnimeID, p.EpisodeID, p.UpdatedAt)
+	}
+	// Views from before the user's first progress report stand in for it
+	var trackedSince time.Time
+	for _, row := range rows {
+		if trackedSince.IsZero() || row.CreatedAt.Before(trackedSince) {
+			trackedSince = row.CreatedAt
+		}
+	}
+	viewed := map[uint]bool{}
+	for _, v := range views {
+		touch(*v.AnimeID, *v.EpisodeID, v.CreatedAt)
+		if trackedSince.IsZero() || v.CreatedAt.Before(trackedSince) {
+			viewed[*v.EpisodeID] = true
+		}
+	}
+	if len(activity) == 0 {
+		return []domain.ContinueWatchingEntry{}, nil
+	}
+
+	recent := make([]*seriesActivity, 0, len(activity))
+	ids := make([]uint, 0, len(activity))
+	for _, a := range activity {
+		recent = append(recent, a)
+		ids = append(ids, a.animeID)
+	}
+	sort.Slice(recent, func(i, j int) bool { return recent[i].at.After(recent[j].at) })
+
+	animes, err := s.repo.GetPublishedAnimesByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	animeByID := make(map[uint]*domain.Anime, len(animes))
+	for i := range animes {
+		animeByID[animes[i].ID] = &animes[i]
+	}
+	episodes, err := s.repo.GetPlayableEpisodes(ids)
+	if err != nil {
+		return nil, err
+	}
+	episodesByAnime := map[uint][]domain.Episode{}
+	for _, ep := range episodes {
+		episodesByAnime[ep.AnimeID] = append(episodesByAnime[ep.AnimeID], ep)
+	}
+	listed, err := s.repo.GetListEntriesByAnime(userID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	done := func(episodeID uint) bool {
+		if p, ok := progress[episodeID]; ok {
+			return p.Completed
+		}
+		return viewed[episodeID]
+	}
+	entries := []domain.ContinueWatchingEntry{}
+	for _, a := range recent {
+		anime, eps := animeByID[a.animeID], episodesByAnime[a.animeID]
+		if anime == nil || len(eps) == 0 {
+			continue
+		}
+		if entry, ok := listed[a.animeID]; ok && !a.at.After(entry.UpdatedAt) &&
+			(entry.Status == domain.ListStatusCompleted || entry.Status == domain.ListStatusDropped) {
+			continue
+		}
+
+		last, watched := -1, 0
+		for i, ep := range eps {
+			if ep.ID == a.episodeID {
+				last = i
+			}
+			if done(ep.ID) {
+				watched++
+			}
+		}
+		entry := domain.ContinueWatchingEntry{Anime: anime, EpisodesWatched: watched, EpisodesTotal: len(eps), LastWatchedAt: a.at}
+		next := -1
+		if last >= 0 && !done(a.episodeID) {
+			// Stopped in it, or opened it without reporting progress yet
+			next = last
+			entry.Resume = true
+			entry.Position = progress[a.episodeID].Position
+			entry.Duration = progress[a.episodeID].Duration
+		} else {
+			for i := last + 1; i < len(eps); i++ {
+				if !done(eps[i].ID) {
+					next = i
+					break
+				}
+			}
+		}
+		if next < 0 {
+			continue // Caught up
+		}
+		ep := eps[next]
+		entry.Episode = domain.ContinueWatchingEpisode{
+			ID:            ep.ID,
+			EpisodeNumber: ep.EpisodeNumber,
+			Title:         ep.Title,
+			TitleEn:       ep.TitleEn,
+			Slug:          ep.Slug,
+			Thumbnail:     ep.Thumbnail,
+			Duration:      ep.Duration,
+		}
+		entries = append(entries, entry)
+		if limit > 0 && len(entries) >= limit {
+			break
+		}
+	}
+	return entries, nil
+}
+
+// Flush writes held reports and forgets keys idle for longer than the throttle.
+// Reports that fail to save are held again for the next flush.
+func (s *WatchProgressService) Flush() error {
+	s.mu.Lock()
+	var pending []domain.WatchProgress
+	now := time.Now()
+	for key, entry := range s.recent {
+		if entry.dirty {
+			pending = append(pending, entry.progress)
+			entry.dirty = false
+			entry.written = now
+		} else if now.Sub(entry.written) >= s.throttle {
+			delete(s.recent, key)
+		}
+	}
+	s.mu.Unlock()
+
+	var errs []error
+	for i := range pending {
+		if err := s.repo.SaveWatchProgress(&pending[i]); err != nil {
+			errs = append(errs, err)
+			s.hold(pending[i])
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// hold marks a report as not written yet, unless a newer one arrived meanwhile
+func (s *WatchProgressService) hold(progress domain.WatchProgress) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := progressKey{progress.UserID, progress.EpisodeID}
+	entry, ok := s.recent[key]
+	if !ok {
+		s.recent[key] = &progressEntry{progress: progress, written: time.Now(), dirty: true}
+		return
+	}
+	if !entry.progress.UpdatedAt.After(progress.UpdatedAt) {
+		entry.progress = progress
+	}
+	entry.dirty = true
+}
+
+// RunFlush flushes held reports every throttle interval until the process exits
+func (s *WatchProgressService) RunFlush() {
+	if s.throttle <= 0 {
+		return
+	}
+	ticker := time.NewTicker(s.throttle)
+	defer ticker.Stop()
+	for {
+		<-ticker.C
+		if err := s.Flush(); err != nil {
+			log.Printf("Watch progress: flush failed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,368 @@
+package service
+
+import (
+	"backend/internal/adapters/repository"
+	"backend/internal/core/domain"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newProgressTestRepo(t *testing.T) *repository.SQLiteRepository {
+	t.Helper()
+	repo, err := repository.NewSQLiteRepository("file:" + filepath.Join(t.TempDir(), "progress.db") + "?_busy_timeout=5000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+// seedAnime creates an anime with episodes numbered 1..episodes and returns their IDs
+func seedAnime(t *testing.T, repo *repository.SQLiteRepository, state domain.ContentState, episodes int) (uint, []uint) {
+	t.Helper()
+	var count int64
+	repo.DB().Model(&domain.Anime{}).Count(&count)
+	anime := domain.Anime{Title: fmt.Sprintf("Anime %d", count+1), Slug: fmt.Sprintf("anime-%d", count+1), State: state}
+	if err := repo.DB().Create(&anime).Error; err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]uint, episodes)
+	for i := range ids {
+		episode := domain.Episode{AnimeID: anime.ID, EpisodeNumber: i + 1, Title: fmt.Sprintf("Episode %d", i+1), State: domain.ContentStatePublished}
+		if err := repo.DB().Create(&episode).Error; err != nil {
+			t.Fatal(err)
+		}
+		ids[i] = episode.ID
+	}
+	return anime.ID, ids
+}
+
+func storedProgress(t *testing.T, repo *repository.SQLiteRepository, userID, episodeID uint) domain.WatchProgress {
+	t.Helper()
+	var progress domain.WatchProgress
+	if err := repo.DB().Where("user_id = ? AND episode_id = ?", userID, episodeID).First(&progress).Error; err != nil {
+		t.Fatal(err)
+	}
+	return progress
+}
+
+func TestWatchProgressSaveThrottles(t *testing.T) {
+	repo := newProgressTestRepo(t)
+	animeID, episodes := seedAnime(t, repo, domain.ContentStatePublished, 1)
+	s := NewWatchProgressService(repo, 90, time.Hour)
+	key := progressKey{1, episodes[0]}
+
+	steps := []struct {
+		name      string
+		position  float64
+		idle      bool    // The throttle interval passed since the last write
+		stored    float64 // Position in the database afterwards
+		completed bool
+	}{
+		{"first report is written", 10, false, 10, false},
+		{"report within the interval is held", 20, false, 10, false},
+		{"report after the interval is written", 30, true, 30, false},
+		{"completion is written at once", 95, false, 95, true},
+		{"rewinding is held and stays completed", 40, false, 95, true},
+		{"past the duration is clamped", 150, true, 100, true},
+	}
+	for _, step := range steps {
+		if step.idle {
+			s.recent[key].written = time.Now().Add(-time.Hour)
+		}
+		progress, err := s.Save(1, episodes[0], step.position, 100)
+		if err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if progress.AnimeID != animeID || progress.Completed != step.completed || progress.Position != min(step.position, 100) {
+			t.Errorf("%s: returned %+v", step.name, progress)
+		}
+		stored := storedProgress(t, repo, 1, episodes[0])
+		if stored.Position != step.stored || stored.Completed != step.completed {
+			t.Errorf("%s: stored position %v completed %v, want %v %v", step.name, stored.Position, stored.Completed, step.stored, step.completed)
+		}
+
+		// Reads see held reports
+		rows, err := s.ByAnime(1, animeID)
+		if err != nil || len(rows) != 1 {
+			t.Fatalf("%s: ByAnime = %v, %v", step.name, rows, err)
+		}
+		if rows[0].Position != min(step.position, 100) {
+			t.Errorf("%s: ByAnime position %v, want %v", step.name, rows[0].Position, step.position)
+		}
+	}
+}
+
+func TestWatchProgressSaveRejects(t *testing.T) {
+	repo := newProgressTestRepo(t)
+	_, published := seedAnime(t, repo, domain.ContentStatePublished, 1)
+	_, inDraftAnime := seedAnime(t, repo, domain.ContentStateDraft, 1)
+	draft := domain.Episode{AnimeID: 1, EpisodeNumber: 2, State: domain.ContentStateDraft}
+	if err := repo.DB().Create(&draft).Error; err != nil {
+		t.Fatal(err)
+	}
+	s := NewWatchProgressService(repo, 90, time.Hour)
+
+	tests := []struct {
+		name      string
+		episodeID uint
+		position  float64
+		duration  float64
+		err       error
+	}{
+		{"negative position", published[0], -1, 100, domain.ErrInvalidProgress},
+		{"no duration", published[0], 10, 0, domain.ErrInvalidProgress},
+		{"missing episode", 999, 10, 100, ErrEpisodeNotFound},
+		{"draft episode", draft.ID, 10, 100, ErrEpisodeNotFound},
+		{"episode of a draft anime", inDraftAnime[0], 10, 100, ErrEpisodeNotFound},
+	}
+	for _, tt := range tests {
+		if _, err := s.Save(1, tt.episodeID, tt.position, tt.duration); !errors.Is(err, tt.err) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.err)
+		}
+	}
+}
+
+func TestWatchProgressFlush(t *testing.T) {
+	repo := newProgressTestRepo(t)
+	_, episodes := seedAnime(t, repo, domain.ContentStatePublished, 1)
+	s := NewWatchProgressService(repo, 90, time.Hour)
+
+	if _, err := s.Save(1, episodes[0], 10, 100); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Save(1, episodes[0], 20, 100); err != nil {
+		t.Fatal(err)
+	}
+
+	// A failed flush holds the report for the next one
+	if err := repo.DB().Migrator().RenameTable("watch_progresses", "watch_progresses_away"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Flush(); err == nil {
+		t.Fatal("Flush succeeded without the table")
+	}
+	if err := repo.DB().Migrator().RenameTable("watch_progresses_away", "watch_progresses"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if stored := storedProgress(t, repo, 1, episodes[0]); stored.Position != 20 {
+		t.Errorf("stored position %v, want 20", stored.Position)
+	}
+
+	// Idle keys are forgotten by the next flush
+	s.recent[progressKey{1, episodes[0]}].written = time.Now().Add(-time.Hour)
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.recent) != 0 {
+		t.Errorf("%d keys held after they went idle", len(s.recent))
+	}
+}
+
+func TestWatchProgressLateFlushKeepsNewerReport(t *testing.T) {
+	repo := newProgressTestRepo(t)
+	_, episodes := seedAnime(t, repo, domain.ContentStatePublished, 1)
+	a := NewWatchProgressService(repo, 90, time.Hour)
+	b := NewWatchProgressService(repo, 90, time.Hour)
+
+	// Replica a holds a report while the player moves on to replica b
+	if _, err := a.Save(1, episodes[0], 10, 100); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Save(1, episodes[0], 20, 100); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Save(1, episodes[0], 30, 100); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if stored := storedProgress(t, repo, 1, episodes[0]); stored.Position != 30 {
+		t.Errorf("stored position %v, want the newer 30", stored.Position)
+	}
+}
+
+func TestContinueWatching(t *testing.T) {
+	type report struct {
+		episode  int // Index into the anime's episodes
+		position float64
+		ago      time.Duration
+	}
+	type want struct {
+		episode  int
+		resume   bool
+		position float64
+		watched  int
+	}
+	tests := []struct {
+		name     string
+		progress []report
+		views    []report          // Episode views, position unused
+		imported []report          // Episode views carried over by a list import
+		list     domain.ListStatus // Status on the user's list, if any
+		listAgo  time.Duration
+		want     *want
+	}{
+		{
+			name:     "resumes the episode stopped in",
+			progress: []report{{0, 100, 3 * time.Hour}, {1, 30, time.Hour}},
+			want:     &want{episode: 1, resume: true, position: 30, watched: 1},
+		},
+		{
+			name:     "moves on after a completed episode",
+			progress: []report{{0, 95, time.Hour}},
+			want:     &want{episode: 1, watched: 1},
+		},
+		{
+			name:     "skips episodes completed earlier",
+			progress: []report{{2, 100, 2 * time.Hour}, {0, 100, time.Hour}},
+			want:     &want{episode: 1, watched: 2},
+		},
+		{
+			name:     "caught up",
+			progress: []report{{0, 100, 3 * time.Hour}, {1, 100, 2 * time.Hour}, {2, 100, time.Hour}},
+		},
+		{
+			name:  "views before any progress count as watched",
+			views: []report{{0, 0, 3 * time.Hour}, {1, 0, 2 * time.Hour}},
+			want:  &want{episode: 2, watched: 2},
+		},
+		{
+			name:     "imported views are not watched here",
+			imported: []report{{0, 0, time.Hour}},
+		},
+		{
+			name:     "a view after tracking started is a resume without a position",
+			progress: []report{{0, 100, 3 * time.Hour}},
+			views:    []report{{1, 0, time.Hour}},
+			want:     &want{episode: 1, resume: true, watched: 1},
+		},
+		{
+			name:     "completed on the list",
+			progress: []report{{0, 100, 2 * time.Hour}},
+			list:     domain.ListStatusCompleted,
+			listAgo:  time.Hour,
+		},
+		{
+			name:     "dropped on the list, then watched again",
+			progress: []report{{0, 100, time.Hour}},
+			list:     domain.ListStatusDropped,
+			listAgo:  2 * time.Hour,
+			want:     &want{episode: 1, watched: 1},
+		},
+		{
+			name:     "watching on the list",
+			progress: []report{{0, 100, 2 * time.Hour}},
+			list:     domain.ListStatusWatching,
+			listAgo:  time.Hour,
+			want:     &want{episode: 1, watched: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newProgressTestRepo(t)
+			animeID, episodes := seedAnime(t, repo, domain.ContentStatePublished, 3)
+			now := time.Now()
+			for _, r := range tt.progress {
+				at := now.Add(-r.ago)
+				p := domain.WatchProgress{UserID: 1, EpisodeID: episodes[r.episode], AnimeID: animeID, Position: r.position, Duration: 100, CreatedAt: at, UpdatedAt: at}
+				p.Completed = p.Percent() >= 90
+				if err := repo.SaveWatchProgress(&p); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for _, v := range tt.views {
+				view := domain.History{UserID: 1, ActivityType: domain.ActivityEpisodeView, AnimeID: &animeID, EpisodeID: &episodes[v.episode], CreatedAt: now.Add(-v.ago)}
+				if err := repo.DB().Create(&view).Error; err != nil {
+					t.Fatal(err)
+				}
+			}
+			for _, v := range tt.imported {
+				view := domain.History{UserID: 1, ActivityType: domain.ActivityEpisodeView, AnimeID: &animeID, EpisodeID: &episodes[v.episode], ImportSource: "mal", CreatedAt: now.Add(-v.ago)}
+				if err := repo.DB().Create(&view).Error; err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.list != "" {
+				at := now.Add(-tt.listAgo)
+				entry := domain.AnimeListEntry{UserID: 1, AnimeID: animeID, Status: tt.list, CreatedAt: at, UpdatedAt: at}
+				if err := repo.DB().Create(&entry).Error; err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			entries, err := NewWatchProgressService(repo, 90, time.Hour).ContinueWatching(1, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.want == nil {
+				if len(entries) != 0 {
+					t.Errorf("got %+v, want no entry", entries)
+				}
+				return
+			}
+			if len(entries) != 1 {
+				t.Fatalf("got %d entries, want 1", len(entries))
+			}
+			got := entries[0]
+			if got.Anime.ID != animeID || got.Episode.ID != episodes[tt.want.episode] || got.Resume != tt.want.resume ||
+				got.Position != tt.want.position || got.EpisodesWatched != tt.want.watched || got.EpisodesTotal != 3 {
+				t.Errorf("got episode %d resume %v at %v with %d/%d watched, want episode %d resume %v at %v with %d/3",
+					got.Episode.ID, got.Resume, got.Position, got.EpisodesWatched, got.EpisodesTotal,
+					episodes[tt.want.episode], tt.want.resume, tt.want.position, tt.want.watched)
+			}
+		})
+	}
+}
+
+func TestContinueWatchingOrderAndVisibility(t *testing.T) {
+	repo := newProgressTestRepo(t)
+	older, olderEpisodes := seedAnime(t, repo, domain.ContentStatePublished, 2)
+	newer, newerEpisodes := seedAnime(t, repo, domain.ContentStatePublished, 2)
+	hidden, hiddenEpisodes := seedAnime(t, repo, domain.ContentStateDraft, 2)
+	s := NewWatchProgressService(repo, 90, time.Hour)
+
+	now := time.Now()
+	for i, r := range []struct {
+		animeID, episodeID uint
+		ago                time.Duration
+	}{
+		{older, olderEpisodes[0], 3 * time.Hour},
+		{newer, newerEpisodes[0], 2 * time.Hour},
+		{hidden, hiddenEpisodes[0], time.Hour},
+	} {
+		at := now.Add(-r.ago)
+		p := domain.WatchProgress{UserID: 1, EpisodeID: r.episodeID, AnimeID: r.animeID, Position: 10, Duration: 100, CreatedAt: at, UpdatedAt: at}
+		if err := repo.SaveWatchProgress(&p); err != nil {
+			t.Fatalf("report %d: %v", i, err)
+		}
+	}
+	// A report held in memory makes the older series the latest
+	if _, err := s.Save(1, olderEpisodes[1], 5, 100); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Save(1, olderEpisodes[1], 50, 100); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := s.ContinueWatching(1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Anime.ID != older || entries[1].Anime.ID != newer {
+		t.Fatalf("got %d entries, want the older then the newer anime without the draft one", len(entries))
+	}
+	if entries[0].Episode.ID != olderEpisodes[1] || entries[0].Position != 50 {
+		t.Errorf("first entry at episode %d position %v, want the held report", entries[0].Episode.ID, entries[0].Position)
+	}
+
+	if limited, err := s.ContinueWatching(1, 1); err != nil || len(limited) != 1 {
+		t.Errorf("limit 1: got %d entries, %v", len(limited), err)
+	}
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"backend/internal/adapters/repository"
+	"backend/internal/core/domain"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+type WorkflowService struct {
+	repo      *repository.SQLiteRepository
+	notifRepo *repository.NotificationRepository
+}
+
+func NewWorkflowService(repo *repository.SQLiteRepository, notifRepo *repository.NotificationRepository) *WorkflowService {
+	return &WorkflowService{repo: repo, notifRepo: notifRepo}
+}
+
+func validateWorkflowEntity(entityType string) error {
+	if entityType != domain.WorkflowEntityAnime && entityType != domain.WorkflowEntityEpisode {
+		return fmt.Errorf("%w: unknown entity %q", domain.ErrContentNotFound, entityType)
+	}
+	return nil
+}
+
+// Can reports whether the user holds a permission key. The admin role holds every key.
+func (s *WorkflowService) Can(userID uint, role, key string) (bool, error) {
+	if strings.EqualFold(role, "admin") {
+		return true, nil
+	}
+	return s.repo.UserHasPermission(userID, key)
+}
+
+// InitialState is the state new content starts in: publishers publish directly,
+// everyone else starts a draft that has to go through review.
+func (s *WorkflowService) InitialState(userID uint, role string) domain.ContentState {
+	if ok, err := s.Can(userID, role, domain.PermissionContentPublish); err == nil && ok {
+		return domain.ContentStatePublished
+	}
+	return domain.ContentStateDraft
+}
+
+// CanEdit checks that the user may change content in its current state. Approved and
+// published content is live or about to go live, so editing, deleting or rolling it back
+// takes the publish permission, the same one that would have put the change online.
+func (s *WorkflowService) CanEdit(entityType string, id uint, userID uint, role string) error {
+	if err := validateWorkflowEntity(entityType); err != nil {
+		return err
+	}
+	state, _, err := s.repo.GetContentState(entityType, id)
+	if err != nil {
+		return err
+	}
+	if state != domain.ContentStateApproved && state != domain.ContentStatePublished {
+		return nil
+	}
+	if ok, err := s.Can(userID, role, domain.PermissionContentPublish); err != nil {
+		return err
+	} else if !ok {
+		return fmt.Errorf("%w: %s is required to change %s content", domain.ErrPermissionDenied, domain.PermissionContentPublish, state)
+	}
+	return nil
+}
+
+// Status returns the current state, the states the user may move to, and the history
+func (s *WorkflowService) Status(entityType string, id uint, userID uint, role string) (*domain.WorkflowStatus, error) {
+	if err := validateWorkflowEntity(entityType); err != nil {
+		return nil, err
+	}
+	state, _, err := s.repo.GetContentState(entityType, id)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := []domain.ContentState{}
+	for _, t := range domain.WorkflowTransitions {
+		if t.From != state {
+			continue
+		}
+		if ok, err := s.Can(userID, role, t.Permission); err != nil {
+			return nil, err
+		} else if ok {
+			allowed = append(allowed, t.To)
+		}
+	}
+
+	history, err := s.repo.GetContentTransitions(entityType, id)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.WorkflowStatus{EntityType: entityType, EntityID: id, State: state, Allowed: allowed, History: history}, nil
+}
+
+// Transition moves an anime or episode to another state if the workflow allows it and
+// the user holds the transition's permission, then notifies reviewers or the submitter.
+func (s *WorkflowService) Transition(entityType string, id uint, to domain.ContentState, comment string, userID uint, role string) (*domain.WorkflowStatus, error) {
+	if err := validateWorkflowEntity(entityType); err != nil {
+		return nil, err
+	}
+	from, title, err := s.repo.GetContentState(entityType, id)
+	if err != nil {
+		return nil, err
+	}
+	transition := domain.FindTransition(from, to)
+	if transition == nil {
+		return nil, fmt.Errorf("%w: %s → %s", domain.ErrTransitionNotAllowed, from, to)
+	}
+	if ok, err := s.Can(userID, role, transition.Permission); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, fmt.Errorf("%w: %s is required", domain.ErrPermissionDenied, transition.Permission)
+	}
+
+	// Look up the submitter before this transition possibly replaces the last submission
+	submission, err := s.repo.GetLastSubmission(entityType, id)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &domain.ContentTransition{EntityType: entityType, EntityID: id, From: from, To: to, Comment: comment, UserID: userID}
+	if err := s.repo.SetContentState(record); err != nil {
+		return nil, err
+	}
+
+	s.notify(record, title, submission)
+	return s.Status(entityType, id, userID, role)
+}
+
+// notify tells reviewers about new submissions and the submitter about every later decision
+func (s *WorkflowService) notify(t *domain.ContentTransition, title string, submission *domain.ContentTransition) {
+	var recipients []uint
+	if t.To == domain.ContentStateInReview {
+		ids, err := s.repo.GetUserIDsWithPermission(domain.PermissionContentReview)
+		if err != nil {
+			log.Printf("Workflow: failed to load reviewers: %v", err)
+			return
+		}
+		recipients = ids
+	} else if submission != nil {
+		recipients = []uint{submission.UserID}
+	}
+
+	data, _ := json.Marshal(map[string]interface{}{
+		"entity_type": t.EntityType,
+		"entity_id":   t.EntityID,
+		"title":       title,
+		"from":        t.From,
+		"to":          t.To,
+		"actor_id":    t.UserID,
+		"comment":     t.Comment,
+	})
+	for _, userID := range recipients {
+		if userID == t.UserID {
+			continue
+		}
+		if err := s.notifRepo.Create(&domain.Notification{UserID: userID, Type: domain.NotificationTypeWorkflow, Data: data}); err != nil {
+			log.Printf("Workflow: failed to notify user %d: %v", userID, err)
+		}
+	}
+}
+
+// ReviewQueue lists content waiting for review. Only reviewers may see it.
+func (s *WorkflowService) ReviewQueue(entityType string, userID uint, role string) ([]domain.ReviewQueueItem, error) {
+	if entityType != "" {
+		if err := validateWorkflowEntity(entityType); err != nil {
+			return nil, err
+		}
+	}
+	if ok, err := s.Can(userID, role, domain.PermissionContentReview); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, fmt.Errorf("%w: %s is required", domain.ErrPermissionDenied, domain.PermissionContentReview)
+	}
+
+	items, err := s.repo.GetReviewQueue(entityType)
+	if err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []domain.ReviewQueueItem{}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].SubmittedAt.Before(items[j].SubmittedAt) })
+	return items, nil
+}
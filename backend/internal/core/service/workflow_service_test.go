@@ -0,0 +1,144 @@
+package service
+
+import (
+	"backend/internal/adapters/repository"
+	"backend/internal/core/domain"
+	"backend/internal/migration"
+	"errors"
+	"testing"
+)
+
+// newWorkflowTestService creates users 1 (admin), 2 (editor: submit) and 3 (publisher: submit and publish)
+func newWorkflowTestService(t *testing.T) (*WorkflowService, *repository.SQLiteRepository) {
+	t.Helper()
+	repo := newProgressTestRepo(t)
+	if err := migration.SeedWorkflowPermissions(repo.DB()); err != nil {
+		t.Fatal(err)
+	}
+	roles := map[string][]string{
+		"admin":     nil,
+		"editor":    {domain.PermissionContentSubmit},
+		"publisher": {domain.PermissionContentSubmit, domain.PermissionContentPublish},
+	}
+	for i, name := range []string{"admin", "editor", "publisher"} {
+		var permissions []domain.Permission
+		if err := repo.DB().Where("key IN ?", roles[name]).Find(&permissions).Error; err != nil {
+			t.Fatal(err)
+		}
+		role := domain.Role{Name: name, Permissions: permissions}
+		if err := repo.DB().Create(&role).Error; err != nil {
+			t.Fatal(err)
+		}
+		user := domain.User{ID: uint(i + 1), Name: name, Email: name + "@example.com", Password: "x", RoleID: role.ID}
+		if err := repo.DB().Create(&user).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewWorkflowService(repo, repository.NewNotificationRepository(repo.DB())), repo
+}
+
+func TestWorkflowTransitionsCheckPermissions(t *testing.T) {
+	s, repo := newWorkflowTestService(t)
+	animeID, _ := seedAnime(t, repo, domain.ContentStateDraft, 0)
+
+	if _, err := s.Transition(domain.WorkflowEntityAnime, animeID, domain.ContentStateApproved, "", 2, "editor"); !errors.Is(err, domain.ErrTransitionNotAllowed) {
+		t.Fatalf("draft → approved = %v, want ErrTransitionNotAllowed", err)
+	}
+	if _, err := s.Transition(domain.WorkflowEntityAnime, animeID, domain.ContentStatePublished, "", 2, "editor"); !errors.Is(err, domain.ErrPermissionDenied) {
+		t.Fatalf("editor publishing a draft = %v, want ErrPermissionDenied", err)
+	}
+	status, err := s.Transition(domain.WorkflowEntityAnime, animeID, domain.ContentStateInReview, "please review", 2, "editor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.State != domain.ContentStateInReview || len(status.Allowed) != 0 || len(status.History) != 1 {
+		t.Fatalf("status after submitting = %+v, want in_review with nothing more allowed for the editor", status)
+	}
+
+	// The admin holds every key and the submitter hears about the decision
+	status, err = s.Transition(domain.WorkflowEntityAnime, animeID, domain.ContentStateApproved, "", 1, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.State != domain.ContentStateApproved {
+		t.Fatalf("state after approval = %s", status.State)
+	}
+	var notified int64
+	repo.DB().Model(&domain.Notification{}).Where("user_id = ? AND type = ?", 2, domain.NotificationTypeWorkflow).Count(&notified)
+	if notified != 1 {
+		t.Fatalf("submitter got %d workflow notifications, want 1", notified)
+	}
+	if _, err := s.Transition(domain.WorkflowEntityAnime, animeID, domain.ContentStatePublished, "", 3, "publisher"); err != nil {
+		t.Fatal(err)
+	}
+	if state, _, _ := repo.GetContentState(domain.WorkflowEntityAnime, animeID); state != domain.ContentStatePublished {
+		t.Fatalf("state after publishing = %s", state)
+	}
+}
+
+func TestWorkflowEpisodeTransitionsMirrorIsPublished(t *testing.T) {
+	s, repo := newWorkflowTestService(t)
+	_, episodes := seedAnime(t, repo, domain.ContentStatePublished, 1)
+
+	if _, err := s.Transition(domain.WorkflowEntityEpisode, episodes[0], domain.ContentStateDraft, "", 3, "publisher"); err != nil {
+		t.Fatal(err)
+	}
+	episode, err := repo.GetEpisodeByID(episodes[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if episode.State != domain.ContentStateDraft || episode.IsPublished {
+		t.Fatalf("unpublished episode state=%s is_published=%v", episode.State, episode.IsPublished)
+	}
+}
+
+func TestWorkflowCanEdit(t *testing.T) {
+	s, repo := newWorkflowTestService(t)
+	tests := []struct {
+		state  domain.ContentState
+		userID uint
+		role   string
+		ok     bool
+	}{
+		{domain.ContentStateDraft, 2, "editor", true},
+		{domain.ContentStateInReview, 2, "editor", true},
+		{domain.ContentStateApproved, 2, "editor", false},
+		{domain.ContentStatePublished, 2, "editor", false},
+		{domain.ContentStateArchived, 2, "editor", true},
+		{domain.ContentStateApproved, 3, "publisher", true},
+		{domain.ContentStatePublished, 3, "publisher", true},
+		{domain.ContentStatePublished, 1, "admin", true},
+	}
+	for _, tt := range tests {
+		animeID, episodes := seedAnime(t, repo, tt.state, 1)
+		if err := repo.DB().Model(&domain.Episode{}).Where("id = ?", episodes[0]).UpdateColumn("state", tt.state).Error; err != nil {
+			t.Fatal(err)
+		}
+		for _, target := range []struct {
+			entity string
+			id     uint
+		}{{domain.WorkflowEntityAnime, animeID}, {domain.WorkflowEntityEpisode, episodes[0]}} {
+			err := s.CanEdit(target.entity, target.id, tt.userID, tt.role)
+			if tt.ok && err != nil {
+				t.Errorf("%s editing a %s %s: %v", tt.role, tt.state, target.entity, err)
+			}
+			if !tt.ok && !errors.Is(err, domain.ErrPermissionDenied) {
+				t.Errorf("%s editing a %s %s = %v, want ErrPermissionDenied", tt.role, tt.state, target.entity, err)
+			}
+		}
+	}
+
+	if err := s.CanEdit(domain.WorkflowEntityAnime, 999, 1, "admin"); !errors.Is(err, domain.ErrContentNotFound) {
+		t.Fatalf("editing a missing anime = %v, want ErrContentNotFound", err)
+	}
+}
+
+func TestWorkflowInitialState(t *testing.T) {
+	s, _ := newWorkflowTestService(t)
+	if got := s.InitialState(2, "editor"); got != domain.ContentStateDraft {
+		t.Errorf("editor starts in %s, want draft", got)
+	}
+	if got := s.InitialState(3, "publisher"); got != domain.ContentStatePublished {
+		t.Errorf("publisher starts in %s, want published", got)
+	}
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"backend/internal/core/domain"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Locale resolves the locale chain of a request and stores it as "locales".
+// ?lang= (comma separated) wins over Accept-Language; region tags fall back to
+// their base language and domain.FallbackLocales closes the chain.
+func Locale() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var requested []string
+		if lang := c.Query("lang"); lang != "" {
+			requested = strings.Split(lang, ",")
+		} else {
+			requested = parseAcceptLanguage(c.GetHeader("Accept-Language"))
+		}
+		c.Set("locales", localeChain(requested))
+		c.Header("Vary", "Accept-Language")
+		c.Next()
+	}
+}
+
+func localeChain(requested []string) []string {
+	var chain []string
+	seen := map[string]bool{}
+	add := func(locale string) {
+		if !seen[locale] {
+			seen[locale] = true
+			chain = append(chain, locale)
+		}
+	}
+	for _, tag := range requested {
+		locale, err := domain.NormalizeLocale(tag)
+		if err != nil {
+			continue
+		}
+		add(locale)
+		if i := strings.IndexByte(locale, '-'); i > 0 {
+			add(locale[:i])
+		}
+	}
+	for _, locale := range domain.FallbackLocales {
+		add(locale)
+	}
+	return chain
+}
+
+// parseAcceptLanguage returns the tags of an Accept-Language header ordered by
+// quality, dropping wildcards and q=0 entries
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.TrimSpace(fields[0])
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if q > 0 {
+			tags = append(tags, weighted{tag, q})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	result := make([]string, len(tags))
+	for i, t := range tags {
+		result[i] = t.tag
+	}
+	return result
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		header string
+		want   []string
+	}{
+		{"", []string{}},
+		{"fr", []string{"fr"}},
+		{"fr-CH, fr;q=0.9, en;q=0.8, de;q=0.7, *;q=0.5", []string{"fr-CH", "fr", "en", "de"}},
+		{"en;q=0.5, ar", []string{"ar", "en"}},
+		{"de;q=0.8, ja;q=0.8, ko", []string{"ko", "de", "ja"}},
+		{"es;q=0, it", []string{"it"}},
+		{"pt-BR;q=bad, en;q=0.9", []string{"pt-BR", "en"}},
+		{" , ja ;q=0.3,", []string{"ja"}},
+	}
+	for _, tt := range tests {
+		if got := parseAcceptLanguage(tt.header); !slices.Equal(got, tt.want) {
+			t.Errorf("parseAcceptLanguage(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestLocaleChain(t *testing.T) {
+	tests := []struct {
+		name      string
+		requested []string
+		want      []string
+	}{
+		{"nothing requested", nil, []string{"en", "ar"}},
+		{"fallback not repeated", []string{"ar"}, []string{"ar", "en"}},
+		{"region falls back to language", []string{"pt_BR", "es"}, []string{"pt-br", "pt", "es", "en", "ar"}},
+		{"invalid tags skipped", []string{"not a locale", "FR"}, []string{"fr", "en", "ar"}},
+		{"duplicates dropped", []string{"en-GB", "en-US", "en"}, []string{"en-gb", "en", "en-us", "ar"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := localeChain(tt.requested); !slices.Equal(got, tt.want) {
+				t.Errorf("localeChain(%q) = %q, want %q", tt.requested, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocaleQueryWinsOverHeader(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		url    string
+		header string
+		want   []string
+	}{
+		{"/?lang=ja,fr", "de", []string{"ja", "fr", "en", "ar"}},
+		{"/", "de-AT, ar;q=0.5", []string{"de-at", "de", "ar", "en"}},
+		{"/?lang=", "ko", []string{"ko", "en", "ar"}},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, tt.url, nil)
+		c.Request.Header.Set("Accept-Language", tt.header)
+		Locale()(c)
+		if got := c.GetStringSlice("locales"); !slices.Equal(got, tt.want) {
+			t.Errorf("%s with %q: locales = %q, want %q", tt.url, tt.header, got, tt.want)
+		}
+		if vary := w.Header().Get("Vary"); vary != "Accept-Language" {
+			t.Errorf("Vary = %q", vary)
+		}
+	}
+}
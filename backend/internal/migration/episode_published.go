@@ -0,0 +1,15 @@
+package migration
+
+import (
+	"backend/internal/core/domain"
+
+	"gorm.io/gorm"
+)
+
+// SyncEpisodePublished sets the legacy is_published flag from the workflow state.
+// Episodes older than the workflow were all public and got the published state by
+// default, but kept whatever is_published they were created with.
+// It is run once, through Once.
+func SyncEpisodePublished(db *gorm.DB) error {
+	return db.Exec("UPDATE episodes SET is_published = (state = ?)", domain.ContentStatePublished).Error
+}
@@ -0,0 +1,47 @@
+package migration
+
+import (
+	"backend/internal/adapters/repository"
+	"backend/internal/core/domain"
+	"path/filepath"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func newMigrationTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	repo, err := repository.NewSQLiteRepository("file:" + filepath.Join(t.TempDir(), "migration.db") + "?_busy_timeout=5000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repo.DB()
+}
+
+func TestSyncEpisodePublished(t *testing.T) {
+	db := newMigrationTestDB(t)
+	episodes := []domain.Episode{
+		{AnimeID: 1, EpisodeNumber: 1, State: domain.ContentStatePublished, IsPublished: false}, // Older than the workflow
+		{AnimeID: 1, EpisodeNumber: 2, State: domain.ContentStateDraft, IsPublished: true},
+		{AnimeID: 1, EpisodeNumber: 3, State: domain.ContentStatePublished, IsPublished: true},
+	}
+	for i := range episodes {
+		if err := db.Create(&episodes[i]).Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Model(&episodes[i]).UpdateColumn("is_published", episodes[i].IsPublished).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := Once(db, "sync_episode_published", SyncEpisodePublished); err != nil {
+		t.Fatal(err)
+	}
+	var got []bool
+	if err := db.Model(&domain.Episode{}).Order("episode_number").Pluck("is_published", &got).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || !got[0] || got[1] || !got[2] {
+		t.Fatalf("is_published after the migration = %v, want [true false true]", got)
+	}
+}
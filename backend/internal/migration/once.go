@@ -0,0 +1,36 @@
+package migration
+
+import (
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// dataMigration records a one-off data migration that has been applied
+type dataMigration struct {
+	Name      string    `gorm:"primaryKey;size:100"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (dataMigration) TableName() string { return "data_migrations" }
+
+// Once runs the data migration fn unless name is already recorded in the
+// data_migrations table. The record is inserted in the same transaction as the
+// migration, so a failed run is retried on the next start and replicas starting
+// together wait on each other instead of converting the same rows twice.
+func Once(db *gorm.DB, name string, fn func(tx *gorm.DB) error) error {
+	if err := db.AutoMigrate(&dataMigration{}); err != nil {
+		return err
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&dataMigration{Name: name, AppliedAt: time.Now()})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		log.Printf("Running data migration %s", name)
+		return fn(tx)
+	})
+}
@@ -0,0 +1,39 @@
+package migration
+
+import (
+	"backend/internal/core/domain"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// BackfillUsernames gives every user without a handle one derived from their
+// name or email, in ID order so older accounts get the plain handle. Users in
+// the trash are included so a restored user keeps a unique handle.
+func BackfillUsernames(db *gorm.DB) error {
+	var users []domain.User
+	if err := db.Unscoped().Select("id", "name", "email").Where("username IS NULL").Order("id").Find(&users).Error; err != nil {
+		return err
+	}
+	for _, user := range users {
+		base := domain.UsernameBase(user.Name, user.Email)
+		for n := 1; ; n++ {
+			candidate := domain.UsernameCandidate(base, n)
+			var count int64
+			if err := db.Unscoped().Model(&domain.User{}).Where("username = ?", candidate).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				continue
+			}
+			if err := db.Unscoped().Model(&domain.User{}).Where("id = ?", user.ID).UpdateColumn("username", candidate).Error; err != nil {
+				return err
+			}
+			break
+		}
+	}
+	if len(users) > 0 {
+		log.Printf("Assigned usernames to %d users", len(users))
+	}
+	return nil
+}
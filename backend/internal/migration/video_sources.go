@@ -0,0 +1,118 @@
+package migration
+
+import (
+	"backend/internal/core/domain"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ConvertVideoURLs turns the legacy Episode.VideoURLs JSON into EpisodeServer rows for
+// episodes that have no servers yet, and fills Kind/Provider on older server rows.
+// It is run once, through Once.
+func ConvertVideoURLs(db *gorm.DB) error {
+	var servers []domain.EpisodeServer
+	if err := db.Where("kind IS NULL OR kind = ''").Find(&servers).Error; err != nil {
+		return err
+	}
+	for _, sv := range servers {
+		kind, provider := legacySourceKind(sv.URL, sv.Type)
+		if err := db.Model(&domain.EpisodeServer{}).Where("id = ?", sv.ID).
+			UpdateColumns(map[string]interface{}{"kind": kind, "type": string(kind), "provider": provider}).Error; err != nil {
+			return err
+		}
+	}
+	if len(servers) > 0 {
+		log.Printf("Filled kind on %d episode servers", len(servers))
+	}
+
+	var episodes []domain.Episode
+	err := db.Select("id", "video_urls").
+		Where("video_urls IS NOT NULL AND video_urls != ''").
+		Where("NOT EXISTS (SELECT 1 FROM episode_servers WHERE episode_servers.episode_id = episodes.id)").
+		Find(&episodes).Error
+	if err != nil {
+		return err
+	}
+
+	created := 0
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for _, ep := range episodes {
+			var rows []domain.EpisodeServer
+			for i, legacy := range parseLegacyVideoURLs(ep.VideoURLs) {
+				u, err := url.Parse(strings.TrimSpace(legacy.URL))
+				if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+					log.Printf("Skipping invalid video URL %q of episode %d", legacy.URL, ep.ID)
+					continue
+				}
+				kind, provider := legacySourceKind(u.String(), "")
+				language, _ := domain.NormalizeLocale(legacy.Type)
+				name := legacy.Name
+				if name == "" {
+					name = fmt.Sprintf("Server %d", i+1)
+				}
+				rows = append(rows, domain.EpisodeServer{
+					EpisodeID: ep.ID,
+					Language:  language,
+					Name:      name,
+					URL:       u.String(),
+					Type:      string(kind),
+					Kind:      kind,
+					Provider:  provider,
+				})
+			}
+			if len(rows) == 0 {
+				continue
+			}
+			if err := tx.Create(&rows).Error; err != nil {
+				return err
+			}
+			created += len(rows)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if created > 0 {
+		log.Printf("Converted legacy video URLs of %d episodes into %d servers", len(episodes), created)
+	}
+	return nil
+}
+
+// parseLegacyVideoURLs accepts the JSON array written by the admin panel, a single
+// object or a bare URL
+func parseLegacyVideoURLs(raw string) []domain.LegacyVideoURL {
+	raw = strings.TrimSpace(raw)
+	var list []domain.LegacyVideoURL
+	if err := json.Unmarshal([]byte(raw), &list); err == nil {
+		return list
+	}
+	var single domain.LegacyVideoURL
+	if err := json.Unmarshal([]byte(raw), &single); err == nil && single.URL != "" {
+		return []domain.LegacyVideoURL{single}
+	}
+	if strings.HasPrefix(raw, "http") {
+		return []domain.LegacyVideoURL{{URL: raw}}
+	}
+	return nil
+}
+
+func legacySourceKind(rawURL, legacyType string) (domain.SourceKind, string) {
+	kind, ok := domain.InferSourceKind(rawURL)
+	if !ok {
+		kind = domain.SourceKind(strings.ToLower(legacyType))
+		if !kind.IsValid() {
+			kind = domain.SourceKindEmbed
+		}
+	}
+	var provider string
+	if u, err := url.Parse(rawURL); err == nil && u.Hostname() != "" {
+		provider = domain.ProviderFromHost(u.Hostname())
+	}
+	return kind, provider
+}
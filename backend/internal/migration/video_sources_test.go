@@ -0,0 +1,76 @@
+package migration
+
+import (
+	"backend/internal/core/domain"
+	"testing"
+)
+
+func TestConvertVideoURLs(t *testing.T) {
+	db := newMigrationTestDB(t)
+	episodes := []domain.Episode{
+		{EpisodeNumber: 1, VideoURLs: `[{"url": "https://www.youtube.com/embed/abc", "type": "ar", "name": "Main"}, {"url": "https://cdn.example.com/e1/index.m3u8", "type": "EN"}]`},
+		{EpisodeNumber: 2, VideoURLs: `{"url": "https://cdn.example.com/e2.mp4"}`},
+		{EpisodeNumber: 3, VideoURLs: `https://player.bbc.co.uk/e3`},
+		{EpisodeNumber: 4, VideoURLs: `[{"url": "javascript:alert(1)"}, {"url": "/relative.mp4"}]`},
+		{EpisodeNumber: 5, VideoURLs: `[{"url": "https://cdn.example.com/e5.mp4"}]`}, // Already has a server
+	}
+	for i := range episodes {
+		episodes[i].AnimeID = 1
+		if err := db.Create(&episodes[i]).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A server saved before sources were typed
+	existing := domain.EpisodeServer{EpisodeID: episodes[4].ID, Name: "Old", URL: "https://cdn.example.com/e5.mpd", Type: "en"}
+	if err := db.Create(&existing).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Once(db, "convert_video_urls", ConvertVideoURLs); err != nil {
+		t.Fatal(err)
+	}
+	// A second start finds the migration recorded and converts nothing again
+	if err := Once(db, "convert_video_urls", ConvertVideoURLs); err != nil {
+		t.Fatal(err)
+	}
+
+	servers := map[uint][]domain.EpisodeServer{}
+	var rows []domain.EpisodeServer
+	if err := db.Order("id").Find(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, sv := range rows {
+		servers[sv.EpisodeID] = append(servers[sv.EpisodeID], sv)
+	}
+
+	tests := []struct {
+		episode int
+		want    []domain.EpisodeServer // Only Name, Language, Kind and Provider are compared
+	}{
+		{0, []domain.EpisodeServer{
+			{Name: "Main", Language: "ar", Kind: domain.SourceKindEmbed, Provider: "youtube"},
+			{Name: "Server 2", Language: "en", Kind: domain.SourceKindHLS, Provider: "example"},
+		}},
+		{1, []domain.EpisodeServer{{Name: "Server 1", Kind: domain.SourceKindDirect, Provider: "example"}}},
+		{2, []domain.EpisodeServer{{Name: "Server 1", Kind: domain.SourceKindEmbed, Provider: "bbc"}}},
+		{3, nil},
+		{4, []domain.EpisodeServer{{Name: "Old", Kind: domain.SourceKindDASH, Provider: "example"}}},
+	}
+	for _, tt := range tests {
+		got := servers[episodes[tt.episode].ID]
+		if len(got) != len(tt.want) {
+			t.Errorf("episode %d has %d servers, want %d", tt.episode+1, len(got), len(tt.want))
+			continue
+		}
+		for i, want := range tt.want {
+			sv := got[i]
+			if sv.Name != want.Name || sv.Language != want.Language || sv.Kind != want.Kind || sv.Provider != want.Provider {
+				t.Errorf("episode %d server %d = %s/%s/%s/%s, want %s/%s/%s/%s", tt.episode+1, i,
+					sv.Name, sv.Language, sv.Kind, sv.Provider, want.Name, want.Language, want.Kind, want.Provider)
+			}
+			if string(sv.Kind) != sv.Type {
+				t.Errorf("episode %d server %d type %q does not mirror kind %q", tt.episode+1, i, sv.Type, sv.Kind)
+			}
+		}
+	}
+}
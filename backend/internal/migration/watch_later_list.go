@@ -0,0 +1,42 @@
+package migration
+
+import (
+	"backend/internal/core/domain"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// watchLaterAnime resolves the anime of a watch later row; a saved episode saves
+// its anime. It is NULL when neither the anime nor the episode exists anymore.
+const watchLaterAnime = `FROM watch_later w
+	LEFT JOIN episodes e ON e.id = w.episode_id
+	LEFT JOIN animes a ON a.id = COALESCE(w.anime_id, e.anime_id)`
+
+// ConvertWatchLater turns watch later rows into plan_to_watch entries of the
+// anime list. Anime already on the user's list keep their entry. Converted rows
+// are deleted; rows whose anime is gone are logged and left in place. Run it
+// through Once.
+func ConvertWatchLater(tx *gorm.DB) error {
+	result := tx.Exec(`INSERT OR IGNORE INTO anime_list_entries
+			(user_id, anime_id, status, score, episodes_watched, rewatch_count, notes, created_at, updated_at)
+		SELECT w.user_id, a.id, ?, 0, 0, 0, '', MIN(w.created_at), MIN(w.created_at)
+		`+watchLaterAnime+`
+		WHERE a.id IS NOT NULL
+		GROUP BY w.user_id, a.id`, domain.ListStatusPlanToWatch)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Converted watch later to %d plan_to_watch list entries", result.RowsAffected)
+	}
+
+	var skipped []domain.WatchLater
+	if err := tx.Raw("SELECT w.* " + watchLaterAnime + " WHERE a.id IS NULL").Scan(&skipped).Error; err != nil {
+		return err
+	}
+	for _, row := range skipped {
+		log.Printf("Watch later %d of user %d kept: its anime no longer exists", row.ID, row.UserID)
+	}
+	return tx.Exec("DELETE FROM watch_later WHERE id IN (SELECT w.id " + watchLaterAnime + " WHERE a.id IS NOT NULL)").Error
+}
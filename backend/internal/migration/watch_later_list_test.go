@@ -0,0 +1,60 @@
+package migration
+
+import (
+	"backend/internal/core/domain"
+	"testing"
+	"time"
+)
+
+func TestConvertWatchLater(t *testing.T) {
+	db := newMigrationTestDB(t)
+	anime := []domain.Anime{{Title: "Saved", Slug: "saved"}, {Title: "Listed", Slug: "listed"}}
+	if err := db.Create(&anime).Error; err != nil {
+		t.Fatal(err)
+	}
+	episode := domain.Episode{AnimeID: anime[0].ID, EpisodeNumber: 1}
+	if err := db.Create(&episode).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&domain.AnimeListEntry{UserID: 1, AnimeID: anime[1].ID, Status: domain.ListStatusCompleted}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	first := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	gone := uint(999)
+	rows := []domain.WatchLater{
+		{UserID: 1, AnimeID: &anime[0].ID, CreatedAt: first.Add(time.Hour)},
+		{UserID: 1, EpisodeID: &episode.ID, CreatedAt: first}, // Same anime through its episode
+		{UserID: 1, AnimeID: &anime[1].ID, CreatedAt: first},  // Already on the list
+		{UserID: 2, AnimeID: &gone, CreatedAt: first},         // Anime no longer exists
+	}
+	if err := db.Create(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Once(db, "convert_watch_later", ConvertWatchLater); err != nil {
+		t.Fatal(err)
+	}
+
+	var entries []domain.AnimeListEntry
+	if err := db.Order("anime_id").Find(&entries).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d list entries, want 2", len(entries))
+	}
+	if saved := entries[0]; saved.AnimeID != anime[0].ID || saved.Status != domain.ListStatusPlanToWatch || !saved.CreatedAt.Equal(first) {
+		t.Errorf("converted entry = %+v, want plan_to_watch from the earliest save", saved)
+	}
+	if listed := entries[1]; listed.Status != domain.ListStatusCompleted {
+		t.Errorf("existing entry became %q, want it kept", listed.Status)
+	}
+
+	var left []domain.WatchLater
+	if err := db.Find(&left).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(left) != 1 || left[0].UserID != 2 {
+		t.Fatalf("watch later rows left = %+v, want only the one whose anime is gone", left)
+	}
+}
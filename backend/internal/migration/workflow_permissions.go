@@ -0,0 +1,24 @@
+package migration
+
+import (
+	"backend/internal/core/domain"
+
+	"gorm.io/gorm"
+)
+
+// SeedWorkflowPermissions makes sure the editorial workflow permission keys exist so they
+// can be assigned to roles (safe to run on every start)
+func SeedWorkflowPermissions(db *gorm.DB) error {
+	permissions := []domain.Permission{
+		{Key: domain.PermissionContentSubmit, Description: "Submit anime and episodes for review"},
+		{Key: domain.PermissionContentReview, Description: "Approve or send back content in review"},
+		{Key: domain.PermissionContentPublish, Description: "Publish and unpublish content"},
+		{Key: domain.PermissionContentArchive, Description: "Archive and reopen published content"},
+	}
+	for _, p := range permissions {
+		if err := db.Where(domain.Permission{Key: p.Key}).FirstOrCreate(&p).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}